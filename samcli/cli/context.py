@@ -13,6 +13,7 @@ from botocore import credentials
 import click
 
 from samcli.commands.exceptions import CredentialsError
+from samcli.lib.utils.colors import Colored
 from samcli.lib.utils.sam_logging import (
     LAMBDA_BULDERS_LOGGER_NAME,
     SamCliLogger,
@@ -41,6 +42,7 @@ class Context:
         Initialize the context with default values
         """
         self._debug = False
+        self._no_color = False
         self._aws_region = None
         self._aws_profile = None
         self._session_id = str(uuid.uuid4())
@@ -65,6 +67,22 @@ class Context:
             SamCliLogger.configure_logger(sam_cli_logger, SAM_CLI_FORMATTER_WITH_TIMESTAMP, logging.DEBUG)
             SamCliLogger.configure_logger(lambda_builders_logger, SAM_CLI_FORMATTER_WITH_TIMESTAMP, logging.DEBUG)
 
+    @property
+    def no_color(self):
+        return self._no_color
+
+    @no_color.setter
+    def no_color(self, value):
+        """
+        Turn off colored output if necessary.
+
+        :param value: Value of no color flag
+        """
+        self._no_color = value
+
+        if self._no_color:
+            Colored.disable_colors()
+
     @property
     def region(self):
         return self._aws_region
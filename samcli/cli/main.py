@@ -15,7 +15,7 @@ from samcli.lib.utils.sam_logging import (
     SAM_CLI_FORMATTER,
     SAM_CLI_LOGGER_NAME,
 )
-from .options import debug_option, region_option, profile_option
+from .options import debug_option, no_color_option, region_option, profile_option
 from .context import Context
 from .command import BaseCommand
 from .global_config import GlobalConfig
@@ -37,6 +37,7 @@ def common_options(f):
     :return: Callback function
     """
     f = debug_option(f)
+    f = no_color_option(f)
     return f
 
 
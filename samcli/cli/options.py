@@ -30,6 +30,31 @@ def debug_option(f):
     )(f)
 
 
+def no_color_option(f):
+    """
+    Configures --no-color option for CLI
+
+    :param f: Callback Function to be passed to Click
+    """
+
+    def callback(ctx, param, value):
+        state = ctx.ensure_object(Context)
+        state.no_color = value
+        if value:
+            # Also strips the colors of the messages Click prints
+            ctx.color = False
+        return value
+
+    return click.option(
+        "--no-color",
+        expose_value=False,
+        is_flag=True,
+        envvar="SAM_NO_COLOR",
+        help="Turn off colored output. Output is not colored by default when it is not written to a terminal.",
+        callback=callback,
+    )(f)
+
+
 def region_option(f):
     """
     Configures --region option for CLI
@@ -2,8 +2,10 @@
 Implementation of custom click parameter types
 """
 
+import ipaddress
 import re
 import json
+import shlex
 from json import JSONDecodeError
 
 import click
@@ -411,3 +413,174 @@ class ImageRepositoriesType(click.ParamType):
         if not is_ecr_url(_value):
             raise click.BadParameter(f"{param.opts[0]} needs to have valid ECR URI as value")
         return {key: _value}
+
+
+class DebugPortType(click.ParamType):
+    """
+    Custom Parameter Type for the debug port option. A value is either a single port, ex: 5858, or an inclusive
+    range of ports, ex: 5000-5010. A range is returned as a ``range`` object so callers can hand out a distinct
+    port to every function.
+    """
+
+    _RANGE_REGEX = re.compile(r"^(\d+)-(\d+)$")
+    _MAX_PORT = 65535
+
+    name = ""
+
+    def convert(self, value, param, ctx):
+        if isinstance(value, (int, range)):
+            return value
+
+        value = str(value).strip()
+        match = self._RANGE_REGEX.match(value)
+        if match:
+            start, end = int(match.group(1)), int(match.group(2))
+            if start > end:
+                raise click.BadParameter(f"{value} is not a valid port range, the start port must not exceed the end")
+            if start < 1 or end > self._MAX_PORT:
+                raise click.BadParameter(
+                    f"{value} is not a valid port range, ports need to be between 1 and {self._MAX_PORT}"
+                )
+            return range(start, end + 1)
+
+        try:
+            port = int(value)
+        except ValueError as ex:
+            raise click.BadParameter(
+                f"{value} is not a valid debug port, it needs to be a port (ex: 5858) or a range (ex: 5000-5010)"
+            ) from ex
+
+        if not 0 < port <= self._MAX_PORT:
+            raise click.BadParameter(
+                f"{value} is not a valid debug port, it needs to be between 1 and {self._MAX_PORT}"
+            )
+        return port
+
+
+class HostEntryType(click.ParamType):
+    """
+    Custom Parameter Type for the entries added to /etc/hosts of the containers, like the --add-host option of
+    docker run. A value is a hostname and an IP address separated by a colon, ex: db.local:192.168.1.10, and is
+    returned as a (hostname, ip) tuple. Like with Docker, the IP can be host-gateway to use the IP of the host.
+    """
+
+    _HOSTNAME_REGEX = re.compile(r"^[A-Za-z0-9]([A-Za-z0-9.-]*[A-Za-z0-9])?$")
+    _HOST_GATEWAY = "host-gateway"
+
+    name = ""
+
+    def convert(self, value, param, ctx):
+        if isinstance(value, tuple):
+            return value
+
+        # IPv6 addresses contain colons, so only the first colon separates the hostname
+        hostname, separator, ip = str(value).strip().partition(":")
+        if not separator or not self._HOSTNAME_REGEX.match(hostname):
+            raise click.BadParameter(
+                f"{value} is not a valid host entry, it needs to be hostname:ip (ex: db.local:10.0.0.2)"
+            )
+
+        if ip != self._HOST_GATEWAY:
+            try:
+                ipaddress.ip_address(ip.strip("[]"))
+            except ValueError as ex:
+                raise click.BadParameter(f"{value} is not a valid host entry, {ip} is not an IP address") from ex
+
+        return hostname, ip
+
+
+class ContainerLabelType(click.ParamType):
+    """
+    Custom Parameter Type for the labels of the containers, like the --label option of docker run. A value is a
+    label name and value separated by an equal sign, ex: team=serverless, and is returned as a (name, value) tuple.
+    The value can be empty, but not the name.
+    """
+
+    name = ""
+
+    def convert(self, value, param, ctx):
+        if isinstance(value, tuple):
+            return value
+
+        name, separator, label_value = str(value).partition("=")
+        if not separator or not name.strip():
+            raise click.BadParameter(f"{value} is not a valid label, it needs to be name=value (ex: team=serverless)")
+
+        return name.strip(), label_value
+
+
+class EntrypointType(click.ParamType):
+    """
+    Custom Parameter Type for the entrypoint of the containers, like the --entrypoint option of docker run. A value is
+    a command line, ex: "/opt/wrapper /var/rapid/aws-lambda-rie", split like a shell would split it, and is returned
+    as a list of arguments.
+    """
+
+    name = ""
+
+    def convert(self, value, param, ctx):
+        if isinstance(value, list):
+            entrypoint = value
+        else:
+            try:
+                entrypoint = shlex.split(str(value))
+            except ValueError as ex:
+                raise click.BadParameter(f"{value} is not a valid entrypoint: {ex}") from ex
+
+        if not entrypoint:
+            raise click.BadParameter("The entrypoint must not be empty")
+
+        return entrypoint
+
+
+class RuntimeImageType(click.ParamType):
+    """
+    Custom Parameter Type for the images that replace the default images of runtimes. A value is a runtime and an
+    image separated by an equal sign, ex: python3.8=registry.example.com/lambda-python3.8@sha256:..., and is returned
+    as a (runtime, image) tuple. The runtime must be one of the given runtimes.
+    """
+
+    name = ""
+
+    def __init__(self, runtimes):
+        self.runtimes = runtimes
+
+    def convert(self, value, param, ctx):
+        if isinstance(value, tuple):
+            return value
+
+        runtime, separator, image = str(value).strip().partition("=")
+        if not separator or not image:
+            raise click.BadParameter(
+                f"{value} is not a valid runtime image, it needs to be runtime=image "
+                "(ex: python3.8=registry.example.com/lambda-python3.8:latest)"
+            )
+
+        if runtime not in self.runtimes:
+            raise click.BadParameter(
+                f"{runtime} is not a supported runtime, it needs to be one of {', '.join(sorted(self.runtimes))}"
+            )
+
+        return runtime, image
+
+
+class JsonObjectType(click.ParamType):
+    """
+    Custom Parameter Type for values that are JSON objects, ex: {"message": "Hello"}, returned as a dictionary
+    """
+
+    name = ""
+
+    def convert(self, value, param, ctx):
+        if isinstance(value, dict):
+            return value
+
+        try:
+            result = json.loads(value)
+        except JSONDecodeError as ex:
+            raise click.BadParameter(f"{value} is not valid JSON: {ex}") from ex
+
+        if not isinstance(result, dict):
+            raise click.BadParameter(f"{value} is not a JSON object, it must look something like '{{\"key\": 1}}'")
+
+        return result
@@ -2,19 +2,29 @@
 Common CLI options shared by various commands
 """
 
+import atexit
 import os
 import logging
+import tempfile
 from functools import partial
 
 import click
 from click.types import FuncParamType
 
 from samcli.commands._utils.template import get_template_data, TemplateNotFoundException
+from samcli.commands._utils.remote_template import (
+    download_template,
+    is_remote_template_url,
+    RemoteTemplateDownloadException,
+)
 from samcli.cli.types import CfnParameterOverridesType, CfnMetadataType, CfnTags, SigningProfilesOptionType
 from samcli.commands._utils.custom_options.option_nargs import OptionNargs
 from samcli.commands._utils.template import get_template_artifacts_format
 
 _TEMPLATE_OPTION_DEFAULT_VALUE = "template.[yaml|yml]"
+_TEMPLATE_FILE_NAMES = ("template.yaml", "template.yml")
+# Value of the template option to read the template from stdin
+_TEMPLATE_STDIN_VALUE = "-"
 DEFAULT_STACK_NAME = "sam-app"
 
 LOG = logging.getLogger(__name__)
@@ -33,9 +43,25 @@ def get_or_default_template_file_name(ctx, param, provided_value, include_build)
     :return: Actual value to be used in the CLI
     """
 
+    if provided_value == _TEMPLATE_OPTION_DEFAULT_VALUE and getattr(ctx, "template_dir_template", None):
+        # A template was picked from the directory given with --template-dir
+        provided_value = getattr(ctx, "template_dir_template")
+
+    if provided_value == _TEMPLATE_STDIN_VALUE:
+        provided_value = _read_template_from_stdin()
+
+    # sam configuration file of a remote template is looked up in the current directory
+    samconfig_dir = os.getcwd() if is_remote_template_url(provided_value) else None
+
+    if samconfig_dir:
+        try:
+            provided_value = download_template(provided_value)
+        except RemoteTemplateDownloadException as ex:
+            raise click.BadParameter(str(ex), param_hint="'--template-file'") from ex
+
     original_template_path = os.path.abspath(provided_value)
 
-    search_paths = ["template.yaml", "template.yml"]
+    search_paths = list(_TEMPLATE_FILE_NAMES)
 
     if include_build:
         search_paths.insert(0, os.path.join(".aws-sam", "build", "template.yaml"))
@@ -60,7 +86,7 @@ def get_or_default_template_file_name(ctx, param, provided_value, include_build)
     if ctx:
         # sam configuration file should always be relative to the supplied original template and should not to be set
         # to be .aws-sam/build/
-        setattr(ctx, "samconfig_dir", os.path.dirname(original_template_path))
+        setattr(ctx, "samconfig_dir", samconfig_dir or os.path.dirname(original_template_path))
         try:
             # FIX-ME: figure out a way to insert this directly to sam-cli context and not use click context.
             template_data = get_template_data(result)
@@ -73,6 +99,84 @@ def get_or_default_template_file_name(ctx, param, provided_value, include_build)
     return result
 
 
+def _read_template_from_stdin():
+    """
+    Reads the template piped to stdin, and saves it to a temporary file in the current directory. The commands read
+    the template from a file, and resolve the CodeUri and other local paths of the template relative to its
+    directory, which must then be the current directory. The file is removed when the command exits.
+
+    :return: Path to the temporary file holding the template
+    """
+    template_body = click.get_text_stream("stdin").read()
+    if not template_body.strip():
+        raise click.BadParameter("No template was piped to stdin", param_hint="'--template-file'")
+
+    with tempfile.NamedTemporaryFile(
+        "w", prefix=".sam-stdin-template-", suffix=".yaml", dir=os.getcwd(), delete=False, encoding="utf-8"
+    ) as template_file:
+        template_file.write(template_body)
+
+    atexit.register(_remove_file, template_file.name)
+    LOG.debug("Saved the template read from stdin to %s", template_file.name)
+    return template_file.name
+
+
+def _remove_file(path):
+    try:
+        os.remove(path)
+    except OSError:
+        LOG.debug("Unable to remove %s", path, exc_info=True)
+
+
+def find_templates(template_dir):
+    """
+    Recursively finds the template.yaml/template.yml files under a directory, skipping hidden directories like
+    .aws-sam that contain build artifacts
+
+    :param template_dir: Directory to search
+    :return: Sorted list of paths to the templates found
+    """
+    templates = []
+    for root, dirs, files in os.walk(template_dir):
+        dirs[:] = [directory for directory in dirs if not directory.startswith(".") and directory != "node_modules"]
+        templates.extend(os.path.join(root, name) for name in files if name in _TEMPLATE_FILE_NAMES)
+
+    return sorted(templates)
+
+
+def get_template_from_template_dir(ctx, param, provided_value):
+    """
+    Picks the template to use from the ones found under the directory given with --template-dir. The user is prompted
+    to select one when there is more than one template. The selected template is used as the default value of the
+    template option, so a template given explicitly with --template-file takes precedence.
+
+    :param ctx: Click Context
+    :param param: Param name
+    :param provided_value: Directory provided by the user, if any
+    :return: Path to the selected template, or None if no directory was provided
+    """
+    if not provided_value or (ctx and ctx.params.get("template_file")):
+        return None
+
+    templates = find_templates(provided_value)
+    if not templates:
+        raise click.BadParameter("No template.yaml or template.yml found under {}".format(provided_value))
+
+    template = templates[0]
+    if len(templates) > 1:
+        click.echo("Templates found under {}:".format(provided_value))
+        for index, path in enumerate(templates, start=1):
+            click.echo("\t{} - {}".format(index, path))
+        choices = list(map(str, range(1, len(templates) + 1)))
+        choice = click.prompt("Template selection", type=click.Choice(choices), show_choices=False)
+        template = templates[int(choice) - 1]
+
+    LOG.debug("Using SAM Template %s found under %s", template, provided_value)
+    if ctx:
+        setattr(ctx, "template_dir_template", template)
+    return template
+
+
 def guided_deploy_stack_name(ctx, param, provided_value):
     """
     Provide a default value for stack name if invoked with a guided deploy.
@@ -217,9 +321,28 @@ def template_click_option(include_build=True):
         callback=partial(get_or_default_template_file_name, include_build=include_build),
         show_default=True,
         is_eager=True,
-        help="AWS SAM template which references built artifacts for resources in the template. (if applicable)"
+        help="AWS SAM template which references built artifacts for resources in the template. (if applicable) "
+        "Use - to read the template from stdin, with its local paths relative to the current directory, or a s3:// or "
+        "https:// URL to download the template."
         if include_build
-        else "AWS SAM template file.",
+        else "AWS SAM template file. Use - to read the template from stdin, with its local paths relative to the "
+        "current directory, or a s3:// or https:// URL to download the template.",
+    )
+
+
+def template_dir_click_option():
+    """
+    Click Option for template directory option
+    """
+    return click.option(
+        "--template-dir",
+        type=click.Path(exists=True, file_okay=False),
+        callback=get_template_from_template_dir,
+        is_eager=True,
+        expose_value=False,
+        help="Directory to search recursively for template.yaml/template.yml files, for repositories with many "
+        "services. When more than one template is found, you are prompted to select one. "
+        "Ignored when --template-file is given.",
     )
 
 
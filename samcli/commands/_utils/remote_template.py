@@ -0,0 +1,113 @@
+"""
+Downloads the templates given as s3:// or https:// URLs, so that the commands can read them like local templates
+"""
+import atexit
+import logging
+import os
+import shutil
+import tempfile
+from typing import Dict, Optional
+from urllib.parse import parse_qs, urlparse
+
+import boto3
+import requests
+from botocore.exceptions import BotoCoreError, ClientError
+
+from samcli.commands.exceptions import UserException
+
+LOG = logging.getLogger(__name__)
+
+REMOTE_TEMPLATE_SCHEMES = ("s3://", "https://")
+
+# Seconds to wait for the server of a https:// template to respond
+DOWNLOAD_TIMEOUT = 30
+
+# URL of each downloaded template, by path of the file it was downloaded to
+_downloaded_templates: Dict[str, str] = {}
+
+
+class RemoteTemplateDownloadException(UserException):
+    pass
+
+
+def is_remote_template_url(value: Optional[str]) -> bool:
+    """
+    Returns whether the value of --template-file is the URL of a remote template rather than a local path
+    """
+    return isinstance(value, str) and value.lower().startswith(REMOTE_TEMPLATE_SCHEMES)
+
+
+def download_template(url: str) -> str:
+    """
+    Downloads the template at the given URL to a temporary directory, which is removed when the command exits.
+    The template option is processed before --profile and --region, so templates in S3 are downloaded with the
+    default credentials and region, which can be set with the AWS_PROFILE and AWS_REGION environment variables.
+
+    Parameters
+    ----------
+    url str
+        s3://bucket/key or https:// URL of the template
+
+    Returns
+    -------
+    str
+        Path to the downloaded template
+
+    Raises
+    ------
+    RemoteTemplateDownloadException
+        When the template cannot be downloaded
+    """
+    if url.lower().startswith("s3://"):
+        template_body = _download_from_s3(url)
+    else:
+        template_body = _download_from_url(url)
+
+    template_dir = tempfile.mkdtemp(prefix="sam-remote-template-")
+    atexit.register(shutil.rmtree, template_dir, True)
+
+    # Keep the name of the template, so that its extension still tells JSON and YAML templates apart
+    template_name = os.path.basename(urlparse(url).path) or "template.yaml"
+    template_path = os.path.join(template_dir, template_name)
+    with open(template_path, "wb") as template_file:
+        template_file.write(template_body)
+
+    _downloaded_templates[template_path] = url
+    LOG.debug("Downloaded the template at %s to %s", url, template_path)
+    return template_path
+
+
+def get_remote_template_url(template_path: Optional[str]) -> Optional[str]:
+    """
+    Returns the URL the template at the given path was downloaded from, or None if it is a local template
+    """
+    if not template_path:
+        return None
+    return _downloaded_templates.get(os.path.abspath(template_path))
+
+
+def _download_from_s3(url: str) -> bytes:
+    parsed = urlparse(url)
+    if not parsed.netloc or not parsed.path.lstrip("/"):
+        raise RemoteTemplateDownloadException("{} is not a valid S3 url, expected s3://bucket/key".format(url))
+
+    location = {"Bucket": parsed.netloc, "Key": parsed.path.lstrip("/")}
+    version_ids = parse_qs(parsed.query).get("versionId")
+    if version_ids:
+        location["VersionId"] = version_ids[0]
+
+    try:
+        response = boto3.client("s3").get_object(**location)
+        return response["Body"].read()
+    except (BotoCoreError, ClientError) as ex:
+        raise RemoteTemplateDownloadException("Unable to download the template at {}: {}".format(url, ex)) from ex
+
+
+def _download_from_url(url: str) -> bytes:
+    try:
+        response = requests.get(url, timeout=DOWNLOAD_TIMEOUT, verify=os.environ.get("AWS_CA_BUNDLE", True))
+        response.raise_for_status()
+    except requests.exceptions.RequestException as ex:
+        raise RemoteTemplateDownloadException("Unable to download the template at {}: {}".format(url, ex)) from ex
+
+    return response.content
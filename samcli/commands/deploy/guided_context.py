@@ -85,9 +85,9 @@ class GuidedContext:
         self.signing_profiles = signing_profiles
         self._capabilities = None
         self._parameter_overrides = None
-        self.start_bold = "\033[1m"
-        self.end_bold = "\033[0m"
         self.color = Colored()
+        self.start_bold = "\033[1m" if self.color.colorize else ""
+        self.end_bold = "\033[0m" if self.color.colorize else ""
         self.function_provider = None
 
     @property
@@ -0,0 +1,65 @@
+"""
+CLI command for "local clean" command
+"""
+
+import logging
+
+import click
+
+from samcli.cli.main import pass_context, common_options as cli_framework_options, print_cmdline_args
+from samcli.commands.exceptions import UserException
+from samcli.lib.telemetry.metric import track_command
+from samcli.lib.utils.version_checker import check_newer_version
+
+LOG = logging.getLogger(__name__)
+
+HELP_TEXT = """
+Removes the Lambda containers created by SAM CLI, like the ones left behind when a command crashes or is killed.
+The containers are found by their com.aws.sam.local=true label. Run it when no other sam local command is running,
+as their containers are removed too.\n
+\b
+Usage:
+$ sam local clean
+"""
+
+
+@click.command("clean", help=HELP_TEXT, short_help="Removes the Lambda containers created by SAM CLI.")
+@cli_framework_options
+@pass_context
+@track_command
+@check_newer_version
+@print_cmdline_args
+def cli(ctx):
+    """
+    `sam local clean` command entry point
+    """
+    # All logic must be implemented in the ``do_cli`` method. This helps with easy unit testing
+
+    do_cli()  # pragma: no cover
+
+
+def do_cli():
+    """
+    Implementation of the ``cli`` method, just separated out for unit testing purposes
+    """
+    import docker
+
+    from samcli.local.docker.container import SAM_LOCAL_FUNCTION_LABEL, SAM_LOCAL_LABEL
+    from samcli.local.docker.utils import is_docker_reachable
+
+    docker_client = docker.from_env()
+    if not is_docker_reachable(docker_client):
+        raise UserException("Running AWS SAM projects locally requires Docker. Have you got it installed and running?")
+
+    containers = docker_client.containers.list(all=True, filters={"label": "{}=true".format(SAM_LOCAL_LABEL)})
+    if not containers:
+        click.echo("No containers created by SAM CLI were found")
+        return
+
+    for container in containers:
+        function_name = (container.labels or {}).get(SAM_LOCAL_FUNCTION_LABEL, "unknown")
+        click.echo("Removing container {} of function {}".format(container.short_id, function_name))
+        try:
+            container.remove(force=True)
+        except docker.errors.APIError as ex:
+            LOG.warning("Unable to remove container %s: %s", container.short_id, ex)
@@ -7,12 +7,16 @@ import logging
 import os
 from enum import Enum
 from pathlib import Path
-from typing import Dict, List, Optional, IO, cast, Tuple, Any
+from typing import Dict, List, Optional, IO, cast, Tuple, Any, Iterable, Union, Set
+
+import tomlkit
 
 import samcli.lib.utils.osutils as osutils
 from samcli.lib.providers.provider import Stack, Function
 from samcli.lib.providers.sam_stack_provider import SamLocalStackProvider
 from samcli.lib.utils.async_utils import AsyncContext
+from samcli.lib.utils.dotenv import parse_dotenv
+from samcli.lib.utils.packagetype import ZIP
 from samcli.lib.utils.stream_writer import StreamWriter
 from samcli.commands.exceptions import ContainersInitializationException
 from samcli.commands.local.cli_common.user_exceptions import InvokeContextException, DebugContextException
@@ -20,10 +24,23 @@ from samcli.commands.local.lib.local_lambda import LocalLambdaRunner
 from samcli.commands.local.lib.debug_context import DebugContext
 from samcli.local.lambdafn.runtime import LambdaRuntime, WarmLambdaRuntime
 from samcli.local.docker.lambda_image import LambdaImage
+from samcli.local.docker.container import SAM_LOCAL_TEMPLATE_LABEL
 from samcli.local.docker.manager import ContainerManager
+from samcli.local.docker.exceptions import DockerAPIVersionNotSupportedException
+from samcli.commands._utils.remote_template import get_remote_template_url
 from samcli.commands._utils.template import TemplateNotFoundException, TemplateFailedParsingException
 from samcli.local.layers.layer_downloader import LayerDownloader
+from samcli.lib.hook import prepare_hook_template
+from samcli.lib.hook.terraform import InvalidTerraformPlanException
 from samcli.lib.providers.sam_function_provider import SamFunctionProvider
+from samcli.lib.build.build_graph import (
+    BuildGraph,
+    DEFAULT_BUILD_GRAPH_FILE_NAME,
+    CODE_URI_FIELD,
+    FUNCTIONS_FIELD,
+    LAYER_FIELD,
+)
+from samcli.yamlhelper import yaml_parse
 
 LOG = logging.getLogger(__name__)
 
@@ -63,7 +80,7 @@ class InvokeContext:
         docker_network: Optional[str] = None,
         log_file: Optional[str] = None,
         skip_pull_image: Optional[bool] = None,
-        debug_ports: Optional[Tuple[int]] = None,
+        debug_ports: Optional[Tuple[Union[int, range], ...]] = None,
         debug_args: Optional[str] = None,
         debugger_path: Optional[str] = None,
         container_env_vars_file: Optional[str] = None,
@@ -77,6 +94,34 @@ class InvokeContext:
         shutdown: bool = False,
         container_host: Optional[str] = None,
         container_host_interface: Optional[str] = None,
+        show_env: bool = False,
+        debug_host_ports: Optional[Tuple[Union[int, range], ...]] = None,
+        runtime_override: Optional[str] = None,
+        container_hostname: Optional[str] = None,
+        shm_size: Optional[str] = None,
+        cpu_proportional: bool = False,
+        timezone: Optional[str] = None,
+        use_build_artifacts: bool = False,
+        buffered_output: bool = False,
+        container_labels_file: Optional[str] = None,
+        env_file: Optional[str] = None,
+        no_memory_limit: bool = False,
+        stdout_file: Optional[str] = None,
+        stderr_file: Optional[str] = None,
+        random_seed: Optional[str] = None,
+        random_seed_env_var: Optional[str] = None,
+        aws_endpoint_url: Optional[str] = None,
+        add_host: Optional[Tuple[Tuple[str, str], ...]] = None,
+        import_values_file: Optional[str] = None,
+        container_labels: Optional[Tuple[Tuple[str, str], ...]] = None,
+        shutdown_timeout: int = 0,
+        hook_name: Optional[str] = None,
+        resource_attributes_file: Optional[str] = None,
+        cloudwatch_style_logs: bool = False,
+        container_entrypoint: Optional[List[str]] = None,
+        runtime_image_override: Optional[Tuple[Tuple[str, str], ...]] = None,
+        force_linux_paths: bool = False,
+        no_build_artifacts: bool = False,
     ) -> None:
         """
         Initialize the context
@@ -100,7 +145,7 @@ class InvokeContext:
         aws_profile str
             Name of the profile to fetch AWS credentials from
         debug_ports tuple(int)
-            Ports to bind the debugger to
+            Ports to bind the debugger to. A range of ports assigns a distinct port to each function
         debug_args str
             Additional arguments passed to the debugger
         debugger_path str
@@ -127,6 +172,69 @@ class InvokeContext:
             Optional. Host of locally emulated Lambda container
         container_host_interface string
             Optional. Interface that Docker host binds ports to
+        show_env bool
+            Optional. If True, print the environment variables of the function before invoking it. Default False.
+        debug_host_ports tuple(int)
+            Optional. Ports on the host to bind the debug ports to, in the same order as debug_ports. By default,
+            every debug port is bound to the same port on the host
+        runtime_override str
+            Optional. Runtime to invoke Zip functions with, instead of the Runtime of the template
+        container_hostname str
+            Optional. Hostname of the Lambda containers
+        shm_size str
+            Optional. Size of /dev/shm of the Lambda containers, like 512m
+        cpu_proportional bool
+            Optional. Limit the CPU of the Lambda containers in proportion to the memory of their function
+        timezone str
+            Optional. Timezone to run the functions in, like Europe/Paris
+        use_build_artifacts bool
+            Optional. Mount the code of functions from their build artifacts next to the template, when they exist
+        buffered_output bool
+            Optional. Let the runtimes buffer the output of functions, instead of disabling their output buffering
+        container_labels_file str
+            Optional. Path to a JSON or YAML file with the labels to apply to the Lambda containers
+        env_file str
+            Optional. Path to a dotenv file with values for the environment variables of all the functions
+        no_memory_limit bool
+            Optional. Do not limit the memory of the Lambda containers to the MemorySize of their function
+        stdout_file str
+            Optional. Path to a file to write the response of the function to, instead of stdout or the log file
+        stderr_file str
+            Optional. Path to a file to write the logs of the function to, instead of stderr or the log file
+        random_seed str
+            Optional. Seed for the random number generators of the functions, passed in an environment variable
+        random_seed_env_var str
+            Optional. Name of the environment variable to pass the random seed in, RANDOM_SEED by default
+        aws_endpoint_url str
+            Optional. Endpoint of the AWS services the functions call, like the URL of LocalStack
+        add_host tuple
+            Optional. Hostname and IP address pairs to add to /etc/hosts of the containers
+        import_values_file str
+            Optional. Path to a JSON file mapping the names of the values other stacks export to the values, which
+            Fn::ImportValue resolves to
+        container_labels tuple
+            Optional. Label name and value pairs to apply to the containers, over the labels of container_labels_file
+        shutdown_timeout int
+            Optional. Seconds the containers have to exit after SIGTERM when they are torn down, before they get
+            SIGKILL. If 0, the containers are killed right away. Default 0
+        hook_name str
+            Optional. Name of the hook reading the functions from the project of another tool, like terraform, in
+            which case template_file is the file describing the project, like the JSON of a Terraform plan
+        resource_attributes_file str
+            Optional. Path to a JSON file mapping attributes of resources, as LogicalId.Attribute, to the values Ref,
+            Fn::GetAtt and Fn::Sub resolve to
+        cloudwatch_style_logs bool
+            Optional. Start each line of the logs of the functions with a log stream name and a timestamp, like
+            CloudWatch Logs shows them. Default False
+        container_entrypoint list
+            Optional. Entrypoint that replaces the one SAM CLI sets up in the containers of the functions
+        runtime_image_override tuple
+            Optional. Runtime and image pairs, the image replaces the default image of the runtime
+        force_linux_paths bool
+            Optional. Should the paths mounted in the containers be converted to Linux paths even when SAM CLI
+            doesn't detect Windows
+        no_build_artifacts bool
+            Optional. Mount the code of functions from the template even when "sam build" left its build information
         """
         self._template_file = template_file
         self._function_identifier = function_identifier
@@ -136,6 +244,7 @@ class InvokeContext:
         self._log_file = log_file
         self._skip_pull_image = skip_pull_image
         self._debug_ports = debug_ports
+        self._debug_host_ports = debug_host_ports
         self._debug_args = debug_args
         self._debugger_path = debugger_path
         self._container_env_vars_file = container_env_vars_file
@@ -154,6 +263,35 @@ class InvokeContext:
 
         self._container_host = container_host
         self._container_host_interface = container_host_interface
+        self._show_env = show_env
+        self._runtime_override = runtime_override
+        self._container_hostname = container_hostname
+        self._shm_size = shm_size
+        self._cpu_proportional = cpu_proportional
+        self._timezone = timezone
+        self._use_build_artifacts = use_build_artifacts
+        self._buffered_output = buffered_output
+        self._container_labels_file = container_labels_file
+        self._env_file = env_file
+        self._no_memory_limit = no_memory_limit
+        self._stdout_file = stdout_file
+        self._stderr_file = stderr_file
+        self._random_seed = random_seed
+        self._random_seed_env_var = random_seed_env_var
+        self._aws_endpoint_url = aws_endpoint_url
+        self._extra_hosts = dict(add_host) if add_host else None
+        self._import_values_file = import_values_file
+        self._import_values: Optional[Dict[str, Any]] = None
+        self._container_labels = dict(container_labels or ())
+        self._shutdown_timeout = shutdown_timeout
+        self._hook_name = hook_name
+        self._resource_attributes_file = resource_attributes_file
+        self._resource_attributes: Optional[Dict[str, Dict[str, Any]]] = None
+        self._cloudwatch_style_logs = cloudwatch_style_logs
+        self._container_entrypoint = container_entrypoint
+        self._runtime_images = dict(runtime_image_override) if runtime_image_override else None
+        self._force_linux_paths = force_linux_paths
+        self._no_build_artifacts = no_build_artifacts
 
         self._containers_mode = ContainersMode.COLD
         self._containers_initializing_mode = ContainersInitializationMode.LAZY
@@ -172,7 +310,11 @@ class InvokeContext:
         self._stacks: List[Stack] = None  # type: ignore
         self._env_vars_value: Optional[Dict] = None
         self._container_env_vars_value: Optional[Dict] = None
+        self._container_labels_value: Optional[Dict[str, str]] = None
+        self._env_file_value: Optional[Dict[str, str]] = None
         self._log_file_handle: Optional[IO] = None
+        self._stdout_file_handle: Optional[IO] = None
+        self._stderr_file_handle: Optional[IO] = None
         self._debug_context: Optional[DebugContext] = None
         self._layers_downloader: Optional[LayerDownloader] = None
         self._container_manager: Optional[ContainerManager] = None
@@ -187,18 +329,54 @@ class InvokeContext:
         :returns InvokeContext: Returns this object
         """
 
+        if self._use_build_artifacts and self._no_build_artifacts:
+            raise InvokeContextException("--use-build-artifacts and --no-build-artifacts cannot be used together.")
+
+        self._import_values = self._get_import_values(self._import_values_file)
+        self._resource_attributes = self._get_resource_attributes(self._resource_attributes_file)
         self._stacks = self._get_stacks()
-        self._function_provider = SamFunctionProvider(self._stacks)
+        # The local paths of a downloaded template are relative to --docker-volume-basedir rather than to the
+        # temporary directory it was downloaded to
+        remote_template_url = get_remote_template_url(self._template_file)
+        self._function_provider = SamFunctionProvider(self._stacks, use_raw_codeuri=bool(remote_template_url))
+        if remote_template_url and not self._docker_volume_basedir:
+            self._check_no_relative_code_paths(remote_template_url)
 
         self._env_vars_value = self._get_env_vars_value(self._env_vars_file)
+        self._env_file_value = self._get_env_file_value(self._env_file)
         self._container_env_vars_value = self._get_env_vars_value(self._container_env_vars_file)
+        # The containers are labeled with their template, so that the ones of a project can be told apart
+        self._container_labels_value = {
+            SAM_LOCAL_TEMPLATE_LABEL: remote_template_url or os.path.abspath(self._template_file),
+            **(self._get_container_labels_value(self._container_labels_file) or {}),
+            **self._container_labels,
+        }
         self._log_file_handle = self._setup_log_file(self._log_file)
+        if self._stdout_file:
+            self._stdout_file_handle = self._setup_log_file(self._stdout_file)
+        if self._stderr_file:
+            # Both streams are written to the same handle when they are redirected to the same file, so that the one
+            # opened last does not overwrite the other
+            if self._stderr_file == self._stdout_file:
+                self._stderr_file_handle = self._stdout_file_handle
+            else:
+                self._stderr_file_handle = self._setup_log_file(self._stderr_file)
 
         # in case of warm containers && debugging is enabled && if debug-function property is not provided, so
         # if the provided template only contains one lambda function, so debug-function will be set to this function
         # if the template contains multiple functions, a warning message "that the debugging option will be ignored"
         # will be printed
-        if self._containers_mode == ContainersMode.WARM and self._debug_ports and not self._debug_function:
+        # a debug port range assigns a port to every function that is debugged, so all of them can be debugged at
+        # the same time
+        function_debug_ports = self._get_function_debug_ports(self._debug_ports, self._get_debugged_functions())
+        debug_host_ports = self._get_debug_host_ports(self._debug_ports, self._debug_host_ports)
+
+        if (
+            self._containers_mode == ContainersMode.WARM
+            and self._debug_ports
+            and not self._debug_function
+            and not function_debug_ports
+        ):
             if len(self._function_provider.functions) == 1:
                 self._debug_function = list(self._function_provider.functions.keys())[0]
             else:
@@ -215,12 +393,29 @@ class InvokeContext:
             self._debugger_path,
             self._container_env_vars_value,
             self._debug_function,
+            function_debug_ports,
+            debug_host_ports,
         )
 
         self._container_manager = self._get_container_manager(
-            self._docker_network, self._skip_pull_image, self._shutdown
+            self._docker_network,
+            self._skip_pull_image,
+            self._shutdown,
+            self._container_hostname,
+            self._shm_size,
+            self._cpu_proportional,
+            self._container_labels_value,
+            self._no_memory_limit,
+            self._extra_hosts,
+            self._shutdown_timeout,
+            self._force_linux_paths,
         )
 
+        try:
+            self._container_manager.check_docker_api_version()
+        except DockerAPIVersionNotSupportedException as ex:
+            raise InvokeContextException(str(ex)) from ex
+
         if not self._container_manager.is_docker_reachable:
             raise InvokeContextException(
                 "Running AWS SAM projects locally requires Docker. Have you got it installed and running?"
@@ -241,8 +436,16 @@ class InvokeContext:
             self._log_file_handle.close()
             self._log_file_handle = None
 
+        for file_handle in {self._stdout_file_handle, self._stderr_file_handle} - {None}:
+            file_handle.close()  # type: ignore
+        self._stdout_file_handle = None
+        self._stderr_file_handle = None
+
         if self._containers_mode == ContainersMode.WARM:
             self._clean_running_containers_and_related_resources()
+        elif self._lambda_runtimes:
+            # The code decompressed from archives is reused across invocations, until the command is done
+            self.lambda_runtime.clean_decompressed_paths()
 
     def _initialize_all_functions_containers(self) -> None:
         """
@@ -252,8 +455,9 @@ class InvokeContext:
 
         def initialize_function_container(function: Function) -> None:
             function_config = self.local_lambda_runner.get_invoke_config(function)
+            debug_context = self._debug_context.for_function(function.name) if self._debug_context else None
             self.lambda_runtime.run(
-                None, function_config, self._debug_context, self._container_host, self._container_host_interface
+                None, function_config, debug_context, self._container_host, self._container_host_interface
             )
 
         try:
@@ -311,7 +515,9 @@ class InvokeContext:
     def lambda_runtime(self) -> LambdaRuntime:
         if not self._lambda_runtimes:
             layer_downloader = LayerDownloader(self._layer_cache_basedir, self.get_cwd(), self._stacks)
-            image_builder = LambdaImage(layer_downloader, self._skip_pull_image, self._force_image_build)
+            image_builder = LambdaImage(
+                layer_downloader, self._skip_pull_image, self._force_image_build, runtime_images=self._runtime_images
+            )
             self._lambda_runtimes = {
                 ContainersMode.WARM: WarmLambdaRuntime(self._container_manager, image_builder),
                 ContainersMode.COLD: LambdaRuntime(self._container_manager, image_builder),
@@ -330,6 +536,7 @@ class InvokeContext:
         if self._local_lambda_runner:
             return self._local_lambda_runner
 
+        build_dir = self.get_build_dir()
         self._local_lambda_runner = LocalLambdaRunner(
             local_runtime=self.lambda_runtime,
             function_provider=self._function_provider,
@@ -340,6 +547,18 @@ class InvokeContext:
             debug_context=self._debug_context,
             container_host=self._container_host,
             container_host_interface=self._container_host_interface,
+            show_env=self._show_env,
+            runtime_override=self._runtime_override,
+            timezone=self._timezone,
+            build_dir=build_dir,
+            up_to_date_build_artifacts=self._get_up_to_date_build_artifacts(build_dir),
+            buffered_output=self._buffered_output,
+            env_file_values=self._env_file_value,
+            random_seed=self._random_seed,
+            random_seed_env_var=self._random_seed_env_var,
+            aws_endpoint_url=self._aws_endpoint_url,
+            cloudwatch_style_logs=self._cloudwatch_style_logs,
+            container_entrypoint=self._container_entrypoint,
         )
         return self._local_lambda_runner
 
@@ -353,7 +572,7 @@ class InvokeContext:
         samcli.lib.utils.stream_writer.StreamWriter
             Stream writer for stdout
         """
-        stream = self._log_file_handle if self._log_file_handle else osutils.stdout()
+        stream = self._stdout_file_handle or self._log_file_handle or osutils.stdout()
         return StreamWriter(stream, self._is_debugging)
 
     @property
@@ -366,7 +585,7 @@ class InvokeContext:
         samcli.lib.utils.stream_writer.StreamWriter
             Stream writer for stderr
         """
-        stream = self._log_file_handle if self._log_file_handle else osutils.stderr()
+        stream = self._stderr_file_handle or self._log_file_handle or osutils.stderr()
         return StreamWriter(stream, self._is_debugging)
 
     @property
@@ -378,6 +597,24 @@ class InvokeContext:
         """
         return self._stacks
 
+    def _check_no_relative_code_paths(self, template_url: str) -> None:
+        """
+        Fails when a function of a remote template has its code at a relative local path, which can only be resolved
+        against the directory given with --docker-volume-basedir
+
+        :raises InvokeContextException: If the code of a function is at a relative local path
+        """
+        for function in self._function_provider.get_all():
+            if function.packagetype != ZIP or function.inlinecode or not function.codeuri:
+                continue
+
+            if not os.path.isabs(function.codeuri):
+                raise InvokeContextException(
+                    "The CodeUri {} of function {} is a relative path, which cannot be resolved for the template "
+                    "downloaded from {}. Use --docker-volume-basedir to set the directory the local paths of the "
+                    "template are relative to.".format(function.codeuri, function.full_path, template_url)
+                )
+
     def get_cwd(self) -> str:
         """
         Get the working directory. This is usually relative to the directory that contains the template. If a Docker
@@ -394,19 +631,118 @@ class InvokeContext:
 
         return cwd
 
+    def get_build_dir(self) -> Optional[str]:
+        """
+        Get the directory of the build artifacts to mount the code of functions from. It is the default build
+        directory of "sam build", relative to the directory that contains the template. Build artifacts are used
+        when requested, or when "sam build" left its build information, the build.toml file, next to the directory,
+        unless they are turned off
+
+        :return string: Build directory, or None if build artifacts should not be used
+        """
+        build_dir = os.path.join(os.path.dirname(os.path.abspath(self._template_file)), ".aws-sam", "build")
+        if self._use_build_artifacts:
+            return build_dir
+
+        if self._no_build_artifacts:
+            return None
+
+        build_info_file = os.path.join(os.path.dirname(build_dir), DEFAULT_BUILD_GRAPH_FILE_NAME)
+        if not os.path.isfile(build_info_file):
+            return None
+
+        LOG.info(
+            "Found build information in %s, using the build artifacts of the functions in %s",
+            build_info_file,
+            build_dir,
+        )
+        return build_dir
+
+    def _get_up_to_date_build_artifacts(self, build_dir: Optional[str]) -> Optional[Set[str]]:
+        """
+        Get the functions and layers whose build artifacts can be used. All of them can be used when build artifacts
+        are requested. Otherwise only the ones that "sam build" recorded in its build information, and whose CodeUri
+        did not change since that build, can be used
+
+        :param string build_dir: Build directory returned by get_build_dir
+        :return set: Full paths of the functions and layers, or None if all the build artifacts can be used
+        """
+        if not build_dir or self._use_build_artifacts:
+            return None
+
+        build_info_file = os.path.join(os.path.dirname(build_dir), DEFAULT_BUILD_GRAPH_FILE_NAME)
+        try:
+            build_info = tomlkit.loads(Path(build_info_file).read_text())
+        except (OSError, tomlkit.exceptions.ParseError) as ex:
+            LOG.warning(
+                "Could not read the build information in %s, not using build artifacts: %s", build_info_file, ex
+            )
+            return set()
+
+        build_time = os.path.getmtime(build_info_file)
+        template_dir = os.path.dirname(os.path.abspath(self._template_file))
+        build_definitions = [
+            (list(definition.get(FUNCTIONS_FIELD, [])), definition.get(CODE_URI_FIELD))
+            for definition in build_info.get(BuildGraph.FUNCTION_BUILD_DEFINITIONS, {}).values()
+        ] + [
+            ([definition.get(LAYER_FIELD)], definition.get(CODE_URI_FIELD))
+            for definition in build_info.get(BuildGraph.LAYER_BUILD_DEFINITIONS, {}).values()
+        ]
+
+        up_to_date_build_artifacts = set()
+        for full_paths, codeuri in build_definitions:
+            if codeuri and self._get_last_modified_time(os.path.join(template_dir, codeuri)) > build_time:
+                LOG.info(
+                    "The code of %s changed since the last 'sam build', using its CodeUri instead of its build "
+                    "artifacts",
+                    ", ".join(str(full_path) for full_path in full_paths),
+                )
+                continue
+            up_to_date_build_artifacts.update(str(full_path) for full_path in full_paths if full_path)
+
+        return up_to_date_build_artifacts
+
+    @staticmethod
+    def _get_last_modified_time(path: str) -> float:
+        """
+        Get the last time a file, or any file or directory under a directory, was modified
+
+        :param string path: Path of the file or directory
+        :return float: Last modification time, or 0 if the path does not exist
+        """
+        if not os.path.exists(path):
+            return 0
+
+        last_modified_time = os.path.getmtime(path)
+        for root, dirs, files in os.walk(path):
+            for name in dirs + files:
+                try:
+                    last_modified_time = max(last_modified_time, os.path.getmtime(os.path.join(root, name)))
+                except OSError:
+                    # The file was removed while walking, or is a broken symlink
+                    continue
+
+        return last_modified_time
+
     @property
     def _is_debugging(self) -> bool:
         return bool(self._debug_context)
 
     def _get_stacks(self) -> List[Stack]:
         try:
+            template_file = self._template_file
+            if self._hook_name:
+                template_file = prepare_hook_template(self._hook_name, template_file)
+
             stacks, _ = SamLocalStackProvider.get_stacks(
-                self._template_file,
+                template_file,
                 parameter_overrides=self._parameter_overrides,
                 global_parameter_overrides=self._global_parameter_overrides,
+                import_values=self._import_values,
+                resource_attributes=self._resource_attributes,
             )
             return stacks
-        except (TemplateNotFoundException, TemplateFailedParsingException) as ex:
+        except (TemplateNotFoundException, TemplateFailedParsingException, InvalidTerraformPlanException) as ex:
             raise InvokeContextException(str(ex)) from ex
 
     @staticmethod
@@ -433,6 +769,128 @@ class InvokeContext:
                 "Could not read environment variables overrides from file {}: {}".format(filename, str(ex))
             ) from ex
 
+    @staticmethod
+    def _get_env_file_value(filename: Optional[str]) -> Optional[Dict[str, str]]:
+        """
+        If the user provided a dotenv file, this method will read the file and return the values of the environment
+        variables it defines
+
+        :param string filename: Path to a dotenv file, with KEY=VALUE lines
+        :return dict: Value of environment variables, if provided. None otherwise
+        :raises InvokeContextException: If the file was not found or not a valid dotenv file
+        """
+        if not filename:
+            return None
+
+        try:
+            with open(filename, "r") as fp:
+                return parse_dotenv(fp.read())
+        except Exception as ex:
+            raise InvokeContextException(
+                "Could not read environment variables from dotenv file {}: {}".format(filename, str(ex))
+            ) from ex
+
+    @staticmethod
+    def _get_container_labels_value(filename: Optional[str]) -> Optional[Dict[str, str]]:
+        """
+        If the user provided a file containing labels for the containers, this method will read the file and
+        return the labels
+
+        :param string filename: Path to a JSON or YAML file containing a mapping of label names to values
+        :return dict: Labels of the containers, if provided. None otherwise
+        :raises InvokeContextException: If the file was not found, or does not contain a mapping of labels
+        """
+        if not filename:
+            return None
+
+        try:
+            with open(filename, "r") as fp:
+                labels = yaml_parse(fp.read())
+        except Exception as ex:
+            raise InvokeContextException(
+                "Could not read container labels from file {}: {}".format(filename, str(ex))
+            ) from ex
+
+        if not isinstance(labels, dict) or any(
+            isinstance(value, (dict, list)) or value is None for value in labels.values()
+        ):
+            raise InvokeContextException(
+                "Could not read container labels from file {}: it must contain a mapping of label names to "
+                "values".format(filename)
+            )
+
+        return {
+            str(name): str(value).lower() if isinstance(value, bool) else str(value) for name, value in labels.items()
+        }
+
+    @staticmethod
+    def _get_import_values(filename: Optional[str]) -> Optional[Dict[str, Any]]:
+        """
+        If the user provided a file containing the values exported by other stacks, this method will read the file
+        and return the values
+
+        :param string filename: Path to a JSON file containing a mapping of export names to values
+        :return dict: Exported values by export name, if provided. None otherwise
+        :raises InvokeContextException: If the file was not found, or does not contain a mapping of export names
+        """
+        if not filename:
+            return None
+
+        try:
+            with open(filename, "r") as fp:
+                import_values = json.load(fp)
+        except Exception as ex:
+            raise InvokeContextException(
+                "Could not read import values from file {}: {}".format(filename, str(ex))
+            ) from ex
+
+        if not isinstance(import_values, dict):
+            raise InvokeContextException(
+                "Could not read import values from file {}: it must contain a mapping of export names to "
+                "values".format(filename)
+            )
+
+        return cast(Dict[str, Any], import_values)
+
+    @staticmethod
+    def _get_resource_attributes(filename: Optional[str]) -> Optional[Dict[str, Dict[str, Any]]]:
+        """
+        If the user provided a file containing values of the attributes of resources, this method will read the file
+        and return the values by logical ID and attribute name, like {"MyTable": {"Arn": "..."}}
+
+        :param string filename: Path to a JSON file containing a mapping of LogicalId.Attribute to values
+        :return dict: Attribute values by logical ID and attribute name, if provided. None otherwise
+        :raises InvokeContextException: If the file was not found, or does not contain a mapping of LogicalId.Attribute
+        """
+        if not filename:
+            return None
+
+        try:
+            with open(filename, "r") as fp:
+                attribute_values = json.load(fp)
+        except Exception as ex:
+            raise InvokeContextException(
+                "Could not read resource attributes from file {}: {}".format(filename, str(ex))
+            ) from ex
+
+        if not isinstance(attribute_values, dict):
+            raise InvokeContextException(
+                "Could not read resource attributes from file {}: it must contain a mapping of LogicalId.Attribute to "
+                "values".format(filename)
+            )
+
+        resource_attributes: Dict[str, Dict[str, Any]] = {}
+        for key, value in attribute_values.items():
+            logical_id, _, attribute = key.partition(".")
+            if not logical_id or not attribute:
+                raise InvokeContextException(
+                    "Could not read resource attributes from file {}: {} is not of the form "
+                    "LogicalId.Attribute".format(filename, key)
+                )
+            resource_attributes.setdefault(logical_id, {})[attribute] = value
+
+        return resource_attributes
+
     @staticmethod
     def _setup_log_file(log_file: Optional[str]) -> Optional[IO]:
         """
@@ -446,13 +904,126 @@ class InvokeContext:
 
         return open(log_file, "wb")
 
+    def _get_debugged_functions(self) -> Iterable[Function]:
+        """
+        Returns the functions that are debugged: the function given with --debug-function, otherwise the invoked
+        function, otherwise all the functions of the template
+
+        Returns
+        -------
+        list(samcli.lib.providers.provider.Function)
+            Functions that need a debug port
+        """
+        function_identifier = self._debug_function or self._function_identifier
+        if not function_identifier:
+            return self._function_provider.get_all()
+
+        function = self._function_provider.get(function_identifier)
+        return [function] if function else []
+
+    @staticmethod
+    def _get_function_debug_ports(
+        debug_ports: Optional[Tuple[Union[int, range], ...]], functions: Iterable[Function]
+    ) -> Optional[Dict[str, int]]:
+        """
+        Assigns a distinct debug port to each function when the debug ports are given as a port range
+
+        Parameters
+        ----------
+        debug_ports tuple(int)
+            Ports to bind the debugger to, port ranges are given as range objects
+        functions list(samcli.lib.providers.provider.Function)
+            Functions that need a debug port
+
+        Returns
+        -------
+        dict
+            Mapping of function logicalId to its debug port. None if no port range was given
+
+        Raises
+        ------
+        samcli.commands.local.cli_common.user_exceptions.DebugContextException
+            When the port range does not have enough ports for all the functions
+        """
+        port_ranges = tuple(port for port in debug_ports or () if isinstance(port, range))
+        if not port_ranges:
+            return None
+
+        available_ports = InvokeContext._flatten_debug_ports(port_ranges)
+        function_names = [function.name for function in functions]
+        if len(function_names) > len(available_ports):
+            raise DebugContextException(
+                "The debug port range has {} ports, but {} functions need to be debugged.".format(
+                    len(available_ports), len(function_names)
+                )
+            )
+
+        function_debug_ports = dict(zip(function_names, available_ports))
+        LOG.info("Debug ports assigned to functions:")
+        for function_name, debug_port in function_debug_ports.items():
+            LOG.info("  %s -> %s", function_name, debug_port)
+
+        return function_debug_ports
+
+    @staticmethod
+    def _get_debug_host_ports(
+        debug_ports: Optional[Tuple[Union[int, range], ...]],
+        debug_host_ports: Optional[Tuple[Union[int, range], ...]],
+    ) -> Optional[Dict[int, int]]:
+        """
+        Pairs every debug port with the port on the host it is bound to
+
+        Parameters
+        ----------
+        debug_ports tuple(int)
+            Ports to bind the debugger to inside the container, port ranges are given as range objects
+        debug_host_ports tuple(int)
+            Ports on the host, in the same order as debug_ports, port ranges are given as range objects
+
+        Returns
+        -------
+        dict
+            Mapping of debug port in the container to the port on the host. None if no host ports were given
+
+        Raises
+        ------
+        samcli.commands.local.cli_common.user_exceptions.DebugContextException
+            When the number of host ports does not match the number of debug ports
+        """
+        if not debug_host_ports:
+            return None
+
+        container_ports = InvokeContext._flatten_debug_ports(debug_ports)
+        host_ports = InvokeContext._flatten_debug_ports(debug_host_ports)
+        if len(container_ports) != len(host_ports):
+            raise DebugContextException(
+                "{} debug host ports were given for {} debug ports, every debug port needs a host port.".format(
+                    len(host_ports), len(container_ports)
+                )
+            )
+
+        return dict(zip(container_ports, host_ports))
+
+    @staticmethod
+    def _flatten_debug_ports(debug_ports: Optional[Tuple[Union[int, range], ...]]) -> List[int]:
+        """
+        Expands the port ranges of the given debug ports into the ports they contain
+        """
+        return [
+            port
+            for debug_port in debug_ports or ()
+            for port in (debug_port if isinstance(debug_port, range) else (debug_port,))
+        ]
+
     @staticmethod
     def _get_debug_context(
-        debug_ports: Optional[Tuple[int]],
+        debug_ports: Optional[Tuple[Union[int, range], ...]],
         debug_args: Optional[str],
         debugger_path: Optional[str],
         container_env_vars: Optional[Dict[str, str]],
         debug_function: Optional[str] = None,
+        function_debug_ports: Optional[Dict[str, int]] = None,
+        debug_host_ports: Optional[Dict[int, int]] = None,
     ) -> DebugContext:
         """
         Creates a DebugContext if the InvokeContext is in a debugging mode
@@ -470,6 +1041,10 @@ class InvokeContext:
         debug_function str
             The Lambda function logicalId that will have the debugging options enabled in case of warm containers
             option is enabled
+        function_debug_ports dict
+            Mapping of function logicalId to the debug port assigned to it from a debug port range
+        debug_host_ports dict
+            Mapping of debug port to the port on the host it is bound to
 
         Returns
         -------
@@ -500,11 +1075,23 @@ class InvokeContext:
             debugger_path=debugger_path,
             debug_function=debug_function,
             container_env_vars=container_env_vars,
+            function_debug_ports=function_debug_ports,
+            debug_host_ports=debug_host_ports,
         )
 
     @staticmethod
     def _get_container_manager(
-        docker_network: Optional[str], skip_pull_image: Optional[bool], shutdown: Optional[bool]
+        docker_network: Optional[str],
+        skip_pull_image: Optional[bool],
+        shutdown: Optional[bool],
+        container_hostname: Optional[str] = None,
+        shm_size: Optional[str] = None,
+        cpu_proportional: bool = False,
+        container_labels: Optional[Dict[str, str]] = None,
+        no_memory_limit: bool = False,
+        extra_hosts: Optional[Dict[str, str]] = None,
+        shutdown_timeout: int = 0,
+        force_linux_paths: bool = False,
     ) -> ContainerManager:
         """
         Creates a ContainerManager with specified options
@@ -517,6 +1104,22 @@ class InvokeContext:
             Should the manager skip pulling the image
         shutdown bool
             Should SHUTDOWN events be sent when tearing down image
+        container_hostname str
+            Hostname of the containers, or None to let Docker assign one
+        shm_size str
+            Size of /dev/shm of the containers, or None to use the default of Docker
+        cpu_proportional bool
+            Should the CPU of the containers be limited in proportion to the memory of their function
+        container_labels dict
+            Labels to apply to the containers, or None to not label them
+        no_memory_limit bool
+            Should the memory of the containers be left unlimited, instead of limited to the memory of their function
+        extra_hosts dict
+            IP addresses by hostname to add to /etc/hosts of the containers, or None to not add any
+        shutdown_timeout int
+            Seconds the containers have to exit after SIGTERM when they are torn down, before they get SIGKILL
+        force_linux_paths bool
+            Should the paths mounted in the containers be converted to Linux paths even when not on Windows
 
         Returns
         -------
@@ -525,5 +1128,15 @@ class InvokeContext:
         """
 
         return ContainerManager(
-            docker_network_id=docker_network, skip_pull_image=skip_pull_image, do_shutdown_event=shutdown
+            docker_network_id=docker_network,
+            skip_pull_image=skip_pull_image,
+            do_shutdown_event=shutdown,
+            container_hostname=container_hostname,
+            shm_size=shm_size,
+            cpu_proportional=cpu_proportional,
+            labels=container_labels,
+            no_memory_limit=no_memory_limit,
+            extra_hosts=extra_hosts,
+            shutdown_timeout=shutdown_timeout,
+            force_linux_paths=force_linux_paths,
         )
@@ -5,8 +5,16 @@ from pathlib import Path
 
 import click
 
-from samcli.commands._utils.options import template_click_option, docker_click_options, parameter_override_click_option
+from samcli.cli.types import ContainerLabelType, DebugPortType, EntrypointType, HostEntryType, RuntimeImageType
+from samcli.commands._utils.options import (
+    template_click_option,
+    template_dir_click_option,
+    docker_click_options,
+    parameter_override_click_option,
+)
 from samcli.commands.local.cli_common.invoke_context import ContainersInitializationMode
+from samcli.lib.hook import HOOK_NAMES
+from samcli.local.common.runtime_template import RUNTIMES
 
 
 def get_application_dir():
@@ -49,6 +57,15 @@ def local_common_options(f):
             help="If set, will emulate a shutdown event after the invoke completes, "
             "in order to test extension handling of shutdown behavior.",
         ),
+        click.option(
+            "--shutdown-timeout",
+            type=click.IntRange(min=0),
+            default=3,
+            show_default=True,
+            help="Number of seconds the Lambda containers have to exit after SIGTERM when they are torn down, before "
+            "they are killed with SIGKILL. Functions and extensions use this time to flush their buffers and close "
+            "their connections. Use 0 to kill the containers right away.",
+        ),
         click.option(
             "--container-host",
             default="localhost",
@@ -65,6 +82,81 @@ def local_common_options(f):
             help="IP address of the host network interface that container ports should bind to. "
             "Use 0.0.0.0 to bind to all interfaces.",
         ),
+        click.option(
+            "--container-hostname",
+            help="Hostname of the Lambda containers. By default Docker assigns a random hostname to every container. "
+            "Set it for functions that rely on the hostname to behave the same across local runs. "
+            "Ignored when the containers run in the host network.",
+        ),
+        click.option(
+            "--shm-size",
+            help="Size of /dev/shm of the Lambda containers, for example 512m or 1g. Docker defaults to 64m, "
+            "which is too small for functions running headless browsers or some machine learning libraries.",
+        ),
+        click.option(
+            "--cpu-proportional",
+            is_flag=True,
+            default=False,
+            help="Limit the CPU of the Lambda containers in proportion to the MemorySize of their function, like "
+            "Lambda allocates 1 vCPU per 1769 MB of memory. Use it to profile the performance of functions locally. "
+            "By default the containers can use all the CPUs of the host.",
+        ),
+        click.option(
+            "--use-build-artifacts",
+            is_flag=True,
+            default=False,
+            help="Mount the code of a function from its 'sam build' artifacts in .aws-sam/build, next to the template, "
+            "when they exist, instead of the CodeUri of the template. Functions that were not built use their CodeUri. "
+            "When 'sam build' left its build information in .aws-sam/build.toml, only the artifacts of functions and "
+            "layers whose CodeUri did not change since the build are used by default.",
+        ),
+        click.option(
+            "--no-build-artifacts",
+            is_flag=True,
+            default=False,
+            help="Always mount the code of functions and layers from their CodeUri in the template, even when 'sam "
+            "build' left its build information in .aws-sam/build.toml.",
+        ),
+        click.option(
+            "--buffered-output",
+            is_flag=True,
+            default=False,
+            help="Let the runtimes buffer the output of functions. By default, the output buffering of runtimes that "
+            "buffer it is disabled, for example with PYTHONUNBUFFERED=1 for Python, so the logs of a function show up "
+            "while it runs instead of in a burst at the end of the invoke.",
+        ),
+        click.option(
+            "--container-labels-from-file",
+            type=click.Path(exists=True, dir_okay=False),
+            help="JSON or YAML file with a mapping of label names to values, to apply to all the Lambda containers "
+            "created by SAM CLI. Use it to apply a standard set of labels, like for cost allocation or ownership, "
+            "and to find the containers for inventory and cleanup.",
+        ),
+        click.option(
+            "--container-labels",
+            type=ContainerLabelType(),
+            multiple=True,
+            help="Label to apply to all the Lambda containers, as name=value. It can be repeated, and takes precedence "
+            "over the labels of --container-labels-from-file. The containers are always labeled with "
+            "com.aws.sam.local=true, along with the template and logical id of their function, which "
+            "sam local clean uses to remove the containers left behind.",
+        ),
+        click.option(
+            "--no-memory-limit",
+            is_flag=True,
+            default=False,
+            help="Do not limit the memory of the Lambda containers to the MemorySize of their function. Use it when "
+            "debugging memory heavy functions, or with a debugger that needs memory of its own. Functions still see "
+            "their MemorySize in AWS_LAMBDA_FUNCTION_MEMORY_SIZE.",
+        ),
+        click.option(
+            "--hook-name",
+            type=click.Choice(HOOK_NAMES),
+            help="Run the functions of the project of another infrastructure as code tool instead of the ones of a SAM "
+            "template. With terraform, --template-file is the JSON of a Terraform plan, created with terraform plan "
+            "-out tfplan && terraform show -json tfplan > plan.json, and its aws_lambda_function resources are the "
+            "functions, by their function_name or resource address.",
+        ),
     ]
 
     # Reverse the list to maintain ordering of options in help text printed with --help
@@ -105,7 +197,11 @@ def service_common_options(port):
                 "--host", default="127.0.0.1", help="Local hostname or IP address to bind to (default: '127.0.0.1')"
             ),
             click.option(
-                "--port", "-p", default=port, help="Local port number to listen on (default: '{}')".format(str(port))
+                "--port",
+                "-p",
+                default=port,
+                help="Local port number to listen on (default: '{}'). Use 0 to listen on a free port chosen by the OS, "
+                "the URL of the service is then printed to stdout as 'Listening on http://HOST:PORT'".format(str(port)),
             ),
         ]
 
@@ -127,6 +223,9 @@ def invoke_common_options(f):
 
     invoke_options = (
         [
+            # --template-dir is declared first, so that the template it selects is known when the template
+            # option falls back to its default value
+            template_dir_click_option(),
             template_click_option(),
             click.option(
                 "--env-vars",
@@ -134,21 +233,103 @@ def invoke_common_options(f):
                 type=click.Path(exists=True),
                 help="JSON file containing values for Lambda function's environment variables.",
             ),
+            click.option(
+                "--env-file",
+                type=click.Path(exists=True, dir_okay=False),
+                help="Dotenv file, with KEY=VALUE lines, containing values for the environment variables of all the "
+                "Lambda functions. Unlike with --env-vars, variables the template does not define for a function are "
+                "set too. Values of the --env-vars file take precedence over the ones of this file.",
+            ),
+            click.option(
+                "--import-values",
+                type=click.Path(exists=True, dir_okay=False),
+                help="JSON file mapping the names of the values other stacks export to the values, like "
+                '{"SharedVpcId": "vpc-0123"}, that Fn::ImportValue resolves to.',
+            ),
+            click.option(
+                "--resource-attributes",
+                type=click.Path(exists=True, dir_okay=False),
+                help="JSON file mapping attributes of resources, as LogicalId.Attribute, to values, like "
+                '{"MyTable.Arn": "arn:aws:dynamodb:us-east-1:123456789012:table/orders"}, that Ref, Fn::GetAtt and '
+                "Fn::Sub resolve to instead of the placeholder values SAM CLI makes up. Use it to point the functions "
+                "at real or emulated resources.",
+            ),
+            click.option(
+                "--cloudwatch-style-logs",
+                is_flag=True,
+                default=False,
+                help="Start each line of the logs of the functions with a log stream name and a timestamp, like "
+                "CloudWatch Logs and sam logs show them.",
+            ),
+            click.option(
+                "--container-entrypoint-override",
+                "--entrypoint",
+                type=EntrypointType(),
+                help="Command line that replaces the entrypoint of the containers of the functions, ex: "
+                '"/opt/wrapper /var/rapid/aws-lambda-rie". The handler of the function is passed to it as the command '
+                "of the container. It also replaces the entrypoint that wires up the debugger with --debug-port.",
+            ),
+            click.option(
+                "--runtime-image-override",
+                type=RuntimeImageType(RUNTIMES),
+                multiple=True,
+                envvar="SAM_RUNTIME_IMAGE_OVERRIDE",
+                help="Image to run the Zip functions of a runtime with instead of the default image of the runtime, "
+                "as runtime=image, ex: python3.8=registry.example.com/lambda-python3.8@sha256:<digest>. Use it with "
+                "mirrored or pinned images. This option can be specified multiple times.",
+            ),
+            click.option(
+                "--force-linux-paths",
+                is_flag=True,
+                default=False,
+                help="Convert the paths mounted in the Lambda containers to Linux paths, ex: C:\\Users\\sam-app to "
+                "/c/Users/sam-app, even when SAM CLI doesn't detect Windows. Use it when SAM CLI runs on Windows in an "
+                "environment like Cygwin or MSYS, with a Linux Docker daemon.",
+            ),
+            click.option(
+                "--aws-endpoint-url",
+                help="Endpoint of the AWS services the functions call, e.g. to point the AWS SDKs at LocalStack. It is "
+                "set as the AWS_ENDPOINT_URL environment variable of the functions, along with the "
+                "AWS_ENDPOINT_URL_<SERVICE> variables of common services. The endpoint must be reachable from the "
+                "Lambda containers: run LocalStack on the network of --docker-network and use its container name as "
+                "the host, like http://localstack:4566.",
+            ),
+            click.option(
+                "--add-host",
+                type=HostEntryType(),
+                multiple=True,
+                help="Entry to add to /etc/hosts of the Lambda containers, as hostname:ip, like the --add-host option "
+                "of docker run. Use it to reach services of the host by name, ex: db.local:192.168.1.10. The IP can be "
+                "host-gateway to use the IP of the host. This option can be specified multiple times.",
+            ),
             parameter_override_click_option(),
             click.option(
                 "--debug-port",
                 "-d",
                 help="When specified, Lambda function container will start in debug mode and will expose this "
-                "port on localhost.",
+                "port on localhost. A port range (ex: 5000-5010) assigns a distinct port from the range to each "
+                "function, so that multiple functions can be debugged at the same time.",
                 envvar="SAM_DEBUG_PORT",
-                type=click.INT,
+                type=DebugPortType(),
+                multiple=True,
+            ),
+            click.option(
+                "--debug-port-host",
+                help="When specified, the debug ports are exposed on these ports of localhost instead of the same "
+                "port numbers. Ports are paired with --debug-port in the order they are given, and must be as many "
+                "as the debug ports. A port range (ex: 6000-6010) is also accepted.",
+                envvar="SAM_DEBUG_PORT_HOST",
+                type=DebugPortType(),
                 multiple=True,
             ),
             click.option(
                 "--debugger-path", help="Host path to a debugger that will be mounted into the Lambda container."
             ),
             click.option(
-                "--debug-args", help="Additional arguments to be passed to the debugger.", envvar="DEBUGGER_ARGS"
+                "--debug-args",
+                help="Additional arguments to be passed to the debugger. Falls back to the DEBUGGER_ARGS environment "
+                "variable when the option is not given.",
+                envvar="DEBUGGER_ARGS",
             ),
             click.option(
                 "--container-env-vars",
@@ -63,3 +63,13 @@ class NotAvailableInRegion(UserException):
     """
     Calling service not available (launched) in specified region
     """
+
+
+class FunctionErrorException(UserException):
+    """
+    The invoked function failed, and an exit code was configured for its kind of error
+    """
+
+    def __init__(self, message, exit_code):
+        super().__init__(message)
+        self.exit_code = exit_code
@@ -3,15 +3,66 @@ Generates the services and commands for selection in SAM CLI generate-event
 """
 
 import functools
+from typing import Dict
 
 import click
 
 import samcli.lib.generated_sample_events.events as events
 from samcli.cli.cli_config_file import TomlProvider, configuration_option
-from samcli.cli.options import debug_option
+from samcli.cli.options import debug_option, no_color_option
+from samcli.cli.types import JsonObjectType
+from samcli.commands._utils.options import template_option_without_build
+from samcli.commands._utils.resources import AWS_SERVERLESS_SIMPLE_TABLE
+from samcli.commands._utils.template import get_template_data
+from samcli.commands.exceptions import UserException
 from samcli.lib.telemetry.metric import track_command
 from samcli.lib.utils.version_checker import check_newer_version
 
+# DynamoDB attribute types of the AWS::Serverless::SimpleTable PrimaryKey types, and a sample value for each
+SIMPLE_TABLE_KEY_TYPES = {"String": "S", "Number": "N", "Binary": "B"}
+SIMPLE_TABLE_KEY_VALUES = {"S": "101", "N": "101", "B": "MTAx"}
+SIMPLE_TABLE_DEFAULT_PRIMARY_KEY = {"Name": "id", "Type": "String"}
+
+
+def get_simple_table_values(template_file: str, logical_id: str) -> Dict[str, str]:
+    """
+    Reads the table name and the primary key schema of an AWS::Serverless::SimpleTable from the template,
+    so that the keys of the generated stream records match the ones of the table
+
+    Parameters
+    ----------
+    template_file str
+        Path to the template defining the table
+    logical_id str
+        Logical ID of the AWS::Serverless::SimpleTable resource
+
+    Returns
+    -------
+    dict
+        Values to substitute into the event json, keyed by the name of the tag
+    """
+    resources = get_template_data(template_file).get("Resources") or {}
+    resource = resources.get(logical_id)
+    if not isinstance(resource, dict) or resource.get("Type") != AWS_SERVERLESS_SIMPLE_TABLE:
+        raise UserException(
+            "Resource {} of type {} is not defined in {}".format(logical_id, AWS_SERVERLESS_SIMPLE_TABLE, template_file)
+        )
+
+    properties = resource.get("Properties") or {}
+    primary_key = properties.get("PrimaryKey") or SIMPLE_TABLE_DEFAULT_PRIMARY_KEY
+    key_name = primary_key.get("Name", SIMPLE_TABLE_DEFAULT_PRIMARY_KEY["Name"])
+    key_type = SIMPLE_TABLE_KEY_TYPES.get(primary_key.get("Type", SIMPLE_TABLE_DEFAULT_PRIMARY_KEY["Type"]))
+    if not isinstance(key_name, str) or not key_type:
+        raise UserException("Unable to resolve the PrimaryKey of {} in {}".format(logical_id, template_file))
+
+    table_name = properties.get("TableName")
+    return {
+        "table": table_name if isinstance(table_name, str) else logical_id,
+        "key_name": key_name,
+        "key_type": key_type,
+        "key_value": SIMPLE_TABLE_KEY_VALUES[key_type],
+    }
+
 
 class ServiceCommand(click.MultiCommand):
     """
@@ -96,6 +147,10 @@ class EventTypeSubCommand(click.MultiCommand):
     """
 
     TAGS = "tags"
+    SIMPLE_TABLE = "simple-table"
+    BATCH = "batch"
+    # Click types of the options of the tags, by type of the tag. The options of the other tags are strings
+    TAG_TYPES = {"number": click.INT, "fields": JsonObjectType()}
 
     def __init__(self, events_lib: events.Events, top_level_cmd_name, subcmd_definition, *args, **kwargs):
         """
@@ -142,10 +197,12 @@ class EventTypeSubCommand(click.MultiCommand):
         parameters = []
         for param_name in self.subcmd_definition[cmd_name][self.TAGS].keys():
             default = self.subcmd_definition[cmd_name][self.TAGS][param_name]["default"]
+            tag_type = self.subcmd_definition[cmd_name][self.TAGS][param_name].get("type")
             parameters.append(
                 click.Option(
                     ["--{}".format(param_name)],
                     default=default,
+                    type=self.TAG_TYPES.get(tag_type),
                     help="Specify the {} name you'd like, otherwise the default = {}".format(param_name, default),
                 )
             )
@@ -161,7 +218,24 @@ class EventTypeSubCommand(click.MultiCommand):
             callback=command_callback,
         )
 
-        cmd = configuration_option(provider=TomlProvider(section="parameters"))(debug_option(cmd))
+        if self.subcmd_definition[cmd_name].get(self.BATCH):
+            cmd = click.option(
+                "--count",
+                type=click.IntRange(min=1),
+                default=None,
+                help="Number of records in the event, each with its own ids, to test functions processing batches. "
+                "By default the event has the records of the sample event.",
+            )(cmd)
+
+        if self.subcmd_definition[cmd_name].get(self.SIMPLE_TABLE):
+            cmd = click.option(
+                "--simple-table",
+                default=None,
+                help="Logical ID of the AWS::Serverless::SimpleTable in the template whose table name and "
+                "primary key are used in the event.",
+            )(template_option_without_build(cmd))
+
+        cmd = configuration_option(provider=TomlProvider(section="parameters"))(no_color_option(debug_option(cmd)))
         return cmd
 
     def list_commands(self, ctx):
@@ -205,7 +279,15 @@ class EventTypeSubCommand(click.MultiCommand):
         event : string
             returns the customized event json as a string
         """
+        template_file = kwargs.pop("template_file", None)
+        simple_table = kwargs.pop("simple_table", None)
+        count = kwargs.pop("count", None)
+        if simple_table:
+            kwargs.update(get_simple_table_values(template_file, simple_table))
+
         event = events_lib.generate_event(top_level_cmd_name, subcmd_name, kwargs)
+        if count is not None:
+            event = events_lib.batch_records(top_level_cmd_name, event, count)
         click.echo(event)
         return event
 
@@ -2,7 +2,13 @@
 CLI command for "local invoke" command
 """
 
+import io
+import json
 import logging
+import os
+import platform
+import subprocess
+
 import click
 
 from samcli.cli.main import pass_context, common_options as cli_framework_options, aws_creds_options, print_cmdline_args
@@ -10,7 +16,10 @@ from samcli.commands.local.cli_common.options import invoke_common_options, loca
 from samcli.commands.local.lib.exceptions import InvalidIntermediateImageError
 from samcli.lib.telemetry.metric import track_command
 from samcli.cli.cli_config_file import configuration_option, TomlProvider
+from samcli.lib.utils.stream_writer import StreamWriter, CapturingStreamWriter
 from samcli.lib.utils.version_checker import check_newer_version
+from samcli.lib.utils.xray import get_trace_header
+from samcli.local.common.runtime_template import RUNTIMES
 from samcli.local.docker.exceptions import ContainerNotStartableException
 
 LOG = logging.getLogger(__name__)
@@ -28,9 +37,39 @@ $ sam local invoke "HelloWorldFunction" -e event.json\n
 \b
 Invoking a Lambda function using input from stdin
 $ echo '{"message": "Hey, are you there?" }' | sam local invoke "HelloWorldFunction" --event - \n
+\b
+Invoking a Lambda function using an event copied to the clipboard
+$ sam local invoke "HelloWorldFunction" --event-clipboard\n
+\b
+Executing a Step Functions state machine, whose Task states invoke the local functions
+$ sam local invoke "OrderStateMachine" -e event.json\n
 """
 STDIN_FILE_NAME = "-"
 
+# Environment variable the response of the function is passed to the after-invoke command in
+AFTER_INVOKE_RESPONSE_ENV_VAR = "SAM_INVOKE_RESPONSE"
+
+# Commands that print the text content of the system clipboard, tried in order for every OS
+CLIPBOARD_COMMANDS = {
+    "darwin": [["pbpaste"]],
+    "windows": [["powershell", "-NoProfile", "-Command", "Get-Clipboard -Raw"]],
+    "linux": [
+        ["wl-paste", "--no-newline"],
+        ["xclip", "-selection", "clipboard", "-out"],
+        ["xsel", "--clipboard", "--output"],
+    ],
+}
+
+# Kinds of function errors that can be mapped to exit codes, with their descriptions
+FUNCTION_ERROR_HANDLED = "Handled"
+FUNCTION_ERROR_UNHANDLED = "Unhandled"
+FUNCTION_ERROR_TIMEOUT = "Timeout"
+FUNCTION_ERROR_DESCRIPTIONS = {
+    FUNCTION_ERROR_HANDLED: "a handled",
+    FUNCTION_ERROR_UNHANDLED: "an unhandled",
+    FUNCTION_ERROR_TIMEOUT: "a timeout",
+}
+
 
 @click.command("invoke", help=HELP_TEXT, short_help="Invokes a local Lambda function once.")
 @configuration_option(provider=TomlProvider(section="parameters"))
@@ -41,7 +80,96 @@ STDIN_FILE_NAME = "-"
     help="JSON file containing event data passed to the Lambda function during invoke. If this option "
     "is not specified, no event is assumed. Pass in the value '-' to input JSON via stdin",
 )
+@click.option(
+    "--event-clipboard",
+    is_flag=True,
+    default=False,
+    help="Read the JSON event data passed to the Lambda function from the system clipboard. "
+    "Cannot be used with --event.",
+)
 @click.option("--no-event", is_flag=True, default=True, help="DEPRECATED: By default no event is assumed.", hidden=True)
+@click.option(
+    "--show-env",
+    is_flag=True,
+    default=False,
+    help="Print the environment variables the function will see before invoking it. "
+    "Values of AWS credentials are masked.",
+)
+@click.option(
+    "--after-invoke",
+    help="Shell command to run after the function completes, e.g. to verify its side effects. The response of the "
+    "function is passed to the command through stdin and the SAM_INVOKE_RESPONSE environment variable. "
+    "The invoke fails if the command exits with a non-zero code.",
+)
+@click.option(
+    "--expect-log",
+    multiple=True,
+    help="Text the logs of the function must contain, e.g. to verify that a code path was executed. "
+    "The invoke fails if the logs do not contain it. Can be repeated to expect several texts.",
+)
+@click.option(
+    "--x-ray-trace-id",
+    help="X-Ray trace id to invoke the function with, e.g. to correlate the invoke with other traces. "
+    "The function sees it in the _X_AMZN_TRACE_ID environment variable. Accepts either a root trace id like "
+    "1-5759e988-bd862e3fe1be46a994272793, or a full tracing header like "
+    "Root=1-5759e988-bd862e3fe1be46a994272793;Sampled=1.",
+)
+@click.option(
+    "--handled-error-exit-code",
+    type=click.IntRange(1, 255),
+    default=1,
+    show_default=True,
+    help="Exit code when the function fails with an error it returns on purpose, e.g. an error returned by a Go "
+    "handler. Errors without a stack trace are taken as handled, as the local runtime does not tell them apart.",
+)
+@click.option(
+    "--unhandled-error-exit-code",
+    type=click.IntRange(1, 255),
+    default=2,
+    show_default=True,
+    help="Exit code when the function fails with an unhandled error, i.e. an exception thrown by the handler, or "
+    "an error of the Lambda runtime like Runtime.ExitError when the function process crashes.",
+)
+@click.option(
+    "--timeout-exit-code",
+    type=click.IntRange(1, 255),
+    help="Exit code when the function times out. By default the invoke succeeds even if the function times out. "
+    "Aborting the invoke with Ctrl+C is not taken as a timeout.",
+)
+@click.option(
+    "--tz",
+    help="Timezone to invoke the function in, like Europe/Paris, e.g. to reproduce bugs in date and time handling. "
+    "It is set as the TZ environment variable of the function. Lambda functions run in UTC by default.",
+)
+@click.option(
+    "--random-seed",
+    help="Seed for the random number generators of the function, e.g. to test a function that uses randomness "
+    "deterministically. SAM can't seed the generators of the function itself: the seed is passed in the RANDOM_SEED "
+    "environment variable, and the handler must seed its generators with it when it is set.",
+)
+@click.option(
+    "--random-seed-env-var",
+    help="Name of the environment variable to pass --random-seed in, instead of RANDOM_SEED.",
+)
+@click.option(
+    "--stdout",
+    "stdout_file",
+    type=click.Path(dir_okay=False, writable=True),
+    help="Write the response of the function to this file instead of stdout, e.g. to capture it separately from "
+    "the logs in a test script. The file is overwritten if it exists.",
+)
+@click.option(
+    "--stderr",
+    "stderr_file",
+    type=click.Path(dir_okay=False, writable=True),
+    help="Write the logs of the function to this file instead of stderr. The file is overwritten if it exists.",
+)
+@click.option(
+    "--runtime",
+    type=click.Choice(sorted(RUNTIMES)),
+    help="Invoke the function with this runtime instead of the Runtime of the template, "
+    "e.g. to validate a handler against a newer runtime. Only applies to functions of PackageType Zip.",
+)
 @invoke_common_options
 @local_common_options
 @cli_framework_options
@@ -74,6 +202,41 @@ def cli(
     config_env,
     container_host,
     container_host_interface,
+    show_env,
+    debug_port_host,
+    runtime,
+    event_clipboard,
+    after_invoke,
+    container_hostname,
+    shm_size,
+    expect_log,
+    x_ray_trace_id,
+    handled_error_exit_code,
+    unhandled_error_exit_code,
+    timeout_exit_code,
+    cpu_proportional,
+    tz,
+    use_build_artifacts,
+    buffered_output,
+    container_labels_from_file,
+    env_file,
+    no_memory_limit,
+    stdout_file,
+    stderr_file,
+    random_seed,
+    random_seed_env_var,
+    aws_endpoint_url,
+    add_host,
+    import_values,
+    container_labels,
+    shutdown_timeout,
+    hook_name,
+    resource_attributes,
+    cloudwatch_style_logs,
+    container_entrypoint_override,
+    runtime_image_override,
+    force_linux_paths,
+    no_build_artifacts,
 ):
     """
     `sam local invoke` command entry point
@@ -101,6 +264,41 @@ def cli(
         parameter_overrides,
         container_host,
         container_host_interface,
+        show_env,
+        debug_port_host,
+        runtime,
+        event_clipboard,
+        after_invoke,
+        container_hostname,
+        shm_size,
+        expect_log,
+        x_ray_trace_id,
+        handled_error_exit_code,
+        unhandled_error_exit_code,
+        timeout_exit_code,
+        cpu_proportional,
+        tz,
+        use_build_artifacts,
+        buffered_output,
+        container_labels_from_file,
+        env_file,
+        no_memory_limit,
+        stdout_file,
+        stderr_file,
+        random_seed,
+        random_seed_env_var,
+        aws_endpoint_url,
+        add_host,
+        import_values,
+        container_labels,
+        shutdown_timeout,
+        hook_name,
+        resource_attributes,
+        cloudwatch_style_logs,
+        container_entrypoint_override,
+        runtime_image_override,
+        force_linux_paths,
+        no_build_artifacts,
     )  # pragma: no cover
 
 
@@ -125,6 +323,41 @@ def do_cli(  # pylint: disable=R0914
     parameter_overrides,
     container_host,
     container_host_interface,
+    show_env,
+    debug_port_host,
+    runtime,
+    event_clipboard,
+    after_invoke,
+    container_hostname,
+    shm_size,
+    expect_log,
+    x_ray_trace_id,
+    handled_error_exit_code,
+    unhandled_error_exit_code,
+    timeout_exit_code,
+    cpu_proportional,
+    tz,
+    use_build_artifacts,
+    buffered_output,
+    container_labels_from_file,
+    env_file,
+    no_memory_limit,
+    stdout_file,
+    stderr_file,
+    random_seed,
+    random_seed_env_var,
+    aws_endpoint_url,
+    add_host,
+    import_values,
+    container_labels,
+    shutdown_timeout,
+    hook_name,
+    resource_attributes,
+    cloudwatch_style_logs,
+    container_entrypoint_override,
+    runtime_image_override,
+    force_linux_paths,
+    no_build_artifacts,
 ):
     """
     Implementation of the ``cli`` method, just separated out for unit testing purposes
@@ -133,15 +366,30 @@ def do_cli(  # pylint: disable=R0914
     from samcli.commands.exceptions import UserException
     from samcli.lib.providers.exceptions import InvalidLayerReference
     from samcli.commands.local.cli_common.invoke_context import InvokeContext
-    from samcli.local.lambdafn.exceptions import FunctionNotFound
+    from samcli.local.lambdafn.exceptions import FunctionNotFound, FunctionTimeoutException, UnsupportedCodeLocation
     from samcli.commands.validate.lib.exceptions import InvalidSamDocumentException
     from samcli.commands.local.lib.exceptions import OverridesNotWellDefinedError, NoPrivilegeException
     from samcli.local.docker.manager import DockerImagePullFailedException
     from samcli.local.docker.lambda_debug_settings import DebuggingNotSupported
+    from samcli.local.services.base_local_service import LambdaOutputParser
+    from samcli.commands.local.lib.batch_item_failures import get_batch_records, report_batch_item_failures
+    from samcli.local.stepfunctions.exceptions import StateMachineDefinitionError, StateMachineExecutionFailed
+    from samcli.local.stepfunctions.state_machine import get_state_machine
 
     LOG.debug("local invoke command is called")
 
-    if event:
+    error_exit_codes = {
+        FUNCTION_ERROR_HANDLED: handled_error_exit_code,
+        FUNCTION_ERROR_UNHANDLED: unhandled_error_exit_code,
+        FUNCTION_ERROR_TIMEOUT: timeout_exit_code,
+    }
+    check_function_error = any(error_exit_codes.values())
+
+    if event_clipboard:
+        if event:
+            raise UserException("--event and --event-clipboard cannot be used together")
+        event_data = _get_event_from_clipboard()
+    elif event:
         event_data = _get_event(event)
     else:
         event_data = "{}"
@@ -169,13 +417,91 @@ def do_cli(  # pylint: disable=R0914
             shutdown=shutdown,
             container_host=container_host,
             container_host_interface=container_host_interface,
+            debug_host_ports=debug_port_host,
+            show_env=show_env,
+            runtime_override=runtime,
+            container_hostname=container_hostname,
+            shm_size=shm_size,
+            cpu_proportional=cpu_proportional,
+            timezone=tz,
+            use_build_artifacts=use_build_artifacts,
+            buffered_output=buffered_output,
+            container_labels_file=container_labels_from_file,
+            env_file=env_file,
+            no_memory_limit=no_memory_limit,
+            aws_endpoint_url=aws_endpoint_url,
+            add_host=add_host,
+            import_values_file=import_values,
+            container_labels=container_labels,
+            shutdown_timeout=shutdown_timeout,
+            hook_name=hook_name,
+            resource_attributes_file=resource_attributes,
+            cloudwatch_style_logs=cloudwatch_style_logs,
+            container_entrypoint=container_entrypoint_override,
+            runtime_image_override=runtime_image_override,
+            force_linux_paths=force_linux_paths,
+            no_build_artifacts=no_build_artifacts,
+            stdout_file=stdout_file,
+            stderr_file=stderr_file,
+            random_seed=random_seed,
+            random_seed_env_var=random_seed_env_var,
         ) as context:
 
+            state_machine = get_state_machine(context.stacks, function_identifier) if function_identifier else None
+            if state_machine:
+                _execute_state_machine(context, state_machine, event_data)
+                return
+
+            batch_records = get_batch_records(event_data)
+            stdout = context.stdout
+            capture_response = batch_records or after_invoke or check_function_error
+            if capture_response:
+                # Capture the response to report which batch records would be retried, to pass it on to the
+                # after-invoke command, or to find out whether the function failed
+                stdout_stream = io.BytesIO()
+                stdout = StreamWriter(stdout_stream)
+
+            stderr = context.stderr
+            if expect_log:
+                # Keep a copy of the logs to look for the expected texts, while still writing them out as they come
+                stderr = CapturingStreamWriter(stderr)
+
             # Invoke the function
-            context.local_lambda_runner.invoke(
-                context.function_identifier, event=event_data, stdout=context.stdout, stderr=context.stderr
-            )
+            timed_out = False
+            try:
+                context.local_lambda_runner.invoke(
+                    context.function_identifier,
+                    event=event_data,
+                    stdout=stdout,
+                    stderr=stderr,
+                    trace_id=get_trace_header(x_ray_trace_id) if x_ray_trace_id else None,
+                )
+            except FunctionTimeoutException as ex:
+                # The timeout is already reported in the logs of the function, and it leaves no response behind,
+                # which the checks of the response below handle as a timeout
+                LOG.debug("Function %s timed out: %s", ex.function_name, ex)
+                timed_out = True
+
+            if capture_response:
+                context.stdout.write(stdout_stream.getvalue())
+                context.stdout.flush()
+                lambda_response, _, _ = LambdaOutputParser.get_lambda_output(stdout_stream)
+
+            if batch_records:
+                event_source, record_ids = batch_records
+                report_batch_item_failures(event_source, record_ids, lambda_response)
 
+            if expect_log:
+                _check_expected_logs(expect_log, stderr.getvalue())
+
+            if after_invoke:
+                _run_after_invoke(after_invoke, lambda_response)
+
+            if check_function_error:
+                _check_function_error(lambda_response, timed_out, error_exit_codes, context.stderr)
+
+    except UnsupportedCodeLocation as ex:
+        raise UserException(str(ex), wrapped_from=ex.__class__.__name__) from ex
     except FunctionNotFound as ex:
         raise UserException(
             "Function {} not found in template".format(function_identifier), wrapped_from=ex.__class__.__name__
@@ -187,6 +513,8 @@ def do_cli(  # pylint: disable=R0914
         InvalidIntermediateImageError,
         DebuggingNotSupported,
         NoPrivilegeException,
+        StateMachineDefinitionError,
+        StateMachineExecutionFailed,
     ) as ex:
         raise UserException(str(ex), wrapped_from=ex.__class__.__name__) from ex
     except DockerImagePullFailedException as ex:
@@ -195,6 +523,127 @@ def do_cli(  # pylint: disable=R0914
         raise UserException(str(ex), wrapped_from=ex.__class__.__name__) from ex
 
 
+def _execute_state_machine(context, state_machine, event_data):
+    """
+    Executes a state machine locally, invoking the functions of its Task states, and writes its output to stdout
+
+    :param InvokeContext context: Context to invoke the functions in
+    :param samcli.local.stepfunctions.state_machine.StateMachine state_machine: State machine to execute
+    :param str event_data: Input of the execution, in JSON
+    :raises UserException: If the input is not valid JSON
+    """
+    from samcli.commands.exceptions import UserException
+    from samcli.local.stepfunctions.executor import LocalStateMachineExecutor
+
+    try:
+        execution_input = json.loads(event_data)
+    except ValueError as ex:
+        raise UserException("Event of state machine {} must be valid JSON: {}".format(state_machine.name, ex)) from ex
+
+    executor = LocalStateMachineExecutor(context.local_lambda_runner, stderr=context.stderr)
+    output = executor.execute(state_machine, execution_input)
+
+    context.stdout.write(json.dumps(output).encode("utf-8"))
+    context.stdout.flush()
+
+
+def _check_expected_logs(expected_logs, logs):
+    """
+    Checks the logs of the function contain every expected text
+
+    :param tuple(str) expected_logs: Texts the logs must contain
+    :param bytes logs: Logs written by the function during the invoke
+    :raises UserException: If a text is not found in the logs
+    """
+    from samcli.commands.exceptions import UserException
+
+    logs = logs.decode("utf-8", errors="replace")
+    missing = [expected for expected in expected_logs if expected not in logs]
+    if missing:
+        raise UserException(
+            "Logs of the function do not contain the expected text: {}".format(
+                ", ".join("'{}'".format(expected) for expected in missing)
+            )
+        )
+
+
+def _get_function_error(lambda_response, timed_out=False):
+    """
+    Finds out how the function failed from its response, like the X-Amz-Function-Error header of Lambda
+
+    :param str lambda_response: Response of the function, empty if the function did not respond
+    :param bool timed_out: Whether the function timed out, in which case it did not respond
+    :return str: Kind of error of the function, or None if the function succeeded or the invoke was aborted
+    """
+    from samcli.local.services.base_local_service import LambdaOutputParser
+
+    if timed_out:
+        return FUNCTION_ERROR_TIMEOUT
+
+    if not lambda_response or not LambdaOutputParser.is_lambda_error_response(lambda_response):
+        return None
+
+    error = json.loads(lambda_response)
+    if "Task timed out" in str(error.get("errorMessage")):
+        return FUNCTION_ERROR_TIMEOUT
+
+    # Lambda reports exceptions thrown by the handler as Unhandled, just like errors of the runtime itself such as
+    # Runtime.ExitError. The runtimes add the stack trace of the exception to the response, as stackTrace or as trace
+    # for Node.js, while an error the handler returns on purpose, like an error value in Go, comes without one.
+    if str(error.get("errorType")).startswith("Runtime.") or error.get("stackTrace") or error.get("trace"):
+        return FUNCTION_ERROR_UNHANDLED
+
+    return FUNCTION_ERROR_HANDLED
+
+
+def _format_function_error(lambda_response):
+    """
+    Formats the error returned by the function for humans, with its type, its message and its stack trace
+
+    :param str lambda_response: Error response of the function, with the errorType, errorMessage and stackTrace keys
+    :return str: Error of the function, one line per frame of the stack trace
+    """
+    error = json.loads(lambda_response)
+    lines = ["{}: {}".format(error.get("errorType"), error.get("errorMessage"))]
+
+    stack_trace = error.get("stackTrace") or []
+    if isinstance(stack_trace, str):
+        stack_trace = stack_trace.splitlines()
+    for frame in stack_trace:
+        # Python runtimes return each frame as a list of file, line number, function name and code
+        if isinstance(frame, list):
+            frame = 'File "{}", line {}, in {}: {}'.format(*frame) if len(frame) == 4 else " ".join(map(str, frame))
+        lines.append("    {}".format(str(frame).strip()))
+
+    return "\n".join(lines) + "\n"
+
+
+def _check_function_error(lambda_response, timed_out, error_exit_codes, stderr):
+    """
+    Fails the invoke with the exit code configured for the error of the function, if it failed. The error and its
+    stack trace are always written to stderr, while the error response stays on stdout for scripts to parse.
+
+    :param str lambda_response: Response of the function
+    :param bool timed_out: Whether the function timed out
+    :param dict error_exit_codes: Exit code of each kind of function error, None to let the invoke succeed
+    :param samcli.lib.utils.stream_writer.StreamWriter stderr: Stream to write the error of the function to
+    :raises FunctionErrorException: If the function failed and an exit code is configured for its error
+    """
+    from samcli.commands.local.cli_common.user_exceptions import FunctionErrorException
+    from samcli.local.services.base_local_service import LambdaOutputParser
+
+    if LambdaOutputParser.is_lambda_error_response(lambda_response):
+        stderr.write(_format_function_error(lambda_response).encode("utf-8"))
+        stderr.flush()
+
+    function_error = _get_function_error(lambda_response, timed_out)
+    exit_code = error_exit_codes.get(function_error)
+    if exit_code:
+        raise FunctionErrorException(
+            "Function failed with {} error".format(FUNCTION_ERROR_DESCRIPTIONS[function_error]), exit_code
+        )
+
+
 def _get_event(event_file_name):
     """
     Read the event JSON data from the given file. If no file is provided, read the event from stdin.
@@ -211,3 +660,51 @@ def _get_event(event_file_name):
     # accidentally closing a standard stream
     with click.open_file(event_file_name, "r", encoding="utf-8") as fp:
         return fp.read()
+
+
+def _get_event_from_clipboard():
+    """
+    Read the event JSON data from the system clipboard, using the clipboard tools of the OS.
+
+    :return string: Contents of the clipboard
+    :raises UserException: If the clipboard cannot be read or is empty
+    """
+    from samcli.commands.exceptions import UserException
+
+    LOG.info("Reading invoke payload from the clipboard")
+
+    for command in CLIPBOARD_COMMANDS.get(platform.system().lower(), []):
+        try:
+            event_data = subprocess.check_output(command, stderr=subprocess.DEVNULL).decode("utf-8")
+        except (OSError, subprocess.CalledProcessError) as ex:
+            LOG.debug("Unable to read the clipboard with %s", command[0], exc_info=ex)
+            continue
+
+        if not event_data.strip():
+            raise UserException("The clipboard is empty, copy the event JSON before invoking")
+        return event_data
+
+    raise UserException(
+        "Unable to read the event from the clipboard on this system. "
+        "Use --event to pass the event from a file or from stdin instead."
+    )
+
+
+def _run_after_invoke(command, lambda_response):
+    """
+    Run the after-invoke shell command, passing the response of the function through stdin and an environment variable
+
+    :param string command: Shell command to run
+    :param string lambda_response: Response of the function
+    :raises UserException: If the command exits with a non-zero code
+    """
+    from samcli.commands.exceptions import UserException
+
+    LOG.info("Running after-invoke command: %s", command)
+
+    env = os.environ.copy()
+    env[AFTER_INVOKE_RESPONSE_ENV_VAR] = lambda_response
+    result = subprocess.run(command, shell=True, input=lambda_response.encode("utf-8"), env=env, check=False)
+
+    if result.returncode != 0:
+        raise UserException("After-invoke command failed with exit code {}".format(result.returncode))
@@ -0,0 +1,115 @@
+"""
+Interprets the partial batch response of functions invoked by batch event sources (SQS, Kinesis, DynamoDB Streams)
+"""
+import json
+import logging
+from typing import List, Optional, Tuple
+
+LOG = logging.getLogger(__name__)
+
+SQS_EVENT_SOURCE = "aws:sqs"
+
+# Event sources supporting ReportBatchItemFailures, mapped to the path of the record field that
+# Lambda expects as the itemIdentifier of a batch item failure
+BATCH_RECORD_ID_PATHS = {
+    SQS_EVENT_SOURCE: ("messageId",),
+    "aws:kinesis": ("kinesis", "sequenceNumber"),
+    "aws:dynamodb": ("dynamodb", "SequenceNumber"),
+}
+
+
+def get_batch_records(event_data: str) -> Optional[Tuple[str, List[str]]]:
+    """
+    Finds the event source and the record identifiers of a batch event
+
+    Parameters
+    ----------
+    event_data str
+        Event the function is invoked with
+
+    Returns
+    -------
+    tuple(str, list(str))
+        Event source and identifiers of the records in the event, or None if the event is not a batch event
+    """
+    try:
+        event = json.loads(event_data)
+    except ValueError:
+        return None
+
+    records = event.get("Records") if isinstance(event, dict) else None
+    if not records or not isinstance(records, list) or not isinstance(records[0], dict):
+        return None
+
+    event_source = records[0].get("eventSource")
+    id_path = BATCH_RECORD_ID_PATHS.get(event_source)
+    if not id_path:
+        return None
+
+    record_ids = []
+    for record in records:
+        value = record
+        for key in id_path:
+            value = value.get(key) if isinstance(value, dict) else None
+        if value is None:
+            return None
+        record_ids.append(str(value))
+
+    return event_source, record_ids
+
+
+def report_batch_item_failures(event_source: str, record_ids: List[str], lambda_response: str) -> None:
+    """
+    Prints which records of the batch the event source would retry, given the batchItemFailures returned by the
+    function. SQS retries only the failed messages, while streams retry from the first failed record onwards.
+
+    Parameters
+    ----------
+    event_source str
+        Event source of the batch, like aws:sqs
+    record_ids list(str)
+        Identifiers of the records in the batch, in order
+    lambda_response str
+        Response returned by the function
+    """
+    try:
+        response = json.loads(lambda_response)
+    except ValueError:
+        return
+
+    if not isinstance(response, dict) or "batchItemFailures" not in response:
+        return
+
+    failures = response["batchItemFailures"]
+    if not failures:
+        LOG.info("No batch item failures reported, no records would be retried")
+        return
+
+    if not isinstance(failures, list):
+        LOG.warning("batchItemFailures must be a list, the whole batch would be retried")
+        return
+
+    failed_ids = []
+    for failure in failures:
+        item_identifier = failure.get("itemIdentifier") if isinstance(failure, dict) else None
+        if not item_identifier or str(item_identifier) not in record_ids:
+            LOG.warning(
+                "Batch item failure %s does not identify a record of the event, the whole batch would be retried",
+                json.dumps(failure),
+            )
+            return
+        failed_ids.append(str(item_identifier))
+
+    if event_source == SQS_EVENT_SOURCE:
+        retried_ids = [record_id for record_id in record_ids if record_id in failed_ids]
+    else:
+        first_failed = min(record_ids.index(failed_id) for failed_id in failed_ids)
+        retried_ids = record_ids[first_failed:]
+
+    LOG.info(
+        "%d of %d records reported as failed, the following records would be retried:",
+        len(set(failed_ids)),
+        len(record_ids),
+    )
+    for record_id in retried_ids:
+        LOG.info("  %s", record_id)
@@ -5,7 +5,14 @@ Information and debug options for a specific runtime.
 
 class DebugContext:
     def __init__(
-        self, debug_ports=None, debugger_path=None, debug_args=None, debug_function=None, container_env_vars=None
+        self,
+        debug_ports=None,
+        debugger_path=None,
+        debug_args=None,
+        debug_function=None,
+        container_env_vars=None,
+        function_debug_ports=None,
+        debug_host_ports=None,
     ):
         """
         Initialize the Debug Context with Lambda debugger options
@@ -16,6 +23,10 @@ class DebugContext:
         :param string debug_function: The Lambda function logicalId that will have the debugging options enabled in case
         of warm containers option is enabled
         :param dict container_env_vars: Additional environmental variables to be set.
+        :param dict function_debug_ports: Optional. Mapping of Lambda function logicalId to the debug port assigned to
+        it, used when a debug port range was given to debug multiple functions at the same time
+        :param dict debug_host_ports: Optional. Mapping of debug port to the port on the host it is bound to, when
+        it differs from the debug port
         """
 
         self.debug_ports = debug_ports
@@ -23,6 +34,32 @@ class DebugContext:
         self.debug_args = debug_args
         self.debug_function = debug_function
         self.container_env_vars = container_env_vars
+        self.function_debug_ports = function_debug_ports
+        self.debug_host_ports = debug_host_ports
+
+    def for_function(self, function_name):
+        """
+        Returns the debug context to use for the given function. When debug ports were assigned per function,
+        the returned context only exposes the port assigned to this function.
+
+        :param string function_name: The Lambda function logicalId
+        :return DebugContext: Debug context for the function, None if the function has no debug port assigned
+        """
+        if not self.function_debug_ports:
+            return self
+
+        debug_port = self.function_debug_ports.get(function_name)
+        if not debug_port:
+            return None
+
+        return DebugContext(
+            debug_ports=(debug_port,),
+            debugger_path=self.debugger_path,
+            debug_args=self.debug_args,
+            debug_function=function_name,
+            container_env_vars=self.container_env_vars,
+            debug_host_ports=self.debug_host_ports,
+        )
 
     def __bool__(self):
         return bool(self.debug_ports)
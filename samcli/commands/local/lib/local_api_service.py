@@ -5,9 +5,13 @@ Connects the CLI with Local API Gateway service.
 import logging
 import os
 
+import click
+
 from samcli.commands.local.lib.exceptions import NoApisDefined
-from samcli.local.apigw.local_apigw_service import LocalApigwService
+from samcli.local.apigw.local_apigw_service import LocalApigwService, Route
+from samcli.lib.providers.api_collector import ApiCollector
 from samcli.lib.providers.api_provider import ApiProvider
+from samcli.lib.providers.provider import Cors
 
 LOG = logging.getLogger(__name__)
 
@@ -18,7 +22,20 @@ class LocalApiService:
     Lambda function.
     """
 
-    def __init__(self, lambda_invoke_context, port, host, static_dir):
+    def __init__(
+        self,
+        lambda_invoke_context,
+        port,
+        host,
+        static_dir,
+        binary_media_types=None,
+        forwarded_host=None,
+        forwarded_proto=None,
+        cors_allow_origin=None,
+        throttle_rate=None,
+        max_concurrent_invocations=None,
+        invocation_queue_timeout=None,
+    ):
         """
         Initialize the local API service.
 
@@ -27,14 +44,32 @@ class LocalApiService:
         :param int port: Port to listen on
         :param string host: Local hostname or IP address to bind to
         :param string static_dir: Optional, directory from which static files will be mounted
+        :param list(str) binary_media_types: Optional, binary media types in addition to the ones of the template
+        :param string forwarded_host: Optional, host passed to the functions in the Host and X-Forwarded-Host headers
+        :param string forwarded_proto: Optional, protocol passed to the functions in the X-Forwarded-Proto header
+        :param string cors_allow_origin: Optional, origin allowed by CORS instead of the one of the template
+        :param float throttle_rate: Optional, maximum number of requests per second of every route
+        :param int max_concurrent_invocations: Optional, maximum number of functions invoked at once
+        :param float invocation_queue_timeout: Optional, seconds a request waits for a function to finish when
+            max_concurrent_invocations functions are running
         """
 
         self.port = port
         self.host = host
         self.static_dir = static_dir
+        self.forwarded_host = forwarded_host
+        self.forwarded_proto = forwarded_proto
+        self.throttle_rate = throttle_rate
+        self.max_concurrent_invocations = max_concurrent_invocations
+        self.invocation_queue_timeout = invocation_queue_timeout
 
         self.cwd = lambda_invoke_context.get_cwd()
         self.api_provider = ApiProvider(lambda_invoke_context.stacks, cwd=self.cwd)
+        for binary_media_type in binary_media_types or []:
+            normalized_binary_media_type = ApiCollector.normalize_binary_media_type(binary_media_type)
+            self.api_provider.api.binary_media_types_set.add(normalized_binary_media_type)
+        if cors_allow_origin:
+            self._override_cors_allow_origin(self.api_provider.api, cors_allow_origin)
         self.lambda_runner = lambda_invoke_context.local_lambda_runner
         self.stderr_stream = lambda_invoke_context.stderr
 
@@ -63,10 +98,21 @@ class LocalApiService:
             port=self.port,
             host=self.host,
             stderr=self.stderr_stream,
+            forwarded_host=self.forwarded_host,
+            forwarded_proto=self.forwarded_proto,
+            throttle_rate=self.throttle_rate,
+            max_concurrent_invocations=self.max_concurrent_invocations,
+            invocation_queue_timeout=self.invocation_queue_timeout,
         )
 
         service.create()
 
+        if self.port == 0:
+            # Let the OS pick a free port, and print it to stdout so scripts starting the API can read it
+            service.bind()
+            self.port = service.port
+            click.echo("Listening on http://{}:{}".format(self.host, self.port))
+
         # Print out the list of routes that will be mounted
         self._print_routes(self.api_provider.api.routes, self.host, self.port)
         LOG.info(
@@ -78,6 +124,23 @@ class LocalApiService:
 
         service.run()
 
+    @staticmethod
+    def _override_cors_allow_origin(api, allow_origin):
+        """
+        Overrides the origin allowed by the CORS configuration of the API. An API without CORS configuration gets
+        one that allows every method, like the string form of the Cors property of AWS::Serverless::Api
+
+        :param samcli.lib.providers.provider.Api api: API to override the CORS configuration of
+        :param string allow_origin: Origin to allow
+        """
+        if api.cors:
+            api.cors = api.cors._replace(allow_origin=allow_origin)
+        else:
+            api.cors = Cors(allow_origin=allow_origin, allow_methods=",".join(sorted(Route.ANY_HTTP_METHODS)))
+
+        # Routes only answer CORS preflight requests if they accept the OPTIONS method
+        api.routes = ApiCollector.normalize_cors_methods(api.routes, api.cors)
+
     @staticmethod
     def _print_routes(routes, host, port):
         """
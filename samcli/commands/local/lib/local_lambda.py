@@ -2,25 +2,29 @@
 Implementation of Local Lambda runner
 """
 
+import copy
 import os
 import logging
-from typing import Any, Dict, Optional, cast
+import uuid
+from datetime import datetime, timezone as dt_timezone
+from typing import Any, Dict, List, Optional, Set, Union, cast
 import boto3
 
-from botocore.credentials import Credentials
+from botocore.credentials import Credentials, RefreshableCredentials
 
 from samcli.commands.local.lib.debug_context import DebugContext
 from samcli.lib.providers.sam_function_provider import SamFunctionProvider
 from samcli.lib.utils.codeuri import resolve_code_path
 from samcli.lib.utils.packagetype import ZIP, IMAGE
-from samcli.lib.utils.stream_writer import StreamWriter
+from samcli.lib.utils.stream_writer import LinePrefixingStreamWriter, StreamWriter, cloudwatch_log_prefix
 from samcli.local.docker.container import ContainerResponseException
 from samcli.local.lambdafn.env_vars import EnvironmentVariables
 from samcli.local.lambdafn.config import FunctionConfig
-from samcli.local.lambdafn.exceptions import FunctionNotFound
+from samcli.local.lambdafn.exceptions import FunctionNotFound, UnsupportedCodeLocation
 from samcli.commands.local.lib.exceptions import InvalidIntermediateImageError
 from samcli.commands.local.lib.exceptions import OverridesNotWellDefinedError, NoPrivilegeException
-from samcli.lib.providers.provider import Function
+from samcli.commands.local.cli_common.user_exceptions import InvalidSamTemplateException
+from samcli.lib.providers.provider import Function, LayerVersion
 from samcli.local.lambdafn.runtime import LambdaRuntime
 
 LOG = logging.getLogger(__name__)
@@ -34,6 +38,10 @@ class LocalLambdaRunner:
 
     MAX_DEBUG_TIMEOUT = 36000  # 10 hours in seconds
 
+    # Environment variables whose values are masked when printing the function environment
+    _SECRET_ENV_VARS = ("AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN")
+    _MASKED_VALUE = "****"
+
     def __init__(
         self,
         local_runtime: LambdaRuntime,
@@ -45,6 +53,18 @@ class LocalLambdaRunner:
         debug_context: Optional[DebugContext] = None,
         container_host: Optional[str] = None,
         container_host_interface: Optional[str] = None,
+        show_env: bool = False,
+        runtime_override: Optional[str] = None,
+        timezone: Optional[str] = None,
+        build_dir: Optional[str] = None,
+        up_to_date_build_artifacts: Optional[Set[str]] = None,
+        buffered_output: bool = False,
+        env_file_values: Optional[Dict[str, str]] = None,
+        random_seed: Optional[str] = None,
+        random_seed_env_var: Optional[str] = None,
+        aws_endpoint_url: Optional[str] = None,
+        cloudwatch_style_logs: bool = False,
+        container_entrypoint: Optional[List[str]] = None,
     ) -> None:
         """
         Initializes the class
@@ -59,6 +79,28 @@ class LocalLambdaRunner:
         :param DebugContext debug_context: Optional. Debug context for the function (includes port, args, and path).
         :param string container_host: Optional. Host of locally emulated Lambda container
         :param string container_host_interface: Optional. Interface that Docker host binds ports to
+        :param bool show_env: Optional. Print the environment variables of the function before invoking it.
+        :param string runtime_override: Optional. Runtime to invoke Zip functions with, instead of their Runtime.
+        :param string timezone: Optional. Timezone to run the functions in, set as their TZ environment variable.
+        :param string build_dir: Optional. Directory of "sam build" artifacts. The code of functions and layers that
+            have build artifacts in it is mounted from there, instead of from their CodeUri.
+        :param set up_to_date_build_artifacts: Optional. Full paths of the functions and layers whose build artifacts
+            are used, the others use their CodeUri. By default, all the build artifacts in build_dir are used.
+        :param bool buffered_output: Optional. Let runtimes buffer the output of functions. By default, environment
+            variables that disable the output buffering of the runtime are set, like PYTHONUNBUFFERED for Python.
+        :param dict env_file_values: Optional. Values of environment variables for all the functions, read from a
+            dotenv file. They also set the variables a function does not define. The values of env_vars_values take
+            precedence over them.
+        :param string random_seed: Optional. Seed for the random number generators of the functions, set as an
+            environment variable that handlers can seed their generators with.
+        :param string random_seed_env_var: Optional. Name of the environment variable to set the random seed in,
+            RANDOM_SEED by default.
+        :param string aws_endpoint_url: Optional. Endpoint of the AWS services the functions call, like the URL of
+            LocalStack, set as their AWS_ENDPOINT_URL environment variables.
+        :param bool cloudwatch_style_logs: Optional. Start each line of the logs of the functions with a log stream
+            name and a timestamp, like CloudWatch Logs shows them.
+        :param list container_entrypoint: Optional. Entrypoint that replaces the one of the containers of the
+            functions, including the one that starts the debugger.
         """
 
         self.local_runtime = local_runtime
@@ -72,6 +114,18 @@ class LocalLambdaRunner:
         self._boto3_region: Optional[str] = None
         self.container_host = container_host
         self.container_host_interface = container_host_interface
+        self.show_env = show_env
+        self.runtime_override = runtime_override
+        self.timezone = timezone
+        self.build_dir = build_dir
+        self.up_to_date_build_artifacts = up_to_date_build_artifacts
+        self.buffered_output = buffered_output
+        self.env_file_values = env_file_values or {}
+        self.random_seed = random_seed
+        self.random_seed_env_var = random_seed_env_var
+        self.aws_endpoint_url = aws_endpoint_url
+        self.cloudwatch_style_logs = cloudwatch_style_logs
+        self.container_entrypoint = container_entrypoint
 
     def invoke(
         self,
@@ -79,6 +133,7 @@ class LocalLambdaRunner:
         event: str,
         stdout: Optional[StreamWriter] = None,
         stderr: Optional[StreamWriter] = None,
+        trace_id: Optional[str] = None,
     ) -> None:
         """
         Find the Lambda function with given name and invoke it. Pass the given event to the function and return
@@ -86,6 +141,17 @@ class LocalLambdaRunner:
 
         This function will block until either the function completes or times out.
 
+        The two streams are never mixed, so callers can present the result and the logs separately:
+
+        * ``stdout`` only receives the response of the function, written in a single call once the invoke is done.
+          Nothing else is written to it, even when the function prints to its own stdout.
+        * ``stderr`` receives the logs of the Lambda runtime and everything the function prints, from a background
+          thread while the function runs. With cold containers all the logs are written by the time this method
+          returns. With warm containers the container keeps running after the invoke, and its logs keep going to
+          the stream of the first invoke until the container is stopped.
+
+        Neither stream is closed by this method, closing them is up to the caller.
+
         Parameters
         ----------
         function_identifier str
@@ -96,6 +162,8 @@ class LocalLambdaRunner:
             Stream writer to write the output of the Lambda function to.
         stderr samcli.lib.utils.stream_writer.StreamWriter
             Stream writer to write the Lambda runtime logs to.
+        trace_id str
+            Optional. X-Ray tracing header to invoke the function with, the function sees it as _X_AMZN_TRACE_ID
 
         Raises
         ------
@@ -107,6 +175,13 @@ class LocalLambdaRunner:
         function = self.provider.get(function_identifier)
 
         if not function:
+            if self.provider.has_s3_code_location(function_identifier):
+                raise UnsupportedCodeLocation(
+                    "Function '{}' has its code in an S3 location, which SAM CLI does not support invoking locally. "
+                    "Set CodeUri to the local path of the function code or of its build artifact, "
+                    "for example the directory created by 'sam build', to invoke it locally".format(function_identifier)
+                )
+
             all_function_full_paths = [f.full_path for f in self.provider.get_all()]
             available_function_message = "{} not found. Possible options in your template: {}".format(
                 function_identifier, all_function_full_paths
@@ -115,7 +190,15 @@ class LocalLambdaRunner:
             raise FunctionNotFound("Unable to find a Function with name '{}'".format(function_identifier))
 
         LOG.debug("Found one Lambda function with name '%s'", function_identifier)
+        if self.runtime_override:
+            if function.packagetype == ZIP:
+                LOG.info("Overriding runtime %s with %s", function.runtime, self.runtime_override)
+                function = function._replace(runtime=self.runtime_override)
+            else:
+                LOG.warning("Ignoring the runtime override, %s is not a function of PackageType Zip", function.name)
         if function.packagetype == ZIP:
+            if not self.runtime_override:
+                self._warn_pinned_runtime_version(function)
             LOG.info("Invoking %s (%s)", function.handler, function.runtime)
         elif function.packagetype == IMAGE:
             if not function.imageuri:
@@ -125,16 +208,28 @@ class LocalLambdaRunner:
             LOG.info("Invoking Container created from %s", function.imageuri)
         config = self.get_invoke_config(function)
 
+        if self.show_env:
+            self._print_env_vars(function.name, config.env_vars.resolve())
+
+        # When debug ports are assigned per function, only expose the port that belongs to this function
+        debug_context = self.debug_context.for_function(function.name) if self.debug_context else self.debug_context
+
+        if self.cloudwatch_style_logs and stderr:
+            log_stream_name = self._make_log_stream_name()
+            LOG.debug("Logs of %s are prefixed with the log stream name %s", function.name, log_stream_name)
+            stderr = LinePrefixingStreamWriter(stderr, cloudwatch_log_prefix(log_stream_name))
+
         # Invoke the function
         try:
             self.local_runtime.invoke(
                 config,
                 event,
-                debug_context=self.debug_context,
+                debug_context=debug_context,
                 stdout=stdout,
                 stderr=stderr,
                 container_host=self.container_host,
                 container_host_interface=self.container_host_interface,
+                trace_id=trace_id,
             )
         except ContainerResponseException:
             # NOTE(sriram-mv): This should still result in a exit code zero to avoid regressions.
@@ -151,6 +246,111 @@ class LocalLambdaRunner:
 
             raise
 
+    @staticmethod
+    def _make_log_stream_name() -> str:
+        """
+        Makes up a log stream name in the format of the log streams of Lambda, like 2021/09/01/[$LATEST]<32 hex digits>
+        """
+        return "{}/[$LATEST]{}".format(datetime.now(dt_timezone.utc).strftime("%Y/%m/%d"), uuid.uuid4().hex)
+
+    @staticmethod
+    def _warn_pinned_runtime_version(function: Function) -> None:
+        """
+        Warns that the runtime version pinned by the RuntimeManagementConfig of the function is not honored locally.
+        The local images only provide one version of every runtime, which can differ from the pinned one.
+
+        Parameters
+        ----------
+        function : samcli.commands.local.lib.provider.Function
+            Lambda function that is about to be invoked
+        """
+        config = function.runtime_management_config
+        if not isinstance(config, dict) or config.get("UpdateRuntimeOn") != "Manual":
+            return
+
+        LOG.warning(
+            "Function %s pins the runtime version %s with its RuntimeManagementConfig, which is not supported "
+            "locally. It is invoked with the version of %s of the local image, which can differ from the pinned one.",
+            function.name,
+            config.get("RuntimeVersionArn"),
+            function.runtime,
+        )
+
+    def _print_env_vars(self, function_name: str, env_vars: Dict[str, str]) -> None:
+        """
+        Prints the environment variables the function will see, masking the values of AWS credentials
+
+        Parameters
+        ----------
+        function_name str
+            Name of the function that is invoked
+        env_vars dict
+            Resolved environment variables of the function
+        """
+        LOG.info("Environment variables for function '%s':", function_name)
+        for name, value in sorted(env_vars.items()):
+            if name in self._SECRET_ENV_VARS and value:
+                value = self._MASKED_VALUE
+            LOG.info("  %s=%s", name, value)
+
+    def _get_build_artifacts_path(self, resource: Union[Function, LayerVersion]) -> Optional[str]:
+        """
+        Returns the path of the build artifacts of the function or layer in the build directory, if they exist
+
+        Parameters
+        ----------
+        resource samcli.lib.providers.provider.Function or samcli.lib.providers.provider.LayerVersion
+            Lambda function or layer to find the build artifacts of
+
+        Returns
+        -------
+        str
+            Absolute path of the build artifacts of the function or layer, or None if they don't exist
+        """
+        if not self.build_dir:
+            return None
+
+        if self.up_to_date_build_artifacts is not None and resource.full_path not in self.up_to_date_build_artifacts:
+            LOG.debug("Build artifacts of %s are missing or outdated, using its CodeUri", resource.full_path)
+            return None
+
+        build_artifacts_path = resource.get_build_dir(self.build_dir)
+        if not os.path.isdir(build_artifacts_path):
+            LOG.debug("No build artifacts found for %s at %s", resource.full_path, build_artifacts_path)
+            return None
+
+        LOG.debug("Using build artifacts of %s at %s", resource.full_path, build_artifacts_path)
+        return os.path.abspath(build_artifacts_path)
+
+    def _get_layers(self, function: Function) -> List[LayerVersion]:
+        """
+        Returns the layers of the function, with the layers defined in the template pointing at their build artifacts
+        when they exist. Both the container mounts and the --watch observers use the CodeUri of the layers, so they
+        see the same code.
+
+        Parameters
+        ----------
+        function samcli.lib.providers.provider.Function
+            Lambda function to get the layers of
+
+        Returns
+        -------
+        list(samcli.lib.providers.provider.LayerVersion)
+            Layers of the function
+        """
+        if not self.build_dir:
+            return function.layers
+
+        layers = []
+        for layer in function.layers:
+            build_artifacts_path = self._get_build_artifacts_path(layer) if layer.is_defined_within_template else None
+            if build_artifacts_path:
+                # The layers are shared with the function provider, so the build artifacts are set on a copy
+                layer = copy.copy(layer)
+                layer.codeuri = build_artifacts_path
+            layers.append(layer)
+        return layers
+
     def is_debugging(self) -> bool:
         """
         Are we debugging the invoke?
@@ -174,7 +374,7 @@ class LocalLambdaRunner:
         env_vars = self._make_env_vars(function)
         code_abs_path = None
         if function.packagetype == ZIP:
-            code_abs_path = resolve_code_path(self.cwd, function.codeuri)
+            code_abs_path = self._get_build_artifacts_path(function) or resolve_code_path(self.cwd, function.codeuri)
             LOG.debug("Resolved absolute path to code is %s", code_abs_path)
 
         function_timeout = function.timeout
@@ -193,12 +393,46 @@ class LocalLambdaRunner:
             imageconfig=function.imageconfig,
             packagetype=function.packagetype,
             code_abs_path=code_abs_path,
-            layers=function.layers,
+            layers=self._get_layers(function),
             memory=function.memory,
             timeout=function_timeout,
             env_vars=env_vars,
+            network_aliases=self._get_network_aliases(function),
+            entrypoint=self.container_entrypoint,
         )
 
+    @staticmethod
+    def _get_network_aliases(function: Function) -> Optional[List[str]]:
+        """
+        Returns the aliases the container of the function is reachable by on the Docker network, as set by
+        NetworkAliases in the Metadata of the function
+
+        Parameters
+        ----------
+        function samcli.commands.local.lib.provider.Function
+            Lambda function to get the aliases of
+
+        Returns
+        -------
+        list(str)
+            Network aliases of the function, or None if it has none
+        """
+        aliases = (function.metadata or {}).get("NetworkAliases")
+        if not aliases:
+            return None
+
+        if isinstance(aliases, str):
+            aliases = [aliases]
+
+        if not isinstance(aliases, list) or not all(isinstance(alias, str) for alias in aliases):
+            raise InvalidSamTemplateException(
+                "NetworkAliases in the Metadata of function {} must be a string or a list of strings".format(
+                    function.name
+                )
+            )
+
+        return aliases
+
     def _make_env_vars(self, function: Function) -> EnvironmentVariables:
         """Returns the environment variables configuration for this function
 
@@ -250,6 +484,12 @@ class LocalLambdaRunner:
             LOG.debug("Environment variables overrides data is standard format")
             overrides = self.env_vars_values.get(name, None)
 
+        # Values of the dotenv file apply to every function, the JSON overrides win on conflict. Unlike the JSON
+        # overrides, they also set the variables the template does not declare for the function.
+        if self.env_file_values:
+            variables = {**self.env_file_values, **(variables or {})}
+            overrides = {**self.env_file_values, **(overrides or {})}
+
         shell_env = os.environ
         aws_creds = self.get_aws_creds()
 
@@ -262,6 +502,12 @@ class LocalLambdaRunner:
             shell_env_values=shell_env,
             override_values=overrides,
             aws_creds=aws_creds,
+            timezone=self.timezone,
+            runtime=function.runtime,
+            buffered_output=self.buffered_output,
+            random_seed=self.random_seed,
+            random_seed_env_var=self.random_seed_env_var,
+            aws_endpoint_url=self.aws_endpoint_url,
         )  # EnvironmentVariables is not yet annotated with type hints, disable mypy check for now. type: ignore
 
     def _get_session_creds(self) -> Credentials:
@@ -306,6 +552,12 @@ class LocalLambdaRunner:
             # If we were unable to load credentials, then just return result. We will use the default
             return result
 
+        # Profiles using SSO or assuming a role, possibly through a chain of source profiles, resolve to temporary
+        # credentials that botocore refreshes when they expire. Freeze them, so that the key, the secret and the token
+        # passed to the function all come from the same refresh.
+        if isinstance(creds, RefreshableCredentials):
+            creds = creds.get_frozen_credentials()
+
         # Only add the key, if its value is present
         if hasattr(creds, "access_key") and creds.access_key:
             result["key"] = creds.access_key
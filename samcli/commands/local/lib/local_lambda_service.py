@@ -3,6 +3,8 @@ Connects the CLI with Local Lambda Invoke Service.
 """
 import logging
 
+import click
+
 from samcli.local.lambda_service.local_lambda_invoke_service import LocalLambdaInvokeService
 
 LOG = logging.getLogger(__name__)
@@ -48,6 +50,12 @@ class LocalLambdaService:
 
         service.create()
 
+        if self.port == 0:
+            # Let the OS pick a free port, and print it to stdout so scripts starting the service can read it
+            service.bind()
+            self.port = service.port
+            click.echo("Listening on http://{}:{}".format(self.host, self.port))
+
         LOG.info(
             "Starting the Local Lambda Service. You can now invoke your Lambda Functions defined in your template"
             " through the endpoint."
@@ -29,7 +29,8 @@ Allows you to run your Serverless application locally for quick development & te
  languages like Node & Python, or a build directory that stores your compiled artifacts or a JAR file. If you are using
  a interpreted language, local changes will be available immediately in Docker container on every invoke. For more
  compiled languages or projects requiring complex packing support, we recommended you run your own building solution
-and point SAM to the directory or file containing build artifacts.
+and point SAM to the directory or file containing build artifacts. GET /_sam/health responds 200 once the server
+is ready to serve requests.
 """
 
 
@@ -47,6 +48,55 @@ and point SAM to the directory or file containing build artifacts.
     default="public",
     help="Any static assets (e.g. CSS/Javascript/HTML) files located in this directory " "will be presented at /",
 )
+@click.option(
+    "--binary-media-types",
+    multiple=True,
+    help="Content type whose request bodies are base64 encoded before being passed to the function, in addition "
+    "to the BinaryMediaTypes of the template. Use */* to treat every request as binary. "
+    "Can be specified multiple times, e.g. --binary-media-types image/png --binary-media-types application/x-protobuf",
+)
+@click.option(
+    "--forwarded-host",
+    help="Host passed to the function in the Host and X-Forwarded-Host headers of the event, instead of the local "
+    "address of the API, e.g. api.example.com. Useful for functions that build absolute URLs from these headers.",
+)
+@click.option(
+    "--forwarded-proto",
+    type=click.Choice(["http", "https"]),
+    help="Protocol passed to the function in the X-Forwarded-Proto header of the event, instead of the protocol "
+    "of the request.",
+)
+@click.option(
+    "--watch",
+    is_flag=True,
+    help="Watch the code of the functions, and recreate the container of a function when its code changes. "
+    "Implies --warm-containers LAZY unless --warm-containers is given.",
+)
+@click.option(
+    "--cors-allow-origin",
+    help="Origin allowed by the CORS configuration of the API, instead of the AllowOrigin of its Cors property, "
+    "e.g. http://localhost:8080 to call the API from a local web application. APIs without a Cors property get one "
+    "that allows every method from this origin.",
+)
+@click.option(
+    "--throttle-rate",
+    type=click.FloatRange(min=0),
+    help="Maximum number of requests per second of every route, like the throttling of API Gateway. Requests above "
+    "the rate get a 429 Too Many Requests response with a Retry-After header, to test the retry logic of clients. "
+    "A rate of 0 throttles every request.",
+)
+@click.option(
+    "--max-concurrent-invocations",
+    type=click.IntRange(min=1),
+    help="Maximum number of functions invoked at once, each in its own container. Requests above the limit wait for "
+    "an invocation to finish. Defaults to twice the number of CPUs of the host.",
+)
+@click.option(
+    "--invocation-queue-timeout",
+    type=click.FloatRange(min=0),
+    help="Seconds a request waits for an invocation to finish when --max-concurrent-invocations functions are "
+    "running, before getting a 503 Service Unavailable response. By default, requests wait as long as needed.",
+)
 @invoke_common_options
 @warm_containers_common_options
 @local_common_options
@@ -83,6 +133,35 @@ def cli(
     debug_function,
     container_host,
     container_host_interface,
+    binary_media_types,
+    debug_port_host,
+    container_hostname,
+    forwarded_host,
+    forwarded_proto,
+    shm_size,
+    watch,
+    cpu_proportional,
+    use_build_artifacts,
+    buffered_output,
+    cors_allow_origin,
+    container_labels_from_file,
+    env_file,
+    no_memory_limit,
+    throttle_rate,
+    aws_endpoint_url,
+    max_concurrent_invocations,
+    invocation_queue_timeout,
+    add_host,
+    import_values,
+    container_labels,
+    shutdown_timeout,
+    hook_name,
+    resource_attributes,
+    cloudwatch_style_logs,
+    container_entrypoint_override,
+    runtime_image_override,
+    force_linux_paths,
+    no_build_artifacts,
 ):
     """
     `sam local start-api` command entry point
@@ -112,6 +191,35 @@ def cli(
         debug_function,
         container_host,
         container_host_interface,
+        binary_media_types,
+        debug_port_host,
+        container_hostname,
+        forwarded_host,
+        forwarded_proto,
+        shm_size,
+        watch,
+        cpu_proportional,
+        use_build_artifacts,
+        buffered_output,
+        cors_allow_origin,
+        container_labels_from_file,
+        env_file,
+        no_memory_limit,
+        throttle_rate,
+        aws_endpoint_url,
+        max_concurrent_invocations,
+        invocation_queue_timeout,
+        add_host,
+        import_values,
+        container_labels,
+        shutdown_timeout,
+        hook_name,
+        resource_attributes,
+        cloudwatch_style_logs,
+        container_entrypoint_override,
+        runtime_image_override,
+        force_linux_paths,
+        no_build_artifacts,
     )  # pragma: no cover
 
 
@@ -138,12 +246,41 @@ def do_cli(  # pylint: disable=R0914
     debug_function,
     container_host,
     container_host_interface,
+    binary_media_types,
+    debug_port_host,
+    container_hostname,
+    forwarded_host,
+    forwarded_proto,
+    shm_size,
+    watch,
+    cpu_proportional,
+    use_build_artifacts,
+    buffered_output,
+    cors_allow_origin,
+    container_labels_from_file,
+    env_file,
+    no_memory_limit,
+    throttle_rate,
+    aws_endpoint_url,
+    max_concurrent_invocations,
+    invocation_queue_timeout,
+    add_host,
+    import_values,
+    container_labels,
+    shutdown_timeout,
+    hook_name,
+    resource_attributes,
+    cloudwatch_style_logs,
+    container_entrypoint_override,
+    runtime_image_override,
+    force_linux_paths,
+    no_build_artifacts,
 ):
     """
     Implementation of the ``cli`` method, just separated out for unit testing purposes
     """
 
-    from samcli.commands.local.cli_common.invoke_context import InvokeContext
+    from samcli.commands.local.cli_common.invoke_context import InvokeContext, ContainersInitializationMode
     from samcli.commands.local.lib.exceptions import NoApisDefined
     from samcli.lib.providers.exceptions import InvalidLayerReference
     from samcli.commands.exceptions import UserException
@@ -154,6 +291,10 @@ def do_cli(  # pylint: disable=R0914
 
     LOG.debug("local start-api command is called")
 
+    if watch and not warm_containers:
+        # Only warm containers are recreated when the code of their function changes
+        warm_containers = ContainersInitializationMode.LAZY.value
+
     # Pass all inputs to setup necessary context to invoke function locally.
     # Handler exception raised by the processor for invalid args and print errors
 
@@ -180,9 +321,42 @@ def do_cli(  # pylint: disable=R0914
             shutdown=shutdown,
             container_host=container_host,
             container_host_interface=container_host_interface,
+            debug_host_ports=debug_port_host,
+            container_hostname=container_hostname,
+            shm_size=shm_size,
+            cpu_proportional=cpu_proportional,
+            use_build_artifacts=use_build_artifacts,
+            buffered_output=buffered_output,
+            container_labels_file=container_labels_from_file,
+            env_file=env_file,
+            no_memory_limit=no_memory_limit,
+            aws_endpoint_url=aws_endpoint_url,
+            add_host=add_host,
+            import_values_file=import_values,
+            container_labels=container_labels,
+            shutdown_timeout=shutdown_timeout,
+            hook_name=hook_name,
+            resource_attributes_file=resource_attributes,
+            cloudwatch_style_logs=cloudwatch_style_logs,
+            container_entrypoint=container_entrypoint_override,
+            runtime_image_override=runtime_image_override,
+            force_linux_paths=force_linux_paths,
+            no_build_artifacts=no_build_artifacts,
         ) as invoke_context:
 
-            service = LocalApiService(lambda_invoke_context=invoke_context, port=port, host=host, static_dir=static_dir)
+            service = LocalApiService(
+                lambda_invoke_context=invoke_context,
+                port=port,
+                host=host,
+                static_dir=static_dir,
+                binary_media_types=binary_media_types,
+                forwarded_host=forwarded_host,
+                forwarded_proto=forwarded_proto,
+                cors_allow_origin=cors_allow_origin,
+                throttle_rate=throttle_rate,
+                max_concurrent_invocations=max_concurrent_invocations,
+                invocation_queue_timeout=invocation_queue_timeout,
+            )
             service.start()
 
     except NoApisDefined as ex:
@@ -30,6 +30,7 @@ SETUP
 ------
 Start the local Lambda endpoint by running this command in the directory that contains your AWS SAM template.
 $ sam local start-lambda\n
+Tests can poll GET /_sam/health, which responds 200 once the endpoint is ready to serve invokes.\n
 \b
 USING AWS CLI
 -------------
@@ -94,6 +95,27 @@ def cli(
     debug_function,
     container_host,
     container_host_interface,
+    debug_port_host,
+    container_hostname,
+    shm_size,
+    cpu_proportional,
+    use_build_artifacts,
+    buffered_output,
+    container_labels_from_file,
+    env_file,
+    no_memory_limit,
+    aws_endpoint_url,
+    add_host,
+    import_values,
+    container_labels,
+    shutdown_timeout,
+    hook_name,
+    resource_attributes,
+    cloudwatch_style_logs,
+    container_entrypoint_override,
+    runtime_image_override,
+    force_linux_paths,
+    no_build_artifacts,
 ):
     """
     `sam local start-lambda` command entry point
@@ -122,6 +144,27 @@ def cli(
         debug_function,
         container_host,
         container_host_interface,
+        debug_port_host,
+        container_hostname,
+        shm_size,
+        cpu_proportional,
+        use_build_artifacts,
+        buffered_output,
+        container_labels_from_file,
+        env_file,
+        no_memory_limit,
+        aws_endpoint_url,
+        add_host,
+        import_values,
+        container_labels,
+        shutdown_timeout,
+        hook_name,
+        resource_attributes,
+        cloudwatch_style_logs,
+        container_entrypoint_override,
+        runtime_image_override,
+        force_linux_paths,
+        no_build_artifacts,
     )  # pragma: no cover
 
 
@@ -147,6 +190,27 @@ def do_cli(  # pylint: disable=R0914
     debug_function,
     container_host,
     container_host_interface,
+    debug_port_host,
+    container_hostname,
+    shm_size,
+    cpu_proportional,
+    use_build_artifacts,
+    buffered_output,
+    container_labels_from_file,
+    env_file,
+    no_memory_limit,
+    aws_endpoint_url,
+    add_host,
+    import_values,
+    container_labels,
+    shutdown_timeout,
+    hook_name,
+    resource_attributes,
+    cloudwatch_style_logs,
+    container_entrypoint_override,
+    runtime_image_override,
+    force_linux_paths,
+    no_build_artifacts,
 ):
     """
     Implementation of the ``cli`` method, just separated out for unit testing purposes
@@ -188,6 +252,27 @@ def do_cli(  # pylint: disable=R0914
             shutdown=shutdown,
             container_host=container_host,
             container_host_interface=container_host_interface,
+            debug_host_ports=debug_port_host,
+            container_hostname=container_hostname,
+            shm_size=shm_size,
+            cpu_proportional=cpu_proportional,
+            use_build_artifacts=use_build_artifacts,
+            buffered_output=buffered_output,
+            container_labels_file=container_labels_from_file,
+            env_file=env_file,
+            no_memory_limit=no_memory_limit,
+            aws_endpoint_url=aws_endpoint_url,
+            add_host=add_host,
+            import_values_file=import_values,
+            container_labels=container_labels,
+            shutdown_timeout=shutdown_timeout,
+            hook_name=hook_name,
+            resource_attributes_file=resource_attributes,
+            cloudwatch_style_logs=cloudwatch_style_logs,
+            container_entrypoint=container_entrypoint_override,
+            runtime_image_override=runtime_image_override,
+            force_linux_paths=force_linux_paths,
+            no_build_artifacts=no_build_artifacts,
         ) as invoke_context:
 
             service = LocalLambdaService(lambda_invoke_context=invoke_context, port=port, host=host)
@@ -0,0 +1,244 @@
+"""
+Lints a SAM template, reporting the references to missing parameters and resources and the functions nothing invokes
+"""
+import logging
+import re
+from typing import Any, Dict, List, NamedTuple, Optional, Set, Tuple
+
+import yaml
+
+from samcli.commands._utils.resources import AWS_SERVERLESS_FUNCTION, AWS_SERVERLESS_STATEMACHINE
+from samcli.yamlhelper import get_node_position, yaml_parse
+
+LOG = logging.getLogger(__name__)
+
+SEVERITY_ERROR = "ERROR"
+SEVERITY_WARNING = "WARNING"
+
+# Pseudo parameters of CloudFormation, which can be referenced without being declared
+PSEUDO_PARAMETERS = {
+    "AWS::AccountId",
+    "AWS::NotificationARNs",
+    "AWS::NoValue",
+    "AWS::Partition",
+    "AWS::Region",
+    "AWS::StackId",
+    "AWS::StackName",
+    "AWS::URLSuffix",
+}
+
+# Resources SAM generates for the Api and HttpApi events of functions that don't reference an API
+IMPLICIT_API_RESOURCES = {"ServerlessRestApi", "ServerlessHttpApi"}
+
+# Property of the events referencing their API, by type of event
+EVENT_API_PROPERTIES = {"Api": "RestApiId", "HttpApi": "ApiId"}
+
+# Variables of Fn::Sub strings, like ${BucketName} or ${MyFunction.Arn}, but not the literal ${!Name}
+SUB_VARIABLE_PATTERN = re.compile(r"\$\{([^!}][^}]*)\}")
+
+
+class LintFinding(NamedTuple):
+    """
+    A problem found in the template
+    """
+
+    # SEVERITY_ERROR or SEVERITY_WARNING
+    severity: str
+    # Path of the node the problem was found at, like Resources.MyFunction.Properties
+    path: str
+    # Line and column of the node in the template, or None if they cannot be found
+    position: Optional[Tuple[int, int]]
+    message: str
+
+    def __str__(self) -> str:
+        position = " (line {}, column {})".format(*self.position) if self.position else ""
+        return "[{}] {}{}: {}".format(self.severity, self.path, position, self.message)
+
+
+def get_lint_findings(template_str: str) -> List[LintFinding]:
+    """
+    Lints the template, reporting as errors the Ref, Fn::GetAtt and Fn::Sub references to parameters and resources
+    that don't exist, as well as the events of functions referencing APIs that don't exist. Functions without any
+    event source are reported as warnings.
+
+    Parameters
+    ----------
+    template_str str
+        Content of the template, in json or yaml
+
+    Returns
+    -------
+    list(LintFinding)
+        Problems found in the template, in the order they appear in the template
+    """
+    template_dict = yaml_parse(template_str) or {}
+
+    try:
+        # Composing keeps the position of each node, see sam_template_schema_validator
+        root_node = yaml.compose(template_str, Loader=yaml.SafeLoader)
+    except yaml.YAMLError as ex:
+        LOG.debug("Unable to find the line numbers of the template", exc_info=ex)
+        root_node = None
+
+    resources = template_dict.get("Resources") or {}
+    if not isinstance(resources, dict):
+        resources = {}
+    known_names = _get_known_names(template_dict, resources)
+
+    findings: List[Tuple[List, str, str]] = []
+    for logical_id, resource in resources.items():
+        if isinstance(resource, dict) and resource.get("Type") == AWS_SERVERLESS_FUNCTION:
+            _lint_function_events(logical_id, resource.get("Properties") or {}, resources, findings)
+
+    # The events referencing missing APIs are reported once, not again as a missing reference
+    event_paths = {tuple(path) for path, _, _ in findings}
+    reference_findings: List[Tuple[List, str, str]] = []
+    for section in ("Resources", "Outputs"):
+        _find_missing_references(template_dict.get(section), [section], known_names, reference_findings)
+    findings.extend(finding for finding in reference_findings if tuple(finding[0]) not in event_paths)
+
+    lint_findings: List[LintFinding] = []
+    reported_findings = set()
+    for path, severity, message in findings:
+        # A Fn::Sub string can reference the same missing name more than once
+        path_str = ".".join(str(key) for key in path)
+        if (path_str, message) not in reported_findings:
+            reported_findings.add((path_str, message))
+            lint_findings.append(LintFinding(severity, path_str, get_node_position(root_node, path), message))
+
+    # Findings whose position is unknown are reported last
+    lint_findings.sort(key=lambda finding: (finding.position is None, finding.position or (0, 0)))
+    return lint_findings
+
+
+def _get_known_names(template_dict: Dict, resources: Dict) -> Set[str]:
+    """
+    Returns the names that can be referenced in the template: its parameters and resources, the pseudo parameters,
+    and the resources SAM generates
+    """
+    parameters = template_dict.get("Parameters") or {}
+    known_names = set(PSEUDO_PARAMETERS) | IMPLICIT_API_RESOURCES | set(resources)
+    if isinstance(parameters, dict):
+        known_names |= set(parameters)
+
+    for logical_id, resource in resources.items():
+        # SAM generates the execution role of functions and state machines that don't declare one
+        if isinstance(resource, dict) and resource.get("Type") in (
+            AWS_SERVERLESS_FUNCTION,
+            AWS_SERVERLESS_STATEMACHINE,
+        ):
+            known_names.add(logical_id + "Role")
+
+    return known_names
+
+
+def _find_missing_references(value: Any, path: List, known_names: Set[str], findings: List):
+    """
+    Walks a section of the template, reporting the Ref, Fn::GetAtt and Fn::Sub references to unknown names
+    """
+    if isinstance(value, list):
+        for index, item in enumerate(value):
+            _find_missing_references(item, path + [index], known_names, findings)
+        return
+
+    if not isinstance(value, dict):
+        return
+
+    if len(value) == 1:
+        name, argument = next(iter(value.items()))
+        if name == "Ref" and isinstance(argument, str):
+            # SAM supports referencing the generated resources of functions and APIs, like MyFunction.Alias
+            if argument.split(".")[0] not in known_names:
+                findings.append(
+                    (path, SEVERITY_ERROR, "Ref references undefined parameter or resource {}".format(argument))
+                )
+            return
+
+        if name == "Fn::GetAtt":
+            target = argument[0] if isinstance(argument, list) and argument else argument
+            if isinstance(target, str) and target.split(".")[0] not in known_names:
+                findings.append((path, SEVERITY_ERROR, "Fn::GetAtt references missing resource {}".format(target)))
+            return
+
+        if name == "Fn::Sub":
+            _find_missing_sub_references(argument, path, known_names, findings)
+            return
+
+    for key, item in value.items():
+        _find_missing_references(item, path + [key], known_names, findings)
+
+
+def _find_missing_sub_references(argument: Any, path: List, known_names: Set[str], findings: List):
+    """
+    Reports the variables of a Fn::Sub string that are neither known names nor variables of the Fn::Sub itself
+    """
+    variables: Dict = {}
+    string = argument
+    if isinstance(argument, list) and argument:
+        string = argument[0]
+        variables = argument[1] if len(argument) > 1 and isinstance(argument[1], dict) else {}
+        _find_missing_references(variables, path + ["Fn::Sub", 1], known_names, findings)
+
+    if not isinstance(string, str):
+        return
+
+    for variable in SUB_VARIABLE_PATTERN.findall(string):
+        name = variable.strip()
+        if name not in variables and name.split(".")[0] not in known_names:
+            findings.append(
+                (path, SEVERITY_ERROR, "Fn::Sub references undefined parameter or resource {}".format(name))
+            )
+
+
+def _lint_function_events(logical_id: str, properties: Dict, resources: Dict, findings: List):
+    """
+    Reports the functions without event sources, and the events referencing APIs that don't exist
+    """
+    events = properties.get("Events")
+    if not events:
+        findings.append(
+            (
+                ["Resources", logical_id],
+                SEVERITY_WARNING,
+                "Function {} has no event sources, it can only be invoked directly".format(logical_id),
+            )
+        )
+        return
+
+    if not isinstance(events, dict):
+        return
+
+    for event_id, event in events.items():
+        if not isinstance(event, dict) or event.get("Type") not in EVENT_API_PROPERTIES:
+            continue
+
+        api_property = EVENT_API_PROPERTIES[event["Type"]]
+        api_id = (event.get("Properties") or {}).get(api_property)
+        api_logical_id = api_id.get("Ref") if isinstance(api_id, dict) else None
+        if not isinstance(api_logical_id, str):
+            continue
+
+        path = ["Resources", logical_id, "Properties", "Events", event_id, "Properties", api_property]
+        if api_logical_id not in resources:
+            message = "Event {} references API {}, which does not exist".format(event_id, api_logical_id)
+            findings.append((path, SEVERITY_ERROR, message))
+            continue
+
+        api_type = (resources[api_logical_id] or {}).get("Type")
+        if api_type not in _get_api_types(event["Type"]):
+            findings.append(
+                (
+                    path,
+                    SEVERITY_ERROR,
+                    "Event {} references {} of type {}, which is not an API".format(event_id, api_logical_id, api_type),
+                )
+            )
+
+
+def _get_api_types(event_type: str) -> Tuple[str, ...]:
+    """
+    Returns the types of the resources the events of the given type can reference as their API
+    """
+    if event_type == "HttpApi":
+        return "AWS::Serverless::HttpApi", "AWS::ApiGatewayV2::Api"
+    return "AWS::Serverless::Api", "AWS::ApiGateway::RestApi"
@@ -0,0 +1,74 @@
+"""
+Validates a SAM template against the JSON Schema of SAM templates bundled with the SAM translator
+"""
+import json
+import logging
+import pkgutil
+from typing import Dict, List
+
+import yaml
+from jsonschema.validators import validator_for
+
+from samcli.yamlhelper import get_node_position, yaml_parse
+
+LOG = logging.getLogger(__name__)
+
+# Package and path of the JSON Schema of SAM templates that is packaged with the SAM translator
+SCHEMA_PACKAGE = "samtranslator.validator"
+SCHEMA_RESOURCE = "sam_schema/schema.json"
+
+
+def get_schema_violations(template_str: str) -> List[str]:
+    """
+    Validates the template against the bundled JSON Schema and describes every violation found, with the line and
+    column of the violating path in the template
+
+    Parameters
+    ----------
+    template_str str
+        Content of the template, in json or yaml
+
+    Returns
+    -------
+    list(str)
+        Description of each violation, in the order they appear in the template
+    """
+    template_dict = yaml_parse(template_str)
+    schema = _read_schema()
+    validator = validator_for(schema)(schema)
+
+    try:
+        # Composing builds the node tree of the template without constructing the intrinsic functions tags,
+        # and keeps the position of each node
+        root_node = yaml.compose(template_str, Loader=yaml.SafeLoader)
+    except yaml.YAMLError as ex:
+        LOG.debug("Unable to find the line numbers of the template", exc_info=ex)
+        root_node = None
+
+    violations = []
+    for error in validator.iter_errors(template_dict):
+        path = list(error.absolute_path)
+        violations.append((get_node_position(root_node, path), ".".join(str(key) for key in path), error.message))
+
+    # Violations whose position is unknown are reported last
+    violations.sort(key=lambda violation: (violation[0] is None, violation[0] or (0, 0)))
+
+    return [
+        "{}{}: {}".format(path or "Template", " (line {}, column {})".format(*position) if position else "", message)
+        for position, path, message in violations
+    ]
+
+
+def _read_schema() -> Dict:
+    """
+    Reads the JSON Schema of SAM templates from the data files of the SAM translator package
+
+    Returns
+    -------
+    dict
+        JSON Schema of SAM templates
+    """
+    schema = pkgutil.get_data(SCHEMA_PACKAGE, SCHEMA_RESOURCE)
+    if schema is None:
+        raise FileNotFoundError(f"{SCHEMA_RESOURCE} is not packaged with {SCHEMA_PACKAGE}")
+    return dict(json.loads(schema))
@@ -9,6 +9,10 @@ from samtranslator.parser import parser
 from samtranslator.translator.translator import Translator
 from boto3.session import Session
 
+from samcli.lib.intrinsic_resolver.intrinsic_property_resolver import IntrinsicResolver
+from samcli.lib.intrinsic_resolver.intrinsics_symbol_table import IntrinsicsSymbolTable
+from samcli.lib.providers.sam_base_provider import SamBaseProvider
+from samcli.lib.samlib.wrapper import pop_connectors
 from samcli.lib.utils.packagetype import ZIP
 from samcli.yamlhelper import yaml_dump
 from .exceptions import InvalidSamDocumentException
@@ -42,6 +46,10 @@ class SamTemplateValidator:
         self.managed_policy_loader = managed_policy_loader
         self.sam_parser = parser.Parser()
         self.boto3_session = Session(profile_name=profile, region_name=region)
+        # Logical ids of the AWS::Serverless::Connector resources, which are skipped by the validation
+        self.connectors = []
+        # VpcConfig of the functions, keyed by logical id, with the intrinsics resolved where possible
+        self.vpc_configs = {}
 
     def is_valid(self):
         """
@@ -62,8 +70,13 @@ class SamTemplateValidator:
             boto_session=self.boto3_session,
         )
 
+        self.vpc_configs = self._get_vpc_configs()
+
         self._replace_local_codeuri()
 
+        # Connectors are not supported by the bundled SAM Translator, skip them instead of failing the validation
+        self.connectors = list(pop_connectors(self.sam_template))
+
         try:
             template = sam_translator.translate(sam_template=self.sam_template, parameter_values={})
             LOG.debug("Translated template is:\n%s", yaml_dump(template))
@@ -72,6 +85,43 @@ class SamTemplateValidator:
                 functools.reduce(lambda message, error: message + " " + str(error), e.causes, str(e))
             ) from e
 
+    def _get_vpc_configs(self):
+        """
+        Collects the VpcConfig of every function in the template, merged with the one in the Globals section, and
+        resolves the intrinsics referenced by its subnets and security groups. Intrinsics which can not be resolved
+        locally, like Fn::ImportValue, are kept as is.
+
+        Returns
+        -------
+        dict
+            Dictionary of the logical id of the function to its resolved VpcConfig
+        """
+        all_resources = self.sam_template.get("Resources", {})
+        global_vpc_config = self.sam_template.get("Globals", {}).get("Function", {}).get("VpcConfig", {})
+
+        resolver = IntrinsicResolver(
+            template=self.sam_template,
+            symbol_resolver=IntrinsicsSymbolTable(
+                logical_id_translator=SamBaseProvider._get_parameter_values(self.sam_template, {}),
+                template=self.sam_template,
+            ),
+        )
+
+        vpc_configs = {}
+        for logical_id, resource in all_resources.items():
+            resource_type = resource.get("Type")
+            vpc_config = resource.get("Properties", {}).get("VpcConfig")
+
+            if resource_type == SamBaseProvider.SERVERLESS_FUNCTION and isinstance(global_vpc_config, dict):
+                vpc_config = {**global_vpc_config, **(vpc_config or {})}
+            elif resource_type != SamBaseProvider.LAMBDA_FUNCTION:
+                continue
+
+            if vpc_config:
+                vpc_configs[logical_id] = resolver.intrinsic_property_resolver(vpc_config, ignore_errors=True)
+
+        return vpc_configs
+
     def _replace_local_codeuri(self):
         """
         Replaces the CodeUri in AWS::Serverless::Function and DefinitionUri in AWS::Serverless::Api and
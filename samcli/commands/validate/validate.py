@@ -1,6 +1,7 @@
 """
 CLI Command for Validating a SAM Template
 """
+import json
 import os
 
 import boto3
@@ -19,6 +20,19 @@ from samcli.lib.utils.version_checker import check_newer_version
 @click.command("validate", short_help="Validate an AWS SAM template.")
 @configuration_option(provider=TomlProvider(section="parameters"))
 @template_option_without_build
+@click.option(
+    "--schema",
+    is_flag=True,
+    help="Also validate the template against the JSON Schema of SAM templates, and report the line and column of "
+    "every violation found.",
+)
+@click.option(
+    "--lint",
+    is_flag=True,
+    help="Also lint the template, and report the line of every finding: references to undefined parameters and "
+    "missing resources, and events referencing missing APIs, as errors; functions without event sources as warnings. "
+    "Only errors make the command fail.",
+)
 @aws_creds_options
 @cli_framework_options
 @pass_context
@@ -28,21 +42,24 @@ from samcli.lib.utils.version_checker import check_newer_version
 def cli(
     ctx,
     template_file,
+    schema,
+    lint,
     config_file,
     config_env,
 ):
 
     # All logic must be implemented in the ``do_cli`` method. This helps with easy unit testing
 
-    do_cli(ctx, template_file)  # pragma: no cover
+    do_cli(ctx, template_file, schema, lint)  # pragma: no cover
 
 
-def do_cli(ctx, template):
+def do_cli(ctx, template, schema, lint):
     """
     Implementation of the ``cli`` method, just separated out for unit testing purposes
     """
     from samtranslator.translator.managed_policy_translator import ManagedPolicyLoader
 
+    from samcli.commands._utils.resources import AWS_SERVERLESS_CONNECTOR
     from samcli.commands.exceptions import UserException
     from samcli.commands.local.cli_common.user_exceptions import InvalidSamTemplateException
     from .lib.exceptions import InvalidSamDocumentException
@@ -50,6 +67,12 @@ def do_cli(ctx, template):
 
     sam_template = _read_sam_file(template)
 
+    if schema:
+        _validate_schema(template)
+
+    if lint:
+        _lint(template)
+
     iam_client = boto3.client("iam")
     validator = SamTemplateValidator(
         sam_template, ManagedPolicyLoader(iam_client), profile=ctx.profile, region=ctx.region
@@ -72,6 +95,87 @@ def do_cli(ctx, template):
 
     click.secho("{} is a valid SAM Template".format(template), fg="green")
 
+    for logical_id in validator.connectors:
+        click.secho(
+            "Resource {} of type {} is recognized but not simulated locally".format(
+                logical_id, AWS_SERVERLESS_CONNECTOR
+            ),
+            fg="yellow",
+        )
+
+    for logical_id, vpc_config in validator.vpc_configs.items():
+        click.secho(
+            "Function {} is configured to run in a VPC (SubnetIds: {}, SecurityGroupIds: {}), "
+            "but VPC networking is not simulated locally".format(
+                logical_id,
+                _format_vpc_references(vpc_config.get("SubnetIds")),
+                _format_vpc_references(vpc_config.get("SecurityGroupIds")),
+            ),
+            fg="yellow",
+        )
+
+
+def _format_vpc_references(references):
+    """
+    Formats the subnet or security group references of a VpcConfig, printing the unresolved intrinsics as JSON
+
+    :param list references: Subnet or security group references
+    :return str: Comma separated list of the references
+    """
+
+    if references is None:
+        return "none"
+
+    if not isinstance(references, list):
+        references = [references]
+
+    return ", ".join(
+        reference if isinstance(reference, str) else json.dumps(reference, sort_keys=True) for reference in references
+    )
+
+
+def _validate_schema(template):
+    """
+    Validates the template against the JSON Schema of SAM templates, and prints every violation found
+
+    :param str template: Path to the template file
+    :raises: InvalidSamTemplateException when the template violates the schema
+    """
+
+    from samcli.commands.local.cli_common.user_exceptions import InvalidSamTemplateException
+    from .lib.sam_template_schema_validator import get_schema_violations
+
+    with click.open_file(template, "r", encoding="utf-8") as sam_template:
+        violations = get_schema_violations(sam_template.read())
+
+    if violations:
+        click.secho("Template provided at '{}' does not match the schema of SAM templates.".format(template), bg="red")
+        for violation in violations:
+            click.secho(violation, fg="red")
+        raise InvalidSamTemplateException("Found {} schema violation(s) in {}".format(len(violations), template))
+
+
+def _lint(template):
+    """
+    Lints the template, and prints every finding in the order they appear in the template
+
+    :param str template: Path to the template file
+    :raises: InvalidSamTemplateException when errors are found. Warnings alone don't fail the linting
+    """
+
+    from samcli.commands.local.cli_common.user_exceptions import InvalidSamTemplateException
+    from .lib.sam_template_linter import SEVERITY_ERROR, get_lint_findings
+
+    with click.open_file(template, "r", encoding="utf-8") as sam_template:
+        findings = get_lint_findings(sam_template.read())
+
+    for finding in findings:
+        click.secho(str(finding), fg="red" if finding.severity == SEVERITY_ERROR else "yellow")
+
+    errors = [finding for finding in findings if finding.severity == SEVERITY_ERROR]
+    if errors:
+        raise InvalidSamTemplateException("Found {} lint error(s) in {}".format(len(errors), template))
+
 
 def _read_sam_file(template):
     """
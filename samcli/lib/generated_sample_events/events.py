@@ -3,10 +3,12 @@ Methods to expose the event types and generate the event jsons for use in SAM CL
 """
 
 import os
+import copy
 import json
+import uuid
 import base64
 import warnings
-from typing import Dict, cast
+from typing import Callable, Dict, cast
 from urllib.parse import quote as url_quote
 
 with warnings.catch_warnings():
@@ -20,6 +22,35 @@ with warnings.catch_warnings():
 from samcli.lib.utils.hash import str_checksum
 
 
+def _set_sqs_record_ids(record: Dict, first_record: Dict, index: int) -> None:
+    record["messageId"] = str(uuid.UUID(int=uuid.UUID(first_record["messageId"]).int + index))
+    record["receiptHandle"] = "{}-{}".format(first_record["receiptHandle"], index + 1)
+
+
+def _set_kinesis_record_ids(record: Dict, first_record: Dict, index: int) -> None:
+    sequence = first_record["kinesis"]["sequenceNumber"]
+    # --sequence is any string, increment it when it is a number like the sequence numbers of Kinesis
+    sequence = str(int(sequence) + index) if sequence.isdigit() else "{}-{}".format(sequence, index + 1)
+    shard_id = first_record["eventID"].rsplit(":", 1)[0]
+    record["kinesis"]["sequenceNumber"] = sequence
+    record["eventID"] = "{}:{}".format(shard_id, sequence)
+
+
+def _set_dynamodb_record_ids(record: Dict, first_record: Dict, index: int) -> None:
+    # The event IDs of the sample records are the MD5 of their position in the batch
+    record["eventID"] = str_checksum(str(index + 1))
+    record["dynamodb"]["SequenceNumber"] = str(int(first_record["dynamodb"]["SequenceNumber"]) + index)
+
+
+# Functions setting the ids of the records of the events that generate-event can batch, so that every record of a
+# batch is distinct. They are given the record, the first record of the sample event and the index of the record.
+RECORD_ID_SETTERS: Dict[str, Callable[[Dict, Dict, int], None]] = {
+    "sqs": _set_sqs_record_ids,
+    "kinesis": _set_kinesis_record_ids,
+    "dynamodb": _set_dynamodb_record_ids,
+}
+
+
 class Events:
 
     """
@@ -180,6 +211,58 @@ class Events:
 
         data = json.dumps(data, indent=2)
 
+        # the placeholders of number tags are quoted to keep the event file valid json, unquote them so that
+        # the substituted values are numbers in the generated event
+        for tag, properties in tags.items():
+            if properties.get("type") == "number":
+                placeholder = "{{{%s}}}" % tag.replace("-", "_")
+                data = data.replace('"{}"'.format(placeholder), placeholder)
+
         # return the substituted file
         # According to chevron's code, it returns a str (A string containing the rendered template.)
-        return cast("str", renderer.render(data, values_to_sub))
+        event = cast("str", renderer.render(data, values_to_sub))
+
+        # the values of fields tags are JSON objects whose fields are added to the event, like the fields of the
+        # payload of the IoT messages that rules select with SELECT *, the fields of the event file come last
+        fields_tags = [tag for tag, properties in tags.items() if properties.get("type") == "fields"]
+        if fields_tags:
+            event_fields: Dict = {}
+            for tag in fields_tags:
+                fields = values_to_sub.get(tag.replace("-", "_"))
+                event_fields.update(json.loads(fields) if isinstance(fields, str) else fields or {})
+            event_fields.update(json.loads(event))
+            event = json.dumps(event_fields, indent=2)
+
+        return event
+
+    @staticmethod
+    def batch_records(service_name: str, event: str, count: int) -> str:
+        """
+        sets the number of records of a generated event, repeating the records
+        of the sample event with distinct ids
+
+        Parameters
+        ----------
+        service_name: string
+            name of the top level service (sqs, kinesis, etc)
+        event: string
+            the generated event json
+        count: int
+            the number of records the event should have
+        Returns
+        -------
+        event: string
+            the event json with the given number of records
+        """
+        data = json.loads(event)
+        records = data["Records"]
+        set_record_ids = RECORD_ID_SETTERS[service_name]
+
+        batch = []
+        for index in range(count):
+            record = copy.deepcopy(records[index % len(records)])
+            set_record_ids(record, records[0], index)
+            batch.append(record)
+
+        data["Records"] = batch
+        return json.dumps(data, indent=2)
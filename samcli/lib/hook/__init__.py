@@ -0,0 +1,30 @@
+"""
+Hooks that adapt the projects of other infrastructure as code tools to the SAM templates the local commands run
+"""
+from samcli.lib.hook import terraform
+
+# Functions preparing a SAM template from the project of a tool, by name of the hook
+_HOOKS = {
+    "terraform": terraform.prepare_template,
+}
+
+HOOK_NAMES = sorted(_HOOKS.keys())
+
+
+def prepare_hook_template(hook_name: str, source_file: str) -> str:
+    """
+    Prepares the SAM template of the project of the tool of the given hook
+
+    Parameters
+    ----------
+    hook_name str
+        Name of the hook, like terraform
+    source_file str
+        Path to the file describing the project, like the JSON of a Terraform plan
+
+    Returns
+    -------
+    str
+        Path to the SAM template
+    """
+    return _HOOKS[hook_name](source_file)
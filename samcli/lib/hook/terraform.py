@@ -0,0 +1,189 @@
+"""
+Reads the aws_lambda_function resources of a Terraform plan, given as the JSON of `terraform show -json`, into the
+AWS::Serverless::Function resources of a SAM template
+"""
+import atexit
+import json
+import logging
+import os
+import re
+import shutil
+import tempfile
+from typing import Dict, Iterator, List, Optional
+
+from samcli.commands._utils.resources import AWS_SERVERLESS_FUNCTION
+from samcli.commands.exceptions import UserException
+
+LOG = logging.getLogger(__name__)
+
+TF_AWS_LAMBDA_FUNCTION = "aws_lambda_function"
+TF_ARCHIVE_FILE = "archive_file"
+
+# Properties of the function by attribute of the aws_lambda_function resource, for the attributes that are copied as is
+_FUNCTION_PROPERTIES = {
+    "function_name": "FunctionName",
+    "handler": "Handler",
+    "runtime": "Runtime",
+    "timeout": "Timeout",
+    "memory_size": "MemorySize",
+    "layers": "Layers",
+    "architectures": "Architectures",
+}
+
+
+class InvalidTerraformPlanException(UserException):
+    pass
+
+
+def prepare_template(plan_file: str) -> str:
+    """
+    Writes the SAM template of the functions of a Terraform plan to a temporary directory, which is removed when
+    the command exits
+
+    Parameters
+    ----------
+    plan_file str
+        Path to the JSON of the plan, created with `terraform show -json <plan> > plan.json`
+
+    Returns
+    -------
+    str
+        Path to the SAM template
+    """
+    try:
+        with open(plan_file, "r", encoding="utf-8") as fp:
+            plan = json.load(fp)
+    except OSError as ex:
+        raise InvalidTerraformPlanException(
+            "Unable to read the Terraform plan {}: {}. Create it with terraform plan -out tfplan && "
+            "terraform show -json tfplan > plan.json, and pass it with --template-file".format(plan_file, ex)
+        ) from ex
+    except ValueError as ex:
+        raise InvalidTerraformPlanException("{} is not the JSON of a Terraform plan: {}".format(plan_file, ex)) from ex
+
+    template = translate_plan(plan, os.path.dirname(os.path.abspath(plan_file)))
+
+    template_dir = tempfile.mkdtemp(prefix="sam-terraform-template-")
+    atexit.register(shutil.rmtree, template_dir, True)
+    template_path = os.path.join(template_dir, "template.json")
+    with open(template_path, "w", encoding="utf-8") as fp:
+        json.dump(template, fp, indent=2)
+
+    LOG.debug("Wrote the SAM template of the Terraform plan %s to %s", plan_file, template_path)
+    return template_path
+
+
+def translate_plan(plan: Dict, plan_dir: str) -> Dict:
+    """
+    Translates the aws_lambda_function resources of a Terraform plan to a SAM template
+
+    Parameters
+    ----------
+    plan dict
+        JSON of the plan
+    plan_dir str
+        Directory the relative paths of the plan are relative to, where terraform runs
+
+    Returns
+    -------
+    dict
+        SAM template with a function by aws_lambda_function resource
+    """
+    if not isinstance(plan, dict) or not isinstance(plan.get("planned_values"), dict):
+        raise InvalidTerraformPlanException("The Terraform plan has no planned_values, is it the JSON of a plan?")
+
+    resources = list(_get_module_resources(plan["planned_values"].get("root_module")))
+    # Data sources read while planning are in the prior state rather than in the planned values
+    prior_state = plan.get("prior_state")
+    data_resources = list(resources)
+    if isinstance(prior_state, dict) and isinstance(prior_state.get("values"), dict):
+        data_resources.extend(_get_module_resources(prior_state["values"].get("root_module")))
+
+    # Functions zipped with the archive_file data source mount the directory of the archive, like functions
+    # with a CodeUri directory, so that code changes don't need a new terraform plan
+    archive_source_dirs = {
+        _abspath(plan_dir, values["output_path"]): _abspath(plan_dir, values["source_dir"])
+        for values in _get_values(data_resources, "data", TF_ARCHIVE_FILE)
+        if values.get("output_path") and values.get("source_dir")
+    }
+
+    template_resources: Dict[str, Dict] = {}
+    addresses: Dict[str, str] = {}
+    for resource in resources:
+        if resource.get("mode") != "managed" or resource.get("type") != TF_AWS_LAMBDA_FUNCTION:
+            continue
+
+        properties = _get_function_properties(resource.get("values") or {}, plan_dir, archive_source_dirs)
+        if properties is None:
+            LOG.warning("Skipping %s, the location of its code is not known from the plan", resource.get("address"))
+            continue
+
+        logical_id = _get_logical_id(resource["address"])
+        if logical_id in addresses:
+            raise InvalidTerraformPlanException(
+                "Functions {} and {} would both have the logical ID {}, rename one of them".format(
+                    addresses[logical_id], resource["address"], logical_id
+                )
+            )
+        addresses[logical_id] = resource["address"]
+        template_resources[logical_id] = {
+            "Type": AWS_SERVERLESS_FUNCTION,
+            "Properties": properties,
+        }
+
+    return {
+        "AWSTemplateFormatVersion": "2010-09-09",
+        "Transform": "AWS::Serverless-2016-10-31",
+        "Resources": template_resources,
+    }
+
+
+def _get_function_properties(values: Dict, plan_dir: str, archive_source_dirs: Dict[str, str]) -> Optional[Dict]:
+    properties = {
+        prop: values[attribute] for attribute, prop in _FUNCTION_PROPERTIES.items() if values.get(attribute) is not None
+    }
+
+    if values.get("package_type") == "Image":
+        if not values.get("image_uri"):
+            return None
+        properties["PackageType"] = "Image"
+        properties["ImageUri"] = values["image_uri"]
+    elif values.get("filename"):
+        code_path = _abspath(plan_dir, values["filename"])
+        properties["CodeUri"] = archive_source_dirs.get(code_path, code_path)
+    else:
+        # The code of functions deployed from s3_bucket and s3_key is not available locally
+        return None
+
+    # Blocks are lists in the JSON of the plan
+    environment = values.get("environment") or []
+    variables = environment[0].get("variables") if environment else None
+    if variables:
+        properties["Environment"] = {"Variables": variables}
+
+    return properties
+
+
+def _get_module_resources(module: Optional[Dict]) -> Iterator[Dict]:
+    if not isinstance(module, dict):
+        return
+    yield from module.get("resources") or []
+    for child_module in module.get("child_modules") or []:
+        yield from _get_module_resources(child_module)
+
+
+def _get_values(resources: List[Dict], mode: str, resource_type: str) -> Iterator[Dict]:
+    for resource in resources:
+        if resource.get("mode") == mode and resource.get("type") == resource_type:
+            yield resource.get("values") or {}
+
+
+def _get_logical_id(address: str) -> str:
+    """
+    Logical IDs are alphanumeric, module.api.aws_lambda_function.hello["a"] becomes ModuleApiAwsLambdaFunctionHelloA
+    """
+    return "".join(part[:1].upper() + part[1:] for part in re.split(r"[^A-Za-z0-9]+", address))
+
+
+def _abspath(plan_dir: str, path: str) -> str:
+    return os.path.normpath(os.path.join(plan_dir, path))
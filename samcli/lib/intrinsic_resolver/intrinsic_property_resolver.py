@@ -66,13 +66,13 @@ class IntrinsicResolver:
 
     CONDITIONAL_FUNCTIONS = [FN_AND, FN_OR, FN_IF, FN_EQUALS, FN_NOT]
 
-    def __init__(self, template, symbol_resolver):
+    def __init__(self, template, symbol_resolver, import_values=None):
         """
         Initializes the Intrinsic Property class with the default intrinsic_key_function_map and
         conditional_key_function_map.
 
-        In the future, for items like Fn::ImportValue multiple templates can be provided
-        into the function.
+        Other stacks are not available locally, so the values they export are provided with import_values, a
+        dictionary of export name to value that Fn::ImportValue is resolved against.
         """
         self._template = None
         self._resources = None
@@ -83,6 +83,7 @@ class IntrinsicResolver:
         self.init_template(template)
 
         self._symbol_resolver = symbol_resolver
+        self._import_values = import_values
 
         self.intrinsic_key_function_map = self.default_intrinsic_function_map()
         self.conditional_key_function_map = self.default_conditional_key_map()
@@ -194,7 +195,21 @@ class IntrinsicResolver:
         if intrinsic is None:
             raise InvalidIntrinsicException("Missing Intrinsic property in {}".format(parent_function))
         if isinstance(intrinsic, list):
-            return [self.intrinsic_property_resolver(item, ignore_errors) for item in intrinsic]
+            sanitized_list = []
+            for item in intrinsic:
+                try:
+                    sanitized_list.append(
+                        self.intrinsic_property_resolver(item, ignore_errors, parent_function=parent_function)
+                    )
+                # Like the properties of a dictionary, an item that can't be resolved is left intact so that it
+                # doesn't prevent the other items of the list from resolving
+                except Exception:
+                    if ignore_errors:
+                        LOG.debug("Unable to resolve item %s. Leaving as is.", item)
+                        sanitized_list.append(item)
+                    else:
+                        raise
+            return sanitized_list
         if not isinstance(intrinsic, dict) or intrinsic == {}:
             return intrinsic
 
@@ -517,7 +532,7 @@ class IntrinsicResolver:
 
         Return
         -------
-        A string with the resolved attributes
+        A list with the availability zones of the region
         """
         intrinsic_value = self.intrinsic_property_resolver(
             intrinsic_value, ignore_errors, parent_function=IntrinsicResolver.FN_GET_AZS
@@ -527,12 +542,13 @@ class IntrinsicResolver:
         if not intrinsic_value:
             intrinsic_value = self._symbol_resolver.handle_pseudo_region()
 
-        if intrinsic_value not in self._symbol_resolver.REGIONS:
+        availability_zones = self._symbol_resolver.get_availability_zone(intrinsic_value)
+        if availability_zones is None:
             raise InvalidIntrinsicException(
                 "Invalid region string passed in to {}".format(IntrinsicResolver.FN_GET_AZS)
             )
 
-        return self._symbol_resolver.REGIONS.get(intrinsic_value)
+        return availability_zones
 
     def handle_fn_transform(self, intrinsic_value, ignore_errors):
         """
@@ -569,18 +585,32 @@ class IntrinsicResolver:
 
         return location_data
 
-    @staticmethod
-    def handle_fn_import_value(intrinsic_value, ignore_errors):
+    def handle_fn_import_value(self, intrinsic_value, ignore_errors):
         """
         { "Fn::ImportValue" : sharedValueToImport }
-        This intrinsic function requires handling multiple stacks, which is not currently supported by SAM-CLI.
-        Thus, it will thrown an exception.
+        This intrinsic function imports the value exported by another stack. Other stacks are not available locally,
+        so the name of the export is resolved and looked up in the import values, given with --import-values.
 
         Return
         -------
-        An InvalidIntrinsicException
+        The value of the export
         """
-        raise InvalidIntrinsicException("Fn::ImportValue is currently not supported by IntrinsicResolver")
+        export_name = self.intrinsic_property_resolver(
+            intrinsic_value, ignore_errors, parent_function=IntrinsicResolver.FN_IMPORT_VALUE
+        )
+        verify_intrinsic_type_str(export_name, IntrinsicResolver.FN_IMPORT_VALUE)
+
+        if self._import_values is None:
+            raise InvalidIntrinsicException(
+                "Fn::ImportValue of {} requires the values exported by other stacks, "
+                "provide them with --import-values".format(export_name)
+            )
+
+        if export_name not in self._import_values:
+            LOG.error("Unable to resolve Fn::ImportValue, the export %s is not in the import values", export_name)
+            raise InvalidIntrinsicException("The export {} is not in the import values".format(export_name))
+
+        return self._import_values[export_name]
 
     def handle_fn_getatt(self, intrinsic_value, ignore_errors):
         """
@@ -686,12 +716,16 @@ class IntrinsicResolver:
             variables, ignore_errors, parent_function=IntrinsicResolver.FN_SUB
         )
 
-        subable_props = re.findall(string=sub_str, pattern=IntrinsicResolver._REGEX_SUB_FUNCTION)
-        for sub_item in subable_props:
-            sanitized_item = sanitized_variables[sub_item] if sub_item in sanitized_variables else sub_item
-            result = resolve_sub_attribute(sanitized_item, self._symbol_resolver)
-            sub_str = re.sub(pattern=r"\$\{" + sub_item + r"\}", string=sub_str, repl=str(result))
-        return sub_str
+        def substitute(text):
+            subable_props = re.findall(string=text, pattern=IntrinsicResolver._REGEX_SUB_FUNCTION)
+            for sub_item in subable_props:
+                sanitized_item = sanitized_variables[sub_item] if sub_item in sanitized_variables else sub_item
+                result = resolve_sub_attribute(sanitized_item, self._symbol_resolver)
+                text = re.sub(pattern=r"\$\{" + sub_item + r"\}", string=text, repl=str(result))
+            return text
+
+        # ${!Literal} escapes the literal ${Literal}, which is written as is instead of being substituted
+        return "${".join(substitute(text) for text in sub_str.split("${!"))
 
     def handle_fn_if(self, intrinsic_value, ignore_errors):
         """
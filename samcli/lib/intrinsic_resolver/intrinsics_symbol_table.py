@@ -3,6 +3,7 @@ The symbol table that is used in IntrinsicResolver in order to resolve runtime a
 """
 import logging
 import os
+import re
 
 from samcli.lib.intrinsic_resolver.intrinsic_property_resolver import IntrinsicResolver
 from samcli.lib.intrinsic_resolver.invalid_intrinsic_exception import InvalidSymbolException
@@ -40,7 +41,7 @@ class IntrinsicsSymbolTable:
         "AWS::StackName": "local",
         "AWS::StackId": "arn:aws:cloudformation:us-east-1:123456789012:stack/"
         "local/51af3dc0-da77-11e4-872e-1234567db123",
-        "AWS::URLSuffix": "localhost",
+        "AWS::URLSuffix": "amazonaws.com",
     }
 
     REGIONS = {
@@ -65,6 +66,10 @@ class IntrinsicsSymbolTable:
         "us-gov-west-1": [],
     }
 
+    # Matches the name of a region, like us-east-1 or us-gov-west-1, that is not listed in REGIONS
+    REGION_NAME_PATTERN = re.compile(r"^[a-z]{2}(-[a-z]+)+-\d+$")
+    DEFAULT_AVAILABILITY_ZONE_SUFFIXES = ["a", "b", "c"]
+
     DEFAULT_PARTITION = "aws"
     GOV_PARTITION = "aws-us-gov"
     CHINA_PARTITION = "aws-cn"
@@ -80,6 +85,7 @@ class IntrinsicsSymbolTable:
     CFN_RESOURCE_TYPE = "Type"
     CFN_RESOURCE_PROPERTIES = "Properties"
     CFN_LAMBDA_FUNCTION_NAME = "FunctionName"
+    CFN_SIMPLE_TABLE_NAME = "TableName"
 
     def __init__(
         self, template=None, logical_id_translator=None, default_type_resolver=None, common_attribute_resolver=None
@@ -157,8 +163,7 @@ class IntrinsicsSymbolTable:
     def get_default_attribute_resolver(self):
         return {"Ref": lambda logical_id: logical_id, "Arn": self.arn_resolver}
 
-    @staticmethod
-    def get_default_type_resolver():
+    def get_default_type_resolver(self):
         return {
             "AWS::ApiGateway::RestApi": {
                 "RootResourceId": "/"  # It usually used as a reference to the parent id of the RestApi,
@@ -169,6 +174,7 @@ class IntrinsicsSymbolTable:
             "AWS::Serverless::LayerVersion": {
                 IntrinsicResolver.REF: lambda logical_id: {IntrinsicResolver.REF: logical_id}
             },
+            "AWS::Serverless::SimpleTable": {IntrinsicResolver.REF: self._get_simple_table_name},
         }
 
     def resolve_symbols(self, logical_id, resource_attribute, ignore_errors=False):
@@ -303,6 +309,25 @@ class IntrinsicsSymbolTable:
         resource_name = resource_properties.get(IntrinsicsSymbolTable.CFN_LAMBDA_FUNCTION_NAME)
         return resource_name or logical_id
 
+    def _get_simple_table_name(self, logical_id):
+        """
+        This function returns the table name of the AWS::Serverless::SimpleTable associated with the logical ID.
+        If the template doesn't define a TableName as a string, it will just return the logical ID, so the
+        function still gets a usable table name locally.
+
+        Parameters
+        -----------
+        logical_id: str
+            This the reference to the table name used
+
+        Return
+        -------
+        The table name
+        """
+        resource_properties = self._resources.get(logical_id, {}).get(IntrinsicsSymbolTable.CFN_RESOURCE_PROPERTIES)
+        table_name = (resource_properties or {}).get(IntrinsicsSymbolTable.CFN_SIMPLE_TABLE_NAME)
+        return table_name if isinstance(table_name, str) else logical_id
+
     def get_translation(self, logical_id, resource_attributes=IntrinsicResolver.REF):
         """
         This gets the logical_id_translation of the logical id and resource_attributes.
@@ -339,9 +364,17 @@ class IntrinsicsSymbolTable:
 
         Return
         -------
-        The list of availability zones for the specified region
+        The list of availability zones for the specified region. Regions without known availability zones get
+        a plausible list of three zones, and None is returned if the region is not a valid region name.
         """
-        return IntrinsicsSymbolTable.REGIONS.get(region)
+        availability_zones = IntrinsicsSymbolTable.REGIONS.get(region)
+        if availability_zones:
+            return availability_zones
+
+        if not isinstance(region, str) or not IntrinsicsSymbolTable.REGION_NAME_PATTERN.match(region):
+            return None
+
+        return [region + suffix for suffix in IntrinsicsSymbolTable.DEFAULT_AVAILABILITY_ZONE_SUFFIXES]
 
     @staticmethod
     def handle_pseudo_account_id():
@@ -378,10 +411,7 @@ class IntrinsicsSymbolTable:
         -------
         The url prefix of amazonaws.com or amazonaws.com.cn
         """
-        aws_region = self.handle_pseudo_region()
-        if self.CHINA_PREFIX in aws_region:
-            return self.CHINA_URL_PREFIX
-        return self.DEFAULT_URL_PREFIX
+        return IntrinsicsSymbolTable.get_url_suffix(self.handle_pseudo_region())
 
     def handle_pseudo_partition(self):
         """
@@ -393,12 +423,45 @@ class IntrinsicsSymbolTable:
         -------
         A pseudo partition like aws-cn or aws or aws-gov
         """
-        aws_region = self.handle_pseudo_region()
-        if self.CHINA_PREFIX in aws_region:
-            return self.CHINA_PARTITION
-        if self.GOV_PREFIX in aws_region:
-            return self.GOV_PARTITION
-        return self.DEFAULT_PARTITION
+        return IntrinsicsSymbolTable.get_partition(self.handle_pseudo_region())
+
+    @staticmethod
+    def get_url_suffix(aws_region):
+        """
+        Returns the AWS::URLSuffix of a region
+
+        Parameters
+        ----------
+        aws_region: str
+            Region like us-east-1 or cn-north-1
+
+        Return
+        -------
+        The url suffix amazonaws.com, or amazonaws.com.cn for china regions
+        """
+        if IntrinsicsSymbolTable.CHINA_PREFIX in aws_region:
+            return IntrinsicsSymbolTable.CHINA_URL_PREFIX
+        return IntrinsicsSymbolTable.DEFAULT_URL_PREFIX
+
+    @staticmethod
+    def get_partition(aws_region):
+        """
+        Returns the AWS::Partition of a region
+
+        Parameters
+        ----------
+        aws_region: str
+            Region like us-east-1 or cn-north-1
+
+        Return
+        -------
+        A partition like aws-cn or aws or aws-us-gov
+        """
+        if IntrinsicsSymbolTable.CHINA_PREFIX in aws_region:
+            return IntrinsicsSymbolTable.CHINA_PARTITION
+        if IntrinsicsSymbolTable.GOV_PREFIX in aws_region:
+            return IntrinsicsSymbolTable.GOV_PARTITION
+        return IntrinsicsSymbolTable.DEFAULT_PARTITION
 
     @staticmethod
     def handle_pseudo_stack_id():
@@ -62,6 +62,8 @@ class Function(NamedTuple):
     codesign_config_arn: Optional[str]
     # The path of the stack relative to the root stack, it is empty for functions in root stack
     stack_path: str = ""
+    # Runtime Management Config, it can pin the version of the runtime with UpdateRuntimeOn set to Manual
+    runtime_management_config: Optional[Dict] = None
 
     @property
     def full_path(self) -> str:
@@ -400,6 +402,11 @@ class Stack(NamedTuple):
     parameters: Optional[Dict]
     # the raw template dict
     template_dict: Dict
+    # The values exported by other stacks, by export name, which Fn::ImportValue resolves to
+    import_values: Optional[Dict] = None
+    # The values of the attributes of resources, by logical ID and attribute name, which Ref, Fn::GetAtt and Fn::Sub
+    # resolve to rather than to the placeholders SAM CLI makes up
+    resource_attributes: Optional[Dict] = None
 
     @property
     def stack_path(self) -> str:
@@ -425,7 +432,9 @@ class Stack(NamedTuple):
         Return the resources dictionary where SAM plugins have been run
         and parameter values have been substituted.
         """
-        processed_template_dict: Dict = SamBaseProvider.get_template(self.template_dict, self.parameters)
+        processed_template_dict: Dict = SamBaseProvider.get_template(
+            self.template_dict, self.parameters, self.import_values, self.resource_attributes
+        )
         resources: Dict = processed_template_dict.get("Resources", {})
         return resources
 
@@ -92,10 +92,12 @@ class SamBaseProvider:
     def _warn_code_extraction(resource_type: str, resource_name: str, code_property: str) -> None:
         LOG.warning(
             "The resource %s '%s' has specified S3 location for %s. "
-            "It will not be built and SAM CLI does not support invoking it locally.",
+            "It will not be built and SAM CLI does not support invoking it locally. "
+            "Set %s to a local path to build or invoke it locally.",
             resource_type,
             resource_name,
             code_property,
+            code_property,
         )
 
     @staticmethod
@@ -137,7 +139,12 @@ class SamBaseProvider:
         return resource_properties.get(code_property_key, None)
 
     @staticmethod
-    def get_template(template_dict: Dict, parameter_overrides: Optional[Dict[str, str]] = None) -> Dict:
+    def get_template(
+        template_dict: Dict,
+        parameter_overrides: Optional[Dict[str, str]] = None,
+        import_values: Optional[Dict[str, str]] = None,
+        resource_attributes: Optional[Dict[str, Dict[str, Any]]] = None,
+    ) -> Dict:
         """
         Given a SAM template dictionary, return a cleaned copy of the template where SAM plugins have been run
         and parameter values have been substituted.
@@ -150,6 +157,13 @@ class SamBaseProvider:
         parameter_overrides: dict
             Optional dictionary of values for template parameters
 
+        import_values: dict
+            Optional dictionary of the values exported by other stacks, by export name, to resolve Fn::ImportValue
+
+        resource_attributes: dict
+            Optional dictionary of the values of the attributes of resources, like {"MyTable": {"Arn": "..."}}, that
+            Ref, Fn::GetAtt and Fn::Sub resolve to before falling back to the placeholder values
+
         Returns
         -------
         dict
@@ -161,9 +175,13 @@ class SamBaseProvider:
             template_dict = SamTranslatorWrapper(template_dict, parameter_values=parameters_values).run_plugins()
         ResourceMetadataNormalizer.normalize(template_dict)
 
+        # The symbol table looks up the attributes of resources in the logical ID translator before making them up
+        logical_id_translator = dict(parameters_values)
+        logical_id_translator.update(resource_attributes or {})
         resolver = IntrinsicResolver(
             template=template_dict,
-            symbol_resolver=IntrinsicsSymbolTable(logical_id_translator=parameters_values, template=template_dict),
+            symbol_resolver=IntrinsicsSymbolTable(logical_id_translator=logical_id_translator, template=template_dict),
+            import_values=import_values,
         )
         template_dict = resolver.resolve_template(ignore_errors=True)
         return template_dict
@@ -197,6 +215,16 @@ class SamBaseProvider:
         parameter_values.update(default_values)
         parameter_values.update(parameter_overrides or {})
 
+        # The partition and the url suffix follow the region, like aws-cn for cn-north-1, unless they are overridden
+        aws_region = parameter_values.get(IntrinsicsSymbolTable.AWS_REGION)
+        if isinstance(aws_region, str):
+            for pseudo_param, get_value in [
+                (IntrinsicsSymbolTable.AWS_PARTITION, IntrinsicsSymbolTable.get_partition),
+                (IntrinsicsSymbolTable.AWS_URL_PREFIX, IntrinsicsSymbolTable.get_url_suffix),
+            ]:
+                if pseudo_param not in (parameter_overrides or {}):
+                    parameter_values[pseudo_param] = get_value(aws_region)
+
         return parameter_values
 
     @staticmethod
@@ -8,7 +8,7 @@ from samcli.commands.local.cli_common.user_exceptions import InvalidLayerVersion
 from samcli.lib.providers.exceptions import InvalidLayerReference
 from samcli.lib.utils.colors import Colored
 from samcli.lib.utils.packagetype import ZIP, IMAGE
-from .provider import Function, LayerVersion, Stack
+from .provider import Function, LayerVersion, Stack, get_full_path
 from .sam_base_provider import SamBaseProvider
 from .sam_stack_provider import SamLocalStackProvider
 
@@ -83,6 +83,35 @@ class SamFunctionProvider(SamBaseProvider):
 
         return None
 
+    def has_s3_code_location(self, name: str) -> bool:
+        """
+        Whether the given name or LogicalId refers to a function whose code is located in S3, either as an S3 URI or
+        as an S3 Bucket/Key object. These functions are skipped when extracting functions, because they can't be
+        invoked locally.
+
+        :param string name: Name of the function
+        :return bool: True, if the function is defined in the template and its code is in S3
+        """
+        for stack in self.stacks:
+            for logical_id, resource in stack.resources.items():
+                resource_type = resource.get("Type")
+                if resource_type not in [SamFunctionProvider.SERVERLESS_FUNCTION, SamFunctionProvider.LAMBDA_FUNCTION]:
+                    continue
+
+                resource_properties = resource.get("Properties", {})
+                if name not in (
+                    logical_id,
+                    get_full_path(stack.stack_path, logical_id),
+                    resource_properties.get("FunctionName"),
+                ):
+                    continue
+
+                code_property_key = SamBaseProvider.CODE_PROPERTY_KEYS[resource_type]
+                if SamBaseProvider._is_s3_location(resource_properties.get(code_property_key)):
+                    return True
+
+        return False
+
     def _deprecate_notification(self, runtime: Optional[str]) -> None:
         if runtime in self._deprecated_runtimes:
             message = (
@@ -329,6 +358,7 @@ class SamFunctionProvider(SamBaseProvider):
             metadata=metadata,
             inlinecode=inlinecode,
             codesign_config_arn=resource_properties.get("CodeSigningConfigArn", None),
+            runtime_management_config=resource_properties.get("RuntimeManagementConfig"),
         )
 
     @staticmethod
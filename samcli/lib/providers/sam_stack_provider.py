@@ -28,6 +28,8 @@ class SamLocalStackProvider(SamBaseProvider):
         template_dict: Dict,
         parameter_overrides: Optional[Dict] = None,
         global_parameter_overrides: Optional[Dict] = None,
+        import_values: Optional[Dict] = None,
+        resource_attributes: Optional[Dict] = None,
     ):
         """
         Initialize the class with SAM template data. The SAM template passed to this provider is assumed
@@ -44,6 +46,10 @@ class SamLocalStackProvider(SamBaseProvider):
             to get substituted within the template
         :param dict global_parameter_overrides: Optional dictionary of values for SAM template global parameters that
             might want to get substituted within the template and all its child templates
+        :param dict import_values: Optional dictionary of the values exported by other stacks, by export name, that
+            Fn::ImportValue resolves to
+        :param dict resource_attributes: Optional dictionary of the values of the attributes of resources, by logical
+            ID and attribute name, that Ref, Fn::GetAtt and Fn::Sub resolve to
         """
 
         self._template_file = template_file
@@ -51,6 +57,8 @@ class SamLocalStackProvider(SamBaseProvider):
         self._template_dict = self.get_template(
             template_dict,
             SamLocalStackProvider.merge_parameter_overrides(parameter_overrides, global_parameter_overrides),
+            import_values,
+            resource_attributes,
         )
         self._resources = self._template_dict.get("Resources", {})
         self._global_parameter_overrides = global_parameter_overrides
@@ -189,6 +197,8 @@ class SamLocalStackProvider(SamBaseProvider):
         name: str = "",
         parameter_overrides: Optional[Dict] = None,
         global_parameter_overrides: Optional[Dict] = None,
+        import_values: Optional[Dict] = None,
+        resource_attributes: Optional[Dict] = None,
     ) -> Tuple[List[Stack], List[str]]:
         """
         Recursively extract stacks from a template file.
@@ -207,6 +217,12 @@ class SamLocalStackProvider(SamBaseProvider):
         global_parameter_overrides: Optional[Dict]
             Optional dictionary of values for SAM template global parameters
             that might want to get substituted within the template and its child templates
+        import_values: Optional[Dict]
+            Optional dictionary of the values exported by other stacks, by export name, that Fn::ImportValue
+            resolves to in the template and its child templates
+        resource_attributes: Optional[Dict]
+            Optional dictionary of the values of the attributes of resources, by logical ID and attribute name, that
+            Ref, Fn::GetAtt and Fn::Sub resolve to in the template and its child templates
 
         Returns
         -------
@@ -223,12 +239,20 @@ class SamLocalStackProvider(SamBaseProvider):
                 template_file,
                 SamLocalStackProvider.merge_parameter_overrides(parameter_overrides, global_parameter_overrides),
                 template_dict,
+                import_values,
+                resource_attributes,
             )
         ]
         remote_stack_full_paths: List[str] = []
 
         current = SamLocalStackProvider(
-            template_file, stack_path, template_dict, parameter_overrides, global_parameter_overrides
+            template_file,
+            stack_path,
+            template_dict,
+            parameter_overrides,
+            global_parameter_overrides,
+            import_values,
+            resource_attributes,
         )
         remote_stack_full_paths.extend(current.remote_stack_full_paths)
 
@@ -239,6 +263,8 @@ class SamLocalStackProvider(SamBaseProvider):
                 child_stack.name,
                 child_stack.parameters,
                 global_parameter_overrides,
+                import_values,
+                resource_attributes,
             )
             stacks.extend(stacks_in_child)
             remote_stack_full_paths.extend(remote_stack_full_paths_in_child)
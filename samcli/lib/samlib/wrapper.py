@@ -8,6 +8,7 @@ rich public interface.
 """
 
 import copy
+import logging
 import os
 import json
 
@@ -30,9 +31,12 @@ from samtranslator.translator.translator import prepare_plugins, Translator
 from samtranslator.translator.managed_policy_translator import ManagedPolicyLoader
 from samtranslator.parser.parser import Parser
 
+from samcli.commands._utils.resources import AWS_SERVERLESS_CONNECTOR
 from samcli.commands.validate.lib.exceptions import InvalidSamDocumentException
 from .local_uri_plugin import SupportLocalUriPlugin
 
+LOG = logging.getLogger(__name__)
+
 
 class SamTranslatorWrapper:
 
@@ -74,6 +78,10 @@ class SamTranslatorWrapper:
             additional_plugins, parameters=self.parameter_values if self.parameter_values else {}
         )
 
+        # The bundled SAM Translator does not know Connectors yet and would report them as invalid resources.
+        # They are not simulated locally, so keep them out of the parser and put them back afterwards.
+        connectors = pop_connectors(template_copy)
+
         try:
             parser.parse(template_copy, all_plugins)  # parse() will run all configured plugins
         except InvalidDocumentException as e:
@@ -81,6 +89,9 @@ class SamTranslatorWrapper:
                 functools.reduce(lambda message, error: message + " " + str(error), e.causes, str(e))
             ) from e
 
+        if connectors:
+            template_copy.setdefault("Resources", {}).update(connectors)
+
         return template_copy
 
     def __translate(self, parameter_values):
@@ -123,6 +134,37 @@ class SamTranslatorWrapper:
             raise ex
 
 
+def pop_connectors(sam_template: Dict) -> Dict:
+    """
+    Removes the AWS::Serverless::Connector resources from the template. Connectors only grant permissions between
+    resources, which are not simulated locally.
+
+    Parameters
+    ----------
+    sam_template dict
+        SAM Template dictionary, modified in place
+
+    Returns
+    -------
+    dict
+        Connector resources removed from the template, keyed by logical id
+    """
+    resources = sam_template.get("Resources")
+    if not isinstance(resources, dict):
+        return {}
+
+    connectors = {
+        logical_id: resource
+        for logical_id, resource in resources.items()
+        if isinstance(resource, dict) and resource.get("Type") == AWS_SERVERLESS_CONNECTOR
+    }
+    for logical_id in connectors:
+        LOG.debug("Skipping %s %s, connectors are not simulated locally", AWS_SERVERLESS_CONNECTOR, logical_id)
+        del resources[logical_id]
+
+    return connectors
+
+
 class _SamParserReimplemented:
     """
     Re-implementation (almost copy) of Parser class from SAM Translator
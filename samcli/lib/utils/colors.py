@@ -1,6 +1,8 @@
 """
 Wrapper to generated colored messages for printing in Terminal
 """
+import os
+import sys
 
 import click
 
@@ -20,19 +22,53 @@ class Colored:
 
         - Transparently turn off colors: In cases when the string is not written to Terminal (ex: log file) the ANSI
             color codes should not be written. This class supports the scenario by allowing you to turn off colors.
-            Calls to methods like `red()` will simply return the input string.
+            Calls to methods like `red()` will simply return the input string. Colors are turned off by default
+            when stdout or stderr is not a Terminal, and always with --no-color or the NO_COLOR environment variable.
     """
 
-    def __init__(self, colorize=True):
+    # Set for the rest of the command by --no-color
+    _colors_disabled = False
+
+    def __init__(self, colorize=None):
         """
         Initialize the object
 
         Parameters
         ----------
         colorize : bool
-            Optional. Set this to True to turn on coloring. False will turn off coloring
+            Optional. Set this to True to turn on coloring. False will turn off coloring. Defaults to coloring when
+            stdout and stderr are Terminals
+        """
+        self.colorize = Colored.colors_enabled(colorize)
+
+    @classmethod
+    def disable_colors(cls):
+        """Turn off colors for every Colored object, like with --no-color"""
+        cls._colors_disabled = True
+
+    @classmethod
+    def colors_enabled(cls, colorize=None):
+        """
+        Whether text can be colored. --no-color and the NO_COLOR environment variable turn colors off regardless of
+        ``colorize``, otherwise ``colorize`` decides, or whether stdout and stderr are Terminals when it is None
+
+        Parameters
+        ----------
+        colorize : bool
+            Optional. True to color text, False not to color it, None to color it only on Terminals
+
+        Returns
+        -------
+        bool
+            True if text can be colored
         """
-        self.colorize = colorize
+        if cls._colors_disabled or os.environ.get("NO_COLOR"):
+            return False
+
+        if colorize is not None:
+            return colorize
+
+        return _isatty(sys.stdout) and _isatty(sys.stderr)
 
     def red(self, msg):
         """Color the input red"""
@@ -62,3 +98,11 @@ class Colored:
         """Internal helper method to add colors to input"""
         kwargs = {"fg": color}
         return click.style(msg, **kwargs) if self.colorize else msg
+
+
+def _isatty(stream):
+    try:
+        return stream is not None and stream.isatty()
+    except ValueError:
+        # The stream is closed
+        return False
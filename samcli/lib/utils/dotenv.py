@@ -0,0 +1,98 @@
+"""
+Contains the parser of dotenv files, which define environment variables in KEY=VALUE lines
+"""
+
+import re
+from typing import Dict
+
+# Name of a variable, optionally preceded by "export " like in shell scripts
+VARIABLE_NAME_PATTERN = re.compile(r"^(?:export\s+)?([A-Za-z_][A-Za-z0-9_.]*)$")
+
+# Escape sequences supported in double quoted values
+DOUBLE_QUOTED_ESCAPES = {"n": "\n", "r": "\r", "t": "\t", '"': '"', "\\": "\\"}
+
+
+class DotenvParseError(ValueError):
+    """
+    Raised when a line of a dotenv file can't be parsed
+    """
+
+
+def parse_dotenv(content: str) -> Dict[str, str]:
+    """
+    Parses the content of a dotenv file.
+
+    Every line defines a variable as KEY=VALUE. Blank lines and lines starting with # are ignored. Values can be
+    enclosed in single quotes, which are taken literally, or in double quotes, which support the \\n, \\r, \\t, \\"
+    and \\\\ escape sequences. A # preceded by a space starts a comment in unquoted values.
+
+    Parameters
+    ----------
+    content str
+        Content of the dotenv file
+
+    Returns
+    -------
+    dict
+        Dictionary of the variable names to their values
+
+    Raises
+    ------
+    DotenvParseError
+        If a line is not a valid KEY=VALUE definition
+    """
+    variables = {}
+
+    for line_number, line in enumerate(content.splitlines(), start=1):
+        line = line.strip()
+        if not line or line.startswith("#"):
+            continue
+
+        name, separator, value = line.partition("=")
+        match = VARIABLE_NAME_PATTERN.match(name.strip())
+        if not separator or not match:
+            raise DotenvParseError("Line {} is not a valid KEY=VALUE definition: {}".format(line_number, line))
+
+        try:
+            variables[match.group(1)] = _parse_value(value.strip())
+        except DotenvParseError as ex:
+            raise DotenvParseError("Line {} has an invalid value: {}".format(line_number, ex)) from ex
+
+    return variables
+
+
+def _parse_value(value: str) -> str:
+    """
+    Parses the value of a variable, removing its quotes or its trailing comment
+    """
+    if value[:1] in ("'", '"'):
+        quote = value[0]
+        end = _find_closing_quote(value, quote)
+        if end < 0:
+            raise DotenvParseError("missing closing quote {}".format(quote))
+
+        remainder = value[end + 1 :].strip()
+        if remainder and not remainder.startswith("#"):
+            raise DotenvParseError("unexpected characters after the closing quote: {}".format(remainder))
+
+        if quote == "'":
+            return value[1:end]
+        return re.sub(r"\\(.)", lambda m: DOUBLE_QUOTED_ESCAPES.get(m.group(1), m.group(0)), value[1:end])
+
+    return re.split(r"\s+#", value, maxsplit=1)[0].strip()
+
+
+def _find_closing_quote(value: str, quote: str) -> int:
+    """
+    Returns the position of the quote closing the value, skipping escaped double quotes, or -1 if it isn't closed
+    """
+    position = 1
+    while position < len(value):
+        if quote == '"' and value[position] == "\\":
+            position += 2
+            continue
+        if value[position] == quote:
+            return position
+        position += 1
+
+    return -1
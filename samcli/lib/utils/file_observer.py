@@ -6,7 +6,7 @@ import threading
 from abc import ABC, abstractmethod
 
 from pathlib import Path
-from threading import Thread, Lock
+from threading import Thread, Lock, Timer
 from typing import Callable, List, Dict, Optional
 
 import docker
@@ -330,6 +330,10 @@ class FileObserver(ResourceObserver):
     A class that will observe some file system paths for any change.
     """
 
+    # Seconds to wait for more events before checking the changed paths, so that a burst of events, ex: a build
+    # writing many files or an editor saving through a temporary file, is reported as a single change
+    DEBOUNCE_INTERVAL = 0.5
+
     def __init__(self, on_change: Callable) -> None:
         """
         Initialize the file observer
@@ -354,12 +358,15 @@ class FileObserver(ResourceObserver):
         self._code_deletion_handler.on_deleted = self.on_change
         self._input_on_change: Callable = on_change
         self._lock: Lock = threading.Lock()
+        self._pending_paths: List[str] = []
+        self._debounce_timer: Optional[Timer] = None
+        self._debounce_lock: Lock = threading.Lock()
 
     def on_change(self, event: FileSystemEvent) -> None:
         """
         It got executed once there is a change in one of the paths that watchdog is observing.
-        This method will check if any of the input paths is really changed, and based on that it will
-        invoke the input on_change function with the changed paths
+        This method collects the observed paths the event happened in, and checks them once no other event happened
+        for DEBOUNCE_INTERVAL seconds
 
         Parameters
         ----------
@@ -370,8 +377,31 @@ class FileObserver(ResourceObserver):
         if not observed_paths:
             return
 
+        with self._debounce_lock:
+            self._pending_paths += [path for path in observed_paths if path not in self._pending_paths]
+            if self._debounce_timer:
+                self._debounce_timer.cancel()
+            debounce_timer = Timer(self.DEBOUNCE_INTERVAL, self._on_debounced_change)
+            debounce_timer.daemon = True
+            self._debounce_timer = debounce_timer
+        debounce_timer.start()
+
+    def _on_debounced_change(self) -> None:
+        """
+        It got executed once the events in the observed paths settled down.
+        This method will check if any of the collected paths is really changed, and based on that it will
+        invoke the input on_change function with the changed paths
+        """
+        with self._debounce_lock:
+            pending_paths = self._pending_paths
+            self._pending_paths = []
+            self._debounce_timer = None
+
         changed_paths = []
-        for path in observed_paths:
+        for path in pending_paths:
+            # The path got unwatched while waiting for the events to settle down
+            if path not in self._observed_paths:
+                continue
             path_obj = Path(path)
             # The path got deleted
             if not path_obj.exists():
@@ -499,6 +529,11 @@ class FileObserver(ResourceObserver):
         with self._lock:
             if self._observer.is_alive():
                 self._observer.stop()
+        with self._debounce_lock:
+            if self._debounce_timer:
+                self._debounce_timer.cancel()
+                self._debounce_timer = None
+            self._pending_paths = []
 
 
 def calculate_checksum(path: str) -> str:
@@ -1,6 +1,8 @@
 """
 This class acts like a wrapper around output streams to provide any flexibility with output we need
 """
+import datetime
+import io
 
 
 class StreamWriter:
@@ -34,3 +36,101 @@ class StreamWriter:
 
     def flush(self):
         self._stream.flush()
+
+
+class CapturingStreamWriter(StreamWriter):
+    """
+    StreamWriter that keeps a copy of everything written to the underlying stream, so that the output can be
+    inspected once it is complete
+    """
+
+    def __init__(self, stream, auto_flush=False):
+        super().__init__(stream, auto_flush)
+        self._captured = io.BytesIO()
+
+    def write(self, output):
+        """
+        Writes specified text to the underlying stream, and keeps a copy of it
+
+        Parameters
+        ----------
+        output bytes-like object
+            Bytes to write
+        """
+        self._captured.write(output)
+        super().write(output)
+
+    def getvalue(self):
+        """
+        Returns
+        -------
+        bytes
+            Everything written so far
+        """
+        return self._captured.getvalue()
+
+
+class LinePrefixingStreamWriter(StreamWriter):
+    """
+    StreamWriter that starts every line written to the underlying stream with a prefix, like the log stream name and
+    timestamp CloudWatch Logs shows the lines of the logs of a function with. Writes don't need to be whole lines, the
+    prefix is only written before the first chunk of each line.
+    """
+
+    def __init__(self, stream, get_prefix, auto_flush=False):
+        """
+        Parameters
+        ----------
+        stream samcli.lib.utils.stream_writer.StreamWriter
+            Stream to wrap
+        get_prefix Callable[[], str]
+            Function returning the prefix of a line, called when the line starts
+        auto_flush bool
+            Whether to autoflush the stream upon writing
+        """
+        super().__init__(stream, auto_flush)
+        self._get_prefix = get_prefix
+        self._at_line_start = True
+
+    def write(self, output):
+        """
+        Writes specified text to the underlying stream, starting each new line with the prefix
+
+        Parameters
+        ----------
+        output bytes-like object or str
+            Text to write
+        """
+        newline = b"\n" if isinstance(output, (bytes, bytearray)) else "\n"
+        prefixed = output[:0]
+        for line in output.splitlines(keepends=True):
+            if self._at_line_start:
+                prefix = self._get_prefix()
+                prefixed += prefix.encode("utf-8") if isinstance(output, (bytes, bytearray)) else prefix
+            prefixed += line
+            self._at_line_start = line.endswith(newline)
+
+        super().write(prefixed)
+
+
+def cloudwatch_log_prefix(log_stream_name):
+    """
+    Returns a function returning the prefix of log lines with the log stream name and the current time, in UTC and
+    RFC3339 format, like the lines of sam logs: 2021/09/01/[$LATEST]0123456789abcdef 2021-09-01T10:15:30.123Z
+
+    Parameters
+    ----------
+    log_stream_name str
+        Name of the log stream
+
+    Returns
+    -------
+    Callable[[], str]
+        Function returning the prefix of a line
+    """
+
+    def get_prefix():
+        timestamp = datetime.datetime.now(datetime.timezone.utc).isoformat(timespec="milliseconds")
+        return "{} {} ".format(log_stream_name, timestamp.replace("+00:00", "Z"))
+
+    return get_prefix
@@ -0,0 +1,40 @@
+"""
+X-Ray tracing related utilities
+"""
+import os
+import time
+
+TRACE_ID_HEADER = "X-Amzn-Trace-Id"
+
+
+def generate_trace_header() -> str:
+    """
+    Generates the tracing header of a new trace, like the one Lambda receives for a sampled request
+
+    Returns
+    -------
+    str
+        Tracing header of the form Root=1-{epoch in hex}-{96 random bits in hex};Sampled=1
+    """
+    return "Root=1-{:08x}-{};Sampled=1".format(int(time.time()), os.urandom(12).hex())
+
+
+def get_trace_header(trace_id: str) -> str:
+    """
+    Returns the tracing header for the given trace id. The trace id can either be a full tracing header, or only the
+    root trace id like 1-5759e988-bd862e3fe1be46a994272793
+
+    Parameters
+    ----------
+    trace_id str
+        Tracing header or root trace id
+
+    Returns
+    -------
+    str
+        Tracing header
+    """
+    if "Root=" in trace_id:
+        return trace_id
+
+    return "Root={};Sampled=1".format(trace_id)
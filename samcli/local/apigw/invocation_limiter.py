@@ -0,0 +1,50 @@
+"""
+Limits the number of functions the local API invokes at once, so that a load test doesn't start more containers than
+the host can run
+"""
+
+import os
+import threading
+
+# Number of functions invoked at once by CPU of the host, when no limit is given. Functions mostly wait on I/O, so the
+# host can run more than one by CPU
+_INVOCATIONS_PER_CPU = 2
+
+
+def default_max_concurrent_invocations():
+    """
+    :return int: Number of functions the host can run at once, derived from its number of CPUs
+    """
+    return (os.cpu_count() or 1) * _INVOCATIONS_PER_CPU
+
+
+class InvocationLimiter:
+    """
+    Semaphore limiting the number of invocations running at once. Invocations above the limit wait for a running one
+    to finish, up to a timeout. This class is thread-safe.
+    """
+
+    def __init__(self, max_invocations, timeout=None):
+        """
+        Creates an InvocationLimiter
+
+        :param int max_invocations: Maximum number of invocations running at once
+        :param float timeout: Optional, seconds an invocation waits for a slot. Waits forever if None
+        """
+        self.max_invocations = max_invocations
+        self.timeout = timeout
+        self._semaphore = threading.BoundedSemaphore(max_invocations)
+
+    def acquire(self):
+        """
+        Waits for a slot to run an invocation. Every successful acquire must be followed by a release
+
+        :return bool: True if a slot was acquired, False if the timeout expired first
+        """
+        return self._semaphore.acquire(timeout=self.timeout)
+
+    def release(self):
+        """
+        Frees the slot of a finished invocation
+        """
+        self._semaphore.release()
@@ -3,6 +3,8 @@ import io
 import json
 import logging
 import base64
+import gzip
+import zlib
 from typing import List, Optional
 
 from flask import Flask, request
@@ -10,9 +12,10 @@ from werkzeug.datastructures import Headers
 from werkzeug.routing import BaseConverter
 
 from samcli.lib.providers.provider import Cors
-from samcli.local.services.base_local_service import BaseLocalService, LambdaOutputParser
+from samcli.local.services.base_local_service import HEALTH_CHECK_PATH, BaseLocalService, LambdaOutputParser
 from samcli.lib.utils.stream_writer import StreamWriter
-from samcli.local.lambdafn.exceptions import FunctionNotFound
+from samcli.lib.utils.xray import TRACE_ID_HEADER, generate_trace_header
+from samcli.local.lambdafn.exceptions import FunctionNotFound, FunctionTimeoutException
 from samcli.local.events.api_event import (
     ContextIdentity,
     ContextHTTP,
@@ -23,10 +26,24 @@ from samcli.local.events.api_event import (
 )
 from .service_error_responses import ServiceErrorResponses
 from .path_converter import PathConverter
+from .throttler import RouteThrottler
+from .invocation_limiter import InvocationLimiter, default_max_concurrent_invocations
 
 LOG = logging.getLogger(__name__)
 
 
+def _inflate(body: bytes) -> bytes:
+    # The deflate content encoding is zlib wrapped, but some servers send raw deflate data
+    try:
+        return zlib.decompress(body)
+    except zlib.error:
+        return zlib.decompress(body, -zlib.MAX_WBITS)
+
+
+# Content encodings of Lambda responses that are decoded for clients which do not accept them
+CONTENT_DECODERS = {"gzip": gzip.decompress, "deflate": _inflate}
+
+
 class LambdaResponseParseException(Exception):
     """
     An exception raised when we fail to parse the response for Lambda
@@ -109,8 +126,23 @@ class CatchAllPathConverter(BaseConverter):
 class LocalApigwService(BaseLocalService):
     _DEFAULT_PORT = 3000
     _DEFAULT_HOST = "127.0.0.1"
+    # Headers of the event that carry the host, overridden by forwarded_host
+    _FORWARDED_HOST_HEADERS = ("Host", "X-Forwarded-Host")
 
-    def __init__(self, api, lambda_runner, static_dir=None, port=None, host=None, stderr=None):
+    def __init__(
+        self,
+        api,
+        lambda_runner,
+        static_dir=None,
+        port=None,
+        host=None,
+        stderr=None,
+        forwarded_host=None,
+        forwarded_proto=None,
+        throttle_rate=None,
+        max_concurrent_invocations=None,
+        invocation_queue_timeout=None,
+    ):
         """
         Creates an ApiGatewayService
 
@@ -130,6 +162,21 @@ class LocalApigwService(BaseLocalService):
             Defaults to '127.0.0.1
         stderr : samcli.lib.utils.stream_writer.StreamWriter
             Optional stream writer where the stderr from Docker container should be written to
+        forwarded_host : str
+            Optional. Host to pass to the function in the Host and X-Forwarded-Host headers of the event,
+            instead of the host the request was sent to
+        forwarded_proto : str
+            Optional. Protocol to pass to the function in the X-Forwarded-Proto header of the event,
+            instead of the scheme of the request
+        throttle_rate : float
+            Optional. Maximum number of requests per second of every route. Requests above the rate get a
+            429 Too Many Requests response, like with the throttling of API Gateway
+        max_concurrent_invocations : int
+            Optional. Maximum number of functions invoked at once, each in its own container. Requests above the
+            limit wait for an invocation to finish. Defaults to a number derived from the CPUs of the host
+        invocation_queue_timeout : float
+            Optional. Seconds a request waits for an invocation to finish before getting a 503 Service Unavailable
+            response. Waits forever if None
         """
         super().__init__(lambda_runner.is_debugging(), port=port, host=host)
         self.api = api
@@ -137,6 +184,12 @@ class LocalApigwService(BaseLocalService):
         self.static_dir = static_dir
         self._dict_of_routes = {}
         self.stderr = stderr
+        self.forwarded_host = forwarded_host
+        self.forwarded_proto = forwarded_proto
+        self._throttler = RouteThrottler(throttle_rate) if throttle_rate is not None else None
+        self._invocation_limiter = InvocationLimiter(
+            max_concurrent_invocations or default_max_concurrent_invocations(), invocation_queue_timeout
+        )
 
     def create(self):
         """
@@ -157,12 +210,20 @@ class LocalApigwService(BaseLocalService):
 
         # This will normalize all endpoints and strip any trailing '/'
         self._app.url_map.strict_slashes = False
+        self._add_health_check_route()
+
         default_route = None
         for api_gateway_route in self.api.routes:
             if api_gateway_route.path == "$default":
                 default_route = api_gateway_route
                 continue
             path = PathConverter.convert_path_to_flask(api_gateway_route.path)
+            if path == HEALTH_CHECK_PATH and "GET" in api_gateway_route.methods:
+                LOG.warning(
+                    "GET %s of function %s is not reachable locally, the health check of SAM CLI responds on it",
+                    HEALTH_CHECK_PATH,
+                    api_gateway_route.function_name,
+                )
             for route_key in self._generate_route_keys(api_gateway_route.methods, path):
                 self._dict_of_routes[route_key] = api_gateway_route
             self._app.add_url_rule(
@@ -189,6 +250,9 @@ class LocalApigwService(BaseLocalService):
 
         self._construct_error_handling()
 
+        # Only logged with --debug, as the requests and responses may contain secrets
+        self._app.after_request(self._log_response)
+
     def _add_catch_all_path(self, methods, path, route):
         """
         Add the catch all route to the _app and the dictionary of routes.
@@ -251,12 +315,34 @@ class LocalApigwService(BaseLocalService):
         Updates the Flask app with Error Handlers for different Error Codes
         """
         # Both path and method not present
-        self._app.register_error_handler(404, ServiceErrorResponses.route_not_found)
+        self._app.register_error_handler(404, self._route_not_found)
         # Path is present, but method not allowed
-        self._app.register_error_handler(405, ServiceErrorResponses.route_not_found)
+        self._app.register_error_handler(405, self._route_not_found)
         # Something went wrong
         self._app.register_error_handler(500, ServiceErrorResponses.lambda_failure_response)
 
+    @staticmethod
+    def _route_not_found(error):
+        """
+        Logs the requests matching no route, before responding like API Gateway
+
+        :param error: Error raised by Flask
+        :return: Response object
+        """
+        LOG.debug("No route matches %s %s", request.method, request.path)
+        return ServiceErrorResponses.route_not_found(error)
+
+    @staticmethod
+    def _log_response(response):
+        """
+        Logs the final status of every request
+
+        :param response: Response object returned to the client
+        :return: The same response object
+        """
+        LOG.debug("Responded to %s %s with status %s", request.method, request.path, response.status_code)
+        return response
+
     def _request_handler(self, **kwargs):
         """
         We handle all requests to the host:port. The general flow of handling a request is as follows
@@ -285,6 +371,16 @@ class LocalApigwService(BaseLocalService):
         cors_headers = Cors.cors_to_headers(self.api.cors)
 
         method, endpoint = self.get_request_methods_endpoints(request)
+        LOG.debug(
+            "Request %s %s matched the route %s of function %s", method, endpoint, route.path, route.function_name
+        )
+
+        if self._throttler:
+            retry_after = self._throttler.acquire(self._route_key(method, route.path))
+            if retry_after:
+                LOG.info("Throttling the request to %s %s, retry after %.2f seconds", method, endpoint, retry_after)
+                return ServiceErrorResponses.too_many_requests_response(retry_after)
+
         if method == "OPTIONS" and self.api.cors:
             headers = Headers(cors_headers)
             return self.service_response("", headers, 200)
@@ -302,10 +398,18 @@ class LocalApigwService(BaseLocalService):
                     self.api.stage_name,
                     self.api.stage_variables,
                     route_key,
+                    forwarded_host=self.forwarded_host,
+                    forwarded_proto=self.forwarded_proto,
                 )
             else:
                 event = self._construct_v_1_0_event(
-                    request, self.port, self.api.binary_media_types, self.api.stage_name, self.api.stage_variables
+                    request,
+                    self.port,
+                    self.api.binary_media_types,
+                    self.api.stage_name,
+                    self.api.stage_variables,
+                    forwarded_host=self.forwarded_host,
+                    forwarded_proto=self.forwarded_proto,
                 )
         except UnicodeDecodeError:
             return ServiceErrorResponses.lambda_failure_response()
@@ -313,11 +417,31 @@ class LocalApigwService(BaseLocalService):
         stdout_stream = io.BytesIO()
         stdout_stream_writer = StreamWriter(stdout_stream, self.is_debugging)
 
+        # Like API Gateway, keep the trace of the request if it is already traced, or start a new one
+        trace_id = request.headers.get(TRACE_ID_HEADER) or generate_trace_header()
+
+        if not self._invocation_limiter.acquire():
+            LOG.info(
+                "Rejecting the request to %s %s, %s functions are already running",
+                method,
+                endpoint,
+                self._invocation_limiter.max_invocations,
+            )
+            return ServiceErrorResponses.service_unavailable_response()
+
         try:
-            self.lambda_runner.invoke(route.function_name, event, stdout=stdout_stream_writer, stderr=self.stderr)
+            self.lambda_runner.invoke(
+                route.function_name, event, stdout=stdout_stream_writer, stderr=self.stderr, trace_id=trace_id
+            )
         except FunctionNotFound:
             return ServiceErrorResponses.lambda_not_found_response()
+        except FunctionTimeoutException as ex:
+            LOG.info("Function %s timed out, responding with 504", route.function_name)
+            return ServiceErrorResponses.gateway_timeout_response(str(ex))
+        finally:
+            self._invocation_limiter.release()
 
+        LOG.debug("Function %s wrote to stdout: %s", route.function_name, stdout_stream.getvalue())
         lambda_response, lambda_logs, _ = LambdaOutputParser.get_lambda_output(stdout_stream)
 
         if self.stderr and lambda_logs:
@@ -339,6 +463,14 @@ class LocalApigwService(BaseLocalService):
             LOG.error("Invalid lambda response received: %s", ex)
             return ServiceErrorResponses.lambda_failure_response()
 
+        if TRACE_ID_HEADER not in headers:
+            headers[TRACE_ID_HEADER] = trace_id
+
+        # Responses carry the CORS headers of the API like the preflight responses, unless the function sets them
+        for header_name, header_value in cors_headers.items():
+            if header_name not in headers:
+                headers[header_name] = header_value
+
         return self.service_response(body, headers, status_code)
 
     def _get_current_route(self, flask_request):
@@ -437,6 +569,8 @@ class LocalApigwService(BaseLocalService):
         except ValueError as ex:
             LambdaResponseParseException(str(ex))
 
+        body = LocalApigwService._decode_unaccepted_content_encoding(flask_request, headers, body)
+
         return status_code, headers, body
 
     @staticmethod
@@ -508,6 +642,8 @@ class LocalApigwService(BaseLocalService):
         except ValueError as ex:
             LambdaResponseParseException(str(ex))
 
+        body = LocalApigwService._decode_unaccepted_content_encoding(flask_request, headers, body)
+
         return status_code, headers, body
 
     @staticmethod
@@ -537,10 +673,84 @@ class LocalApigwService(BaseLocalService):
         True if the body from the request should be converted to binary, otherwise false
 
         """
-        best_match_mimetype = flask_request.accept_mimetypes.best_match(lamba_response_headers.get_all("Content-Type"))
-        is_best_match_in_binary_types = best_match_mimetype in binary_types or "*/*" in binary_types
+        # The Content-Type of the response is matched without its parameters, like multipart/form-data; boundary=...
+        response_mimetypes = [
+            content_type.split(";")[0].strip() for content_type in lamba_response_headers.get_all("Content-Type")
+        ]
+        best_match_mimetype = flask_request.accept_mimetypes.best_match(response_mimetypes)
+
+        return (
+            best_match_mimetype
+            and LocalApigwService._is_binary_mimetype(binary_types, best_match_mimetype)
+            and is_base_64_encoded
+        )
+
+    @staticmethod
+    def _decode_unaccepted_content_encoding(flask_request, lambda_response_headers, body):
+        """
+        Decompresses a gzip or deflate encoded body when the client did not accept the encoding in Accept-Encoding,
+        and removes the Content-Encoding header, so the client doesn't receive a body it can't read
+
+        Parameters
+        ----------
+        flask_request flask.request
+            Flask request
+        lambda_response_headers werkzeug.datastructures.Headers
+            Headers Lambda returns
+        body bytes
+            Body of the response, only binary bodies can be compressed
+
+        Returns
+        -------
+        The body, decompressed if the client does not accept its encoding
+        """
+        encoding = (lambda_response_headers.get("Content-Encoding") or "").strip().lower()
+        if not isinstance(body, bytes) or encoding not in CONTENT_DECODERS:
+            return body
 
-        return best_match_mimetype and is_best_match_in_binary_types and is_base_64_encoded
+        if LocalApigwService._accepts_encoding(flask_request.headers.get("Accept-Encoding") or "", encoding):
+            return body
+
+        try:
+            body = CONTENT_DECODERS[encoding](body)
+        except (OSError, EOFError, zlib.error) as ex:
+            LOG.warning("Unable to decode the %s encoded body returned by the Lambda function: %s", encoding, ex)
+            return body
+
+        LOG.debug("Decoded the %s encoded body, the client does not accept this encoding", encoding)
+        del lambda_response_headers["Content-Encoding"]
+        return body
+
+    @staticmethod
+    def _accepts_encoding(accept_encoding, encoding):
+        """
+        Whether the Accept-Encoding header of a request accepts the given content encoding
+
+        Parameters
+        ----------
+        accept_encoding str
+            Value of the Accept-Encoding header, like "gzip, deflate;q=0.5"
+        encoding str
+            Content encoding, like gzip
+
+        Returns
+        -------
+        True if the encoding is accepted with a non zero quality, either by name or by the * wildcard
+        """
+        accepted = {}
+        for coding in accept_encoding.split(","):
+            name, _, params = coding.partition(";")
+            quality = 1.0
+            params = params.strip()
+            if params.startswith("q="):
+                try:
+                    quality = float(params[2:])
+                except ValueError:
+                    quality = 0.0
+            accepted[name.strip().lower()] = quality
+
+        quality = accepted.get(encoding, accepted.get("*", 0.0))
+        return quality > 0
 
     @staticmethod
     def _merge_response_headers(headers, multi_headers):
@@ -576,7 +786,15 @@ class LocalApigwService(BaseLocalService):
         return processed_headers
 
     @staticmethod
-    def _construct_v_1_0_event(flask_request, port, binary_types, stage_name=None, stage_variables=None):
+    def _construct_v_1_0_event(
+        flask_request,
+        port,
+        binary_types,
+        stage_name=None,
+        stage_variables=None,
+        forwarded_host=None,
+        forwarded_proto=None,
+    ):
         """
         Helper method that constructs the Event to be passed to Lambda
 
@@ -585,6 +803,8 @@ class LocalApigwService(BaseLocalService):
         :param binary_types: list of binary types
         :param stage_name: Optional, the stage name string
         :param stage_variables: Optional, API Gateway Stage Variables
+        :param forwarded_host: Optional, the host to use instead of the host of the request
+        :param forwarded_proto: Optional, the protocol to use instead of the scheme of the request
         :return: String representing the event
         """
         # pylint: disable-msg=too-many-locals
@@ -594,7 +814,7 @@ class LocalApigwService(BaseLocalService):
         endpoint = PathConverter.convert_path_to_api_gateway(flask_request.endpoint)
         method = flask_request.method
         protocol = flask_request.environ.get("SERVER_PROTOCOL", "HTTP/1.1")
-        host = flask_request.host
+        host = forwarded_host or flask_request.host
 
         request_data = flask_request.get_data()
 
@@ -624,7 +844,9 @@ class LocalApigwService(BaseLocalService):
             domain_name=host,
         )
 
-        headers_dict, multi_value_headers_dict = LocalApigwService._event_headers(flask_request, port)
+        headers_dict, multi_value_headers_dict = LocalApigwService._event_headers(
+            flask_request, port, forwarded_host, forwarded_proto
+        )
 
         event = ApiGatewayLambdaEvent(
             http_method=method,
@@ -647,7 +869,14 @@ class LocalApigwService(BaseLocalService):
 
     @staticmethod
     def _construct_v_2_0_event_http(
-        flask_request, port, binary_types, stage_name=None, stage_variables=None, route_key=None
+        flask_request,
+        port,
+        binary_types,
+        stage_name=None,
+        stage_variables=None,
+        route_key=None,
+        forwarded_host=None,
+        forwarded_proto=None,
     ):
         """
         Helper method that constructs the Event 2.0 to be passed to Lambda
@@ -660,6 +889,8 @@ class LocalApigwService(BaseLocalService):
         :param stage_name: Optional, the stage name string
         :param stage_variables: Optional, API Gateway Stage Variables
         :param route_key: Optional, the route key for the route
+        :param forwarded_host: Optional, the host to use instead of the host of the request
+        :param forwarded_proto: Optional, the protocol to use instead of the scheme of the request
         :return: String representing the event
         """
         # pylint: disable-msg=too-many-locals
@@ -682,7 +913,7 @@ class LocalApigwService(BaseLocalService):
         query_string_dict, _ = LocalApigwService._query_string_params(flask_request)
 
         cookies = LocalApigwService._event_http_cookies(flask_request)
-        headers = LocalApigwService._event_http_headers(flask_request, port)
+        headers = LocalApigwService._event_http_headers(flask_request, port, forwarded_host, forwarded_proto)
         context_http = ContextHTTP(method=method, path=flask_request.path, source_ip=flask_request.remote_addr)
         context = RequestContextV2(http=context_http, route_key=route_key, stage=stage_name)
         event = ApiGatewayV2LambdaEvent(
@@ -737,7 +968,7 @@ class LocalApigwService(BaseLocalService):
         return query_string_dict, multi_value_query_string_dict
 
     @staticmethod
-    def _event_headers(flask_request, port):
+    def _event_headers(flask_request, port, forwarded_host=None, forwarded_proto=None):
         """
         Constructs an APIGW equivalent headers dictionary
 
@@ -747,8 +978,10 @@ class LocalApigwService(BaseLocalService):
             Request from Flask
         int port
             Forwarded Port
-        cors_headers dict
-            Dict of the Cors properties
+        forwarded_host str
+            Optional. Host to set in the Host and X-Forwarded-Host headers
+        forwarded_proto str
+            Optional. Protocol to set in the X-Forwarded-Proto header, defaults to the scheme of the request
 
         Returns dict (str: str), dict (str: list of str)
         -------
@@ -761,11 +994,18 @@ class LocalApigwService(BaseLocalService):
         # Multi-value request headers is not really supported by Flask.
         # See https://github.com/pallets/flask/issues/850
         for header_key in flask_request.headers.keys():
-            headers_dict[header_key] = flask_request.headers.get(header_key)
-            multi_value_headers_dict[header_key] = flask_request.headers.getlist(header_key)
+            header_values = flask_request.headers.getlist(header_key)
+            # Like API Gateway, the single-value headers keep the last value of a repeated header
+            headers_dict[header_key] = header_values[-1] if header_values else ""
+            multi_value_headers_dict[header_key] = header_values
+
+        if forwarded_host:
+            for header_key in LocalApigwService._FORWARDED_HOST_HEADERS:
+                headers_dict[header_key] = forwarded_host
+                multi_value_headers_dict[header_key] = [forwarded_host]
 
-        headers_dict["X-Forwarded-Proto"] = flask_request.scheme
-        multi_value_headers_dict["X-Forwarded-Proto"] = [flask_request.scheme]
+        headers_dict["X-Forwarded-Proto"] = forwarded_proto or flask_request.scheme
+        multi_value_headers_dict["X-Forwarded-Proto"] = [forwarded_proto or flask_request.scheme]
 
         headers_dict["X-Forwarded-Port"] = str(port)
         multi_value_headers_dict["X-Forwarded-Port"] = [str(port)]
@@ -794,7 +1034,7 @@ class LocalApigwService(BaseLocalService):
         return cookies
 
     @staticmethod
-    def _event_http_headers(flask_request, port):
+    def _event_http_headers(flask_request, port, forwarded_host=None, forwarded_proto=None):
         """
         Duplicate headers are combined with commas.
 
@@ -804,6 +1044,12 @@ class LocalApigwService(BaseLocalService):
         ----------
         flask_request request
             Request from Flask
+        int port
+            Forwarded Port
+        forwarded_host str
+            Optional. Host to set in the Host and X-Forwarded-Host headers
+        forwarded_proto str
+            Optional. Protocol to set in the X-Forwarded-Proto header, defaults to the scheme of the request
 
         Returns list
         -------
@@ -816,7 +1062,11 @@ class LocalApigwService(BaseLocalService):
         for header_key in flask_request.headers.keys():
             headers[header_key] = flask_request.headers.get(header_key)
 
-        headers["X-Forwarded-Proto"] = flask_request.scheme
+        if forwarded_host:
+            for header_key in LocalApigwService._FORWARDED_HOST_HEADERS:
+                headers[header_key] = forwarded_host
+
+        headers["X-Forwarded-Proto"] = forwarded_proto or flask_request.scheme
         headers["X-Forwarded-Port"] = str(port)
         return headers
 
@@ -837,4 +1087,26 @@ class LocalApigwService(BaseLocalService):
             True if the data should be encoded to Base64 otherwise False
 
         """
-        return request_mimetype in binary_types or "*/*" in binary_types
+        return LocalApigwService._is_binary_mimetype(binary_types, request_mimetype)
+
+    @staticmethod
+    def _is_binary_mimetype(binary_types, mimetype):
+        """
+        Whether the mimetype is one of the binary types, which can have wildcards like image/* or */*
+
+        Parameters
+        ----------
+        binary_types list(basestring)
+            Corresponds to self.binary_types (aka. what is parsed from SAM Template
+        mimetype str
+            Mimetype without parameters, ex: multipart/form-data
+
+        Returns
+        -------
+            True if the mimetype is a binary type otherwise False
+
+        """
+        if "*/*" in binary_types or mimetype in binary_types:
+            return True
+
+        return bool(mimetype) and f"{mimetype.split('/')[0]}/*" in binary_types
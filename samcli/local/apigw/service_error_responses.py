@@ -1,5 +1,7 @@
 """Class container to hold common Service Responses"""
 
+import math
+
 from flask import jsonify, make_response
 
 
@@ -8,9 +10,14 @@ class ServiceErrorResponses:
     _NO_LAMBDA_INTEGRATION = {"message": "No function defined for resource method"}
     _MISSING_AUTHENTICATION = {"message": "Missing Authentication Token"}
     _LAMBDA_FAILURE = {"message": "Internal server error"}
+    _TOO_MANY_REQUESTS = {"message": "Too Many Requests"}
+    _SERVICE_UNAVAILABLE = {"message": "Service Unavailable"}
 
     HTTP_STATUS_CODE_502 = 502
     HTTP_STATUS_CODE_403 = 403
+    HTTP_STATUS_CODE_429 = 429
+    HTTP_STATUS_CODE_503 = 503
+    HTTP_STATUS_CODE_504 = 504
 
     @staticmethod
     def lambda_failure_response(*args):
@@ -32,6 +39,41 @@ class ServiceErrorResponses:
         response_data = jsonify(ServiceErrorResponses._NO_LAMBDA_INTEGRATION)
         return make_response(response_data, ServiceErrorResponses.HTTP_STATUS_CODE_502)
 
+    @staticmethod
+    def too_many_requests_response(retry_after):
+        """
+        Constructs a Flask Response for when a request is throttled, with a Retry-After header telling the client
+        how many seconds to wait before retrying
+
+        :param float retry_after: Seconds until the route accepts a request
+        :return: a Flask Response
+        """
+        response_data = jsonify(ServiceErrorResponses._TOO_MANY_REQUESTS)
+        response = make_response(response_data, ServiceErrorResponses.HTTP_STATUS_CODE_429)
+        response.headers["Retry-After"] = str(max(1, math.ceil(retry_after)))
+        return response
+
+    @staticmethod
+    def service_unavailable_response(*args):
+        """
+        Constructs a Flask Response for when the request can't be served because too many functions are running
+
+        :return: a Flask Response
+        """
+        response_data = jsonify(ServiceErrorResponses._SERVICE_UNAVAILABLE)
+        return make_response(response_data, ServiceErrorResponses.HTTP_STATUS_CODE_503)
+
+    @staticmethod
+    def gateway_timeout_response(message):
+        """
+        Constructs a Flask Response for when the function of the route runs past its timeout
+
+        :param str message: Message telling how long the function ran before it timed out
+        :return: a Flask Response
+        """
+        response_data = jsonify({"message": message})
+        return make_response(response_data, ServiceErrorResponses.HTTP_STATUS_CODE_504)
+
     @staticmethod
     def route_not_found(*args):
         """
@@ -0,0 +1,48 @@
+"""
+Throttles the requests of the routes of the local API, like the throttling of API Gateway
+"""
+
+import threading
+import time
+
+
+class RouteThrottler:
+    """
+    Limits the rate of requests of every route with a token bucket. A bucket holds up to one second of requests,
+    so short bursts are allowed as long as the average rate stays below the limit. This class is thread-safe.
+    """
+
+    def __init__(self, rate, clock=time.monotonic):
+        """
+        Creates a RouteThrottler
+
+        :param float rate: Maximum number of requests per second of every route. A rate of 0 throttles every request
+        :param clock: Optional, function returning the current time in seconds, used by the tests
+        """
+        self.rate = rate
+        self._capacity = max(rate, 1)
+        self._clock = clock
+        self._buckets = {}
+        self._lock = threading.Lock()
+
+    def acquire(self, route_key):
+        """
+        Takes a request from the bucket of the route
+
+        :param str route_key: Key identifying the route of the request
+        :return float: 0 if the request is allowed, otherwise the number of seconds until the route accepts a request
+        """
+        if self.rate <= 0:
+            return 1.0
+
+        with self._lock:
+            now = self._clock()
+            tokens, last_time = self._buckets.get(route_key, (self._capacity, now))
+            tokens = min(self._capacity, tokens + (now - last_time) * self.rate)
+
+            if tokens >= 1:
+                self._buckets[route_key] = (tokens - 1, now)
+                return 0.0
+
+            self._buckets[route_key] = (tokens, now)
+            return (1 - tokens) / self.rate
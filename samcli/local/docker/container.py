@@ -2,21 +2,30 @@
 Representation of a generic Docker container
 """
 import logging
+import os
 import tarfile
 import tempfile
 import threading
+import time
 
 import docker
 import requests
 
 from docker.errors import NotFound as DockerNetworkNotFound
 from samcli.lib.utils.retry import retry
+from samcli.lib.utils.xray import TRACE_ID_HEADER
 from .exceptions import ContainerNotStartableException
 
 from .utils import to_posix_path, find_free_port, NoFreePortsError
 
 LOG = logging.getLogger(__name__)
 
+# Label of all the containers SAM CLI creates, which "sam local clean" looks for
+SAM_LOCAL_LABEL = "com.aws.sam.local"
+# Labels of the template and the logical id of the function a container runs
+SAM_LOCAL_TEMPLATE_LABEL = "com.aws.sam.local.template"
+SAM_LOCAL_FUNCTION_LABEL = "com.aws.sam.local.function"
+
 
 class ContainerResponseException(Exception):
     """
@@ -41,6 +50,13 @@ class Container:
     URL = "http://{host}:{port}/2015-03-31/functions/{function_name}/invocations"
     # Set connection timeout to 1 sec to support the large input.
     RAPID_CONNECTION_TIMEOUT = 1
+    # Max seconds to wait for the remaining container logs to be written once the container is deleted
+    LOGS_THREAD_JOIN_TIMEOUT = 1
+    # Max seconds to wait for RAPID to serve requests once the container is started, and the interval to poll it at
+    RAPID_READY_TIMEOUT = 10
+    RAPID_READY_POLL_INTERVAL = 0.1
+    # Lambda allocates CPU in proportion to memory, with the equivalent of one vCPU at 1769 MB
+    MEMORY_MB_PER_VCPU = 1769
 
     def __init__(
         self,
@@ -57,6 +73,8 @@ class Container:
         additional_volumes=None,
         container_host="localhost",
         container_host_interface="127.0.0.1",
+        network_aliases=None,
+        labels=None,
     ):
         """
         Initializes the class with given configuration. This does not automatically create or run the container.
@@ -75,6 +93,9 @@ class Container:
         :param additional_volumes: Optional list of additional volumes
         :param string container_host: Optional. Host of locally emulated Lambda container
         :param string container_host_interface: Optional. Interface that Docker host binds ports to
+        :param list network_aliases: Optional. Aliases the container is reachable by on the Docker network
+        :param dict labels: Optional. Labels of this container. The container manager adds the labels common to all
+            its containers before creating it
         """
 
         self._image = image
@@ -86,9 +107,18 @@ class Container:
         self._env_vars = env_vars
         self._memory_limit_mb = memory_limit_mb
         self._network_id = None
+        self._network_aliases = network_aliases
+        self.labels = labels
+        self.hostname = None
+        self.shm_size = None
+        self.cpu_proportional = False
+        self.no_memory_limit = False
+        self.extra_hosts = None
+        self.force_linux_paths = False
         self._container_opts = container_opts
         self._additional_volumes = additional_volumes
         self._logs_thread = None
+        self._is_ready = False
 
         # Use the given Docker client or create new one
         self.docker_client = docker_client or docker.from_env()
@@ -153,7 +183,9 @@ class Container:
             kwargs["volumes"].update(self._additional_volumes)
 
         # Make sure all mounts are of posix path style.
-        kwargs["volumes"] = {to_posix_path(host_dir): mount for host_dir, mount in kwargs["volumes"].items()}
+        kwargs["volumes"] = {
+            to_posix_path(host_dir, self.force_linux_paths): mount for host_dir, mount in kwargs["volumes"].items()
+        }
 
         if self._env_vars:
             kwargs["environment"] = self._env_vars
@@ -171,12 +203,27 @@ class Container:
         if self._entrypoint:
             kwargs["entrypoint"] = self._entrypoint
 
-        if self._memory_limit_mb:
+        if self._memory_limit_mb and not self.no_memory_limit:
             # Ex: 128m => 128MB
             kwargs["mem_limit"] = "{}m".format(self._memory_limit_mb)
 
+        if self.shm_size:
+            kwargs["shm_size"] = self.shm_size
+
+        # Labeling every container makes the ones left behind when SAM CLI crashes possible to find and remove
+        kwargs["labels"] = {**(self.labels or {}), SAM_LOCAL_LABEL: "true"}
+
+        if self.extra_hosts:
+            kwargs["extra_hosts"] = self.extra_hosts
+
+        if self.cpu_proportional and self._memory_limit_mb:
+            kwargs["nano_cpus"] = self.get_proportional_nano_cpus(self._memory_limit_mb)
+
         if self.network_id == "host":
             kwargs["network_mode"] = self.network_id
+        elif self.hostname:
+            # Docker rejects a hostname for containers sharing the network stack of the host
+            kwargs["hostname"] = self.hostname
 
         real_container = self.docker_client.containers.create(self._image, **kwargs)
         self.id = real_container.id
@@ -186,7 +233,10 @@ class Container:
         if self.network_id and self.network_id != "host":
             try:
                 network = self.docker_client.networks.get(self.network_id)
-                network.connect(self.id)
+                if self._network_aliases:
+                    network.connect(self.id, aliases=self._network_aliases)
+                else:
+                    network.connect(self.id)
             except DockerNetworkNotFound:
                 # stop and delete the created container before raising the exception
                 real_container.remove(force=True)
@@ -196,13 +246,14 @@ class Container:
 
     def stop(self, time=3):
         """
-        Stop a container, with a given number of seconds between sending SIGTERM and SIGKILL.
+        Stop a container, with a given number of seconds between sending SIGTERM and SIGKILL. The runtime and the
+        extensions use this time to flush their buffers and close their connections.
 
         Parameters
         ----------
         time
             Optional. Number of seconds between SIGTERM and SIGKILL. Effectively, the amount of time
-            the container has to perform shutdown steps. Default: 3
+            the container has to perform shutdown steps. The container is killed right away if it is 0. Default: 3
         """
         if not self.is_created():
             LOG.debug("Container was not created, cannot run stop.")
@@ -216,12 +267,13 @@ class Container:
         except docker.errors.APIError as ex:
             msg = str(ex)
             removal_in_progress = ("removal of container" in msg) and ("is already in progress" in msg)
+            not_running = "is not running" in msg
 
-            # When removal is already started, Docker API will throw an exception
+            # When removal is already started, or the container already exited, Docker API will throw an exception
             # Skip such exceptions and log
-            if not removal_in_progress:
+            if not removal_in_progress and not not_running:
                 raise ex
-            LOG.debug("Container removal is in progress, skipping exception: %s", msg)
+            LOG.debug("Container removal is in progress or container is not running, skipping exception: %s", msg)
 
     def delete(self):
         """
@@ -246,6 +298,11 @@ class Container:
                 raise ex
             LOG.debug("Container removal is in progress, skipping exception: %s", msg)
 
+        # Removing the container closes its attach stream. Wait for the logs thread to write what is left,
+        # so all the logs of the container are in the stderr stream once it is deleted.
+        if self._logs_thread and self._logs_thread.is_alive():
+            self._logs_thread.join(timeout=self.LOGS_THREAD_JOIN_TIMEOUT)
+
         self.id = None
 
     def start(self, input_data=None):
@@ -272,20 +329,52 @@ class Container:
         # Start the container
         real_container.start()
 
+    def wait_until_ready(self):
+        """
+        Waits until RAPID in the started container serves requests, so that the first invoke of a freshly started
+        container does not race the startup of the runtime interface emulator. RAPID only answers after it is
+        listening, and holds the invokes it receives until the bootstrap of the function is initialized.
+
+        Raises
+        ------
+        ContainerResponseException
+            If RAPID does not serve requests within RAPID_READY_TIMEOUT seconds
+        """
+        if self._is_ready:
+            return
+
+        url = "http://{host}:{port}/".format(host=self._container_host, port=self.rapid_port_host)
+        deadline = time.monotonic() + self.RAPID_READY_TIMEOUT
+        while True:
+            try:
+                # Any response, even an error status, means RAPID is listening
+                requests.get(url, timeout=self.RAPID_CONNECTION_TIMEOUT)
+                self._is_ready = True
+                return
+            except requests.exceptions.RequestException as ex:
+                if time.monotonic() >= deadline:
+                    raise ContainerResponseException(
+                        "Container did not become ready within {} seconds".format(self.RAPID_READY_TIMEOUT)
+                    ) from ex
+                LOG.debug("Waiting for the container to become ready")
+                time.sleep(self.RAPID_READY_POLL_INTERVAL)
+
     @retry(exc=requests.exceptions.RequestException, exc_raise=ContainerResponseException)
-    def wait_for_http_response(self, name, event, stdout):
+    def wait_for_http_response(self, name, event, stdout, trace_id=None):
         # TODO(sriram-mv): `aws-lambda-rie` is in a mode where the function_name is always "function"
         # NOTE(sriram-mv): There is a connection timeout set on the http call to `aws-lambda-rie`, however there is not
         # a read time out for the response received from the server.
+        # `aws-lambda-rie` passes the tracing header of the invoke to the runtime, which sets it as _X_AMZN_TRACE_ID
 
         resp = requests.post(
             self.URL.format(host=self._container_host, port=self.rapid_port_host, function_name="function"),
             data=event.encode("utf-8"),
+            headers={TRACE_ID_HEADER: trace_id} if trace_id else None,
             timeout=(self.RAPID_CONNECTION_TIMEOUT, None),
         )
         stdout.write(resp.content)
 
-    def wait_for_result(self, name, event, stdout, stderr):
+    def wait_for_result(self, name, event, stdout, stderr, trace_id=None):
         # NOTE(sriram-mv): Let logging happen in its own thread, so that a http request can be sent.
         # NOTE(sriram-mv): All logging is re-directed to stderr, so that only the lambda function return
         # will be written to stdout.
@@ -296,7 +385,7 @@ class Container:
             self._logs_thread = threading.Thread(target=self.wait_for_logs, args=(stderr, stderr), daemon=True)
             self._logs_thread.start()
 
-        self.wait_for_http_response(name, event, stdout)
+        self.wait_for_http_response(name, event, stdout, trace_id=trace_id)
 
     def wait_for_logs(self, stdout=None, stderr=None):
 
@@ -333,6 +422,18 @@ class Container:
             with tarfile.open(fileobj=fp, mode="r") as tar:
                 tar.extractall(path=to_host_path)
 
+    @staticmethod
+    def get_proportional_nano_cpus(memory_mb):
+        """
+        Computes the CPU Lambda allocates to a function with the given memory, in billionths of a CPU like the
+        NanoCpus of Docker. Docker rejects more CPUs than the host has, so it is capped at the CPUs of the host.
+
+        :param int memory_mb: Memory of the function in MB
+        :return int: CPU of the function in billionths of a CPU
+        """
+        nano_cpus = int(int(memory_mb) * 1e9 / Container.MEMORY_MB_PER_VCPU)
+        return min(nano_cpus, int((os.cpu_count() or 1) * 1e9))
+
     @staticmethod
     def _write_container_output(output_itr, stdout=None, stderr=None):
         """
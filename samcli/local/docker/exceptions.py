@@ -11,3 +11,9 @@ class NoFreePortsError(Exception):
     """
     Exception to raise when there are no free ports found in a specified range.
     """
+
+
+class DockerAPIVersionNotSupportedException(Exception):
+    """
+    Exception to raise when the Docker daemon is older than the minimum API version SAM CLI supports.
+    """
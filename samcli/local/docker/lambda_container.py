@@ -47,6 +47,9 @@ class LambdaContainer(Container):
         debug_options=None,
         container_host=None,
         container_host_interface=None,
+        network_aliases=None,
+        labels=None,
+        entrypoint=None,
     ):
         """
         Initializes the class
@@ -80,6 +83,13 @@ class LambdaContainer(Container):
             Optional. Host of locally emulated Lambda container
         container_host_interface
             Optional. Interface that Docker host binds ports to
+        network_aliases list(str)
+            Optional. Aliases the container is reachable by on the Docker network
+        labels dict
+            Optional. Labels of the container, like the logical id of its function
+        entrypoint list(str)
+            Optional. Entrypoint that replaces the one of the runtime or of the image, and the one starting the
+            debugger. The handler of the function, or the command of the image, is passed to it as the command
         """
         if not Runtime.has_value(runtime) and not packagetype == IMAGE:
             raise ValueError("Unsupported Lambda runtime {}".format(runtime))
@@ -87,7 +97,7 @@ class LambdaContainer(Container):
         image = LambdaContainer._get_image(lambda_image, runtime, packagetype, imageuri, layers)
         ports = LambdaContainer._get_exposed_ports(debug_options)
         config = LambdaContainer._get_config(lambda_image, image)
-        entry, container_env_vars = LambdaContainer._get_debug_settings(runtime, debug_options)
+        entry, container_env_vars = LambdaContainer._get_debug_settings(runtime, debug_options, memory_mb)
         additional_options = LambdaContainer._get_additional_options(runtime, debug_options)
         additional_volumes = LambdaContainer._get_additional_volumes(runtime, debug_options)
 
@@ -113,7 +123,25 @@ class LambdaContainer(Container):
                 _entrypoint = _entrypoint + _additional_entrypoint_args
             _work_dir = (image_config.get("WorkingDirectory") if image_config else None) or config.get("WorkingDir")
 
-        env_vars = {**env_vars, **container_env_vars}
+        if entrypoint:
+            if debug_options:
+                LOG.warning(
+                    "The entrypoint of the container is overridden, the debugger is not started with the function. "
+                    "Start it from the overriding entrypoint to debug the function."
+                )
+            _entrypoint = entrypoint
+            entry = None
+            if packagetype != IMAGE:
+                _command = [handler]
+
+        # The heap of a debugged function is sized by its debug settings. Otherwise it is sized here with the same
+        # ratio, the function's own environment variables take precedence.
+        heap_env_vars = (
+            {}
+            if debug_options and debug_options.debug_ports
+            else LambdaDebugSettings.get_heap_env_vars(runtime, memory_mb)
+        )
+        env_vars = {**heap_env_vars, **env_vars, **container_env_vars}
         super().__init__(
             image,
             _command if _command else [],
@@ -127,6 +155,8 @@ class LambdaContainer(Container):
             additional_volumes=additional_volumes,
             container_host=container_host,
             container_host_interface=container_host_interface,
+            network_aliases=network_aliases,
+            labels=labels,
         )
 
     @staticmethod
@@ -135,7 +165,7 @@ class LambdaContainer(Container):
         Return Docker container port binding information. If a debug port tuple is given, then we will ask Docker to
         bind every given port to same port both inside and outside the container ie.
         Runtime process is started in debug mode with at given port inside the container
-        and exposed to the host machine at the same port.
+        and exposed to the host machine at the same port, unless a different host port was given for it.
 
         :param DebugContext debug_options: Debugging options for the function (includes debug port, args, and path)
         :return dict: Dictionary containing port binding information. None, if debug_port was not given
@@ -146,10 +176,12 @@ class LambdaContainer(Container):
         if not debug_options.debug_ports:
             return None
 
+        debug_host_ports = debug_options.debug_host_ports or {}
+
         # container port : host port
         ports_map = {}
         for port in debug_options.debug_ports:
-            ports_map[port] = port
+            ports_map[port] = debug_host_ports.get(port, port)
 
         return ports_map
 
@@ -217,7 +249,7 @@ class LambdaContainer(Container):
         return lambda_image.get_config(image)
 
     @staticmethod
-    def _get_debug_settings(runtime, debug_options=None):  # pylint: disable=too-many-branches
+    def _get_debug_settings(runtime, debug_options=None, memory_mb=None):  # pylint: disable=too-many-branches
         """
         Returns the entry point for the container. The default value for the entry point is already configured in the
         Dockerfile. We override this default specifically when enabling debugging. The overridden entry point includes
@@ -225,6 +257,7 @@ class LambdaContainer(Container):
 
         :param string runtime: Lambda function runtime name.
         :param DebugContext debug_options: Optional. Debug context for the function (includes port, args, and path).
+        :param int memory_mb: Optional. Memory of the function in MB, used to size the runtime heap.
         :return list: List containing the new entry points. Each element in the list is one portion of the command.
             ie. if command is ``node index.js arg1 arg2``, then this list will be ["node", "index.js", "arg1", "arg2"]
         """
@@ -251,4 +284,5 @@ class LambdaContainer(Container):
             _container_env_vars=container_env_vars,
             runtime=runtime,
             options=LambdaContainer._DEBUG_ENTRYPOINT_OPTIONS,
+            memory_mb=memory_mb,
         )
@@ -5,7 +5,7 @@ Represents Lambda debug entrypoints.
 import logging
 from argparse import ArgumentParser
 from collections import namedtuple
-from typing import List, cast
+from typing import Dict, List, Optional, cast
 
 from samcli.local.docker.lambda_image import Runtime
 
@@ -20,8 +20,14 @@ LOG = logging.getLogger(__name__)
 
 
 class LambdaDebugSettings:
+    # Share of the function memory handed to the managed heap (JVM heap, Node.js old space) of a function
+    _HEAP_MEMORY_RATIO = 0.85
+    # Memory of the largest (3008 MB) function, the JVM heap is sized for it when the function memory is not known
+    _MAX_MEMORY_MB = 3008
+    _DEFAULT_JAVA_MAX_HEAP_SIZE_KB = int(_MAX_MEMORY_MB * 1024 * _HEAP_MEMORY_RATIO)
+
     @staticmethod
-    def get_debug_settings(debug_port, debug_args_list, _container_env_vars, runtime, options):
+    def get_debug_settings(debug_port, debug_args_list, _container_env_vars, runtime, options, memory_mb=None):
         """
         Get Debug settings based on the Runtime
 
@@ -37,6 +43,8 @@ class LambdaDebugSettings:
             Lambda Function runtime
         options dict
             Additonal options needed (i.e delve Path)
+        memory_mb int
+            Optional. Memory of the function in MB, used to size the runtime heap
 
         Returns
         -------
@@ -50,6 +58,12 @@ class LambdaDebugSettings:
         if not _container_env_vars:
             _container_env_vars = dict()
 
+        # The heap is sized with the same ratio as without a debugger (see get_heap_env_vars), so GC behaves the same
+        # with and without a debugger attached
+        java_max_heap_size_kb = LambdaDebugSettings._get_java_max_heap_size_kb(memory_mb)
+        node_max_old_space_size_mb = LambdaDebugSettings._get_node_max_old_space_size_mb(memory_mb)
+        node_memory_args = [f"--max-old-space-size={node_max_old_space_size_mb}"] if node_max_old_space_size_mb else []
+
         # The value of entrypoint_mapping is a callable instead of DebugSettings
         # so that DebugSetting objects are not always created.
         entrypoint_mapping = {
@@ -57,7 +71,7 @@ class LambdaDebugSettings:
                 entry,
                 container_env_vars={
                     "_JAVA_OPTIONS": "-agentlib:jdwp=transport=dt_socket,server=y,suspend=y,quiet=y,"
-                    f"address={debug_port} -XX:MaxHeapSize=2834432k -XX:MaxMetaspaceSize=163840k "
+                    f"address={debug_port} -XX:MaxHeapSize={java_max_heap_size_kb}k -XX:MaxMetaspaceSize=163840k "
                     "-XX:ReservedCodeCacheSize=81920k -XX:+UseSerialGC -XX:-TieredCompilation "
                     "-Djava.net.preferIPv4Stack=true -Xshare:off" + " ".join(debug_args_list),
                     **_container_env_vars,
@@ -67,7 +81,7 @@ class LambdaDebugSettings:
                 entry,
                 container_env_vars={
                     "_JAVA_OPTIONS": "-agentlib:jdwp=transport=dt_socket,server=y,suspend=y,quiet=y,"
-                    f"address={debug_port} -XX:MaxHeapSize=2834432k -XX:MaxMetaspaceSize=163840k "
+                    f"address={debug_port} -XX:MaxHeapSize={java_max_heap_size_kb}k -XX:MaxMetaspaceSize=163840k "
                     "-XX:ReservedCodeCacheSize=81920k -XX:+UseSerialGC -XX:-TieredCompilation "
                     "-Djava.net.preferIPv4Stack=true -Xshare:off" + " ".join(debug_args_list),
                     **_container_env_vars,
@@ -77,7 +91,7 @@ class LambdaDebugSettings:
                 entry,
                 container_env_vars={
                     "_JAVA_OPTIONS": "-agentlib:jdwp=transport=dt_socket,server=y,suspend=y,quiet=y,"
-                    f"address=*:{debug_port} -XX:MaxHeapSize=2834432k -XX:MaxMetaspaceSize=163840k "
+                    f"address=*:{debug_port} -XX:MaxHeapSize={java_max_heap_size_kb}k -XX:MaxMetaspaceSize=163840k "
                     "-XX:ReservedCodeCacheSize=81920k -XX:+UseSerialGC -XX:-TieredCompilation "
                     "-Djava.net.preferIPv4Stack=true" + " ".join(debug_args_list),
                     **_container_env_vars,
@@ -106,6 +120,7 @@ class LambdaDebugSettings:
                 + ["/var/lang/bin/node"]
                 + debug_args_list
                 + ["--no-lazy", "--expose-gc"]
+                + node_memory_args
                 + ["/var/runtime/index.js"],
                 container_env_vars={
                     "NODE_PATH": "/opt/nodejs/node_modules:/opt/nodejs/node10/node_modules:/var/runtime/node_modules",
@@ -119,6 +134,7 @@ class LambdaDebugSettings:
                 + ["/var/lang/bin/node"]
                 + debug_args_list
                 + ["--no-lazy", "--expose-gc"]
+                + node_memory_args
                 + ["/var/runtime/index.js"],
                 container_env_vars={
                     "NODE_PATH": "/opt/nodejs/node_modules:/opt/nodejs/node12/node_modules:/var/runtime/node_modules",
@@ -132,6 +148,7 @@ class LambdaDebugSettings:
                 + ["/var/lang/bin/node"]
                 + debug_args_list
                 + ["--no-lazy", "--expose-gc"]
+                + node_memory_args
                 + ["/var/runtime/index.js"],
                 container_env_vars={
                     "NODE_PATH": "/opt/nodejs/node_modules:/opt/nodejs/node14/node_modules:/var/runtime/node_modules",
@@ -173,3 +190,41 @@ class LambdaDebugSettings:
         if unknown_args:
             LOG.warning('Ignoring unrecognized arguments: %s. Only "-delveAPI" is supported.', unknown_args)
         return cast(int, args.delveAPI)
+
+    @staticmethod
+    def get_heap_env_vars(runtime: Optional[str], memory_mb: Optional[int] = None) -> Dict[str, str]:
+        """
+        Get the environment variables that size the managed heap of a function that is not debugged
+
+        Parameters
+        ----------
+        runtime str
+            Lambda Function runtime
+        memory_mb int
+            Optional. Memory of the function in MB
+
+        Returns
+        -------
+        dict
+            Environment variables sizing the heap, empty for runtimes without a managed heap
+        """
+        if runtime in (Runtime.java8.value, Runtime.java8al2.value, Runtime.java11.value):
+            return {"_JAVA_OPTIONS": f"-XX:MaxHeapSize={LambdaDebugSettings._get_java_max_heap_size_kb(memory_mb)}k"}
+
+        if runtime in (Runtime.nodejs10x.value, Runtime.nodejs12x.value, Runtime.nodejs14x.value) and memory_mb:
+            node_max_old_space_size_mb = LambdaDebugSettings._get_node_max_old_space_size_mb(memory_mb)
+            return {"NODE_OPTIONS": f"--max-old-space-size={node_max_old_space_size_mb}"}
+
+        return {}
+
+    @staticmethod
+    def _get_java_max_heap_size_kb(memory_mb: Optional[int]) -> int:
+        if not memory_mb:
+            return LambdaDebugSettings._DEFAULT_JAVA_MAX_HEAP_SIZE_KB
+        return int(memory_mb * 1024 * LambdaDebugSettings._HEAP_MEMORY_RATIO)
+
+    @staticmethod
+    def _get_node_max_old_space_size_mb(memory_mb: Optional[int]) -> Optional[int]:
+        if not memory_mb:
+            return None
+        return int(memory_mb * LambdaDebugSettings._HEAP_MEMORY_RATIO)
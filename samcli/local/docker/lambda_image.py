@@ -58,7 +58,7 @@ class LambdaImage:
     _SAM_CLI_REPO_NAME = "samcli/lambda"
     _RAPID_SOURCE_PATH = Path(__file__).parent.joinpath("..", "rapid").resolve()
 
-    def __init__(self, layer_downloader, skip_pull_image, force_image_build, docker_client=None):
+    def __init__(self, layer_downloader, skip_pull_image, force_image_build, docker_client=None, runtime_images=None):
         """
 
         Parameters
@@ -71,11 +71,14 @@ class LambdaImage:
             True to download the layer and rebuild the image even if it exists already on the system
         docker_client docker.DockerClient
             Optional docker client object
+        runtime_images dict
+            Optional. Images to use instead of the default images of runtimes, by runtime
         """
         self.layer_downloader = layer_downloader
         self.skip_pull_image = skip_pull_image
         self.force_image_build = force_image_build
         self.docker_client = docker_client or docker.from_env()
+        self.runtime_images = runtime_images or {}
 
     def build(self, runtime, packagetype, image, layers, stream=None):
         """
@@ -102,7 +105,7 @@ class LambdaImage:
         if packagetype == IMAGE:
             image_name = image
         elif packagetype == ZIP:
-            image_name = f"{self._INVOKE_REPO_PREFIX}-{runtime}:latest"
+            image_name = self.runtime_images.get(runtime) or f"{self._INVOKE_REPO_PREFIX}-{runtime}:latest"
 
         if not image_name:
             raise InvalidIntermediateImageError(f"Invalid PackageType, PackageType needs to be one of [{ZIP}, {IMAGE}]")
@@ -121,6 +124,9 @@ class LambdaImage:
             downloaded_layers = self.layer_downloader.download_all(layers, self.force_image_build)
 
             docker_image_version = self._generate_docker_image_version(downloaded_layers, runtime)
+            if runtime in self.runtime_images:
+                # The layers on an overridden image must not reuse the image of the layers on the default image
+                docker_image_version += "-" + hashlib.sha256(image_name.encode("utf-8")).hexdigest()[0:12]
             image_tag = f"{self._SAM_CLI_REPO_NAME}:{docker_image_version}"
 
         image_not_found = False
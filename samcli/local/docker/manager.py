@@ -7,12 +7,15 @@ import logging
 import sys
 import re
 import threading
+import time
 
 import docker
+import requests
 
 from samcli.lib.utils.stream_writer import StreamWriter
 from samcli.local.docker import utils
 from samcli.local.docker.container import Container
+from samcli.local.docker.exceptions import DockerAPIVersionNotSupportedException
 
 LOG = logging.getLogger(__name__)
 
@@ -24,7 +27,26 @@ class ContainerManager:
     serve requests faster. It is also thread-safe.
     """
 
-    def __init__(self, docker_network_id=None, docker_client=None, skip_pull_image=False, do_shutdown_event=False):
+    # Number of attempts to pull an image before giving up, and the delay in seconds before the first retry.
+    # The delay doubles after every failed attempt.
+    PULL_ATTEMPTS = 3
+    PULL_RETRY_DELAY = 1
+
+    def __init__(
+        self,
+        docker_network_id=None,
+        docker_client=None,
+        skip_pull_image=False,
+        do_shutdown_event=False,
+        container_hostname=None,
+        shm_size=None,
+        cpu_proportional=False,
+        labels=None,
+        no_memory_limit=False,
+        extra_hosts=None,
+        shutdown_timeout=0,
+        force_linux_paths=False,
+    ):
         """
         Instantiate the container manager
 
@@ -32,10 +54,28 @@ class ContainerManager:
         :param docker_client: Optional docker client object
         :param bool skip_pull_image: Should we pull new Docker container image?
         :param bool do_shutdown_event: Optional. If True, send a SHUTDOWN event to the container before final teardown.
+        :param string container_hostname: Optional. Hostname of the containers. Docker assigns one if not set.
+        :param string shm_size: Optional. Size of /dev/shm of the containers, like 512m. Docker defaults to 64m.
+        :param bool cpu_proportional: Optional. If True, limit the CPU of the containers in proportion to their memory.
+        :param dict labels: Optional. Labels to apply to the containers.
+        :param bool no_memory_limit: Optional. If True, do not limit the memory of the containers to their function's.
+        :param dict extra_hosts: Optional. IP addresses by hostname to add to /etc/hosts of the containers.
+        :param int shutdown_timeout: Optional. Seconds the containers have to exit after SIGTERM when they are stopped,
+            before they get SIGKILL. If 0, the containers are killed right away, unless do_shutdown_event is set.
+        :param bool force_linux_paths: Optional. If True, convert the paths mounted in the containers to Linux paths
+            even when not running on Windows.
         """
 
         self.skip_pull_image = skip_pull_image
         self.docker_network_id = docker_network_id
+        self.container_hostname = container_hostname
+        self.shm_size = shm_size
+        self.cpu_proportional = cpu_proportional
+        self.labels = labels
+        self.no_memory_limit = no_memory_limit
+        self.extra_hosts = extra_hosts
+        self.shutdown_timeout = shutdown_timeout
+        self.force_linux_paths = force_linux_paths
         self.docker_client = docker_client or docker.from_env()
         self.do_shutdown_event = do_shutdown_event
 
@@ -54,6 +94,28 @@ class ContainerManager:
         """
         return utils.is_docker_reachable(self.docker_client)
 
+    def check_docker_api_version(self):
+        """
+        Checks that the Docker daemon is recent enough for SAM CLI. Older daemons reject the requests of the docker
+        client with errors that don't tell how to fix them. Nothing is checked when the daemon is not reachable.
+
+        Raises
+        ------
+        DockerAPIVersionNotSupportedException
+            If the API version of the Docker daemon is older than the minimum supported one
+        """
+        api_version = utils.get_docker_api_version(self.docker_client)
+        if api_version is None:
+            return
+
+        LOG.debug("Docker daemon API version is %s", api_version)
+        if not utils.is_docker_api_version_supported(api_version):
+            raise DockerAPIVersionNotSupportedException(
+                "Docker API version {} is older than {}, the minimum that AWS SAM CLI supports. "
+                "Please update Docker, or if you use a remote Docker daemon, check that DOCKER_HOST "
+                "points to an up to date one.".format(api_version, utils.MINIMUM_DOCKER_API_VERSION)
+            )
+
     def create(self, container):
         """
         Create a container based on the given configuration.
@@ -76,8 +138,15 @@ class ContainerManager:
         # a) Image is available AND we are asked to skip pulling the image
         # OR b) Image name is samcli/lambda
         # OR c) Image is available AND image name ends with "rapid-${SAM_CLI_VERSION}"
+        # Being asked to skip pulling an image that isn't available fails here, rather than with a confusing error
+        # from Docker when creating the container
         if is_image_local and self.skip_pull_image:
             LOG.info("Requested to skip pulling images ...\n")
+        elif self.skip_pull_image and not image_name.startswith("samcli/lambda"):
+            raise DockerImagePullFailedException(
+                "Could not find {0} image locally and pulling images is skipped. Pull it with 'docker pull {0}', "
+                "or run without --skip-pull-image.".format(image_name)
+            )
         elif image_name.startswith("samcli/lambda") or (is_image_local and self._is_rapid_image(image_name)):
             LOG.info("Skip pulling image and use local one: %s.\n", image_name)
         else:
@@ -92,6 +161,14 @@ class ContainerManager:
                 LOG.info("Failed to download a new %s image. Invoking with the already downloaded image.", image_name)
 
         container.network_id = self.docker_network_id
+        container.hostname = self.container_hostname
+        container.shm_size = self.shm_size
+        container.cpu_proportional = self.cpu_proportional
+        # The labels of the container itself, like the function it runs, take precedence over the common ones
+        container.labels = {**(self.labels or {}), **(container.labels or {})}
+        container.no_memory_limit = self.no_memory_limit
+        container.extra_hosts = self.extra_hosts
+        container.force_linux_paths = self.force_linux_paths
         container.create()
 
     def run(self, container, input_data=None):
@@ -118,11 +195,14 @@ class ContainerManager:
 
     def stop(self, container: Container) -> None:
         """
-        Stop and delete the container
+        Stop and delete the container. The container gets SIGTERM first, and SIGKILL when it did not exit within
+        the shutdown timeout.
 
         :param samcli.local.docker.container.Container container: Container to stop
         """
-        if self.do_shutdown_event:
+        if self.shutdown_timeout:
+            container.stop(time=self.shutdown_timeout)
+        elif self.do_shutdown_event:
             container.stop()
         container.delete()
 
@@ -156,17 +236,19 @@ class ContainerManager:
         with image_lock:
             stream_writer = stream or StreamWriter(sys.stderr)
 
-            try:
-                result_itr = self.docker_client.api.pull(image_name, tag=tag, stream=True, decode=True)
-            except docker.errors.APIError as ex:
-                LOG.debug("Failed to download image with name %s", image_name)
-                raise DockerImagePullFailedException(str(ex)) from ex
+            result_itr = self._pull_with_retries(image_name, tag)
 
             # io streams, especially StringIO, work only with unicode strings
             stream_writer.write("\nFetching {} Docker container image...".format(image_name))
 
             # Each line contains information on progress of the pull. Each line is a JSON string
-            for _ in result_itr:
+            for progress in result_itr:
+                # A pull that fails after it started, ex: because of a missing manifest or a full disk, is reported
+                # on a line of the stream instead of as an API error
+                if "error" in progress:
+                    LOG.debug("Failed to download image with name %s", image_name)
+                    raise DockerImagePullFailedException(progress["error"])
+
                 # For every line, print a dot to show progress
                 stream_writer.write(".")
                 stream_writer.flush()
@@ -174,6 +256,63 @@ class ContainerManager:
             # We are done. Go to the next line
             stream_writer.write("\n")
 
+    def _pull_with_retries(self, image_name, tag):
+        """
+        Starts pulling the image, retrying with exponential backoff on transient errors: registry rate limiting (429),
+        server errors (5xx) and connection failures. Other errors, like images that do not exist or denied access, are
+        not retried.
+
+        Parameters
+        ----------
+        image_name str
+            Name of the image
+        tag str
+            Tag of the image
+
+        Returns
+        -------
+        iterator
+            Progress of the pull, one decoded JSON object per line
+
+        Raises
+        ------
+        DockerImagePullFailedException
+            If the Docker image could not be pulled after all attempts
+        """
+        attempt = 1
+        delay = self.PULL_RETRY_DELAY
+        while True:
+            try:
+                return self.docker_client.api.pull(image_name, tag=tag, stream=True, decode=True)
+            except (docker.errors.APIError, requests.exceptions.ConnectionError, requests.exceptions.Timeout) as ex:
+                if not self._is_transient_pull_error(ex) or attempt >= self.PULL_ATTEMPTS:
+                    LOG.debug("Failed to download image with name %s", image_name)
+                    raise DockerImagePullFailedException(str(ex)) from ex
+
+                LOG.info(
+                    "Failed to pull %s (attempt %d of %d), retrying in %d seconds: %s",
+                    image_name,
+                    attempt,
+                    self.PULL_ATTEMPTS,
+                    delay,
+                    ex,
+                )
+                time.sleep(delay)
+                attempt += 1
+                delay *= 2
+
+    @staticmethod
+    def _is_transient_pull_error(ex):
+        """
+        Is the error of an image pull worth retrying?
+
+        :param Exception ex: Error raised when starting the pull
+        :return bool: True, if the registry is rate limiting, failing or not reachable. False, otherwise
+        """
+        if isinstance(ex, docker.errors.APIError):
+            return ex.status_code == 429 or ex.is_server_error()
+        return True
+
     def has_image(self, image_name):
         """
         Is the container image with given name available?
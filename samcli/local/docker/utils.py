@@ -18,15 +18,27 @@ from samcli.local.docker.exceptions import NoFreePortsError
 
 LOG = logging.getLogger(__name__)
 
+# Oldest Docker Engine API that SAM CLI talks to. This is the API version that the docker client requests by default,
+# older daemons reject every versioned request of the client.
+MINIMUM_DOCKER_API_VERSION = "1.35"
 
-def to_posix_path(code_path):
+# Windows paths of the files of WSL distros, ex: //wsl$/Ubuntu/home/UserName or //wsl.localhost/Ubuntu/home/UserName
+_WSL_PATH_REGEX = re.compile(r"^//wsl(\$|\.localhost)/[^/]+(?P<path>/.*)?$", re.IGNORECASE)
+_EXTENDED_LENGTH_PATH_PREFIX_REGEX = re.compile(r"^//\?/(?P<unc>UNC/)?", re.IGNORECASE)
+
+
+def to_posix_path(code_path, force_linux_paths=False):
     """
     Change the code_path to be of unix-style if running on windows when supplied with an absolute windows path.
+    Drive letters become the first directory of the path, network (UNC) paths keep their leading //, and the
+    paths of WSL distros, like \\\\wsl$\\\\Ubuntu\\\\home, become the paths inside the distro.
 
     Parameters
     ----------
     code_path : str
         Directory in the host operating system that should be mounted within the container.
+    force_linux_paths : bool
+        Optional. Change the code_path even when not running on windows, ex: in Cygwin or MSYS.
     Returns
     -------
     str
@@ -37,16 +49,27 @@ def to_posix_path(code_path):
     /Users/UserName/sam-app
     >>> to_posix_path('C:\\\\Users\\\\UserName\\\\AppData\\\\Local\\\\Temp\\\\mydir')
     /c/Users/UserName/AppData/Local/Temp/mydir
+    >>> to_posix_path('\\\\\\\\fileserver\\\\share\\\\sam-app')
+    //fileserver/share/sam-app
+    >>> to_posix_path('\\\\\\\\wsl$\\\\Ubuntu\\\\home\\\\UserName\\\\sam-app')
+    /home/UserName/sam-app
     """
 
-    return (
-        re.sub(
-            "^([A-Za-z])+:",
-            lambda match: posixpath.sep + match.group().replace(":", "").lower(),
-            pathlib.PureWindowsPath(code_path).as_posix(),
-        )
-        if os.name == "nt"
-        else code_path
+    if os.name != "nt" and not force_linux_paths:
+        return code_path
+
+    posix_path = pathlib.PureWindowsPath(code_path).as_posix()
+    # Extended-length paths, ex: \\?\C:\Users or \\?\UNC\fileserver\share, are regular paths without their prefix
+    posix_path = _EXTENDED_LENGTH_PATH_PREFIX_REGEX.sub(lambda match: "//" if match.group("unc") else "", posix_path)
+
+    wsl_match = _WSL_PATH_REGEX.match(posix_path)
+    if wsl_match:
+        return wsl_match.group("path") or posixpath.sep
+
+    return re.sub(
+        "^([A-Za-z])+:",
+        lambda match: posixpath.sep + match.group().replace(":", "").lower(),
+        posix_path,
     )
 
 
@@ -69,12 +92,9 @@ def find_free_port(start=5000, end=9000):
     raise NoFreePortsError(f"No free ports on the host machine from {start} to {end}")
 
 
-def is_docker_reachable(docker_client):
+def _docker_connection_errors():
     """
-    Checks if Docker daemon is running.
-
-    :param docker_client : docker.from_env() - docker client object
-    :returns True, if Docker is available, False otherwise.
+    Returns the errors that the docker client raises when the Docker daemon can't be reached
     """
     errors = (
         docker.errors.APIError,
@@ -85,12 +105,47 @@ def is_docker_reachable(docker_client):
 
         errors += (pywintypes.error,)  # pylint: disable=no-member
 
+    return errors
+
+
+def is_docker_reachable(docker_client):
+    """
+    Checks if Docker daemon is running.
+
+    :param docker_client : docker.from_env() - docker client object
+    :returns True, if Docker is available, False otherwise.
+    """
     try:
         docker_client.ping()
         return True
 
     # When Docker is not installed, a request.exceptions.ConnectionError is thrown.
     # and also windows-specific errors
-    except errors:
+    except _docker_connection_errors():
         LOG.debug("Docker is not reachable", exc_info=True)
         return False
+
+
+def get_docker_api_version(docker_client):
+    """
+    Returns the API version of the Docker daemon. The version is read from the unversioned endpoint, which daemons
+    that are too old for the client still serve.
+
+    :param docker_client : docker.from_env() - docker client object
+    :returns str, API version of the daemon like 1.41, or None if the daemon is not reachable.
+    """
+    try:
+        return docker_client.api.version(api_version=False).get("ApiVersion")
+    except _docker_connection_errors():
+        LOG.debug("Could not get the API version of Docker", exc_info=True)
+        return None
+
+
+def is_docker_api_version_supported(api_version):
+    """
+    Checks if the given Docker API version is at least the minimum that SAM CLI supports.
+
+    :param str api_version: Docker API version like 1.41
+    :returns True, if the version is supported, False otherwise.
+    """
+    return not docker.utils.version_lt(api_version, MINIMUM_DOCKER_API_VERSION)
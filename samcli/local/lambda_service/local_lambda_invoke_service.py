@@ -8,7 +8,7 @@ from flask import Flask, request
 
 from samcli.lib.utils.stream_writer import StreamWriter
 from samcli.local.services.base_local_service import BaseLocalService, LambdaOutputParser
-from samcli.local.lambdafn.exceptions import FunctionNotFound
+from samcli.local.lambdafn.exceptions import FunctionNotFound, FunctionTimeoutException
 from .lambda_error_responses import LambdaErrorResponses
 
 LOG = logging.getLogger(__name__)
@@ -48,6 +48,7 @@ class LocalLambdaInvokeService(BaseLocalService):
             methods=["POST"],
             provide_automatic_options=False,
         )
+        self._add_health_check_route()
 
         # setup request validation before Flask calls the view_func
         self._app.before_request(LocalLambdaInvokeService.validate_request)
@@ -152,6 +153,13 @@ class LocalLambdaInvokeService(BaseLocalService):
         except FunctionNotFound:
             LOG.debug("%s was not found to invoke.", function_name)
             return LambdaErrorResponses.resource_not_found(function_name)
+        except FunctionTimeoutException as ex:
+            # Like Lambda, a timeout is an unhandled error of the function
+            return self.service_response(
+                json.dumps({"errorMessage": str(ex)}),
+                {"Content-Type": "application/json", "x-amz-function-error": "Unhandled"},
+                200,
+            )
 
         lambda_response, lambda_logs, is_lambda_user_error_response = LambdaOutputParser.get_lambda_output(
             stdout_stream
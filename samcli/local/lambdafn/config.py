@@ -27,6 +27,8 @@ class FunctionConfig:
         memory=None,
         timeout=None,
         env_vars=None,
+        network_aliases=None,
+        entrypoint=None,
     ):
         """
         Initialize the class.
@@ -50,6 +52,10 @@ class FunctionConfig:
         env_vars samcli.local.lambdafn.env_vars.EnvironmentVariables
             Optional, Environment variables.
             If it not provided, this class will generate one for you based on the function properties
+        network_aliases list(str)
+            Optional. Aliases the container of the function is reachable by on the Docker network
+        entrypoint list(str)
+            Optional. Entrypoint that replaces the one SAM CLI sets up in the container of the function
         """
         self.name = name
         self.runtime = runtime
@@ -59,6 +65,8 @@ class FunctionConfig:
         self.handler = handler
         self.code_abs_path = code_abs_path
         self.layers = layers
+        self.network_aliases = network_aliases
+        self.entrypoint = entrypoint
         self.memory = memory or self._DEFAULT_MEMORY
 
         self.timeout = timeout or self._DEFAULT_TIMEOUT_SECONDS
@@ -33,6 +33,29 @@ class EnvironmentVariables:
     _BLANK_VALUE = ""
     _DEFAULT_AWS_CREDS = {"region": "us-east-1", "key": "defaultkey", "secret": "defaultsecret"}
 
+    # Variables that stop runtimes from buffering the output of the function, by prefix of the runtime name. Without
+    # them, the logs of the function only show up in a burst once the invoke is done.
+    _UNBUFFERED_OUTPUT_VARIABLES = {"python": {"PYTHONUNBUFFERED": "1"}}
+
+    # Variable the random seed is passed in, unless another name is given. SAM can't seed the random number generators
+    # of the function, the handler must read the seed from this variable to produce reproducible outputs.
+    _DEFAULT_RANDOM_SEED_ENV_VAR = "RANDOM_SEED"
+
+    # Services whose AWS_ENDPOINT_URL_<SERVICE> variable is set along with AWS_ENDPOINT_URL, for the SDKs and tools
+    # that only read the endpoint of each service
+    _ENDPOINT_URL_SERVICES = [
+        "DYNAMODB",
+        "EVENTBRIDGE",
+        "KINESIS",
+        "LAMBDA",
+        "S3",
+        "SECRETS_MANAGER",
+        "SFN",
+        "SNS",
+        "SQS",
+        "SSM",
+    ]
+
     def __init__(
         self,
         function_name=None,
@@ -43,6 +66,12 @@ class EnvironmentVariables:
         shell_env_values=None,
         override_values=None,
         aws_creds=None,
+        timezone=None,
+        runtime=None,
+        buffered_output=False,
+        random_seed=None,
+        random_seed_env_var=None,
+        aws_endpoint_url=None,
     ):
         """
         Initializes this class. It takes in two sets of properties:
@@ -61,6 +90,19 @@ class EnvironmentVariables:
             from ``default_values`` and ``shell_env_values``.
         :param dict aws_creds: Optional. Dictionary containing AWS credentials passed to the Lambda runtime through
             environment variables. It should contain "key", "secret", "region" and optional "sessiontoken" keys
+        :param str timezone: Optional. Timezone of the function, like Europe/Paris. It is passed to the Lambda runtime
+            through the TZ environment variable, and takes precedence over any other value of TZ.
+        :param str runtime: Optional. Runtime of the function, used to disable the output buffering of the runtime.
+        :param bool buffered_output: Optional. Let the runtime buffer the output of the function, instead of passing
+            the variables that disable it. Defaults to False.
+        :param str random_seed: Optional. Seed for the random number generators of the function. It is passed to the
+            Lambda runtime through the random_seed_env_var environment variable, and takes precedence over any other
+            value of that variable. Handlers that seed their generators with it produce reproducible outputs.
+        :param str random_seed_env_var: Optional. Name of the variable to pass the random seed in. Defaults to
+            RANDOM_SEED.
+        :param str aws_endpoint_url: Optional. Endpoint of the AWS services, like the URL of LocalStack. It is passed
+            to the Lambda runtime through AWS_ENDPOINT_URL and the AWS_ENDPOINT_URL_<SERVICE> variables of the common
+            services, which the function can still override.
         """
 
         self._function = {
@@ -74,6 +116,12 @@ class EnvironmentVariables:
         self.shell_env_values = shell_env_values or {}
         self.override_values = override_values or {}
         self.aws_creds = aws_creds or {}
+        self.timezone = timezone
+        self.runtime = runtime
+        self.buffered_output = buffered_output
+        self.random_seed = random_seed
+        self.random_seed_env_var = random_seed_env_var or self._DEFAULT_RANDOM_SEED_ENV_VAR
+        self.aws_endpoint_url = aws_endpoint_url
 
     def resolve(self):
         """
@@ -87,6 +135,10 @@ class EnvironmentVariables:
         # AWS_* variables must always be passed to the function, but user has the choice to override them
         result = self._get_aws_variables()
 
+        # Variables disabling the output buffering of the runtime come next, the function can still override them
+        if not self.buffered_output:
+            result.update(self._get_unbuffered_output_variables())
+
         # Default value for the variable gets lowest priority
         for name, value in self.variables.items():
 
@@ -102,6 +154,12 @@ class EnvironmentVariables:
             # Runtime expects a Map<String, String> for environment variables
             result[name] = self._stringify_value(value)
 
+        if self.timezone:
+            result["TZ"] = self.timezone
+
+        if self.random_seed is not None:
+            result[self.random_seed_env_var] = self._stringify_value(self.random_seed)
+
         return result
 
     def add_lambda_event_body(self, value):
@@ -173,8 +231,27 @@ class EnvironmentVariables:
         if self.aws_creds.get("sessiontoken"):
             result["AWS_SESSION_TOKEN"] = self.aws_creds.get("sessiontoken")
 
+        if self.aws_endpoint_url:
+            result["AWS_ENDPOINT_URL"] = self.aws_endpoint_url
+            for service in self._ENDPOINT_URL_SERVICES:
+                result["AWS_ENDPOINT_URL_{}".format(service)] = self.aws_endpoint_url
+
         return result
 
+    def _get_unbuffered_output_variables(self):
+        """
+        Returns the environment variables that stop the runtime of the function from buffering its output, so the
+        logs of the function show up while it runs.
+
+        :return dict: Name and value of the environment variables, empty if the runtime doesn't need any
+        """
+
+        for runtime_prefix, variables in self._UNBUFFERED_OUTPUT_VARIABLES.items():
+            if self.runtime and self.runtime.startswith(runtime_prefix):
+                return dict(variables)
+
+        return {}
+
     def _stringify_value(self, value):
         """
         This method stringifies values of environment variables. If the value of the method is a list or dictionary,
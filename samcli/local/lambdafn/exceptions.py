@@ -9,7 +9,24 @@ class FunctionNotFound(Exception):
     """
 
 
+class UnsupportedCodeLocation(FunctionNotFound):
+    """
+    Raised when the requested Lambda function has its code located in S3, which can't be invoked locally
+    """
+
+
 class ResourceNotFound(Exception):
     """
     Raised when the requested resource is not found
     """
+
+
+class FunctionTimeoutException(Exception):
+    """
+    Raised when the Lambda function runs past its timeout, and is stopped before it responds
+    """
+
+    def __init__(self, function_name, timeout):
+        self.function_name = function_name
+        self.timeout = timeout
+        super().__init__("Task timed out after {:.2f} seconds".format(timeout))
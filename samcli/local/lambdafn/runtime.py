@@ -11,9 +11,11 @@ import threading
 from typing import Optional
 
 from samcli.local.docker.lambda_container import LambdaContainer
+from samcli.local.docker.container import SAM_LOCAL_FUNCTION_LABEL
 from samcli.lib.utils.file_observer import LambdaFunctionObserver
 from samcli.lib.utils.packagetype import ZIP
 from samcli.lib.telemetry.metric import capture_parameter
+from .exceptions import FunctionTimeoutException
 from .zip import unzip
 from ...lib.utils.stream_writer import StreamWriter
 
@@ -43,6 +45,13 @@ class LambdaRuntime:
         self._container_manager = container_manager
         self._image_builder = image_builder
         self._temp_uncompressed_paths_to_be_cleaned = []
+        # Archives decompressed by previous invocations, by path, with the modification time they were decompressed at
+        self._decompressed_archives = {}
+        self._decompressed_archives_lock = threading.Lock()
+        # Containers of the invocations that ran past the timeout of their function. The timers of the invocations add
+        # them from their own threads
+        self._timed_out_containers = set()
+        self._timed_out_containers_lock = threading.Lock()
 
     def create(self, function_config, debug_context=None, container_host=None, container_host_interface=None):
         """
@@ -82,6 +91,9 @@ class LambdaRuntime:
             debug_options=debug_context,
             container_host=container_host,
             container_host_interface=container_host_interface,
+            network_aliases=function_config.network_aliases,
+            labels={SAM_LOCAL_FUNCTION_LABEL: function_config.name},
+            entrypoint=function_config.entrypoint,
         )
         try:
             # create the container.
@@ -143,6 +155,7 @@ class LambdaRuntime:
         stderr: Optional[StreamWriter] = None,
         container_host=None,
         container_host_interface=None,
+        trace_id=None,
     ):
         """
         Invoke the given Lambda function locally.
@@ -165,6 +178,8 @@ class LambdaRuntime:
             Host of locally emulated Lambda container
         :param string container_host_interface: Optional.
             Interface that Docker host binds ports to
+        :param string trace_id: Optional.
+            X-Ray tracing header the function is invoked with
         :raises Keyboard
         """
         timer = None
@@ -186,7 +201,20 @@ class LambdaRuntime:
             # Block on waiting for result from the init process on the container, below method also
             # starts another thread to stream logs. This method will terminate
             # either successfully or be killed by one of the interrupt handlers above.
-            container.wait_for_result(name=function_config.name, event=event, stdout=stdout, stderr=stderr)
+            try:
+                container.wait_for_result(
+                    name=function_config.name, event=event, stdout=stdout, stderr=stderr, trace_id=trace_id
+                )
+            except Exception as ex:
+                # Stopping a container that timed out fails the wait for its result, report it as a timeout instead
+                if self._is_timed_out(container):
+                    self._report_timeout(function_config, stderr)
+                    raise FunctionTimeoutException(function_config.name, function_config.timeout) from ex
+                raise
+
+            if self._is_timed_out(container):
+                self._report_timeout(function_config, stderr)
+                raise FunctionTimeoutException(function_config.name, function_config.timeout)
 
         except KeyboardInterrupt:
             # When user presses Ctrl+C, we receive a Keyboard Interrupt. This is especially very common when
@@ -201,8 +229,45 @@ class LambdaRuntime:
             # If we are in debugging mode, timer would not be created. So skip cleanup of the timer
             if timer:
                 timer.cancel()
+            with self._timed_out_containers_lock:
+                self._timed_out_containers.discard(container)
             self._on_invoke_done(container)
 
+    def _set_timed_out(self, container):
+        """
+        Marks the container of an invocation as timed out, from the thread of the timer of the invocation
+        """
+        with self._timed_out_containers_lock:
+            self._timed_out_containers.add(container)
+
+    def _is_timed_out(self, container):
+        """
+        Returns whether the invocation running in the container timed out
+        """
+        with self._timed_out_containers_lock:
+            return container in self._timed_out_containers
+
+    @staticmethod
+    def _report_timeout(function_config, stderr):
+        """
+        Write the timeout message and REPORT line, like Lambda logs them for an invocation that times out
+
+        :param FunctionConfig function_config: Configuration of the function that timed out
+        :param samcli.lib.utils.stream_writer.StreamWriter stderr: Optional.
+            StreamWriter that receives the logs of the function
+        """
+        if not stderr:
+            return
+
+        report = "{}\nREPORT Function: {}\tDuration: {:.2f} ms\tMemory Size: {} MB\tStatus: timeout\n".format(
+            str(FunctionTimeoutException(function_config.name, function_config.timeout)),
+            function_config.name,
+            function_config.timeout * 1000,
+            function_config.memory,
+        )
+        stderr.write(report.encode("utf-8"))
+        stderr.flush()
+
     def _on_invoke_done(self, container):
         """
         Cleanup the created resources, just before the invoke function ends
@@ -214,7 +279,6 @@ class LambdaRuntime:
         """
         if container:
             self._container_manager.stop(container)
-        self._clean_decompressed_paths()
 
     def _configure_interrupt(self, function_name, timeout, container, is_debugging):
         """
@@ -232,6 +296,7 @@ class LambdaRuntime:
         def timer_handler():
             # NOTE: This handler runs in a separate thread. So don't try to mutate any non-thread-safe data structures
             LOG.info("Function '%s' timed out after %d seconds", function_name, timeout)
+            self._set_timed_out(container)
             self._container_manager.stop(container)
 
         def signal_handler(sig, frame):
@@ -256,7 +321,8 @@ class LambdaRuntime:
         be mounted directly inside the Docker container.
 
         This method handles a few different cases for ``code_path``:
-            - ``code_path``is a existent zip/jar file: Unzip in a temp directory and return the temp directory
+            - ``code_path``is a existent zip/jar file: Unzip in a temp directory and return the temp directory. The
+                temp directory is reused by the next invocations until the file changes
             - ``code_path`` is a existent directory: Return this immediately
             - ``code_path`` is a file/dir that does not exist: Return it as is. May be this method is not clever to
                 detect the existence of the path
@@ -274,21 +340,36 @@ class LambdaRuntime:
         """
 
         if code_path and os.path.isfile(code_path) and code_path.endswith(self.SUPPORTED_ARCHIVE_EXTENSIONS):
-            decompressed_dir = _unzip_file(code_path)
-            self._temp_uncompressed_paths_to_be_cleaned += [decompressed_dir]
-            return decompressed_dir
+            modification_time = os.path.getmtime(code_path)
+            with self._decompressed_archives_lock:
+                decompressed_modification_time, decompressed_dir = self._decompressed_archives.get(
+                    code_path, (None, None)
+                )
+                if decompressed_dir and decompressed_modification_time == modification_time:
+                    LOG.debug("Reusing %s decompressed in %s", code_path, decompressed_dir)
+                    return decompressed_dir
+
+                # The directory of a previous version of the archive may still be mounted by a warm container, so it
+                # is only removed with the others once the command is done
+                decompressed_dir = _unzip_file(code_path)
+                self._temp_uncompressed_paths_to_be_cleaned += [decompressed_dir]
+                self._decompressed_archives[code_path] = (modification_time, decompressed_dir)
+                return decompressed_dir
 
         LOG.debug("Code %s is not a zip/jar file", code_path)
         return code_path
 
-    def _clean_decompressed_paths(self):
+    def clean_decompressed_paths(self):
         """
-        Clean the temporary decompressed code dirs
+        Clean the temporary decompressed code dirs. They are reused across invocations, so this must only be called
+        once no more functions are invoked
         """
         LOG.debug("Cleaning all decompressed code dirs")
-        for decompressed_dir in self._temp_uncompressed_paths_to_be_cleaned:
-            shutil.rmtree(decompressed_dir)
-        self._temp_uncompressed_paths_to_be_cleaned = []
+        with self._decompressed_archives_lock:
+            for decompressed_dir in self._temp_uncompressed_paths_to_be_cleaned:
+                shutil.rmtree(decompressed_dir)
+            self._temp_uncompressed_paths_to_be_cleaned = []
+            self._decompressed_archives = {}
 
 
 class WarmLambdaRuntime(LambdaRuntime):
@@ -363,6 +444,34 @@ class WarmLambdaRuntime(LambdaRuntime):
 
         return container
 
+    def run(self, container, function_config, debug_context, container_host=None, container_host_interface=None):
+        """
+        Run the container of the passed function like the LambdaRuntime does, then wait until it is ready to serve
+        invokes. Warm containers may be started right before a request is routed to them, for example in eager
+        mode, so the first invoke must not race the startup of the container.
+
+        Parameters
+        ----------
+        container Container
+            the created container to be run
+        function_config FunctionConfig
+            Configuration of the function to run its created container.
+        debug_context DebugContext
+            Debugging context for the function (includes port, args, and path)
+        container_host string
+            Host of locally emulated Lambda container
+        container_host_interface string
+            Optional. Interface that Docker host binds ports to
+
+        Returns
+        -------
+        Container
+            the running container
+        """
+        container = super().run(container, function_config, debug_context, container_host, container_host_interface)
+        container.wait_until_ready()
+        return container
+
     def _on_invoke_done(self, container):
         """
         Cleanup the created resources, just before the invoke function ends.
@@ -401,6 +510,12 @@ class WarmLambdaRuntime(LambdaRuntime):
         def timer_handler():
             # NOTE: This handler runs in a separate thread. So don't try to mutate any non-thread-safe data structures
             LOG.info("Function '%s' timed out after %d seconds", function_name, timeout)
+            self._set_timed_out(container)
+            # The function may still be running in the container, so it can't serve the next invokes. It is stopped
+            # like the containers of changed functions, and a new one is created for the next invoke
+            self._container_manager.stop(container)
+            if self._containers.get(function_name) is container:
+                self._containers.pop(function_name, None)
 
         def signal_handler(sig, frame):
             # NOTE: This handler runs in a separate thread. So don't try to mutate any non-thread-safe data structures
@@ -425,7 +540,7 @@ class WarmLambdaRuntime(LambdaRuntime):
         for function_name, container in self._containers.items():
             LOG.debug("Terminate running warm container for Lambda Function '%s'", function_name)
             self._container_manager.stop(container)
-        self._clean_decompressed_paths()
+        self.clean_decompressed_paths()
         self._observer.stop()
 
     def _on_code_change(self, functions):
@@ -5,9 +5,12 @@ import logging
 import os
 
 from flask import Response
+from werkzeug.serving import make_server
 
 LOG = logging.getLogger(__name__)
 
+HEALTH_CHECK_PATH = "/_sam/health"
+
 
 class BaseLocalService:
     def __init__(self, is_debugging, port, host):
@@ -27,6 +30,7 @@ class BaseLocalService:
         self.port = port
         self.host = host
         self._app = None
+        self._server = None
 
     def create(self):
         """
@@ -34,9 +38,26 @@ class BaseLocalService:
         """
         raise NotImplementedError("Required method to implement")
 
+    def bind(self):
+        """
+        Binds the server to the host and port of the service before running it. When the port is 0, the OS assigns a
+        free port to the server, which becomes the port of the service.
+
+        Raises
+        ------
+        RuntimeError
+            if the service was not created
+        """
+        if not self._app:
+            raise RuntimeError("The application must be created before binding it")
+
+        # Single threaded when debugging, for the same reasons as in run()
+        self._server = make_server(self.host, self.port, self._app, threaded=not self.is_debugging)
+        self.port = self._server.server_port
+
     def run(self):
         """
-        This starts up the (threaded) Local Server.
+        This starts up the (threaded) Local Server. If the server was bound with bind(), it serves on that socket.
         Note: This is a **blocking call**
 
         Raises
@@ -60,8 +81,34 @@ class BaseLocalService:
         # our cli and not on a production server.
         os.environ["WERKZEUG_RUN_MAIN"] = "true"
 
+        if self._server:
+            self._server.serve_forever()
+            return
+
         self._app.run(threaded=multi_threaded, host=self.host, port=self.port)
 
+    def _add_health_check_route(self):
+        """
+        Adds the health check route, that orchestrators and test harnesses poll to know when the service is ready
+        to serve requests. Services add it before their own routes, so that it takes precedence over them
+        """
+        self._app.add_url_rule(
+            HEALTH_CHECK_PATH,
+            endpoint="_sam_health",
+            view_func=self._health_check_handler,
+            methods=["GET"],
+            provide_automatic_options=False,
+        )
+
+    def _health_check_handler(self):
+        """
+        Responds 200. The service only responds once it serves requests, which is after the connectivity to Docker
+        was verified
+
+        :return: Flask Response
+        """
+        return self.service_response(json.dumps({"status": "ok"}), {"Content-Type": "application/json"}, 200)
+
     @staticmethod
     def service_response(body, headers, status_code):
         """
@@ -0,0 +1,23 @@
+"""
+Custom exceptions used by the local execution of Step Functions state machines
+"""
+
+
+class StateMachineDefinitionError(Exception):
+    """
+    Raised when the definition of a state machine is invalid, or uses features that can't be executed locally
+    """
+
+
+class StateMachineExecutionFailed(Exception):
+    """
+    Raised when the execution of a state machine fails, from a Fail state or from an error no Catch handles
+    """
+
+    def __init__(self, error, cause=None):
+        self.error = error
+        self.cause = cause
+        message = "Execution failed with error {}".format(error)
+        if cause:
+            message += ": {}".format(cause)
+        super().__init__(message)
@@ -0,0 +1,478 @@
+"""
+Executes Step Functions state machines locally, invoking the local Lambda functions of their Task states
+"""
+
+import copy
+import io
+import json
+import logging
+import operator
+import re
+from datetime import datetime, timedelta, timezone
+from typing import Any, Callable, Dict, List, Optional, Tuple
+
+from samcli.lib.utils.stream_writer import StreamWriter
+from samcli.local.lambdafn.exceptions import FunctionTimeoutException
+from samcli.local.services.base_local_service import LambdaOutputParser
+from samcli.local.stepfunctions.exceptions import StateMachineDefinitionError, StateMachineExecutionFailed
+from samcli.local.stepfunctions.state_machine import StateMachine
+
+LOG = logging.getLogger(__name__)
+
+# Resource of the Task states invoking a Lambda function through the optimized integration
+LAMBDA_INVOKE_RESOURCE = "arn:aws:states:::lambda:invoke"
+
+# ARN of a Lambda function, optionally with a version or alias, capturing the name of the function
+LAMBDA_FUNCTION_ARN_PATTERN = re.compile(r"^arn:[^:]+:lambda:[^:]*:[^:]*:function:([^:]+)(?::[^:]+)?$")
+
+# Steps of the paths of the Amazon States Language, like .name, [0] or ['name']
+PATH_STEP_PATTERN = re.compile(r"\.([^.\[\]]+)|\[(\d+)\]|\['([^']*)'\]")
+
+# Timestamps of the Choice rules, in the RFC3339 format like 2016-03-14T01:59:00Z
+TIMESTAMP_PATTERN = re.compile(
+    r"^(\d{4})-(\d{2})-(\d{2})[Tt](\d{2}):(\d{2}):(\d{2})(?:\.(\d+))?([Zz]|[+-](\d{2}):(\d{2}))$"
+)
+
+# Errors matching every error, or every error but a timeout, in the ErrorEquals of Retry and Catch
+ERROR_ALL = "States.ALL"
+ERROR_TASK_FAILED = "States.TaskFailed"
+ERROR_TIMEOUT = "States.Timeout"
+ERROR_RUNTIME = "States.Runtime"
+ERROR_NO_CHOICE_MATCHED = "States.NoChoiceMatched"
+
+# Number of times a Retry retries a state unless it sets MaxAttempts
+DEFAULT_MAX_ATTEMPTS = 3
+
+# Number of states an execution may enter before it is stopped, like the 25,000 events of the execution history of
+# Step Functions. Wait states do not wait locally, so a polling loop would otherwise spin forever.
+MAX_STATE_TRANSITIONS = 25000
+
+
+def _is_number(value: Any) -> bool:
+    return isinstance(value, (int, float)) and not isinstance(value, bool)
+
+
+def _parse_timestamp(value: Any) -> Optional[datetime]:
+    match = TIMESTAMP_PATTERN.match(value) if isinstance(value, str) else None
+    if not match:
+        return None
+
+    year, month, day, hour, minute, second, fraction, offset, offset_hours, offset_minutes = match.groups()
+    tzinfo = timezone.utc
+    if offset not in ("Z", "z"):
+        delta = timedelta(hours=int(offset_hours), minutes=int(offset_minutes))
+        tzinfo = timezone(delta if offset.startswith("+") else -delta)
+    try:
+        return datetime(
+            int(year),
+            int(month),
+            int(day),
+            int(hour),
+            int(minute),
+            int(second),
+            int((fraction or "0")[:6].ljust(6, "0")),
+            tzinfo=tzinfo,
+        )
+    except ValueError:
+        return None
+
+
+def _matches_wildcard(value: str, pattern: str) -> bool:
+    """
+    Matches a string against the pattern of StringMatches, where * matches any characters and \\* a literal *
+    """
+    regex = ""
+    position = 0
+    while position < len(pattern):
+        if pattern[position] == "\\" and position + 1 < len(pattern):
+            regex += re.escape(pattern[position + 1])
+            position += 2
+            continue
+        regex += ".*" if pattern[position] == "*" else re.escape(pattern[position])
+        position += 1
+    return re.fullmatch(regex, value, re.DOTALL) is not None
+
+
+# Comparison operators of the Choice rules, with the type check of the compared values and the comparison itself
+COMPARISONS: Dict[str, Tuple[Callable[[Any], Any], Callable[[Any, Any], bool]]] = {}
+for _prefix, _convert in [
+    ("String", lambda value: value if isinstance(value, str) else None),
+    ("Numeric", lambda value: value if _is_number(value) else None),
+    ("Timestamp", _parse_timestamp),
+]:
+    for _suffix, _operator in [
+        ("Equals", operator.eq),
+        ("LessThan", operator.lt),
+        ("GreaterThan", operator.gt),
+        ("LessThanEquals", operator.le),
+        ("GreaterThanEquals", operator.ge),
+    ]:
+        COMPARISONS[_prefix + _suffix] = (_convert, _operator)
+COMPARISONS["BooleanEquals"] = (lambda value: value if isinstance(value, bool) else None, operator.eq)
+COMPARISONS["StringMatches"] = (lambda value: value if isinstance(value, str) else None, _matches_wildcard)
+
+# Type tests of the Choice rules
+TYPE_TESTS: Dict[str, Callable[[Any], bool]] = {
+    "IsNull": lambda value: value is None,
+    "IsString": lambda value: isinstance(value, str),
+    "IsNumeric": _is_number,
+    "IsBoolean": lambda value: isinstance(value, bool),
+    "IsTimestamp": lambda value: _parse_timestamp(value) is not None,
+}
+
+
+class _PathNotFound(Exception):
+    """
+    Raised when a path does not match anything in the data it is applied to
+    """
+
+
+class LocalStateMachineExecutor:
+    """
+    Executes a state machine locally, like a Standard workflow of Step Functions. The Task states invoke the functions
+    of the template with the local Lambda runner, and the output of every state is passed on as the input of the next.
+
+    Task, Pass, Choice, Wait, Succeed and Fail states are supported, along with the InputPath, Parameters,
+    ResultSelector, ResultPath and OutputPath fields, Retry and Catch. Wait states and the intervals of Retry don't
+    wait. Parallel and Map states, intrinsic functions and service integrations other than Lambda are not supported.
+    """
+
+    def __init__(self, local_lambda_runner, stderr: Optional[StreamWriter] = None):
+        """
+        Creates a LocalStateMachineExecutor
+
+        :param samcli.commands.local.lib.local_lambda.LocalLambdaRunner local_lambda_runner: Runner invoking the
+            functions of the Task states
+        :param samcli.lib.utils.stream_writer.StreamWriter stderr: Optional. Stream to write the logs of the
+            functions to
+        """
+        self._local_lambda_runner = local_lambda_runner
+        self._stderr = stderr
+
+    def execute(self, state_machine: StateMachine, execution_input: Any) -> Any:
+        """
+        Executes the state machine from its StartAt state until a state ends the execution
+
+        :param StateMachine state_machine: State machine to execute
+        :param execution_input: Input of the execution, already parsed from JSON
+        :return: Output of the execution
+        :raises StateMachineExecutionFailed: If the execution fails, from a Fail state or an error no Catch handles
+        :raises StateMachineDefinitionError: If the definition is invalid, or uses features that are not supported
+        """
+        definition = state_machine.definition
+        states = definition.get("States") or {}
+        state_name = definition.get("StartAt")
+
+        start_time = self._now()
+        context = {
+            "Execution": {"Input": execution_input, "Name": "local", "StartTime": start_time},
+            "StateMachine": {"Name": state_machine.name},
+        }
+
+        data = execution_input
+        transitions = 0
+        while True:
+            transitions += 1
+            if transitions > MAX_STATE_TRANSITIONS:
+                raise StateMachineExecutionFailed(
+                    ERROR_RUNTIME,
+                    "Execution exceeded the maximum of {} state transitions before entering state {}. Wait states "
+                    "do not wait when running locally, check whether the state machine loops forever".format(
+                        MAX_STATE_TRANSITIONS, state_name
+                    ),
+                )
+
+            state = states.get(state_name)
+            if not isinstance(state, dict):
+                raise StateMachineDefinitionError(
+                    "State {} of state machine {} does not exist".format(state_name, state_machine.name)
+                )
+
+            LOG.info("Entering state %s (%s)", state_name, state.get("Type"))
+            context["State"] = {"Name": state_name, "EnteredTime": self._now()}
+            data, next_state = self._run_state(state_name, state, data, context)
+            if next_state is None:
+                return data
+            state_name = next_state
+
+    def _run_state(self, name: str, state: Dict, data: Any, context: Dict) -> Tuple[Any, Optional[str]]:
+        """
+        Runs a state
+
+        :return tuple: Output of the state, and the name of the next state or None if the state ends the execution
+        """
+        state_type = state.get("Type")
+
+        if state_type == "Fail":
+            raise StateMachineExecutionFailed(state.get("Error", "States.Fail"), state.get("Cause"))
+
+        effective_input = self._get_input_path(state, data)
+
+        if state_type == "Choice":
+            return self._get_output_path(state, effective_input), self._choose_next_state(name, state, effective_input)
+
+        if state_type in ("Wait", "Succeed"):
+            if state_type == "Wait":
+                LOG.info("Not waiting in state %s, Wait states are skipped locally", name)
+            return self._get_output_path(state, effective_input), self._get_next_state(state)
+
+        if state_type == "Pass":
+            result = state["Result"] if "Result" in state else self._get_parameters(state, effective_input, context)
+        elif state_type == "Task":
+            try:
+                result = self._run_task(
+                    name, state, self._get_parameters(state, effective_input, context), effective_input
+                )
+            except StateMachineExecutionFailed as ex:
+                catcher = self._find_error_handler(state.get("Catch"), ex.error)
+                if catcher is None:
+                    raise
+                LOG.info("Error %s of state %s is caught, going to state %s", ex.error, name, catcher.get("Next"))
+                error_output = {"Error": ex.error, "Cause": ex.cause}
+                return self._set_result_path(data, catcher.get("ResultPath", "$"), error_output), catcher.get("Next")
+        else:
+            raise StateMachineDefinitionError("{} state {} can't be executed locally".format(state_type, name))
+
+        if "ResultSelector" in state:
+            result = self._resolve_parameters(state["ResultSelector"], result, context)
+
+        output = self._set_result_path(data, state.get("ResultPath", "$"), result)
+        return self._get_output_path(state, output), self._get_next_state(state)
+
+    def _run_task(self, name: str, state: Dict, task_input: Any, effective_input: Any) -> Any:
+        """
+        Invokes the function of a Task state, retrying it as configured by its Retry field. Like Step Functions, the
+        lambda:invoke integration sends the effective input of the state to the function when there is no Payload
+        """
+        resource = state.get("Resource")
+        if resource == LAMBDA_INVOKE_RESOURCE:
+            if not isinstance(task_input, dict) or not task_input.get("FunctionName"):
+                raise StateMachineDefinitionError("Parameters of state {} must have a FunctionName".format(name))
+            function_name = self._get_function_name(task_input["FunctionName"])
+            payload = task_input["Payload"] if "Payload" in task_input else effective_input
+        else:
+            match = LAMBDA_FUNCTION_ARN_PATTERN.match(resource) if isinstance(resource, str) else None
+            if not match:
+                raise StateMachineDefinitionError(
+                    "Resource {} of state {} can't be executed locally, only Lambda functions are supported".format(
+                        resource, name
+                    )
+                )
+            function_name = match.group(1)
+            payload = task_input
+
+        attempts: Dict[int, int] = {}
+        while True:
+            try:
+                response = self._invoke_function(function_name, payload)
+                break
+            except StateMachineExecutionFailed as ex:
+                retriers = state.get("Retry") or []
+                retrier = self._find_error_handler(retriers, ex.error)
+                if retrier is None:
+                    raise
+                index = retriers.index(retrier)
+                attempts[index] = attempts.get(index, 0) + 1
+                if attempts[index] > retrier.get("MaxAttempts", DEFAULT_MAX_ATTEMPTS):
+                    raise
+                LOG.info("Retrying state %s after error %s (attempt %d)", name, ex.error, attempts[index])
+
+        if resource == LAMBDA_INVOKE_RESOURCE:
+            return {"ExecutedVersion": "$LATEST", "Payload": response, "StatusCode": 200}
+        return response
+
+    def _invoke_function(self, function_name: str, payload: Any) -> Any:
+        """
+        Invokes a function locally
+
+        :return: Response of the function
+        :raises StateMachineExecutionFailed: If the function fails or times out
+        """
+        stdout_stream = io.BytesIO()
+        try:
+            self._local_lambda_runner.invoke(
+                function_name, event=json.dumps(payload), stdout=StreamWriter(stdout_stream), stderr=self._stderr
+            )
+        except FunctionTimeoutException as ex:
+            raise StateMachineExecutionFailed(ERROR_TIMEOUT, "Function {} timed out".format(function_name)) from ex
+        response, _, is_error = LambdaOutputParser.get_lambda_output(stdout_stream)
+
+        if not response:
+            raise StateMachineExecutionFailed(ERROR_TIMEOUT, "Function {} timed out".format(function_name))
+        if is_error:
+            raise StateMachineExecutionFailed(json.loads(response).get("errorType"), response)
+
+        try:
+            return json.loads(response)
+        except ValueError:
+            return response
+
+    def _choose_next_state(self, name: str, state: Dict, data: Any) -> str:
+        for choice in state.get("Choices") or []:
+            if self._evaluate_rule(choice, data):
+                return choice["Next"]
+
+        if "Default" in state:
+            return state["Default"]
+        raise StateMachineExecutionFailed(ERROR_NO_CHOICE_MATCHED, "No choice of state {} matched".format(name))
+
+    def _evaluate_rule(self, rule: Dict, data: Any) -> bool:
+        """
+        Evaluates a rule of a Choice state against the input of the state
+        """
+        if "And" in rule:
+            return all(self._evaluate_rule(sub_rule, data) for sub_rule in rule["And"])
+        if "Or" in rule:
+            return any(self._evaluate_rule(sub_rule, data) for sub_rule in rule["Or"])
+        if "Not" in rule:
+            return not self._evaluate_rule(rule["Not"], data)
+
+        variable = rule.get("Variable")
+        if "IsPresent" in rule:
+            try:
+                self._get_path(data, variable)
+                return rule["IsPresent"] is True
+            except _PathNotFound:
+                return rule["IsPresent"] is False
+
+        value = self._get_path_or_fail(data, variable)
+        for test, matches in TYPE_TESTS.items():
+            if test in rule:
+                return matches(value) == rule[test]
+
+        for comparison, (convert, compare) in COMPARISONS.items():
+            if comparison in rule:
+                expected = rule[comparison]
+            elif comparison + "Path" in rule:
+                expected = self._get_path_or_fail(data, rule[comparison + "Path"])
+            else:
+                continue
+            value, expected = convert(value), convert(expected)
+            return value is not None and expected is not None and compare(value, expected)
+
+        raise StateMachineDefinitionError("Choice rule {} is not supported locally".format(json.dumps(rule)))
+
+    def _get_input_path(self, state: Dict, data: Any) -> Any:
+        input_path = state.get("InputPath", "$")
+        return {} if input_path is None else self._get_path_or_fail(data, input_path)
+
+    def _get_output_path(self, state: Dict, data: Any) -> Any:
+        output_path = state.get("OutputPath", "$")
+        return {} if output_path is None else self._get_path_or_fail(data, output_path)
+
+    def _get_parameters(self, state: Dict, data: Any, context: Dict) -> Any:
+        if "Parameters" not in state:
+            return data
+        return self._resolve_parameters(state["Parameters"], data, context)
+
+    def _resolve_parameters(self, template: Any, data: Any, context: Dict) -> Any:
+        """
+        Builds the Parameters or the ResultSelector of a state, replacing the values of the keys ending with .$ by
+        the values their path selects in the data, or in the context object for paths starting with $$
+        """
+        if isinstance(template, list):
+            return [self._resolve_parameters(item, data, context) for item in template]
+        if not isinstance(template, dict):
+            return template
+
+        resolved = {}
+        for key, value in template.items():
+            if not key.endswith(".$"):
+                resolved[key] = self._resolve_parameters(value, data, context)
+                continue
+
+            if not isinstance(value, str) or not value.startswith("$"):
+                raise StateMachineDefinitionError(
+                    "Value {} of {} is not supported locally, intrinsic functions can't be executed".format(value, key)
+                )
+            if value.startswith("$$"):
+                resolved[key[:-2]] = self._get_path_or_fail(context, value[1:])
+            else:
+                resolved[key[:-2]] = self._get_path_or_fail(data, value)
+
+        return resolved
+
+    def _set_result_path(self, data: Any, result_path: Optional[str], result: Any) -> Any:
+        """
+        Combines the input of a state with its result, as selected by the ResultPath of the state
+        """
+        if result_path is None:
+            return data
+        steps = self._parse_path(result_path)
+        if not steps:
+            return result
+
+        output = copy.deepcopy(data) if isinstance(data, dict) else {}
+        parent = output
+        for step in steps[:-1]:
+            if not isinstance(parent.get(step), dict):
+                parent[step] = {}
+            parent = parent[step]
+        parent[steps[-1]] = result
+        return output
+
+    def _get_path_or_fail(self, data: Any, path: str) -> Any:
+        try:
+            return self._get_path(data, path)
+        except _PathNotFound as ex:
+            raise StateMachineExecutionFailed(
+                ERROR_RUNTIME, "Path {} does not match any value of the input {}".format(path, json.dumps(data))
+            ) from ex
+
+    def _get_path(self, data: Any, path: str) -> Any:
+        value = data
+        for step in self._parse_path(path):
+            if isinstance(step, int) and isinstance(value, list) and step < len(value):
+                value = value[step]
+            elif isinstance(step, str) and isinstance(value, dict) and step in value:
+                value = value[step]
+            else:
+                raise _PathNotFound(path)
+        return value
+
+    @staticmethod
+    def _parse_path(path: Any) -> List:
+        """
+        Splits a path of the Amazon States Language, like $.order.items[0], in its keys and indexes
+        """
+        if not isinstance(path, str) or not path.startswith("$"):
+            raise StateMachineDefinitionError("Path {} must start with $".format(path))
+
+        steps: List = []
+        position = 1
+        while position < len(path):
+            match = PATH_STEP_PATTERN.match(path, position)
+            if not match:
+                raise StateMachineDefinitionError("Path {} is not supported locally".format(path))
+            name, index, quoted_name = match.groups()
+            steps.append(int(index) if index is not None else (name if name is not None else quoted_name))
+            position = match.end()
+        return steps
+
+    @staticmethod
+    def _find_error_handler(handlers: Optional[List[Dict]], error: str) -> Optional[Dict]:
+        """
+        Finds the first Retry or Catch whose ErrorEquals matches the error
+        """
+        for handler in handlers or []:
+            error_equals = handler.get("ErrorEquals") or []
+            if (
+                error in error_equals
+                or ERROR_ALL in error_equals
+                or (ERROR_TASK_FAILED in error_equals and error != ERROR_TIMEOUT)
+            ):
+                return handler
+        return None
+
+    @staticmethod
+    def _get_function_name(function_name: str) -> str:
+        match = LAMBDA_FUNCTION_ARN_PATTERN.match(function_name)
+        return match.group(1) if match else function_name
+
+    @staticmethod
+    def _get_next_state(state: Dict) -> Optional[str]:
+        return None if state.get("End") or state.get("Type") == "Succeed" else state.get("Next")
+
+    @staticmethod
+    def _now() -> str:
+        return datetime.now(timezone.utc).strftime("%Y-%m-%dT%H:%M:%S.%fZ")
@@ -0,0 +1,108 @@
+"""
+Reads the Step Functions state machines of a template, to execute them locally
+"""
+
+import json
+import logging
+import os
+import re
+from typing import Dict, List, NamedTuple, Optional
+
+from samcli.commands._utils.resources import AWS_SERVERLESS_STATEMACHINE, AWS_STEPFUNCTIONS_STATEMACHINE
+from samcli.lib.providers.provider import Stack, get_full_path
+from samcli.local.stepfunctions.exceptions import StateMachineDefinitionError
+from samcli.yamlhelper import yaml_parse
+
+LOG = logging.getLogger(__name__)
+
+# Placeholders of the DefinitionSubstitutions in the definition, like ${HelloFunctionArn}
+SUBSTITUTION_PATTERN = re.compile(r"\$\{([A-Za-z0-9_]+)\}")
+
+
+class StateMachine(NamedTuple):
+    """
+    A Step Functions state machine of the template
+    """
+
+    # Logical id of the state machine
+    name: str
+    # Definition of the state machine in the Amazon States Language, with its DefinitionSubstitutions substituted
+    definition: Dict
+
+
+def get_state_machine(stacks: List[Stack], identifier: str) -> Optional[StateMachine]:
+    """
+    Finds a state machine of the template by its logical id. If it is in a nested stack, the logical id can be prefixed
+    with the stack path, like "ChildStack/OrderStateMachine".
+
+    :param list(Stack) stacks: Stacks of the template
+    :param str identifier: Logical id of the state machine
+    :return StateMachine: State machine with its definition, or None if there is no state machine with this logical id
+    :raises StateMachineDefinitionError: If the definition of the state machine can't be read
+    """
+    for stack in stacks:
+        for logical_id, resource in stack.resources.items():
+            if not isinstance(resource, dict) or identifier not in (
+                logical_id,
+                get_full_path(stack.stack_path, logical_id),
+            ):
+                continue
+
+            resource_type = resource.get("Type")
+            if resource_type not in (AWS_SERVERLESS_STATEMACHINE, AWS_STEPFUNCTIONS_STATEMACHINE):
+                continue
+
+            properties = resource.get("Properties") or {}
+            definition = _get_definition(logical_id, properties, os.path.dirname(stack.location))
+            substitutions = properties.get("DefinitionSubstitutions") or {}
+            return StateMachine(name=logical_id, definition=_substitute(definition, substitutions))
+
+    return None
+
+
+def _get_definition(logical_id: str, properties: Dict, template_dir: str) -> Dict:
+    """
+    Reads the definition of a state machine from its Definition, DefinitionString or local DefinitionUri property
+    """
+    definition = properties.get("Definition")
+    if definition is None and isinstance(properties.get("DefinitionString"), str):
+        try:
+            definition = json.loads(properties["DefinitionString"])
+        except ValueError as ex:
+            raise StateMachineDefinitionError(
+                "DefinitionString of state machine {} is not valid JSON: {}".format(logical_id, ex)
+            ) from ex
+
+    definition_uri = properties.get("DefinitionUri")
+    if definition is None and isinstance(definition_uri, str) and not definition_uri.startswith("s3://"):
+        path = os.path.join(template_dir, definition_uri)
+        LOG.debug("Reading the definition of state machine %s from %s", logical_id, path)
+        try:
+            with open(path, "r", encoding="utf-8") as definition_file:
+                definition = yaml_parse(definition_file.read())
+        except OSError as ex:
+            raise StateMachineDefinitionError(
+                "Definition of state machine {} can't be read from {}: {}".format(logical_id, path, ex)
+            ) from ex
+
+    if not isinstance(definition, dict) or not isinstance(definition.get("States"), dict):
+        raise StateMachineDefinitionError(
+            "State machine {} must have an inline Definition or a local DefinitionUri to be executed locally".format(
+                logical_id
+            )
+        )
+
+    return definition
+
+
+def _substitute(value, substitutions: Dict):
+    """
+    Replaces the ${Name} placeholders of the definition with the values of the DefinitionSubstitutions
+    """
+    if isinstance(value, dict):
+        return {key: _substitute(item, substitutions) for key, item in value.items()}
+    if isinstance(value, list):
+        return [_substitute(item, substitutions) for item in value]
+    if isinstance(value, str):
+        return SUBSTITUTION_PATTERN.sub(lambda match: str(substitutions.get(match.group(1), match.group(0))), value)
+    return value
@@ -18,7 +18,7 @@ https://github.com/aws/aws-cli/blob/develop/awscli/customizations/cloudformation
 # pylint: disable=too-many-ancestors
 
 import json
-from typing import Dict, Optional
+from typing import Dict, List, Optional, Tuple
 from botocore.compat import OrderedDict
 import yaml
 
@@ -122,6 +122,36 @@ def yaml_parse(yamlstr):
         return yaml.safe_load(yamlstr)
 
 
+def get_node_position(node: Optional[yaml.Node], path: List) -> Optional[Tuple[int, int]]:
+    """
+    Finds the line and column, starting at 1, of the node at the given path of a composed yaml document
+
+    Parameters
+    ----------
+    node yaml.Node
+        Root node of the document, as returned by yaml.compose
+    path list
+        Keys and indices leading to the node
+
+    Returns
+    -------
+    tuple(int, int)
+        Line and column of the node, or None if it cannot be found
+    """
+    for key in path:
+        if isinstance(node, yaml.MappingNode):
+            node = next((value for name, value in node.value if name.value == key), None)
+        elif isinstance(node, yaml.SequenceNode) and isinstance(key, int) and key < len(node.value):
+            node = node.value[key]
+        else:
+            node = None
+
+    if node is None:
+        return None
+
+    return node.start_mark.line + 1, node.start_mark.column + 1
+
+
 def parse_yaml_file(file_path, extra_context: Optional[Dict] = None):
     """
     Read the file, do variable substitution, parse it as JSON/YAML
@@ -9,6 +9,40 @@ class Test_EventGeneration_Integ(TestCase):
         process.communicate()
         self.assertEqual(process.returncode, 0)
 
+    def test_generate_alb_event_substitution(self):
+        process = Popen(
+            [
+                Test_EventGeneration_Integ._get_command(),
+                "local",
+                "generate-event",
+                "alb",
+                "request",
+                "--method",
+                "POST",
+                "--path",
+                "orders",
+            ]
+        )
+        process.communicate()
+        self.assertEqual(process.returncode, 0)
+
+    def test_generate_cloudfront_viewer_request_event_substitution(self):
+        process = Popen(
+            [
+                Test_EventGeneration_Integ._get_command(),
+                "local",
+                "generate-event",
+                "cloudfront",
+                "viewer-request",
+                "--uri",
+                "/images/logo.png",
+                "--distribution-id",
+                "E2EXAMPLE",
+            ]
+        )
+        process.communicate()
+        self.assertEqual(process.returncode, 0)
+
     @staticmethod
     def _get_command():
         command = "sam"
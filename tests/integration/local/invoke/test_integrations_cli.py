@@ -280,7 +280,7 @@ class TestSamPython36HelloWorldIntegration(InvokeIntegBase):
             "arn:aws:cloudformation:us-east-1:123456789012:stack/" "local/51af3dc0-da77-11e4-872e-1234567db123",
         )
 
-        self.assertEqual(environ["URLSuffix"], "localhost")
+        self.assertEqual(environ["URLSuffix"], "amazonaws.com")
         self.assertEqual(environ["Timeout"], "100")
         self.assertEqual(environ["MyRuntimeVersion"], "v0")
         self.assertEqual(environ["EmptyDefaultParameter"], "")
@@ -305,6 +305,26 @@ class TestSamPython36HelloWorldIntegration(InvokeIntegBase):
 
         self.assertEqual(environ["Region"], custom_region)
 
+    @pytest.mark.flaky(reruns=3)
+    def test_invoke_with_env_using_parameters_with_china_region(self):
+        command_list = self.get_command_list(
+            "EchoEnvWithParameters", template_path=self.template_path, event_path=self.event_path, region="cn-north-1"
+        )
+
+        process = Popen(command_list, stdout=PIPE)
+        try:
+            stdout, _ = process.communicate(timeout=TIMEOUT)
+        except TimeoutExpired:
+            process.kill()
+            raise
+
+        process_stdout = stdout.strip()
+        environ = json.loads(process_stdout.decode("utf-8"))
+
+        self.assertEqual(environ["Region"], "cn-north-1")
+        self.assertEqual(environ["Partition"], "aws-cn")
+        self.assertEqual(environ["URLSuffix"], "amazonaws.com.cn")
+
     @pytest.mark.flaky(reruns=3)
     def test_invoke_with_env_with_aws_creds(self):
         custom_region = "my-custom-region"
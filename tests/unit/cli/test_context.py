@@ -62,6 +62,24 @@ class TestContext(TestCase):
         ctx.debug = False
         self.assertEqual(ctx.debug, False, "debug must be set to False")
 
+    @patch("samcli.cli.context.Colored")
+    def test_must_set_get_no_color_flag(self, colored_mock):
+        ctx = Context()
+        self.assertEqual(ctx.no_color, False, "no_color must default to False")
+
+        ctx.no_color = True
+
+        self.assertEqual(ctx.no_color, True, "no_color must be set to True")
+        colored_mock.disable_colors.assert_called_once_with()
+
+    @patch("samcli.cli.context.Colored")
+    def test_must_not_disable_colors_without_no_color_flag(self, colored_mock):
+        ctx = Context()
+
+        ctx.no_color = False
+
+        colored_mock.disable_colors.assert_not_called()
+
     def test_must_set_aws_region_in_boto_session(self):
         region = "myregion"
         ctx = Context()
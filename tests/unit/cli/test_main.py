@@ -33,6 +33,16 @@ class TestCliBase(TestCase):
             result = runner.invoke(cli, ["local", "generate-event", "s3", "put", "--debug"])
             self.assertEqual(result.exit_code, 0)
 
+    @patch("samcli.cli.context.Colored")
+    def test_cli_with_no_color(self, colored_mock):
+        mock_cfg = Mock()
+        with patch("samcli.cli.main.global_cfg", mock_cfg):
+            runner = CliRunner()
+            result = runner.invoke(cli, ["local", "generate-event", "s3", "put", "--no-color"])
+            self.assertEqual(result.exit_code, 0)
+            self.assertNotIn("\x1b[", result.output)
+            colored_mock.disable_colors.assert_called_once_with()
+
     @patch("samcli.cli.main.send_installed_metric")
     def test_cli_enable_telemetry_with_prompt(self, send_installed_metric_mock):
         with patch("samcli.cli.global_config.GlobalConfig.telemetry_enabled", new_callable=PropertyMock) as mock_flag:
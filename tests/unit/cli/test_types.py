@@ -10,6 +10,12 @@ from samcli.cli.types import (
     SigningProfilesOptionType,
     ImageRepositoryType,
     ImageRepositoriesType,
+    DebugPortType,
+    HostEntryType,
+    ContainerLabelType,
+    EntrypointType,
+    RuntimeImageType,
+    JsonObjectType,
 )
 from samcli.cli.types import CfnMetadataType
 
@@ -377,3 +383,189 @@ class TestImageRepositoriesType(TestCase):
     def test_successful_parsing(self, input, expected):
         result = self.param_type.convert(input, self.mock_param, Mock())
         self.assertEqual(result, expected, msg="Failed with Input = " + str(input))
+
+
+class TestDebugPortType(TestCase):
+    def setUp(self):
+        self.param_type = DebugPortType()
+        self.mock_param = Mock(opts=["--debug-port"])
+
+    @parameterized.expand(
+        [
+            # Not a number
+            ("abc"),
+            # Range with a missing end
+            ("5000-"),
+            # Range with a start greater than the end
+            ("5010-5000"),
+            # Too many parts
+            ("5000-5005-5010"),
+            # Ports out of the valid port numbers
+            ("0"),
+            ("65536"),
+            ("65530-65540"),
+            ("0-10"),
+        ]
+    )
+    def test_must_fail_on_invalid_format(self, input):
+        with self.assertRaises(BadParameter):
+            self.param_type.convert(input, self.mock_param, Mock())
+
+    @parameterized.expand(
+        [
+            ("5858", 5858),
+            (5858, 5858),
+            (" 5858 ", 5858),
+            ("5000-5010", range(5000, 5011)),
+            ("5000-5000", range(5000, 5001)),
+            ("65535", 65535),
+            ("65530-65535", range(65530, 65536)),
+            (range(5000, 5002), range(5000, 5002)),
+        ]
+    )
+    def test_successful_parsing(self, input, expected):
+        result = self.param_type.convert(input, self.mock_param, Mock())
+        self.assertEqual(result, expected, msg="Failed with Input = " + str(input))
+
+
+class TestHostEntryType(TestCase):
+    def setUp(self):
+        self.param_type = HostEntryType()
+        self.mock_param = Mock(opts=["--add-host"])
+
+    @parameterized.expand(
+        [
+            # Missing IP
+            ("db.local",),
+            ("db.local:",),
+            # Missing hostname
+            (":10.0.0.2",),
+            # Not an IP
+            ("db.local:db.remote",),
+            ("db.local:10.0.0.256",),
+            # Invalid hostname
+            ("db_local:10.0.0.2",),
+        ]
+    )
+    def test_must_fail_on_invalid_format(self, input):
+        with self.assertRaises(BadParameter):
+            self.param_type.convert(input, self.mock_param, Mock())
+
+    @parameterized.expand(
+        [
+            ("db.local:192.168.1.10", ("db.local", "192.168.1.10")),
+            (" db.local:192.168.1.10 ", ("db.local", "192.168.1.10")),
+            ("ipv6.local:2001:db8::1", ("ipv6.local", "2001:db8::1")),
+            ("host:host-gateway", ("host", "host-gateway")),
+            (("db.local", "192.168.1.10"), ("db.local", "192.168.1.10")),
+        ]
+    )
+    def test_successful_parsing(self, input, expected):
+        result = self.param_type.convert(input, self.mock_param, Mock())
+        self.assertEqual(result, expected, msg="Failed with Input = " + str(input))
+
+
+class TestContainerLabelType(TestCase):
+    def setUp(self):
+        self.param_type = ContainerLabelType()
+        self.mock_param = Mock(opts=["--container-labels"])
+
+    @parameterized.expand([("team",), ("=serverless",), (" =serverless",)])
+    def test_must_fail_on_invalid_format(self, input):
+        with self.assertRaises(BadParameter):
+            self.param_type.convert(input, self.mock_param, Mock())
+
+    @parameterized.expand(
+        [
+            ("team=serverless", ("team", "serverless")),
+            ("com.example.owner=alice", ("com.example.owner", "alice")),
+            ("query=a=b", ("query", "a=b")),
+            ("empty=", ("empty", "")),
+            (("team", "serverless"), ("team", "serverless")),
+        ]
+    )
+    def test_successful_parsing(self, input, expected):
+        result = self.param_type.convert(input, self.mock_param, Mock())
+        self.assertEqual(result, expected, msg="Failed with Input = " + str(input))
+
+
+class TestEntrypointType(TestCase):
+    def setUp(self):
+        self.param_type = EntrypointType()
+        self.mock_param = Mock(opts=["--container-entrypoint-override"])
+
+    @parameterized.expand([("",), ("   ",), ('"/opt/wrapper',), ([],)])
+    def test_must_fail_on_invalid_format(self, input):
+        with self.assertRaises(BadParameter):
+            self.param_type.convert(input, self.mock_param, Mock())
+
+    @parameterized.expand(
+        [
+            ("/opt/wrapper", ["/opt/wrapper"]),
+            ("/opt/wrapper /var/rapid/aws-lambda-rie", ["/opt/wrapper", "/var/rapid/aws-lambda-rie"]),
+            ('/bin/sh -c "exec /opt/wrapper"', ["/bin/sh", "-c", "exec /opt/wrapper"]),
+            (["/opt/wrapper", "--verbose"], ["/opt/wrapper", "--verbose"]),
+        ]
+    )
+    def test_successful_parsing(self, input, expected):
+        result = self.param_type.convert(input, self.mock_param, Mock())
+        self.assertEqual(result, expected, msg="Failed with Input = " + str(input))
+
+
+class TestRuntimeImageType(TestCase):
+    def setUp(self):
+        self.param_type = RuntimeImageType({"python3.8", "nodejs14.x"})
+        self.mock_param = Mock(opts=["--runtime-image-override"])
+
+    @parameterized.expand(
+        [
+            ("registry.example.com/lambda-python3.8:1.0",),
+            ("python3.8",),
+            ("python3.8=",),
+            ("=registry.example.com/lambda-python3.8:1.0",),
+            ("python9.9=registry.example.com/lambda-python9.9:1.0",),
+        ]
+    )
+    def test_must_fail_on_invalid_format(self, input):
+        with self.assertRaises(BadParameter):
+            self.param_type.convert(input, self.mock_param, Mock())
+
+    @parameterized.expand(
+        [
+            (
+                "python3.8=registry.example.com/lambda-python3.8:1.0",
+                ("python3.8", "registry.example.com/lambda-python3.8:1.0"),
+            ),
+            (
+                "nodejs14.x=registry.example.com/lambda-nodejs@sha256:0123456789abcdef",
+                ("nodejs14.x", "registry.example.com/lambda-nodejs@sha256:0123456789abcdef"),
+            ),
+            (("python3.8", "lambda-python3.8"), ("python3.8", "lambda-python3.8")),
+        ]
+    )
+    def test_successful_parsing(self, input, expected):
+        result = self.param_type.convert(input, self.mock_param, Mock())
+        self.assertEqual(result, expected, msg="Failed with Input = " + str(input))
+
+
+class TestJsonObjectType(TestCase):
+    def setUp(self):
+        self.param_type = JsonObjectType()
+        self.mock_param = Mock(opts=["--payload"])
+
+    @parameterized.expand([("",), ("message=hi",), ('{"message": ',), ('["message"]',), ("1",), ("null",)])
+    def test_must_fail_on_invalid_format(self, input):
+        with self.assertRaises(BadParameter):
+            self.param_type.convert(input, self.mock_param, Mock())
+
+    @parameterized.expand(
+        [
+            ("{}", {}),
+            ('{"message": "Hello from AWS IoT console"}', {"message": "Hello from AWS IoT console"}),
+            ('{"reading": {"temperature": 21.5}}', {"reading": {"temperature": 21.5}}),
+            ({"message": "hi"}, {"message": "hi"}),
+        ]
+    )
+    def test_successful_parsing(self, input, expected):
+        result = self.param_type.convert(input, self.mock_param, Mock())
+        self.assertEqual(result, expected, msg="Failed with Input = " + str(input))
@@ -3,6 +3,7 @@ Test the common CLI options
 """
 
 import os
+import tempfile
 from datetime import datetime
 
 from unittest import TestCase
@@ -15,12 +16,15 @@ from tomlkit import parse
 from samcli.commands._utils.options import (
     get_or_default_template_file_name,
     _TEMPLATE_OPTION_DEFAULT_VALUE,
+    find_templates,
+    get_template_from_template_dir,
     guided_deploy_stack_name,
     artifact_callback,
     resolve_s3_callback,
     image_repositories_callback,
     _space_separated_list_func_type,
 )
+from samcli.commands._utils.remote_template import RemoteTemplateDownloadException
 from samcli.commands.package.exceptions import PackageResolveS3AndS3SetError, PackageResolveS3AndS3NotSetError
 from samcli.lib.utils.packagetype import IMAGE, ZIP
 from tests.unit.cli.test_cli_config_file import MockContext
@@ -102,6 +106,114 @@ class TestGetOrDefaultTemplateFileName(TestCase):
         result = get_or_default_template_file_name(ctx_mock, None, _TEMPLATE_OPTION_DEFAULT_VALUE, include_build=True)
         self.assertEqual(result, expected_result_from_ctx)
 
+    def test_must_use_template_from_template_dir(self):
+        ctx_mock = Mock()
+        ctx_mock.default_map = {"template": "bar.txt"}
+        ctx_mock.template_dir_template = os.path.join("services", "orders", "template.yaml")
+
+        result = get_or_default_template_file_name(ctx_mock, None, _TEMPLATE_OPTION_DEFAULT_VALUE, include_build=True)
+        self.assertEqual(result, os.path.abspath(ctx_mock.template_dir_template))
+        self.assertEqual(ctx_mock.samconfig_dir, os.path.abspath(os.path.join("services", "orders")))
+
+    def test_must_prefer_user_provided_value_over_template_dir(self):
+        ctx_mock = Mock()
+        ctx_mock.default_map = {}
+        ctx_mock.template_dir_template = os.path.join("services", "orders", "template.yaml")
+
+        result = get_or_default_template_file_name(ctx_mock, None, "foo.txt", include_build=True)
+        self.assertEqual(result, os.path.abspath("foo.txt"))
+
+    @patch("samcli.commands._utils.options.get_template_data")
+    @patch("samcli.commands._utils.options.download_template")
+    def test_must_download_remote_template(self, download_template_mock, get_template_data_mock):
+        ctx_mock = Mock()
+        ctx_mock.default_map = {}
+        download_template_mock.return_value = os.path.join(tempfile.gettempdir(), "sam-remote", "template.yaml")
+
+        result = get_or_default_template_file_name(
+            ctx_mock, None, "https://example.com/template.yaml", include_build=True
+        )
+
+        download_template_mock.assert_called_once_with("https://example.com/template.yaml")
+        self.assertEqual(result, download_template_mock.return_value)
+        # The configuration file is still looked up in the current directory
+        self.assertEqual(ctx_mock.samconfig_dir, os.getcwd())
+        get_template_data_mock.assert_called_once_with(download_template_mock.return_value)
+
+    @patch("samcli.commands._utils.options.download_template")
+    def test_must_fail_when_remote_template_cannot_be_downloaded(self, download_template_mock):
+        download_template_mock.side_effect = RemoteTemplateDownloadException("Unable to download the template")
+
+        with self.assertRaises(click.BadParameter) as ex_ctx:
+            get_or_default_template_file_name(None, None, "s3://bucket/template.yaml", include_build=False)
+
+        self.assertIn("Unable to download the template", ex_ctx.exception.message)
+
+
+class TestTemplateDir(TestCase):
+    def setUp(self):
+        self.template_dir = tempfile.TemporaryDirectory()
+        self.root = self.template_dir.name
+        for path in [
+            ("orders", "template.yaml"),
+            ("payments", "api", "template.yml"),
+            ("payments", ".aws-sam", "build", "template.yaml"),
+            ("payments", "node_modules", "lib", "template.yaml"),
+            ("payments", "other.yaml"),
+        ]:
+            os.makedirs(os.path.join(self.root, *path[:-1]), exist_ok=True)
+            open(os.path.join(self.root, *path), "w").close()
+
+    def tearDown(self):
+        self.template_dir.cleanup()
+
+    def test_must_find_templates_recursively(self):
+        self.assertEqual(
+            find_templates(self.root),
+            [
+                os.path.join(self.root, "orders", "template.yaml"),
+                os.path.join(self.root, "payments", "api", "template.yml"),
+            ],
+        )
+
+    def test_must_return_none_without_template_dir(self):
+        self.assertIsNone(get_template_from_template_dir(None, None, None))
+
+    def test_must_use_only_template_found(self):
+        ctx = Mock()
+        ctx.params = {}
+
+        result = get_template_from_template_dir(ctx, None, os.path.join(self.root, "orders"))
+
+        expected = os.path.join(self.root, "orders", "template.yaml")
+        self.assertEqual(result, expected)
+        self.assertEqual(ctx.template_dir_template, expected)
+
+    @patch("samcli.commands._utils.options.click.prompt")
+    def test_must_prompt_when_many_templates_found(self, prompt_mock):
+        prompt_mock.return_value = "2"
+        ctx = Mock()
+        ctx.params = {}
+
+        result = get_template_from_template_dir(ctx, None, self.root)
+
+        self.assertEqual(result, os.path.join(self.root, "payments", "api", "template.yml"))
+        self.assertEqual(list(prompt_mock.call_args[1]["type"].choices), ["1", "2"])
+
+    def test_must_ignore_template_dir_when_template_file_given(self):
+        ctx = Mock()
+        ctx.params = {"template_file": "template.yaml"}
+
+        self.assertIsNone(get_template_from_template_dir(ctx, None, self.root))
+        self.assertFalse(hasattr(ctx, "template_dir_template"))
+
+    def test_must_fail_when_no_template_found(self):
+        empty_dir = os.path.join(self.root, "empty")
+        os.makedirs(empty_dir)
+
+        with self.assertRaises(click.BadParameter):
+            get_template_from_template_dir(None, None, empty_dir)
+
 
 class TestImageRepositoriesCallBack(TestCase):
     def test_image_repositories_callback(self):
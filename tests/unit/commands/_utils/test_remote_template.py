@@ -0,0 +1,127 @@
+import os
+import threading
+from http.server import BaseHTTPRequestHandler, HTTPServer
+from unittest import TestCase
+from unittest.mock import patch, MagicMock
+
+from botocore.exceptions import ClientError
+
+from samcli.commands._utils.remote_template import (
+    download_template,
+    get_remote_template_url,
+    is_remote_template_url,
+    RemoteTemplateDownloadException,
+)
+
+TEMPLATE = """AWSTemplateFormatVersion: '2010-09-09'
+Transform: AWS::Serverless-2016-10-31
+Resources:
+  HelloWorldFunction:
+    Type: AWS::Serverless::Function
+    Properties:
+      Handler: app.handler
+      Runtime: python3.8
+"""
+
+
+class TemplateHandler(BaseHTTPRequestHandler):
+    def do_GET(self):
+        if self.path != "/templates/template.yaml":
+            self.send_error(404)
+            return
+
+        body = TEMPLATE.encode("utf-8")
+        self.send_response(200)
+        self.send_header("Content-Length", str(len(body)))
+        self.end_headers()
+        self.wfile.write(body)
+
+    def log_message(self, *args):
+        pass
+
+
+class TestIsRemoteTemplateUrl(TestCase):
+    def test_must_detect_urls(self):
+        self.assertTrue(is_remote_template_url("s3://bucket/template.yaml"))
+        self.assertTrue(is_remote_template_url("https://example.com/template.yaml"))
+        self.assertTrue(is_remote_template_url("HTTPS://example.com/template.yaml"))
+
+    def test_must_not_detect_local_paths(self):
+        self.assertFalse(is_remote_template_url("template.yaml"))
+        self.assertFalse(is_remote_template_url("/path/to/s3://template.yaml"))
+        self.assertFalse(is_remote_template_url(None))
+
+    def test_must_not_detect_plain_http_urls(self):
+        # Templates are only downloaded over encrypted connections
+        self.assertFalse(is_remote_template_url("http://example.com/template.yaml"))
+
+
+class TestDownloadTemplateFromServer(TestCase):
+    def setUp(self):
+        self.server = HTTPServer(("127.0.0.1", 0), TemplateHandler)
+        self.thread = threading.Thread(target=self.server.serve_forever, daemon=True)
+        self.thread.start()
+        self.base_url = "http://127.0.0.1:{}".format(self.server.server_port)
+
+    def tearDown(self):
+        self.server.shutdown()
+        self.server.server_close()
+
+    def test_must_download_template(self):
+        url = self.base_url + "/templates/template.yaml"
+
+        template_path = download_template(url)
+
+        self.assertEqual(os.path.basename(template_path), "template.yaml")
+        with open(template_path, "r", encoding="utf-8") as template_file:
+            self.assertEqual(template_file.read(), TEMPLATE)
+        self.assertEqual(get_remote_template_url(template_path), url)
+
+    def test_must_fail_when_server_responds_with_error(self):
+        with self.assertRaises(RemoteTemplateDownloadException) as ex_ctx:
+            download_template(self.base_url + "/missing.yaml")
+
+        self.assertIn("Unable to download the template at", str(ex_ctx.exception))
+
+
+class TestDownloadTemplateFromS3(TestCase):
+    @patch("samcli.commands._utils.remote_template.boto3")
+    def test_must_download_template(self, boto3_mock):
+        s3_client = boto3_mock.client.return_value
+        s3_client.get_object.return_value = {"Body": MagicMock(read=MagicMock(return_value=TEMPLATE.encode("utf-8")))}
+
+        template_path = download_template("s3://bucket/prefix/template.yaml")
+
+        boto3_mock.client.assert_called_once_with("s3")
+        s3_client.get_object.assert_called_once_with(Bucket="bucket", Key="prefix/template.yaml")
+        with open(template_path, "r", encoding="utf-8") as template_file:
+            self.assertEqual(template_file.read(), TEMPLATE)
+        self.assertEqual(get_remote_template_url(template_path), "s3://bucket/prefix/template.yaml")
+
+    @patch("samcli.commands._utils.remote_template.boto3")
+    def test_must_download_version_of_template(self, boto3_mock):
+        s3_client = boto3_mock.client.return_value
+        s3_client.get_object.return_value = {"Body": MagicMock(read=MagicMock(return_value=b"{}"))}
+
+        download_template("s3://bucket/template.json?versionId=abc")
+
+        s3_client.get_object.assert_called_once_with(Bucket="bucket", Key="template.json", VersionId="abc")
+
+    @patch("samcli.commands._utils.remote_template.boto3")
+    def test_must_fail_when_object_cannot_be_read(self, boto3_mock):
+        boto3_mock.client.return_value.get_object.side_effect = ClientError(
+            {"Error": {"Code": "NoSuchKey", "Message": "Not Found"}}, "GetObject"
+        )
+
+        with self.assertRaises(RemoteTemplateDownloadException):
+            download_template("s3://bucket/template.yaml")
+
+    def test_must_fail_on_invalid_url(self):
+        with self.assertRaises(RemoteTemplateDownloadException):
+            download_template("s3://bucket")
+
+
+class TestGetRemoteTemplateUrl(TestCase):
+    def test_must_return_none_for_local_templates(self):
+        self.assertIsNone(get_remote_template_url("template.yaml"))
+        self.assertIsNone(get_remote_template_url(None))
@@ -0,0 +1,34 @@
+from unittest import TestCase
+from unittest.mock import Mock, patch
+
+from samcli.commands.exceptions import UserException
+from samcli.commands.local.clean.cli import do_cli as clean_cli
+
+
+class TestCli(TestCase):
+    @patch("samcli.local.docker.utils.is_docker_reachable")
+    @patch("docker.from_env")
+    def test_must_remove_sam_containers(self, from_env_mock, is_docker_reachable_mock):
+        is_docker_reachable_mock.return_value = True
+        containers = [
+            Mock(short_id="abc123", labels={"com.aws.sam.local.function": "HelloWorldFunction"}),
+            Mock(short_id="def456", labels={}),
+        ]
+        docker_client = from_env_mock.return_value
+        docker_client.containers.list.return_value = containers
+
+        clean_cli()
+
+        docker_client.containers.list.assert_called_once_with(all=True, filters={"label": "com.aws.sam.local=true"})
+        for container in containers:
+            container.remove.assert_called_once_with(force=True)
+
+    @patch("samcli.local.docker.utils.is_docker_reachable")
+    @patch("docker.from_env")
+    def test_must_fail_when_docker_is_not_reachable(self, from_env_mock, is_docker_reachable_mock):
+        is_docker_reachable_mock.return_value = False
+
+        with self.assertRaises(UserException):
+            clean_cli()
+
+        from_env_mock.return_value.containers.list.assert_not_called()
@@ -3,6 +3,8 @@ Tests the InvokeContext class
 """
 import errno
 import os
+import shutil
+import tempfile
 
 from samcli.commands._utils.template import TemplateFailedParsingException
 from samcli.commands.local.cli_common.user_exceptions import InvokeContextException, DebugContextException
@@ -10,8 +12,14 @@ from samcli.commands.local.cli_common.invoke_context import InvokeContext, Conta
 
 from unittest import TestCase
 from unittest.mock import Mock, PropertyMock, patch, ANY, mock_open, call
+from pathlib import Path
+
+from parameterized import parameterized
 
 from samcli.lib.providers.provider import Stack
+from samcli.local.docker.container import SAM_LOCAL_TEMPLATE_LABEL
+from samcli.local.docker.manager import ContainerManager
+from samcli.lib.hook.terraform import InvalidTerraformPlanException
 
 
 class TestInvokeContext__enter__(TestCase):
@@ -77,16 +85,26 @@ class TestInvokeContext__enter__(TestCase):
         self.assertEqual(invoke_context._containers_initializing_mode, ContainersInitializationMode.LAZY)
 
         invoke_context._get_stacks.assert_called_once()
-        SamFunctionProviderMock.assert_called_with(stacks)
+        SamFunctionProviderMock.assert_called_with(stacks, use_raw_codeuri=False)
         self.assertEqual(invoke_context._global_parameter_overrides, {"AWS::Region": "region"})
         self.assertEqual(invoke_context._get_env_vars_value.call_count, 2)
         self.assertEqual(invoke_context._get_env_vars_value.call_args_list, [call(env_vars_file), call(None)])
         invoke_context._setup_log_file.assert_called_with(log_file)
         invoke_context._get_debug_context.assert_called_once_with(
-            [1111], "args", "path-to-debugger", "env_vars_value", None
+            [1111], "args", "path-to-debugger", "env_vars_value", None, None, None
         )
         ContainerManagerMock.assert_called_once_with(
-            docker_network_id="network", skip_pull_image=True, do_shutdown_event=False
+            docker_network_id="network",
+            skip_pull_image=True,
+            do_shutdown_event=False,
+            container_hostname=None,
+            shm_size=None,
+            cpu_proportional=False,
+            labels={SAM_LOCAL_TEMPLATE_LABEL: os.path.abspath(template_file)},
+            no_memory_limit=False,
+            extra_hosts=None,
+            shutdown_timeout=0,
+            force_linux_paths=False,
         )
 
     @patch("samcli.commands.local.cli_common.invoke_context.ContainerManager")
@@ -159,16 +177,26 @@ class TestInvokeContext__enter__(TestCase):
         self.assertEqual(invoke_context._containers_initializing_mode, ContainersInitializationMode.EAGER)
 
         invoke_context._get_stacks.assert_called_once()
-        SamFunctionProviderMock.assert_called_with(stacks)
+        SamFunctionProviderMock.assert_called_with(stacks, use_raw_codeuri=False)
         self.assertEqual(invoke_context._global_parameter_overrides, {"AWS::Region": "region"})
         self.assertEqual(invoke_context._get_env_vars_value.call_count, 2)
         self.assertEqual(invoke_context._get_env_vars_value.call_args_list, [call(env_vars_file), call(None)])
         invoke_context._setup_log_file.assert_called_with(log_file)
         invoke_context._get_debug_context.assert_called_once_with(
-            None, "args", "path-to-debugger", "env_vars_value", None
+            None, "args", "path-to-debugger", "env_vars_value", None, None, None
         )
         ContainerManagerMock.assert_called_once_with(
-            docker_network_id="network", skip_pull_image=True, do_shutdown_event=True
+            docker_network_id="network",
+            skip_pull_image=True,
+            do_shutdown_event=True,
+            container_hostname=None,
+            shm_size=None,
+            cpu_proportional=False,
+            labels={SAM_LOCAL_TEMPLATE_LABEL: os.path.abspath(template_file)},
+            no_memory_limit=False,
+            extra_hosts=None,
+            shutdown_timeout=0,
+            force_linux_paths=False,
         )
         _initialize_all_functions_containers_mock.assert_called_once_with()
 
@@ -242,7 +270,7 @@ class TestInvokeContext__enter__(TestCase):
         self.assertEqual(invoke_context._containers_initializing_mode, ContainersInitializationMode.EAGER)
 
         invoke_context._get_stacks.assert_called_once()
-        SamFunctionProviderMock.assert_called_with(stacks)
+        SamFunctionProviderMock.assert_called_with(stacks, use_raw_codeuri=False)
         self.assertEqual(invoke_context._global_parameter_overrides, {"AWS::Region": "region"})
         self.assertEqual(invoke_context._get_env_vars_value.call_count, 2)
         self.assertEqual(
@@ -250,10 +278,20 @@ class TestInvokeContext__enter__(TestCase):
         )
         invoke_context._setup_log_file.assert_called_with(log_file)
         invoke_context._get_debug_context.assert_called_once_with(
-            [1111], "args", "path-to-debugger", "Debug env var value", "function_name"
+            [1111], "args", "path-to-debugger", "Debug env var value", "function_name", None, None
         )
         ContainerManagerMock.assert_called_once_with(
-            docker_network_id="network", skip_pull_image=True, do_shutdown_event=True
+            docker_network_id="network",
+            skip_pull_image=True,
+            do_shutdown_event=True,
+            container_hostname=None,
+            shm_size=None,
+            cpu_proportional=False,
+            labels={SAM_LOCAL_TEMPLATE_LABEL: os.path.abspath(template_file)},
+            no_memory_limit=False,
+            extra_hosts=None,
+            shutdown_timeout=0,
+            force_linux_paths=False,
         )
         _initialize_all_functions_containers_mock.assert_called_once_with()
 
@@ -323,16 +361,62 @@ class TestInvokeContext__enter__(TestCase):
         self.assertEqual(invoke_context._containers_initializing_mode, ContainersInitializationMode.LAZY)
 
         invoke_context._get_stacks.assert_called_once()
-        SamFunctionProviderMock.assert_called_with(stacks)
+        SamFunctionProviderMock.assert_called_with(stacks, use_raw_codeuri=False)
         self.assertEqual(invoke_context._global_parameter_overrides, {"AWS::Region": "region"})
         self.assertEqual(invoke_context._get_env_vars_value.call_count, 2)
         self.assertEqual(invoke_context._get_env_vars_value.call_args_list, [call(env_vars_file), call(None)])
         invoke_context._setup_log_file.assert_called_with(log_file)
         invoke_context._get_debug_context.assert_called_once_with(
-            [1111], "args", "path-to-debugger", "env_vars_value", "debug_function"
+            [1111], "args", "path-to-debugger", "env_vars_value", "debug_function", None, None
         )
         ContainerManagerMock.assert_called_once_with(
-            docker_network_id="network", skip_pull_image=True, do_shutdown_event=True
+            docker_network_id="network",
+            skip_pull_image=True,
+            do_shutdown_event=True,
+            container_hostname=None,
+            shm_size=None,
+            cpu_proportional=False,
+            labels={SAM_LOCAL_TEMPLATE_LABEL: os.path.abspath(template_file)},
+            no_memory_limit=False,
+            extra_hosts=None,
+            shutdown_timeout=0,
+            force_linux_paths=False,
+        )
+
+    @patch("samcli.commands.local.cli_common.invoke_context.ContainerManager")
+    @patch("samcli.commands.local.cli_common.invoke_context.SamFunctionProvider")
+    def test_must_assign_debug_ports_to_all_functions_if_debug_port_range_is_given(
+        self, SamFunctionProviderMock, ContainerManagerMock
+    ):
+        function_a = Mock()
+        function_a.name = "FunctionA"
+        function_b = Mock()
+        function_b.name = "FunctionB"
+        function_provider = Mock()
+        function_provider.get_all.return_value = [function_a, function_b]
+        function_provider.functions = {"FunctionA": function_a, "FunctionB": function_b}
+        SamFunctionProviderMock.return_value = function_provider
+
+        invoke_context = InvokeContext(
+            template_file="template_file",
+            debug_ports=(range(5000, 5011),),
+            warm_container_initialization_mode=ContainersInitializationMode.LAZY.value,
+        )
+
+        invoke_context._get_stacks = Mock(return_value=[Mock()])
+        invoke_context._get_env_vars_value = Mock(return_value=None)
+        invoke_context._setup_log_file = Mock(return_value=None)
+        invoke_context._get_debug_context = Mock()
+
+        container_manager_mock = Mock()
+        container_manager_mock.is_docker_reachable = True
+        ContainerManagerMock.return_value = container_manager_mock
+
+        invoke_context.__enter__()
+
+        self.assertEqual(invoke_context._debug_ports, (range(5000, 5011),))
+        invoke_context._get_debug_context.assert_called_once_with(
+            (range(5000, 5011),), None, None, None, None, {"FunctionA": 5000, "FunctionB": 5001}, None
         )
 
     @patch("samcli.commands.local.cli_common.invoke_context.SamFunctionProvider")
@@ -361,6 +445,32 @@ class TestInvokeContext__enter__(TestCase):
 
             is_docker_reachable_mock.assert_called_once_with()
 
+    @parameterized.expand(
+        [
+            ("response.json", "logs.txt", ["response.json", "logs.txt"], "stderr-handle"),
+            ("output.txt", "output.txt", ["output.txt"], "stdout-handle"),
+        ]
+    )
+    @patch("samcli.commands.local.cli_common.invoke_context.SamFunctionProvider")
+    def test_must_open_stdout_and_stderr_files(
+        self, stdout_file, stderr_file, expected_opened_files, expected_stderr_handle, SamFunctionProviderMock
+    ):
+        invoke_context = InvokeContext("template-file", stdout_file=stdout_file, stderr_file=stderr_file)
+
+        invoke_context._get_stacks = Mock(return_value=[Mock()])
+        invoke_context._get_env_vars_value = Mock()
+        invoke_context._get_debug_context = Mock()
+        invoke_context._get_container_manager = Mock()
+        handles = {"response.json": "stdout-handle", "output.txt": "stdout-handle", "logs.txt": "stderr-handle"}
+        invoke_context._setup_log_file = Mock(side_effect=lambda log_file: handles.get(log_file))
+
+        invoke_context.__enter__()
+
+        invoke_context._setup_log_file.assert_has_calls([call(None)] + [call(path) for path in expected_opened_files])
+        self.assertEqual(invoke_context._setup_log_file.call_count, 1 + len(expected_opened_files))
+        self.assertEqual(invoke_context._stdout_file_handle, "stdout-handle")
+        self.assertEqual(invoke_context._stderr_file_handle, expected_stderr_handle)
+
     @patch("samcli.commands.local.cli_common.invoke_context.SamFunctionProvider")
     def test_must_raise_if_docker_is_not_reachable(self, SamFunctionProviderMock):
         invoke_context = InvokeContext("template-file")
@@ -392,6 +502,28 @@ class TestInvokeContext__enter__(TestCase):
                     str(ex_ctx.exception),
                 )
 
+    @patch("samcli.commands.local.cli_common.invoke_context.SamFunctionProvider")
+    def test_must_raise_if_docker_api_version_is_not_supported(self, SamFunctionProviderMock):
+        invoke_context = InvokeContext("template-file")
+
+        invoke_context._get_stacks = Mock()
+        invoke_context._get_stacks.return_value = [Mock()]
+        invoke_context._get_env_vars_value = Mock()
+        invoke_context._setup_log_file = Mock()
+        invoke_context._get_debug_context = Mock()
+
+        docker_client_mock = Mock()
+        docker_client_mock.api.version.return_value = {"ApiVersion": "1.24"}
+        invoke_context._get_container_manager = Mock()
+        invoke_context._get_container_manager.return_value = ContainerManager(docker_client=docker_client_mock)
+
+        with self.assertRaises(InvokeContextException) as ex_ctx:
+            invoke_context.__enter__()
+
+        self.assertIn("Docker API version 1.24 is older than 1.35", str(ex_ctx.exception))
+        self.assertIn("Please update Docker", str(ex_ctx.exception))
+        docker_client_mock.ping.assert_not_called()
+
     @patch("samcli.commands.local.cli_common.invoke_context.SamLocalStackProvider.get_stacks")
     def test_must_raise_if_template_cannot_be_parsed(self, get_buildable_stacks_mock):
         invoke_context = InvokeContext("template-file")
@@ -401,6 +533,46 @@ class TestInvokeContext__enter__(TestCase):
             invoke_context.__enter__()
 
 
+class TestInvokeContext_extra_hosts(TestCase):
+    def test_must_map_added_hosts_to_their_ip(self):
+        context = InvokeContext(
+            template_file="template", add_host=(("db.local", "192.168.1.10"), ("cache.local", "host-gateway"))
+        )
+
+        self.assertEqual(context._extra_hosts, {"db.local": "192.168.1.10", "cache.local": "host-gateway"})
+
+    def test_must_not_add_hosts_by_default(self):
+        context = InvokeContext(template_file="template")
+
+        self.assertIsNone(context._extra_hosts)
+
+
+class TestInvokeContext_container_labels(TestCase):
+    @patch("samcli.commands.local.cli_common.invoke_context.ContainerManager")
+    @patch("samcli.commands.local.cli_common.invoke_context.SamFunctionProvider")
+    def test_must_label_containers_with_template_and_user_labels(self, SamFunctionProviderMock, ContainerManagerMock):
+        invoke_context = InvokeContext(
+            template_file="template.yaml",
+            container_labels_file="labels.yaml",
+            container_labels=(("team", "payments"), ("env", "dev")),
+        )
+        invoke_context._get_stacks = Mock(return_value=[])
+        invoke_context._get_container_labels_value = Mock(return_value={"team": "serverless", "owner": "alice"})
+        ContainerManagerMock.return_value.is_docker_reachable = True
+
+        invoke_context.__enter__()
+
+        self.assertEqual(
+            ContainerManagerMock.call_args[1]["labels"],
+            {
+                SAM_LOCAL_TEMPLATE_LABEL: os.path.abspath("template.yaml"),
+                "team": "payments",
+                "owner": "alice",
+                "env": "dev",
+            },
+        )
+
+
 class TestInvokeContext__exit__(TestCase):
     def test_must_close_opened_logfile(self):
         context = InvokeContext(template_file="template")
@@ -419,6 +591,27 @@ class TestInvokeContext__exit__(TestCase):
         context.__exit__()
         self.assertIsNone(context._log_file_handle)
 
+    def test_must_close_stdout_and_stderr_files_once(self):
+        context = InvokeContext(template_file="template")
+        handle_mock = Mock()
+        context._stdout_file_handle = handle_mock
+        context._stderr_file_handle = handle_mock
+
+        context.__exit__()
+
+        handle_mock.close.assert_called_once_with()
+        self.assertIsNone(context._stdout_file_handle)
+        self.assertIsNone(context._stderr_file_handle)
+
+    def test_must_clean_decompressed_code_of_cold_runtime(self):
+        context = InvokeContext(template_file="template")
+        lambda_runtime_mock = Mock()
+        context._lambda_runtimes = {ContainersMode.COLD: lambda_runtime_mock}
+
+        context.__exit__()
+
+        lambda_runtime_mock.clean_decompressed_paths.assert_called_once_with()
+
 
 class TestInvokeContextAsContextManager(TestCase):
     """
@@ -534,7 +727,7 @@ class TestInvokeContext_local_lambda_runner(TestCase):
             self.assertEqual(result, runner_mock)
 
             LambdaRuntimeMock.assert_called_with(container_manager_mock, image_mock)
-            lambda_image_patch.assert_called_once_with(download_mock, True, True)
+            lambda_image_patch.assert_called_once_with(download_mock, True, True, runtime_images=None)
             LocalLambdaMock.assert_called_with(
                 local_runtime=runtime_mock,
                 function_provider=ANY,
@@ -545,6 +738,18 @@ class TestInvokeContext_local_lambda_runner(TestCase):
                 aws_region="region",
                 container_host=None,
                 container_host_interface=None,
+                show_env=False,
+                runtime_override=None,
+                timezone=None,
+                build_dir=None,
+                up_to_date_build_artifacts=None,
+                buffered_output=False,
+                env_file_values=None,
+                random_seed=None,
+                random_seed_env_var=None,
+                aws_endpoint_url=None,
+                cloudwatch_style_logs=False,
+                container_entrypoint=None,
             )
 
             result = self.context.local_lambda_runner
@@ -607,7 +812,7 @@ class TestInvokeContext_local_lambda_runner(TestCase):
             self.assertEqual(result, runner_mock)
 
             WarmLambdaRuntimeMock.assert_called_with(container_manager_mock, image_mock)
-            lambda_image_patch.assert_called_once_with(download_mock, True, True)
+            lambda_image_patch.assert_called_once_with(download_mock, True, True, runtime_images=None)
             LocalLambdaMock.assert_called_with(
                 local_runtime=runtime_mock,
                 function_provider=ANY,
@@ -618,6 +823,18 @@ class TestInvokeContext_local_lambda_runner(TestCase):
                 aws_region="region",
                 container_host=None,
                 container_host_interface=None,
+                show_env=False,
+                runtime_override=None,
+                timezone=None,
+                build_dir=None,
+                up_to_date_build_artifacts=None,
+                buffered_output=False,
+                env_file_values=None,
+                random_seed=None,
+                random_seed_env_var=None,
+                aws_endpoint_url=None,
+                cloudwatch_style_logs=False,
+                container_entrypoint=None,
             )
 
             result = self.context.local_lambda_runner
@@ -681,7 +898,7 @@ class TestInvokeContext_local_lambda_runner(TestCase):
             self.assertEqual(result, runner_mock)
 
             LambdaRuntimeMock.assert_called_with(container_manager_mock, image_mock)
-            lambda_image_patch.assert_called_once_with(download_mock, True, True)
+            lambda_image_patch.assert_called_once_with(download_mock, True, True, runtime_images=None)
             LocalLambdaMock.assert_called_with(
                 local_runtime=runtime_mock,
                 function_provider=ANY,
@@ -692,6 +909,18 @@ class TestInvokeContext_local_lambda_runner(TestCase):
                 aws_region="region",
                 container_host="abcdef",
                 container_host_interface="192.168.100.101",
+                show_env=False,
+                runtime_override=None,
+                timezone=None,
+                build_dir=None,
+                up_to_date_build_artifacts=None,
+                buffered_output=False,
+                env_file_values=None,
+                random_seed=None,
+                random_seed_env_var=None,
+                aws_endpoint_url=None,
+                cloudwatch_style_logs=False,
+                container_entrypoint=None,
             )
 
             result = self.context.local_lambda_runner
@@ -914,6 +1143,21 @@ class TestInvokeContext_stderr_property(TestCase):
                 self.assertEqual(stream_writer_mock, stderr)
 
 
+class TestInvokeContext_redirected_streams(TestCase):
+    @patch("samcli.commands.local.cli_common.invoke_context.StreamWriter")
+    def test_must_prefer_stdout_and_stderr_files_to_log_file(self, StreamWriterMock):
+        context = InvokeContext(template_file="template")
+        context._log_file_handle = "log-file-handle"
+        context._stdout_file_handle = "stdout-file-handle"
+        context._stderr_file_handle = "stderr-file-handle"
+
+        context.stdout
+        StreamWriterMock.assert_called_with("stdout-file-handle", ANY)
+
+        context.stderr
+        StreamWriterMock.assert_called_with("stderr-file-handle", ANY)
+
+
 class TestInvokeContextget_cwd(TestCase):
     def test_must_return_template_file_dir_name(self):
         filename = "filename"
@@ -932,6 +1176,132 @@ class TestInvokeContextget_cwd(TestCase):
         self.assertEqual(result, "basedir")
 
 
+class TestInvokeContext_check_no_relative_code_paths(TestCase):
+    def setUp(self):
+        self.context = InvokeContext(template_file="template.yaml")
+        self.context._function_provider = Mock()
+
+    def test_must_accept_absolute_inline_and_image_code(self):
+        self.context._function_provider.get_all.return_value = [
+            Mock(packagetype="Zip", inlinecode=None, codeuri=os.path.abspath("code")),
+            Mock(packagetype="Zip", inlinecode="def handler(event, context): pass", codeuri=None),
+            Mock(packagetype="Image", inlinecode=None, codeuri=None),
+        ]
+
+        self.context._check_no_relative_code_paths("https://example.com/template.yaml")
+
+    def test_must_fail_on_relative_code_path(self):
+        function = Mock(packagetype="Zip", inlinecode=None, codeuri="hello_world", full_path="HelloWorldFunction")
+        self.context._function_provider.get_all.return_value = [function]
+
+        with self.assertRaises(InvokeContextException) as ex_ctx:
+            self.context._check_no_relative_code_paths("https://example.com/template.yaml")
+
+        self.assertIn("HelloWorldFunction", str(ex_ctx.exception))
+        self.assertIn("--docker-volume-basedir", str(ex_ctx.exception))
+
+    @patch("samcli.commands.local.cli_common.invoke_context.SamFunctionProvider")
+    @patch("samcli.commands.local.cli_common.invoke_context.get_remote_template_url")
+    def test_enter_must_use_raw_code_paths_of_remote_template(self, get_remote_template_url_mock, provider_mock):
+        get_remote_template_url_mock.return_value = "s3://bucket/template.yaml"
+        provider_mock.return_value.get_all.return_value = [
+            Mock(packagetype="Zip", inlinecode=None, codeuri="hello_world", full_path="HelloWorldFunction")
+        ]
+        self.context._get_stacks = Mock(return_value=[])
+
+        with self.assertRaises(InvokeContextException):
+            self.context.__enter__()
+
+        provider_mock.assert_called_with([], use_raw_codeuri=True)
+
+
+class TestInvokeContext_get_build_dir(TestCase):
+    def test_must_return_none_if_build_artifacts_are_not_used(self):
+        context = InvokeContext(template_file="filename")
+
+        self.assertIsNone(context.get_build_dir())
+
+    def test_must_return_build_dir_if_build_information_exists(self):
+        with tempfile.TemporaryDirectory() as app_dir:
+            os.makedirs(os.path.join(app_dir, ".aws-sam"))
+            Path(app_dir, ".aws-sam", "build.toml").write_text("")
+            context = InvokeContext(template_file=os.path.join(app_dir, "template.yaml"))
+
+            self.assertEqual(context.get_build_dir(), os.path.join(os.path.abspath(app_dir), ".aws-sam", "build"))
+
+    def test_must_return_build_dir_next_to_template_file(self):
+        filename = os.path.join("app", "template.yaml")
+        context = InvokeContext(template_file=filename, use_build_artifacts=True)
+
+        expected = os.path.join(os.path.dirname(os.path.abspath(filename)), ".aws-sam", "build")
+        self.assertEqual(context.get_build_dir(), expected)
+
+    def test_must_return_none_if_build_artifacts_are_turned_off(self):
+        with tempfile.TemporaryDirectory() as app_dir:
+            os.makedirs(os.path.join(app_dir, ".aws-sam"))
+            Path(app_dir, ".aws-sam", "build.toml").write_text("")
+            context = InvokeContext(template_file=os.path.join(app_dir, "template.yaml"), no_build_artifacts=True)
+
+            self.assertIsNone(context.get_build_dir())
+
+    def test_must_fail_if_build_artifacts_are_both_used_and_turned_off(self):
+        context = InvokeContext(template_file="filename", use_build_artifacts=True, no_build_artifacts=True)
+
+        with self.assertRaises(InvokeContextException):
+            context.__enter__()
+
+
+class TestInvokeContext_get_up_to_date_build_artifacts(TestCase):
+    def setUp(self):
+        self.app_dir = tempfile.mkdtemp()
+        self.build_dir = os.path.join(self.app_dir, ".aws-sam", "build")
+        os.makedirs(self.build_dir)
+        self.build_info_file = os.path.join(self.app_dir, ".aws-sam", "build.toml")
+        Path(self.build_info_file).write_text("")
+        os.utime(self.build_info_file, (1000, 1000))
+        self.context = InvokeContext(template_file=os.path.join(self.app_dir, "template.yaml"))
+
+    def tearDown(self):
+        shutil.rmtree(self.app_dir)
+
+    def _write_code(self, codeuri, modified_time):
+        os.makedirs(os.path.join(self.app_dir, codeuri))
+        code_file = os.path.join(self.app_dir, codeuri, "app.py")
+        Path(code_file).write_text("")
+        os.utime(code_file, (modified_time, modified_time))
+        os.utime(os.path.join(self.app_dir, codeuri), (modified_time, modified_time))
+
+    @patch("samcli.commands.local.cli_common.invoke_context.tomlkit")
+    def test_must_return_functions_and_layers_whose_code_did_not_change(self, tomlkit_mock):
+        tomlkit_mock.loads.return_value = {
+            "function_build_definitions": {
+                "built": {"codeuri": "built", "functions": ["BuiltFunction", "ChildStack/BuiltFunction"]},
+                "changed": {"codeuri": "changed", "functions": ["ChangedFunction"]},
+                "image": {"functions": ["ImageFunction"]},
+            },
+            "layer_build_definitions": {
+                "built_layer": {"codeuri": "built_layer", "layer": "BuiltLayer"},
+                "changed_layer": {"codeuri": "changed_layer", "layer": "ChangedLayer"},
+            },
+        }
+        self._write_code("built", 500)
+        self._write_code("changed", 2000)
+        self._write_code("built_layer", 500)
+        self._write_code("changed_layer", 2000)
+
+        result = self.context._get_up_to_date_build_artifacts(self.build_dir)
+
+        self.assertEqual(result, {"BuiltFunction", "ChildStack/BuiltFunction", "ImageFunction", "BuiltLayer"})
+
+    def test_must_return_none_if_build_artifacts_are_not_used(self):
+        self.assertIsNone(self.context._get_up_to_date_build_artifacts(None))
+
+    def test_must_return_none_if_build_artifacts_are_requested(self):
+        context = InvokeContext(template_file=os.path.join(self.app_dir, "template.yaml"), use_build_artifacts=True)
+
+        self.assertIsNone(context._get_up_to_date_build_artifacts(self.build_dir))
+
+
 class TestInvokeContext_get_env_vars_value(TestCase):
     def test_must_return_if_no_file(self):
         result = InvokeContext._get_env_vars_value(filename=None)
@@ -968,6 +1338,144 @@ class TestInvokeContext_get_env_vars_value(TestCase):
             )
 
 
+class TestInvokeContext_get_env_file_value(TestCase):
+    def test_must_return_if_no_file(self):
+        result = InvokeContext._get_env_file_value(filename=None)
+        self.assertIsNone(result, "No value must be returned")
+
+    def test_must_read_file_and_parse_as_dotenv(self):
+        filename = "filename"
+        file_data = "# comment\nA=b\n"
+
+        m = mock_open(read_data=file_data)
+
+        with patch("samcli.commands.local.cli_common.invoke_context.open", m):
+            result = InvokeContext._get_env_file_value(filename)
+
+        self.assertEqual(result, {"A": "b"})
+        m.assert_called_with(filename, "r")
+
+    def test_must_raise_if_failed_to_parse_dotenv(self):
+        filename = "filename"
+
+        m = mock_open(read_data="not a definition")
+
+        with patch("samcli.commands.local.cli_common.invoke_context.open", m):
+            with self.assertRaises(InvokeContextException) as ex_ctx:
+                InvokeContext._get_env_file_value(filename)
+
+        self.assertTrue(
+            str(ex_ctx.exception).startswith("Could not read environment variables from dotenv file filename")
+        )
+
+
+class TestInvokeContext_get_import_values(TestCase):
+    def test_must_return_if_no_file(self):
+        self.assertIsNone(InvokeContext._get_import_values(filename=None))
+
+    def test_must_read_json_file(self):
+        with tempfile.TemporaryDirectory() as tmp_dir:
+            filename = os.path.join(tmp_dir, "exports.json")
+            Path(filename).write_text('{"SharedVpcId": "vpc-0123", "OrdersTableName": "orders"}')
+
+            result = InvokeContext._get_import_values(filename)
+
+        self.assertEqual(result, {"SharedVpcId": "vpc-0123", "OrdersTableName": "orders"})
+
+    @parameterized.expand([("not json",), ('["SharedVpcId"]',)])
+    def test_must_raise_on_invalid_file(self, file_data):
+        m = mock_open(read_data=file_data)
+
+        with patch("samcli.commands.local.cli_common.invoke_context.open", m):
+            with self.assertRaises(InvokeContextException) as ex_ctx:
+                InvokeContext._get_import_values("filename")
+
+        self.assertIn("Could not read import values from file filename", str(ex_ctx.exception))
+
+
+class TestInvokeContext_get_resource_attributes(TestCase):
+    def test_must_return_if_no_file(self):
+        self.assertIsNone(InvokeContext._get_resource_attributes(filename=None))
+
+    def test_must_read_attributes_by_logical_id(self):
+        with tempfile.TemporaryDirectory() as tmp_dir:
+            filename = os.path.join(tmp_dir, "attributes.json")
+            Path(filename).write_text(
+                '{"MyTable.Arn": "arn:aws:dynamodb:us-west-2:111122223333:table/orders", "MyTable.StreamArn": "s", '
+                '"MyQueue.QueueName": "jobs"}'
+            )
+
+            result = InvokeContext._get_resource_attributes(filename)
+
+        self.assertEqual(
+            result,
+            {
+                "MyTable": {"Arn": "arn:aws:dynamodb:us-west-2:111122223333:table/orders", "StreamArn": "s"},
+                "MyQueue": {"QueueName": "jobs"},
+            },
+        )
+
+    @parameterized.expand([("not json",), ('["MyTable.Arn"]',), ('{"MyTable": "arn"}',), ('{".Arn": "arn"}',)])
+    def test_must_raise_on_invalid_file(self, file_data):
+        m = mock_open(read_data=file_data)
+
+        with patch("samcli.commands.local.cli_common.invoke_context.open", m):
+            with self.assertRaises(InvokeContextException) as ex_ctx:
+                InvokeContext._get_resource_attributes("filename")
+
+        self.assertIn("Could not read resource attributes from file filename", str(ex_ctx.exception))
+
+
+class TestInvokeContext_get_container_labels_value(TestCase):
+    def test_must_return_if_no_file(self):
+        result = InvokeContext._get_container_labels_value(filename=None)
+        self.assertIsNone(result, "No value must be returned")
+
+    @parameterized.expand(
+        [
+            ('{"team": "serverless", "cost-center": 42}',),
+            ("team: serverless\ncost-center: 42\n",),
+        ]
+    )
+    def test_must_read_json_or_yaml_file(self, file_data):
+        filename = "filename"
+
+        m = mock_open(read_data=file_data)
+
+        with patch("samcli.commands.local.cli_common.invoke_context.open", m):
+            result = InvokeContext._get_container_labels_value(filename)
+
+        self.assertEqual(result, {"team": "serverless", "cost-center": "42"})
+        m.assert_called_with(filename, "r")
+
+    def test_must_convert_booleans_to_lowercase(self):
+        m = mock_open(read_data="billable: true\n")
+
+        with patch("samcli.commands.local.cli_common.invoke_context.open", m):
+            result = InvokeContext._get_container_labels_value("filename")
+
+        self.assertEqual(result, {"billable": "true"})
+
+    @parameterized.expand(
+        [
+            ("- team\n- serverless\n",),
+            ("team:\n  name: serverless\n",),
+            ("team:\n",),
+            ("team: [serverless\n",),
+        ]
+    )
+    def test_must_raise_if_file_is_not_a_mapping_of_labels(self, file_data):
+        filename = "filename"
+
+        m = mock_open(read_data=file_data)
+
+        with patch("samcli.commands.local.cli_common.invoke_context.open", m):
+            with self.assertRaises(InvokeContextException) as ex_ctx:
+                InvokeContext._get_container_labels_value(filename)
+
+        self.assertTrue(str(ex_ctx.exception).startswith("Could not read container labels from file filename"))
+
+
 class TestInvokeContext_setup_log_file(TestCase):
     def test_must_return_if_file_not_given(self):
         result = InvokeContext._setup_log_file(log_file=None)
@@ -983,6 +1491,96 @@ class TestInvokeContext_setup_log_file(TestCase):
         m.assert_called_with(filename, "wb")
 
 
+class TestInvokeContext_get_function_debug_ports(TestCase):
+    def setUp(self):
+        self.function_a = Mock()
+        self.function_a.name = "FunctionA"
+        self.function_b = Mock()
+        self.function_b.name = "FunctionB"
+
+    def test_no_debug_ports(self):
+        self.assertIsNone(InvokeContext._get_function_debug_ports(None, [self.function_a]))
+
+    def test_no_debug_port_range(self):
+        self.assertIsNone(InvokeContext._get_function_debug_ports((5000, 5001), [self.function_a, self.function_b]))
+
+    def test_assigns_distinct_port_to_each_function(self):
+        function_debug_ports = InvokeContext._get_function_debug_ports(
+            (range(5000, 5011),), [self.function_a, self.function_b]
+        )
+
+        self.assertEqual(function_debug_ports, {"FunctionA": 5000, "FunctionB": 5001})
+
+    def test_assigns_ports_across_multiple_ranges(self):
+        function_debug_ports = InvokeContext._get_function_debug_ports(
+            (range(5000, 5001), range(6000, 6001)), [self.function_a, self.function_b]
+        )
+
+        self.assertEqual(function_debug_ports, {"FunctionA": 5000, "FunctionB": 6000})
+
+    def test_must_raise_if_range_is_too_small(self):
+        with self.assertRaises(DebugContextException):
+            InvokeContext._get_function_debug_ports((range(5000, 5001),), [self.function_a, self.function_b])
+
+
+class TestInvokeContext_get_debugged_functions(TestCase):
+    def setUp(self):
+        self.function_a = Mock()
+        self.function_a.name = "FunctionA"
+        self.function_b = Mock()
+        self.function_b.name = "FunctionB"
+        self.function_provider = Mock()
+        self.function_provider.get_all.return_value = [self.function_a, self.function_b]
+        self.function_provider.get.side_effect = {"FunctionA": self.function_a, "FunctionB": self.function_b}.get
+
+    def test_all_functions_without_debug_function_and_function_identifier(self):
+        invoke_context = InvokeContext(template_file="template_file")
+        invoke_context._function_provider = self.function_provider
+
+        self.assertEqual(list(invoke_context._get_debugged_functions()), [self.function_a, self.function_b])
+
+    def test_only_the_invoked_function(self):
+        invoke_context = InvokeContext(template_file="template_file", function_identifier="FunctionB")
+        invoke_context._function_provider = self.function_provider
+
+        self.assertEqual(invoke_context._get_debugged_functions(), [self.function_b])
+
+    def test_debug_function_takes_precedence_over_the_invoked_function(self):
+        invoke_context = InvokeContext(
+            template_file="template_file", function_identifier="FunctionB", debug_function="FunctionA"
+        )
+        invoke_context._function_provider = self.function_provider
+
+        self.assertEqual(invoke_context._get_debugged_functions(), [self.function_a])
+
+    def test_no_functions_if_debug_function_is_not_found(self):
+        invoke_context = InvokeContext(template_file="template_file", debug_function="Unknown")
+        invoke_context._function_provider = self.function_provider
+
+        self.assertEqual(invoke_context._get_debugged_functions(), [])
+
+
+class TestInvokeContext_get_debug_host_ports(TestCase):
+    def test_no_debug_host_ports(self):
+        self.assertIsNone(InvokeContext._get_debug_host_ports((5000,), None))
+        self.assertIsNone(InvokeContext._get_debug_host_ports((5000,), ()))
+
+    def test_pairs_debug_ports_with_host_ports(self):
+        debug_host_ports = InvokeContext._get_debug_host_ports((5858, 9229), (6858, 7229))
+
+        self.assertEqual(debug_host_ports, {5858: 6858, 9229: 7229})
+
+    def test_pairs_debug_port_ranges_with_host_ports(self):
+        debug_host_ports = InvokeContext._get_debug_host_ports((range(5000, 5002),), (6000, range(7000, 7001)))
+
+        self.assertEqual(debug_host_ports, {5000: 6000, 5001: 7000})
+
+    @parameterized.expand([((5000,), (6000, 6001)), ((5000, 5001), (6000,)), (None, (6000,))])
+    def test_must_raise_if_number_of_ports_differ(self, debug_ports, debug_host_ports):
+        with self.assertRaises(DebugContextException):
+            InvokeContext._get_debug_host_ports(debug_ports, debug_host_ports)
+
+
 class TestInvokeContext_get_debug_context(TestCase):
     @patch("samcli.commands.local.cli_common.invoke_context.Path")
     def test_debugger_path_not_found(self, pathlib_mock):
@@ -1039,6 +1637,8 @@ class TestInvokeContext_get_debug_context(TestCase):
             debugger_path=None,
             debug_function=None,
             container_env_vars={"env": "var"},
+            function_debug_ports=None,
+            debug_host_ports=None,
         )
 
     @patch("samcli.commands.local.cli_common.invoke_context.Path")
@@ -1120,7 +1720,13 @@ class TestInvokeContext_get_debug_context(TestCase):
         self.assertEqual(debug_context, "I am the DebugContext")
 
         debug_context_mock.assert_called_once_with(
-            debug_ports=1111, debug_args="args", debugger_path="full/path", debug_function=None, container_env_vars=None
+            debug_ports=1111,
+            debug_args="args",
+            debugger_path="full/path",
+            debug_function=None,
+            container_env_vars=None,
+            function_debug_ports=None,
+            debug_host_ports=None,
         )
         resolve_path_mock.is_dir.assert_called_once()
         pathlib_path_mock.resolve.assert_called_once_with(strict=True)
@@ -1134,5 +1740,70 @@ class TestInvokeContext_get_stacks(TestCase):
         invoke_context = InvokeContext("template_file", aws_region="my-custom-region")
         invoke_context._get_stacks()
         get_stacks_mock.assert_called_with(
-            "template_file", parameter_overrides=None, global_parameter_overrides={"AWS::Region": "my-custom-region"}
+            "template_file",
+            parameter_overrides=None,
+            global_parameter_overrides={"AWS::Region": "my-custom-region"},
+            import_values=None,
+            resource_attributes=None,
         )
+
+    @patch("samcli.commands.local.cli_common.invoke_context.SamLocalStackProvider.get_stacks")
+    def test_must_pass_import_values(self, get_stacks_mock):
+        get_stacks_mock.return_value = [Mock(), []]
+        invoke_context = InvokeContext("template_file")
+        invoke_context._import_values = {"SharedVpcId": "vpc-0123"}
+        invoke_context._get_stacks()
+        get_stacks_mock.assert_called_with(
+            "template_file",
+            parameter_overrides=None,
+            global_parameter_overrides=None,
+            import_values={"SharedVpcId": "vpc-0123"},
+            resource_attributes=None,
+        )
+
+    @patch("samcli.commands.local.cli_common.invoke_context.SamLocalStackProvider.get_stacks")
+    def test_must_pass_resource_attributes(self, get_stacks_mock):
+        get_stacks_mock.return_value = [Mock(), []]
+        invoke_context = InvokeContext("template_file")
+        invoke_context._resource_attributes = {"MyTable": {"Arn": "arn:aws:dynamodb:us-west-2:111122223333:table/t"}}
+        invoke_context._get_stacks()
+        get_stacks_mock.assert_called_with(
+            "template_file",
+            parameter_overrides=None,
+            global_parameter_overrides=None,
+            import_values=None,
+            resource_attributes={"MyTable": {"Arn": "arn:aws:dynamodb:us-west-2:111122223333:table/t"}},
+        )
+
+    @patch("samcli.commands.local.cli_common.invoke_context.SamLocalStackProvider.get_stacks")
+    @patch("samcli.commands.local.cli_common.invoke_context.prepare_hook_template")
+    def test_must_read_stacks_from_hook_template(self, prepare_hook_template_mock, get_stacks_mock):
+        prepare_hook_template_mock.return_value = "/tmp/sam-terraform-template/template.json"
+        get_stacks_mock.return_value = (["stack"], [])
+        context = InvokeContext(template_file="plan.json", hook_name="terraform")
+
+        self.assertEqual(context._get_stacks(), ["stack"])
+
+        prepare_hook_template_mock.assert_called_once_with("terraform", "plan.json")
+        self.assertEqual(get_stacks_mock.call_args[0][0], "/tmp/sam-terraform-template/template.json")
+
+    @patch("samcli.commands.local.cli_common.invoke_context.SamLocalStackProvider.get_stacks")
+    @patch("samcli.commands.local.cli_common.invoke_context.prepare_hook_template")
+    def test_must_read_template_without_hook(self, prepare_hook_template_mock, get_stacks_mock):
+        get_stacks_mock.return_value = (["stack"], [])
+        context = InvokeContext(template_file="template.yaml")
+
+        context._get_stacks()
+
+        prepare_hook_template_mock.assert_not_called()
+        self.assertEqual(get_stacks_mock.call_args[0][0], "template.yaml")
+
+    @patch("samcli.commands.local.cli_common.invoke_context.prepare_hook_template")
+    def test_must_raise_if_terraform_plan_is_invalid(self, prepare_hook_template_mock):
+        prepare_hook_template_mock.side_effect = InvalidTerraformPlanException("plan.json is not the JSON of a plan")
+        context = InvokeContext(template_file="plan.json", hook_name="terraform")
+
+        with self.assertRaises(InvokeContextException) as ex_ctx:
+            context._get_stacks()
+
+        self.assertEqual(str(ex_ctx.exception), "plan.json is not the JSON of a plan")
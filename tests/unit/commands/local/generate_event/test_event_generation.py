@@ -1,11 +1,17 @@
+import json
 import os
+import re
 
 from unittest import TestCase
 from unittest.mock import Mock, patch
 
+from click import BadParameter
+
+from samcli.commands.exceptions import UserException
 from samcli.lib.generated_sample_events import events
 from samcli.commands.local.generate_event.event_generation import ServiceCommand
 from samcli.commands.local.generate_event.event_generation import EventTypeSubCommand
+from samcli.commands.local.generate_event.event_generation import get_simple_table_values
 
 
 class TestEvents(TestCase):
@@ -73,6 +79,148 @@ class TestEvents(TestCase):
         }
         self.assertEqual(result, expected)
 
+    @patch("samcli.lib.generated_sample_events.events.renderer")
+    def test_generate_event_unquotes_number_tags(self, renderer_mock):
+        renderer_mock.render.side_effect = lambda data, values_to_sub: data
+        values_to_sub = {"topic": "mytopic", "partition": 0, "key": "recordKey", "value": "hello"}
+
+        result = events.Events().generate_event("kafka", "self-managed", values_to_sub)
+
+        self.assertIn('"partition": {{{partition}}}', result)
+        self.assertIn('"{{{topic}}}-{{{partition}}}": [', result)
+        self.assertIn('"topic": "{{{topic}}}"', result)
+        renderer_mock.render.assert_called_once_with(result, values_to_sub)
+        self.assertEqual(values_to_sub["key"], "cmVjb3JkS2V5")
+        self.assertEqual(values_to_sub["value"], "aGVsbG8=")
+
+    @patch("samcli.lib.generated_sample_events.events.renderer")
+    def test_generate_mq_events(self, renderer_mock):
+        renderer_mock.render.side_effect = lambda data, values_to_sub: re.sub(
+            r"{{{(\w+)}}}", lambda match: values_to_sub[match.group(1)], data
+        )
+        broker = "arn:aws:mq:us-east-1:123456789012:broker:MyBroker:b-1234"
+
+        activemq = json.loads(
+            events.Events().generate_event("mq", "activemq", {"broker": broker, "queue": "Orders", "body": "hello"})
+        )
+        rabbitmq = json.loads(
+            events.Events().generate_event("mq", "rabbitmq", {"broker": broker, "queue": "Orders", "body": "hello"})
+        )
+
+        self.assertEqual(activemq["eventSource"], "aws:mq")
+        self.assertEqual(activemq["eventSourceArn"], broker)
+        self.assertEqual(activemq["messages"][0]["destination"], {"physicalName": "Orders"})
+        self.assertEqual(activemq["messages"][0]["data"], "aGVsbG8=")
+        self.assertEqual(rabbitmq["eventSource"], "aws:rmq")
+        self.assertEqual(rabbitmq["eventSourceArn"], broker)
+        self.assertEqual(rabbitmq["rmqMessagesByQueue"]["Orders::/"][0]["data"], "aGVsbG8=")
+
+    @patch("samcli.lib.generated_sample_events.events.renderer")
+    def test_generate_iot_rule_event_with_payload_fields(self, renderer_mock):
+        renderer_mock.render.side_effect = lambda data, values_to_sub: re.sub(
+            r"{{{(\w+)}}}", lambda match: values_to_sub[match.group(1)], data
+        )
+
+        event = json.loads(
+            events.Events().generate_event(
+                "iot", "rule", {"topic": "devices/thermostat", "payload": {"temperature": 21.5, "topic": "ignored"}}
+            )
+        )
+
+        self.assertEqual(event, {"temperature": 21.5, "topic": "devices/thermostat"})
+
+    @patch("samcli.lib.generated_sample_events.events.renderer")
+    def test_generate_iot_rule_event_with_payload_json(self, renderer_mock):
+        renderer_mock.render.side_effect = lambda data, values_to_sub: re.sub(
+            r"{{{(\w+)}}}", lambda match: values_to_sub[match.group(1)], data
+        )
+
+        event = json.loads(
+            events.Events().generate_event(
+                "iot", "rule", {"topic": "sdk/test/python", "payload": '{"message": {"id": 1}}'}
+            )
+        )
+
+        self.assertEqual(event, {"message": {"id": 1}, "topic": "sdk/test/python"})
+
+    @patch("samcli.lib.generated_sample_events.events.renderer")
+    def test_generate_iot_button_event(self, renderer_mock):
+        renderer_mock.render.side_effect = lambda data, values_to_sub: re.sub(
+            r"{{{(\w+)}}}", lambda match: values_to_sub[match.group(1)], data
+        )
+
+        event = json.loads(
+            events.Events().generate_event(
+                "iot",
+                "button",
+                {"serial_number": "G030JF055364XVRB", "click_type": "DOUBLE", "battery_voltage": "1705mV"},
+            )
+        )
+
+        self.assertEqual(event, {"serialNumber": "G030JF055364XVRB", "clickType": "DOUBLE", "batteryVoltage": "1705mV"})
+
+
+class TestBatchRecords(TestCase):
+    def generate(self, service_name, event_type, values_to_sub, count):
+        lib = events.Events()
+        event = lib.generate_event(service_name, event_type, values_to_sub)
+        return json.loads(lib.batch_records(service_name, event, count))
+
+    def test_sqs_records_match_count(self):
+        values_to_sub = {
+            "partition": "aws",
+            "region": "us-east-1",
+            "account_id": "123456789012",
+            "queue_name": "MyQueue",
+            "body": "hello",
+        }
+
+        records = self.generate("sqs", "receive-message", values_to_sub, 5)["Records"]
+
+        self.assertEqual(len(records), 5)
+        self.assertEqual(len({record["messageId"] for record in records}), 5)
+        self.assertEqual(len({record["receiptHandle"] for record in records}), 5)
+        self.assertEqual({record["body"] for record in records}, {"hello"})
+
+    def test_kinesis_records_match_count(self):
+        values_to_sub = {
+            "region": "us-east-1",
+            "partition": "aws",
+            "sequence": "100",
+            "data": "hello",
+            "partition_key": "key",
+        }
+
+        records = self.generate("kinesis", "get-records", values_to_sub, 3)["Records"]
+
+        self.assertEqual([record["kinesis"]["sequenceNumber"] for record in records], ["100", "101", "102"])
+        self.assertEqual(
+            [record["eventID"] for record in records],
+            ["shardId-000000000000:100", "shardId-000000000000:101", "shardId-000000000000:102"],
+        )
+
+    def test_dynamodb_records_match_count(self):
+        values_to_sub = {
+            "region": "us-east-1",
+            "partition": "aws",
+            "account_id": "123456789012",
+            "table": "orders",
+            "key_name": "id",
+            "key_type": "S",
+            "key_value": "101",
+        }
+        sample = json.loads(events.Events().generate_event("dynamodb", "update", dict(values_to_sub)))
+
+        one = self.generate("dynamodb", "update", dict(values_to_sub), 1)["Records"]
+        three = self.generate("dynamodb", "update", dict(values_to_sub), 3)["Records"]
+        seven = self.generate("dynamodb", "update", dict(values_to_sub), 7)["Records"]
+
+        self.assertEqual(one, sample["Records"][:1])
+        self.assertEqual(three, sample["Records"])
+        self.assertEqual(len(seven), 7)
+        self.assertEqual(len({record["eventID"] for record in seven}), 7)
+        self.assertEqual([record["eventName"] for record in seven[3:6]], ["INSERT", "MODIFY", "REMOVE"])
+
 
 class TestServiceCommand(TestCase):
     def setUp(self):
@@ -189,3 +337,123 @@ class TestEventTypeSubCommand(TestCase):
             self.service_cmd_name, self.event_type_name, {"key": "value"}
         )
         self.assertEqual(event, event_json)
+
+    def test_must_batch_records_with_count(self):
+        self.events_lib_mock.generate_event.return_value = '{"Records": [{}]}'
+        self.events_lib_mock.batch_records.return_value = '{"Records": [{}, {}]}'
+        s = EventTypeSubCommand(self.events_lib_mock, "hello", {})
+        event = s.cmd_implementation(
+            self.events_lib_mock, self.service_cmd_name, self.event_type_name, body="hello", count=2
+        )
+        self.events_lib_mock.generate_event.assert_called_with(
+            self.service_cmd_name, self.event_type_name, {"body": "hello"}
+        )
+        self.events_lib_mock.batch_records.assert_called_once_with(self.service_cmd_name, '{"Records": [{}]}', 2)
+        self.assertEqual(event, '{"Records": [{}, {}]}')
+
+    def test_must_not_batch_records_without_count(self):
+        s = EventTypeSubCommand(self.events_lib_mock, "hello", {})
+        s.cmd_implementation(self.events_lib_mock, self.service_cmd_name, self.event_type_name, count=None)
+        self.events_lib_mock.batch_records.assert_not_called()
+
+    def test_must_add_count_option_to_batch_events(self):
+        s = EventTypeSubCommand(events.Events(), "sqs", events.Events().event_mapping["sqs"])
+        cmd = s.get_command(None, "receive-message")
+        self.assertIn("count", [param.name for param in cmd.params])
+
+        s = EventTypeSubCommand(events.Events(), "s3", events.Events().event_mapping["s3"])
+        cmd = s.get_command(None, "put")
+        self.assertNotIn("count", [param.name for param in cmd.params])
+
+    def test_must_parse_payload_of_iot_rule_as_json_object(self):
+        s = EventTypeSubCommand(events.Events(), "iot", events.Events().event_mapping["iot"])
+        cmd = s.get_command(None, "rule")
+        payload = [param for param in cmd.params if param.name == "payload"][0]
+
+        self.assertEqual(payload.type_cast_value(None, '{"message": "hi"}'), {"message": "hi"})
+        with self.assertRaises(BadParameter):
+            payload.type_cast_value(None, '["message"]')
+
+    @patch("samcli.commands.local.generate_event.event_generation.get_simple_table_values")
+    def test_must_substitute_simple_table_values(self, get_simple_table_values_mock):
+        get_simple_table_values_mock.return_value = {"table": "MyTable", "key_name": "id"}
+        s = EventTypeSubCommand(self.events_lib_mock, "hello", {})
+        s.cmd_implementation(
+            self.events_lib_mock,
+            self.service_cmd_name,
+            self.event_type_name,
+            region="us-east-1",
+            template_file="template.yaml",
+            simple_table="Table",
+        )
+        get_simple_table_values_mock.assert_called_once_with("template.yaml", "Table")
+        self.events_lib_mock.generate_event.assert_called_with(
+            self.service_cmd_name,
+            self.event_type_name,
+            {"region": "us-east-1", "table": "MyTable", "key_name": "id"},
+        )
+
+    @patch("samcli.commands.local.generate_event.event_generation.get_simple_table_values")
+    def test_must_not_read_template_without_simple_table(self, get_simple_table_values_mock):
+        s = EventTypeSubCommand(self.events_lib_mock, "hello", {})
+        s.cmd_implementation(
+            self.events_lib_mock, self.service_cmd_name, self.event_type_name, template_file="template.yaml"
+        )
+        get_simple_table_values_mock.assert_not_called()
+        self.events_lib_mock.generate_event.assert_called_with(self.service_cmd_name, self.event_type_name, {})
+
+
+class TestGetSimpleTableValues(TestCase):
+    @patch("samcli.commands.local.generate_event.event_generation.get_template_data")
+    def test_must_use_default_primary_key(self, get_template_data_mock):
+        get_template_data_mock.return_value = {"Resources": {"Table": {"Type": "AWS::Serverless::SimpleTable"}}}
+        result = get_simple_table_values("template.yaml", "Table")
+        self.assertEqual(result, {"table": "Table", "key_name": "id", "key_type": "S", "key_value": "101"})
+        get_template_data_mock.assert_called_once_with("template.yaml")
+
+    @patch("samcli.commands.local.generate_event.event_generation.get_template_data")
+    def test_must_use_primary_key_and_table_name(self, get_template_data_mock):
+        get_template_data_mock.return_value = {
+            "Resources": {
+                "Table": {
+                    "Type": "AWS::Serverless::SimpleTable",
+                    "Properties": {"TableName": "orders", "PrimaryKey": {"Name": "orderId", "Type": "Number"}},
+                }
+            }
+        }
+        result = get_simple_table_values("template.yaml", "Table")
+        self.assertEqual(result, {"table": "orders", "key_name": "orderId", "key_type": "N", "key_value": "101"})
+
+    @patch("samcli.commands.local.generate_event.event_generation.get_template_data")
+    def test_must_fail_when_resource_is_not_simple_table(self, get_template_data_mock):
+        get_template_data_mock.return_value = {"Resources": {"Table": {"Type": "AWS::DynamoDB::Table"}}}
+        with self.assertRaises(UserException):
+            get_simple_table_values("template.yaml", "Table")
+
+    @patch("samcli.commands.local.generate_event.event_generation.get_template_data")
+    def test_must_fail_on_unknown_key_type(self, get_template_data_mock):
+        get_template_data_mock.return_value = {
+            "Resources": {
+                "Table": {"Type": "AWS::Serverless::SimpleTable", "Properties": {"PrimaryKey": {"Type": "Map"}}}
+            }
+        }
+        with self.assertRaises(UserException):
+            get_simple_table_values("template.yaml", "Table")
+
+    def test_dynamodb_update_event_uses_primary_key(self):
+        event = events.Events().generate_event(
+            "dynamodb",
+            "update",
+            {
+                "region": "us-east-1",
+                "partition": "aws",
+                "account_id": "123456789012",
+                "table": "orders",
+                "key_name": "orderId",
+                "key_type": "S",
+                "key_value": "101",
+            },
+        )
+        record = json.loads(event)["Records"][0]
+        self.assertEqual(record["dynamodb"]["Keys"], {"orderId": {"S": "101"}})
+        self.assertEqual(record["dynamodb"]["NewImage"]["orderId"], {"S": "101"})
@@ -2,19 +2,30 @@
 Tests Local Invoke CLI
 """
 
+import json
 from unittest import TestCase
-from unittest.mock import patch, Mock
+from unittest.mock import patch, Mock, ANY
 from parameterized import parameterized, param
 
 from samcli.local.docker.exceptions import ContainerNotStartableException
-from samcli.local.lambdafn.exceptions import FunctionNotFound
+from samcli.local.lambdafn.exceptions import FunctionNotFound, UnsupportedCodeLocation
 from samcli.lib.providers.exceptions import InvalidLayerReference
 from samcli.commands.validate.lib.exceptions import InvalidSamDocumentException
 from samcli.commands.exceptions import UserException
-from samcli.commands.local.invoke.cli import do_cli as invoke_cli, _get_event as invoke_cli_get_event
+from samcli.commands.local.cli_common.user_exceptions import FunctionErrorException
+from samcli.commands.local.invoke.cli import (
+    do_cli as invoke_cli,
+    _get_event as invoke_cli_get_event,
+    _get_event_from_clipboard as invoke_cli_get_event_from_clipboard,
+    _run_after_invoke as invoke_cli_run_after_invoke,
+    _get_function_error as invoke_cli_get_function_error,
+    _format_function_error as invoke_cli_format_function_error,
+)
 from samcli.commands.local.lib.exceptions import OverridesNotWellDefinedError, InvalidIntermediateImageError
 from samcli.local.docker.manager import DockerImagePullFailedException
 from samcli.local.docker.lambda_debug_settings import DebuggingNotSupported
+from samcli.local.stepfunctions.exceptions import StateMachineDefinitionError, StateMachineExecutionFailed
+from samcli.local.stepfunctions.state_machine import StateMachine
 
 
 STDIN_FILE_NAME = "-"
@@ -22,6 +33,12 @@ STDIN_FILE_NAME = "-"
 
 class TestCli(TestCase):
     def setUp(self):
+        get_state_machine_patcher = patch(
+            "samcli.local.stepfunctions.state_machine.get_state_machine", return_value=None
+        )
+        self.get_state_machine_mock = get_state_machine_patcher.start()
+        self.addCleanup(get_state_machine_patcher.stop)
+
         self.function_id = "id"
         self.template = "template"
         self.eventfile = "eventfile"
@@ -43,6 +60,41 @@ class TestCli(TestCase):
         self.profile = "profile"
         self.container_host = "localhost"
         self.container_host_interface = "127.0.0.1"
+        self.show_env = False
+        self.debug_port_host = ()
+        self.runtime = "python3.8"
+        self.event_clipboard = False
+        self.after_invoke = None
+        self.container_hostname = "sam-local"
+        self.shm_size = "1g"
+        self.cpu_proportional = False
+        self.tz = None
+        self.use_build_artifacts = False
+        self.buffered_output = False
+        self.container_labels_from_file = "labels.yaml"
+        self.env_file = ".env"
+        self.no_memory_limit = False
+        self.aws_endpoint_url = "http://localstack:4566"
+        self.add_host = (("db.local", "192.168.1.10"),)
+        self.import_values = "exports.json"
+        self.container_labels = (("team", "serverless"),)
+        self.shutdown_timeout = 5
+        self.hook_name = "terraform"
+        self.resource_attributes = "attributes.json"
+        self.cloudwatch_style_logs = True
+        self.container_entrypoint_override = ["/opt/wrapper", "/var/rapid/aws-lambda-rie"]
+        self.runtime_image_override = (("python3.8", "registry.example.com/lambda-python3.8:1.0"),)
+        self.force_linux_paths = True
+        self.no_build_artifacts = True
+        self.stdout_file = "response.json"
+        self.stderr_file = "logs.txt"
+        self.random_seed = "42"
+        self.random_seed_env_var = "TEST_SEED"
+        self.expect_log = ()
+        self.x_ray_trace_id = None
+        self.handled_error_exit_code = None
+        self.unhandled_error_exit_code = None
+        self.timeout_exit_code = None
 
     @patch("samcli.commands.local.cli_common.invoke_context.InvokeContext")
     @patch("samcli.commands.local.invoke.cli._get_event")
@@ -79,6 +131,41 @@ class TestCli(TestCase):
             shutdown=self.shutdown,
             container_host=self.container_host,
             container_host_interface=self.container_host_interface,
+            show_env=self.show_env,
+            debug_port_host=self.debug_port_host,
+            runtime=self.runtime,
+            event_clipboard=self.event_clipboard,
+            after_invoke=self.after_invoke,
+            container_hostname=self.container_hostname,
+            shm_size=self.shm_size,
+            cpu_proportional=self.cpu_proportional,
+            tz=self.tz,
+            use_build_artifacts=self.use_build_artifacts,
+            buffered_output=self.buffered_output,
+            container_labels_from_file=self.container_labels_from_file,
+            env_file=self.env_file,
+            no_memory_limit=self.no_memory_limit,
+            aws_endpoint_url=self.aws_endpoint_url,
+            add_host=self.add_host,
+            import_values=self.import_values,
+            container_labels=self.container_labels,
+            shutdown_timeout=self.shutdown_timeout,
+            hook_name=self.hook_name,
+            resource_attributes=self.resource_attributes,
+            cloudwatch_style_logs=self.cloudwatch_style_logs,
+            container_entrypoint_override=self.container_entrypoint_override,
+            runtime_image_override=self.runtime_image_override,
+            force_linux_paths=self.force_linux_paths,
+            no_build_artifacts=self.no_build_artifacts,
+            stdout_file=self.stdout_file,
+            stderr_file=self.stderr_file,
+            random_seed=self.random_seed,
+            random_seed_env_var=self.random_seed_env_var,
+            expect_log=self.expect_log,
+            x_ray_trace_id=self.x_ray_trace_id,
+            handled_error_exit_code=self.handled_error_exit_code,
+            unhandled_error_exit_code=self.unhandled_error_exit_code,
+            timeout_exit_code=self.timeout_exit_code,
         )
 
         InvokeContextMock.assert_called_with(
@@ -101,13 +188,451 @@ class TestCli(TestCase):
             aws_profile=self.profile,
             container_host=self.container_host,
             container_host_interface=self.container_host_interface,
+            show_env=self.show_env,
+            runtime_override=self.runtime,
+            debug_host_ports=self.debug_port_host,
+            container_hostname=self.container_hostname,
+            shm_size=self.shm_size,
+            cpu_proportional=self.cpu_proportional,
+            timezone=self.tz,
+            use_build_artifacts=self.use_build_artifacts,
+            buffered_output=self.buffered_output,
+            container_labels_file=self.container_labels_from_file,
+            env_file=self.env_file,
+            no_memory_limit=self.no_memory_limit,
+            aws_endpoint_url=self.aws_endpoint_url,
+            add_host=self.add_host,
+            import_values_file=self.import_values,
+            container_labels=self.container_labels,
+            shutdown_timeout=self.shutdown_timeout,
+            hook_name=self.hook_name,
+            resource_attributes_file=self.resource_attributes,
+            cloudwatch_style_logs=self.cloudwatch_style_logs,
+            container_entrypoint=self.container_entrypoint_override,
+            runtime_image_override=self.runtime_image_override,
+            force_linux_paths=self.force_linux_paths,
+            no_build_artifacts=self.no_build_artifacts,
+            stdout_file=self.stdout_file,
+            stderr_file=self.stderr_file,
+            random_seed=self.random_seed,
+            random_seed_env_var=self.random_seed_env_var,
         )
 
         context_mock.local_lambda_runner.invoke.assert_called_with(
-            context_mock.function_identifier, event=event_data, stdout=context_mock.stdout, stderr=context_mock.stderr
+            context_mock.function_identifier,
+            event=event_data,
+            stdout=context_mock.stdout,
+            stderr=context_mock.stderr,
+            trace_id=None,
         )
         get_event_mock.assert_called_with(self.eventfile)
 
+    @patch("samcli.commands.local.lib.batch_item_failures.report_batch_item_failures")
+    @patch("samcli.commands.local.cli_common.invoke_context.InvokeContext")
+    @patch("samcli.commands.local.invoke.cli._get_event")
+    def test_cli_must_report_batch_item_failures_of_batch_events(
+        self, get_event_mock, InvokeContextMock, report_batch_item_failures_mock
+    ):
+        event_data = json.dumps(
+            {"Records": [{"eventSource": "aws:sqs", "messageId": "1"}, {"eventSource": "aws:sqs", "messageId": "2"}]}
+        )
+        get_event_mock.return_value = event_data
+        response = b'{"batchItemFailures": [{"itemIdentifier": "2"}]}'
+
+        context_mock = Mock()
+        context_mock.local_lambda_runner.invoke.side_effect = lambda *args, **kwargs: kwargs["stdout"].write(response)
+        InvokeContextMock.return_value.__enter__.return_value = context_mock
+
+        invoke_cli(
+            ctx=Mock(),
+            function_identifier=self.function_id,
+            template=self.template,
+            event=self.eventfile,
+            no_event=self.no_event,
+            env_vars=self.env_vars,
+            debug_port=self.debug_ports,
+            debug_args=self.debug_args,
+            debugger_path=self.debugger_path,
+            container_env_vars=self.container_env_vars,
+            docker_volume_basedir=self.docker_volume_basedir,
+            docker_network=self.docker_network,
+            log_file=self.log_file,
+            skip_pull_image=self.skip_pull_image,
+            parameter_overrides=self.parameter_overrides,
+            layer_cache_basedir=self.layer_cache_basedir,
+            force_image_build=self.force_image_build,
+            shutdown=self.shutdown,
+            container_host=self.container_host,
+            container_host_interface=self.container_host_interface,
+            show_env=self.show_env,
+            debug_port_host=self.debug_port_host,
+            runtime=self.runtime,
+            event_clipboard=self.event_clipboard,
+            after_invoke=self.after_invoke,
+            container_hostname=self.container_hostname,
+            shm_size=self.shm_size,
+            cpu_proportional=self.cpu_proportional,
+            tz=self.tz,
+            use_build_artifacts=self.use_build_artifacts,
+            buffered_output=self.buffered_output,
+            container_labels_from_file=self.container_labels_from_file,
+            env_file=self.env_file,
+            no_memory_limit=self.no_memory_limit,
+            aws_endpoint_url=self.aws_endpoint_url,
+            add_host=self.add_host,
+            import_values=self.import_values,
+            container_labels=self.container_labels,
+            shutdown_timeout=self.shutdown_timeout,
+            hook_name=self.hook_name,
+            resource_attributes=self.resource_attributes,
+            cloudwatch_style_logs=self.cloudwatch_style_logs,
+            container_entrypoint_override=self.container_entrypoint_override,
+            runtime_image_override=self.runtime_image_override,
+            force_linux_paths=self.force_linux_paths,
+            no_build_artifacts=self.no_build_artifacts,
+            stdout_file=self.stdout_file,
+            stderr_file=self.stderr_file,
+            random_seed=self.random_seed,
+            random_seed_env_var=self.random_seed_env_var,
+            expect_log=self.expect_log,
+            x_ray_trace_id=self.x_ray_trace_id,
+            handled_error_exit_code=self.handled_error_exit_code,
+            unhandled_error_exit_code=self.unhandled_error_exit_code,
+            timeout_exit_code=self.timeout_exit_code,
+        )
+
+        context_mock.stdout.write.assert_called_with(response)
+        report_batch_item_failures_mock.assert_called_with("aws:sqs", ["1", "2"], response.decode("utf-8"))
+
+    @patch("samcli.commands.local.invoke.cli._run_after_invoke")
+    @patch("samcli.commands.local.cli_common.invoke_context.InvokeContext")
+    @patch("samcli.commands.local.invoke.cli._get_event")
+    def test_cli_must_run_after_invoke_command_with_response(
+        self, get_event_mock, InvokeContextMock, run_after_invoke_mock
+    ):
+        get_event_mock.return_value = "{}"
+        response = b'{"statusCode": 200}'
+
+        context_mock = Mock()
+        context_mock.local_lambda_runner.invoke.side_effect = lambda *args, **kwargs: kwargs["stdout"].write(response)
+        InvokeContextMock.return_value.__enter__.return_value = context_mock
+
+        invoke_cli(
+            ctx=Mock(),
+            function_identifier=self.function_id,
+            template=self.template,
+            event=self.eventfile,
+            no_event=self.no_event,
+            env_vars=self.env_vars,
+            debug_port=self.debug_ports,
+            debug_args=self.debug_args,
+            debugger_path=self.debugger_path,
+            container_env_vars=self.container_env_vars,
+            docker_volume_basedir=self.docker_volume_basedir,
+            docker_network=self.docker_network,
+            log_file=self.log_file,
+            skip_pull_image=self.skip_pull_image,
+            parameter_overrides=self.parameter_overrides,
+            layer_cache_basedir=self.layer_cache_basedir,
+            force_image_build=self.force_image_build,
+            shutdown=self.shutdown,
+            container_host=self.container_host,
+            container_host_interface=self.container_host_interface,
+            show_env=self.show_env,
+            debug_port_host=self.debug_port_host,
+            runtime=self.runtime,
+            event_clipboard=self.event_clipboard,
+            after_invoke="./verify.sh",
+            container_hostname=self.container_hostname,
+            shm_size=self.shm_size,
+            cpu_proportional=self.cpu_proportional,
+            tz=self.tz,
+            use_build_artifacts=self.use_build_artifacts,
+            buffered_output=self.buffered_output,
+            container_labels_from_file=self.container_labels_from_file,
+            env_file=self.env_file,
+            no_memory_limit=self.no_memory_limit,
+            aws_endpoint_url=self.aws_endpoint_url,
+            add_host=self.add_host,
+            import_values=self.import_values,
+            container_labels=self.container_labels,
+            shutdown_timeout=self.shutdown_timeout,
+            hook_name=self.hook_name,
+            resource_attributes=self.resource_attributes,
+            cloudwatch_style_logs=self.cloudwatch_style_logs,
+            container_entrypoint_override=self.container_entrypoint_override,
+            runtime_image_override=self.runtime_image_override,
+            force_linux_paths=self.force_linux_paths,
+            no_build_artifacts=self.no_build_artifacts,
+            stdout_file=self.stdout_file,
+            stderr_file=self.stderr_file,
+            random_seed=self.random_seed,
+            random_seed_env_var=self.random_seed_env_var,
+            expect_log=self.expect_log,
+            x_ray_trace_id=self.x_ray_trace_id,
+            handled_error_exit_code=self.handled_error_exit_code,
+            unhandled_error_exit_code=self.unhandled_error_exit_code,
+            timeout_exit_code=self.timeout_exit_code,
+        )
+
+        context_mock.stdout.write.assert_called_with(response)
+        run_after_invoke_mock.assert_called_with("./verify.sh", response.decode("utf-8"))
+
+    @patch("samcli.commands.local.cli_common.invoke_context.InvokeContext")
+    @patch("samcli.commands.local.invoke.cli._get_event")
+    def test_cli_must_fail_when_logs_do_not_contain_expected_text(self, get_event_mock, InvokeContextMock):
+        get_event_mock.return_value = "{}"
+        logs = b"START RequestId: 1234\nprocessed order 42\nEND RequestId: 1234\n"
+
+        context_mock = Mock()
+        context_mock.local_lambda_runner.invoke.side_effect = lambda *args, **kwargs: kwargs["stderr"].write(logs)
+        InvokeContextMock.return_value.__enter__.return_value = context_mock
+
+        with self.assertRaises(UserException) as ex_ctx:
+            invoke_cli(
+                ctx=Mock(),
+                function_identifier=self.function_id,
+                template=self.template,
+                event=self.eventfile,
+                no_event=self.no_event,
+                env_vars=self.env_vars,
+                debug_port=self.debug_ports,
+                debug_args=self.debug_args,
+                debugger_path=self.debugger_path,
+                container_env_vars=self.container_env_vars,
+                docker_volume_basedir=self.docker_volume_basedir,
+                docker_network=self.docker_network,
+                log_file=self.log_file,
+                skip_pull_image=self.skip_pull_image,
+                parameter_overrides=self.parameter_overrides,
+                layer_cache_basedir=self.layer_cache_basedir,
+                force_image_build=self.force_image_build,
+                shutdown=self.shutdown,
+                container_host=self.container_host,
+                container_host_interface=self.container_host_interface,
+                show_env=self.show_env,
+                debug_port_host=self.debug_port_host,
+                runtime=self.runtime,
+                event_clipboard=self.event_clipboard,
+                after_invoke=self.after_invoke,
+                container_hostname=self.container_hostname,
+                shm_size=self.shm_size,
+                cpu_proportional=self.cpu_proportional,
+                tz=self.tz,
+                use_build_artifacts=self.use_build_artifacts,
+                buffered_output=self.buffered_output,
+                container_labels_from_file=self.container_labels_from_file,
+                env_file=self.env_file,
+                no_memory_limit=self.no_memory_limit,
+                aws_endpoint_url=self.aws_endpoint_url,
+                add_host=self.add_host,
+                import_values=self.import_values,
+                container_labels=self.container_labels,
+                shutdown_timeout=self.shutdown_timeout,
+                hook_name=self.hook_name,
+                resource_attributes=self.resource_attributes,
+                cloudwatch_style_logs=self.cloudwatch_style_logs,
+                container_entrypoint_override=self.container_entrypoint_override,
+                runtime_image_override=self.runtime_image_override,
+                force_linux_paths=self.force_linux_paths,
+                no_build_artifacts=self.no_build_artifacts,
+                stdout_file=self.stdout_file,
+                stderr_file=self.stderr_file,
+                random_seed=self.random_seed,
+                random_seed_env_var=self.random_seed_env_var,
+                expect_log=("processed order 42", "order shipped"),
+                x_ray_trace_id=self.x_ray_trace_id,
+                handled_error_exit_code=self.handled_error_exit_code,
+                unhandled_error_exit_code=self.unhandled_error_exit_code,
+                timeout_exit_code=self.timeout_exit_code,
+            )
+
+        self.assertEqual(
+            str(ex_ctx.exception), "Logs of the function do not contain the expected text: 'order shipped'"
+        )
+        context_mock.stderr.write.assert_called_with(logs)
+
+    @parameterized.expand(
+        [
+            ("1-5759e988-bd862e3fe1be46a994272793", "Root=1-5759e988-bd862e3fe1be46a994272793;Sampled=1"),
+            (
+                "Root=1-5759e988-bd862e3fe1be46a994272793;Sampled=0",
+                "Root=1-5759e988-bd862e3fe1be46a994272793;Sampled=0",
+            ),
+        ]
+    )
+    @patch("samcli.commands.local.cli_common.invoke_context.InvokeContext")
+    @patch("samcli.commands.local.invoke.cli._get_event")
+    def test_cli_must_invoke_with_x_ray_trace_id(
+        self, x_ray_trace_id, expected_trace_id, get_event_mock, InvokeContextMock
+    ):
+        get_event_mock.return_value = "{}"
+
+        context_mock = Mock()
+        InvokeContextMock.return_value.__enter__.return_value = context_mock
+
+        invoke_cli(
+            ctx=Mock(),
+            function_identifier=self.function_id,
+            template=self.template,
+            event=self.eventfile,
+            no_event=self.no_event,
+            env_vars=self.env_vars,
+            debug_port=self.debug_ports,
+            debug_args=self.debug_args,
+            debugger_path=self.debugger_path,
+            container_env_vars=self.container_env_vars,
+            docker_volume_basedir=self.docker_volume_basedir,
+            docker_network=self.docker_network,
+            log_file=self.log_file,
+            skip_pull_image=self.skip_pull_image,
+            parameter_overrides=self.parameter_overrides,
+            layer_cache_basedir=self.layer_cache_basedir,
+            force_image_build=self.force_image_build,
+            shutdown=self.shutdown,
+            container_host=self.container_host,
+            container_host_interface=self.container_host_interface,
+            show_env=self.show_env,
+            debug_port_host=self.debug_port_host,
+            runtime=self.runtime,
+            event_clipboard=self.event_clipboard,
+            after_invoke=self.after_invoke,
+            container_hostname=self.container_hostname,
+            shm_size=self.shm_size,
+            cpu_proportional=self.cpu_proportional,
+            tz=self.tz,
+            use_build_artifacts=self.use_build_artifacts,
+            buffered_output=self.buffered_output,
+            container_labels_from_file=self.container_labels_from_file,
+            env_file=self.env_file,
+            no_memory_limit=self.no_memory_limit,
+            aws_endpoint_url=self.aws_endpoint_url,
+            add_host=self.add_host,
+            import_values=self.import_values,
+            container_labels=self.container_labels,
+            shutdown_timeout=self.shutdown_timeout,
+            hook_name=self.hook_name,
+            resource_attributes=self.resource_attributes,
+            cloudwatch_style_logs=self.cloudwatch_style_logs,
+            container_entrypoint_override=self.container_entrypoint_override,
+            runtime_image_override=self.runtime_image_override,
+            force_linux_paths=self.force_linux_paths,
+            no_build_artifacts=self.no_build_artifacts,
+            stdout_file=self.stdout_file,
+            stderr_file=self.stderr_file,
+            random_seed=self.random_seed,
+            random_seed_env_var=self.random_seed_env_var,
+            expect_log=self.expect_log,
+            x_ray_trace_id=x_ray_trace_id,
+            handled_error_exit_code=self.handled_error_exit_code,
+            unhandled_error_exit_code=self.unhandled_error_exit_code,
+            timeout_exit_code=self.timeout_exit_code,
+        )
+
+        context_mock.local_lambda_runner.invoke.assert_called_with(
+            context_mock.function_identifier,
+            event="{}",
+            stdout=context_mock.stdout,
+            stderr=context_mock.stderr,
+            trace_id=expected_trace_id,
+        )
+
+    @parameterized.expand(
+        [
+            (b'{"errorMessage": "Exited", "errorType": "Runtime.ExitError"}', 3, b"Runtime.ExitError: Exited\n"),
+            (
+                b'{"errorMessage": "division by zero", "errorType": "ZeroDivisionError", '
+                b'"stackTrace": [["/var/task/app.py", 2, "handler", "return 1 / 0"]]}',
+                3,
+                b'ZeroDivisionError: division by zero\n    File "/var/task/app.py", line 2, in handler: return 1 / 0\n',
+            ),
+            (b'{"errorMessage": "Bad order", "errorType": "ValueError"}', None, b"ValueError: Bad order\n"),
+            (b'{"statusCode": 200}', None, None),
+            # An invoke aborted with Ctrl+C leaves no response, but did not time out
+            (b"", None, None),
+        ]
+    )
+    @patch("samcli.commands.local.cli_common.invoke_context.InvokeContext")
+    @patch("samcli.commands.local.invoke.cli._get_event")
+    def test_cli_must_exit_with_code_of_function_error(
+        self, response, expected_exit_code, expected_stderr, get_event_mock, InvokeContextMock
+    ):
+        get_event_mock.return_value = "{}"
+
+        context_mock = Mock()
+        context_mock.local_lambda_runner.invoke.side_effect = lambda *args, **kwargs: kwargs["stdout"].write(response)
+        InvokeContextMock.return_value.__enter__.return_value = context_mock
+
+        exit_code = None
+        try:
+            invoke_cli(
+                ctx=Mock(),
+                function_identifier=self.function_id,
+                template=self.template,
+                event=self.eventfile,
+                no_event=self.no_event,
+                env_vars=self.env_vars,
+                debug_port=self.debug_ports,
+                debug_args=self.debug_args,
+                debugger_path=self.debugger_path,
+                container_env_vars=self.container_env_vars,
+                docker_volume_basedir=self.docker_volume_basedir,
+                docker_network=self.docker_network,
+                log_file=self.log_file,
+                skip_pull_image=self.skip_pull_image,
+                parameter_overrides=self.parameter_overrides,
+                layer_cache_basedir=self.layer_cache_basedir,
+                force_image_build=self.force_image_build,
+                shutdown=self.shutdown,
+                container_host=self.container_host,
+                container_host_interface=self.container_host_interface,
+                show_env=self.show_env,
+                debug_port_host=self.debug_port_host,
+                runtime=self.runtime,
+                event_clipboard=self.event_clipboard,
+                after_invoke=self.after_invoke,
+                container_hostname=self.container_hostname,
+                shm_size=self.shm_size,
+                cpu_proportional=self.cpu_proportional,
+                tz=self.tz,
+                use_build_artifacts=self.use_build_artifacts,
+                buffered_output=self.buffered_output,
+                container_labels_from_file=self.container_labels_from_file,
+                env_file=self.env_file,
+                no_memory_limit=self.no_memory_limit,
+                aws_endpoint_url=self.aws_endpoint_url,
+                add_host=self.add_host,
+                import_values=self.import_values,
+                container_labels=self.container_labels,
+                shutdown_timeout=self.shutdown_timeout,
+                hook_name=self.hook_name,
+                resource_attributes=self.resource_attributes,
+                cloudwatch_style_logs=self.cloudwatch_style_logs,
+                container_entrypoint_override=self.container_entrypoint_override,
+                runtime_image_override=self.runtime_image_override,
+                force_linux_paths=self.force_linux_paths,
+                no_build_artifacts=self.no_build_artifacts,
+                stdout_file=self.stdout_file,
+                stderr_file=self.stderr_file,
+                random_seed=self.random_seed,
+                random_seed_env_var=self.random_seed_env_var,
+                expect_log=self.expect_log,
+                x_ray_trace_id=self.x_ray_trace_id,
+                handled_error_exit_code=None,
+                unhandled_error_exit_code=3,
+                timeout_exit_code=4,
+            )
+        except FunctionErrorException as ex:
+            self.assertEqual(str(ex), "Function failed with an unhandled error")
+            exit_code = ex.exit_code
+
+        self.assertEqual(exit_code, expected_exit_code)
+        context_mock.stdout.write.assert_called_with(response)
+        if expected_stderr:
+            context_mock.stderr.write.assert_called_with(expected_stderr)
+        else:
+            context_mock.stderr.write.assert_not_called()
+
     @patch("samcli.commands.local.cli_common.invoke_context.InvokeContext")
     @patch("samcli.commands.local.invoke.cli._get_event")
     def test_cli_must_invoke_with_no_event(self, get_event_mock, InvokeContextMock):
@@ -141,6 +666,41 @@ class TestCli(TestCase):
             shutdown=self.shutdown,
             container_host=self.container_host,
             container_host_interface=self.container_host_interface,
+            show_env=self.show_env,
+            debug_port_host=self.debug_port_host,
+            runtime=self.runtime,
+            event_clipboard=self.event_clipboard,
+            after_invoke=self.after_invoke,
+            container_hostname=self.container_hostname,
+            shm_size=self.shm_size,
+            cpu_proportional=self.cpu_proportional,
+            tz=self.tz,
+            use_build_artifacts=self.use_build_artifacts,
+            buffered_output=self.buffered_output,
+            container_labels_from_file=self.container_labels_from_file,
+            env_file=self.env_file,
+            no_memory_limit=self.no_memory_limit,
+            aws_endpoint_url=self.aws_endpoint_url,
+            add_host=self.add_host,
+            import_values=self.import_values,
+            container_labels=self.container_labels,
+            shutdown_timeout=self.shutdown_timeout,
+            hook_name=self.hook_name,
+            resource_attributes=self.resource_attributes,
+            cloudwatch_style_logs=self.cloudwatch_style_logs,
+            container_entrypoint_override=self.container_entrypoint_override,
+            runtime_image_override=self.runtime_image_override,
+            force_linux_paths=self.force_linux_paths,
+            no_build_artifacts=self.no_build_artifacts,
+            stdout_file=self.stdout_file,
+            stderr_file=self.stderr_file,
+            random_seed=self.random_seed,
+            random_seed_env_var=self.random_seed_env_var,
+            expect_log=self.expect_log,
+            x_ray_trace_id=self.x_ray_trace_id,
+            handled_error_exit_code=self.handled_error_exit_code,
+            unhandled_error_exit_code=self.unhandled_error_exit_code,
+            timeout_exit_code=self.timeout_exit_code,
         )
 
         InvokeContextMock.assert_called_with(
@@ -163,16 +723,147 @@ class TestCli(TestCase):
             aws_profile=self.profile,
             container_host=self.container_host,
             container_host_interface=self.container_host_interface,
+            show_env=self.show_env,
+            runtime_override=self.runtime,
+            debug_host_ports=self.debug_port_host,
+            container_hostname=self.container_hostname,
+            shm_size=self.shm_size,
+            cpu_proportional=self.cpu_proportional,
+            timezone=self.tz,
+            use_build_artifacts=self.use_build_artifacts,
+            buffered_output=self.buffered_output,
+            container_labels_file=self.container_labels_from_file,
+            env_file=self.env_file,
+            no_memory_limit=self.no_memory_limit,
+            aws_endpoint_url=self.aws_endpoint_url,
+            add_host=self.add_host,
+            import_values_file=self.import_values,
+            container_labels=self.container_labels,
+            shutdown_timeout=self.shutdown_timeout,
+            hook_name=self.hook_name,
+            resource_attributes_file=self.resource_attributes,
+            cloudwatch_style_logs=self.cloudwatch_style_logs,
+            container_entrypoint=self.container_entrypoint_override,
+            runtime_image_override=self.runtime_image_override,
+            force_linux_paths=self.force_linux_paths,
+            no_build_artifacts=self.no_build_artifacts,
+            stdout_file=self.stdout_file,
+            stderr_file=self.stderr_file,
+            random_seed=self.random_seed,
+            random_seed_env_var=self.random_seed_env_var,
         )
 
         get_event_mock.assert_not_called()
         context_mock.local_lambda_runner.invoke.assert_called_with(
-            context_mock.function_identifier, event="{}", stdout=context_mock.stdout, stderr=context_mock.stderr
+            context_mock.function_identifier,
+            event="{}",
+            stdout=context_mock.stdout,
+            stderr=context_mock.stderr,
+            trace_id=None,
         )
 
+    @parameterized.expand(
+        [
+            param(None, None),
+            param(
+                StateMachineExecutionFailed("OrderNotFound", "Order 42 does not exist"),
+                "Execution failed with error OrderNotFound: Order 42 does not exist",
+            ),
+            param(
+                StateMachineDefinitionError("Map state Process can't be executed locally"),
+                "Map state Process can't be executed locally",
+            ),
+        ]
+    )
+    @patch("samcli.local.stepfunctions.executor.LocalStateMachineExecutor")
+    @patch("samcli.commands.local.cli_common.invoke_context.InvokeContext")
+    @patch("samcli.commands.local.invoke.cli._get_event")
+    def test_cli_must_execute_state_machine(
+        self, side_effect_exception, expected_exception_message, get_event_mock, InvokeContextMock, ExecutorMock
+    ):
+        get_event_mock.return_value = '{"orderId": 42}'
+        ctx_mock = Mock()
+        context_mock = Mock()
+        InvokeContextMock.return_value.__enter__.return_value = context_mock
+        state_machine = StateMachine(name="OrderStateMachine", definition={"StartAt": "A", "States": {}})
+        self.get_state_machine_mock.return_value = state_machine
+        ExecutorMock.return_value.execute.side_effect = side_effect_exception
+        ExecutorMock.return_value.execute.return_value = {"status": "shipped"}
+
+        exception_message = None
+        try:
+            invoke_cli(
+                ctx=ctx_mock,
+                function_identifier=self.function_id,
+                template=self.template,
+                event=self.eventfile,
+                no_event=self.no_event,
+                env_vars=self.env_vars,
+                debug_port=self.debug_ports,
+                debug_args=self.debug_args,
+                debugger_path=self.debugger_path,
+                container_env_vars=self.container_env_vars,
+                docker_volume_basedir=self.docker_volume_basedir,
+                docker_network=self.docker_network,
+                log_file=self.log_file,
+                skip_pull_image=self.skip_pull_image,
+                parameter_overrides=self.parameter_overrides,
+                layer_cache_basedir=self.layer_cache_basedir,
+                force_image_build=self.force_image_build,
+                shutdown=self.shutdown,
+                container_host=self.container_host,
+                container_host_interface=self.container_host_interface,
+                show_env=self.show_env,
+                debug_port_host=self.debug_port_host,
+                runtime=self.runtime,
+                event_clipboard=self.event_clipboard,
+                after_invoke=self.after_invoke,
+                container_hostname=self.container_hostname,
+                shm_size=self.shm_size,
+                cpu_proportional=self.cpu_proportional,
+                tz=self.tz,
+                use_build_artifacts=self.use_build_artifacts,
+                buffered_output=self.buffered_output,
+                container_labels_from_file=self.container_labels_from_file,
+                env_file=self.env_file,
+                no_memory_limit=self.no_memory_limit,
+                aws_endpoint_url=self.aws_endpoint_url,
+                add_host=self.add_host,
+                import_values=self.import_values,
+                container_labels=self.container_labels,
+                shutdown_timeout=self.shutdown_timeout,
+                hook_name=self.hook_name,
+                resource_attributes=self.resource_attributes,
+                cloudwatch_style_logs=self.cloudwatch_style_logs,
+                container_entrypoint_override=self.container_entrypoint_override,
+                runtime_image_override=self.runtime_image_override,
+                force_linux_paths=self.force_linux_paths,
+                no_build_artifacts=self.no_build_artifacts,
+                stdout_file=self.stdout_file,
+                stderr_file=self.stderr_file,
+                random_seed=self.random_seed,
+                random_seed_env_var=self.random_seed_env_var,
+                expect_log=self.expect_log,
+                x_ray_trace_id=self.x_ray_trace_id,
+                handled_error_exit_code=self.handled_error_exit_code,
+                unhandled_error_exit_code=self.unhandled_error_exit_code,
+                timeout_exit_code=self.timeout_exit_code,
+            )
+        except UserException as ex:
+            exception_message = str(ex)
+
+        self.assertEqual(exception_message, expected_exception_message)
+        self.get_state_machine_mock.assert_called_once_with(context_mock.stacks, self.function_id)
+        ExecutorMock.assert_called_once_with(context_mock.local_lambda_runner, stderr=context_mock.stderr)
+        ExecutorMock.return_value.execute.assert_called_once_with(state_machine, {"orderId": 42})
+        context_mock.local_lambda_runner.invoke.assert_not_called()
+        if not side_effect_exception:
+            context_mock.stdout.write.assert_called_once_with(b'{"status": "shipped"}')
+
     @parameterized.expand(
         [
             param(FunctionNotFound("not found"), "Function id not found in template"),
+            param(UnsupportedCodeLocation("Function 'id' has its code in S3"), "Function 'id' has its code in S3"),
             param(DockerImagePullFailedException("Failed to pull image"), "Failed to pull image"),
         ]
     )
@@ -217,6 +908,41 @@ class TestCli(TestCase):
                 shutdown=self.shutdown,
                 container_host=self.container_host,
                 container_host_interface=self.container_host_interface,
+                show_env=self.show_env,
+                debug_port_host=self.debug_port_host,
+                runtime=self.runtime,
+                event_clipboard=self.event_clipboard,
+                after_invoke=self.after_invoke,
+                container_hostname=self.container_hostname,
+                shm_size=self.shm_size,
+                cpu_proportional=self.cpu_proportional,
+                tz=self.tz,
+                use_build_artifacts=self.use_build_artifacts,
+                buffered_output=self.buffered_output,
+                container_labels_from_file=self.container_labels_from_file,
+                env_file=self.env_file,
+                no_memory_limit=self.no_memory_limit,
+                aws_endpoint_url=self.aws_endpoint_url,
+                add_host=self.add_host,
+                import_values=self.import_values,
+                container_labels=self.container_labels,
+                shutdown_timeout=self.shutdown_timeout,
+                hook_name=self.hook_name,
+                resource_attributes=self.resource_attributes,
+                cloudwatch_style_logs=self.cloudwatch_style_logs,
+                container_entrypoint_override=self.container_entrypoint_override,
+                runtime_image_override=self.runtime_image_override,
+                force_linux_paths=self.force_linux_paths,
+                no_build_artifacts=self.no_build_artifacts,
+                stdout_file=self.stdout_file,
+                stderr_file=self.stderr_file,
+                random_seed=self.random_seed,
+                random_seed_env_var=self.random_seed_env_var,
+                expect_log=self.expect_log,
+                x_ray_trace_id=self.x_ray_trace_id,
+                handled_error_exit_code=self.handled_error_exit_code,
+                unhandled_error_exit_code=self.unhandled_error_exit_code,
+                timeout_exit_code=self.timeout_exit_code,
             )
 
         msg = str(ex_ctx.exception)
@@ -271,6 +997,41 @@ class TestCli(TestCase):
                 shutdown=self.shutdown,
                 container_host=self.container_host,
                 container_host_interface=self.container_host_interface,
+                show_env=self.show_env,
+                debug_port_host=self.debug_port_host,
+                runtime=self.runtime,
+                event_clipboard=self.event_clipboard,
+                after_invoke=self.after_invoke,
+                container_hostname=self.container_hostname,
+                shm_size=self.shm_size,
+                cpu_proportional=self.cpu_proportional,
+                tz=self.tz,
+                use_build_artifacts=self.use_build_artifacts,
+                buffered_output=self.buffered_output,
+                container_labels_from_file=self.container_labels_from_file,
+                env_file=self.env_file,
+                no_memory_limit=self.no_memory_limit,
+                aws_endpoint_url=self.aws_endpoint_url,
+                add_host=self.add_host,
+                import_values=self.import_values,
+                container_labels=self.container_labels,
+                shutdown_timeout=self.shutdown_timeout,
+                hook_name=self.hook_name,
+                resource_attributes=self.resource_attributes,
+                cloudwatch_style_logs=self.cloudwatch_style_logs,
+                container_entrypoint_override=self.container_entrypoint_override,
+                runtime_image_override=self.runtime_image_override,
+                force_linux_paths=self.force_linux_paths,
+                no_build_artifacts=self.no_build_artifacts,
+                stdout_file=self.stdout_file,
+                stderr_file=self.stderr_file,
+                random_seed=self.random_seed,
+                random_seed_env_var=self.random_seed_env_var,
+                expect_log=self.expect_log,
+                x_ray_trace_id=self.x_ray_trace_id,
+                handled_error_exit_code=self.handled_error_exit_code,
+                unhandled_error_exit_code=self.unhandled_error_exit_code,
+                timeout_exit_code=self.timeout_exit_code,
             )
 
         msg = str(ex_ctx.exception)
@@ -323,6 +1084,41 @@ class TestCli(TestCase):
                 shutdown=self.shutdown,
                 container_host=self.container_host,
                 container_host_interface=self.container_host_interface,
+                show_env=self.show_env,
+                debug_port_host=self.debug_port_host,
+                runtime=self.runtime,
+                event_clipboard=self.event_clipboard,
+                after_invoke=self.after_invoke,
+                container_hostname=self.container_hostname,
+                shm_size=self.shm_size,
+                cpu_proportional=self.cpu_proportional,
+                tz=self.tz,
+                use_build_artifacts=self.use_build_artifacts,
+                buffered_output=self.buffered_output,
+                container_labels_from_file=self.container_labels_from_file,
+                env_file=self.env_file,
+                no_memory_limit=self.no_memory_limit,
+                aws_endpoint_url=self.aws_endpoint_url,
+                add_host=self.add_host,
+                import_values=self.import_values,
+                container_labels=self.container_labels,
+                shutdown_timeout=self.shutdown_timeout,
+                hook_name=self.hook_name,
+                resource_attributes=self.resource_attributes,
+                cloudwatch_style_logs=self.cloudwatch_style_logs,
+                container_entrypoint_override=self.container_entrypoint_override,
+                runtime_image_override=self.runtime_image_override,
+                force_linux_paths=self.force_linux_paths,
+                no_build_artifacts=self.no_build_artifacts,
+                stdout_file=self.stdout_file,
+                stderr_file=self.stderr_file,
+                random_seed=self.random_seed,
+                random_seed_env_var=self.random_seed_env_var,
+                expect_log=self.expect_log,
+                x_ray_trace_id=self.x_ray_trace_id,
+                handled_error_exit_code=self.handled_error_exit_code,
+                unhandled_error_exit_code=self.unhandled_error_exit_code,
+                timeout_exit_code=self.timeout_exit_code,
             )
 
         msg = str(ex_ctx.exception)
@@ -363,6 +1159,41 @@ class TestCli(TestCase):
                 shutdown=self.shutdown,
                 container_host=self.container_host,
                 container_host_interface=self.container_host_interface,
+                show_env=self.show_env,
+                debug_port_host=self.debug_port_host,
+                runtime=self.runtime,
+                event_clipboard=self.event_clipboard,
+                after_invoke=self.after_invoke,
+                container_hostname=self.container_hostname,
+                shm_size=self.shm_size,
+                cpu_proportional=self.cpu_proportional,
+                tz=self.tz,
+                use_build_artifacts=self.use_build_artifacts,
+                buffered_output=self.buffered_output,
+                container_labels_from_file=self.container_labels_from_file,
+                env_file=self.env_file,
+                no_memory_limit=self.no_memory_limit,
+                aws_endpoint_url=self.aws_endpoint_url,
+                add_host=self.add_host,
+                import_values=self.import_values,
+                container_labels=self.container_labels,
+                shutdown_timeout=self.shutdown_timeout,
+                hook_name=self.hook_name,
+                resource_attributes=self.resource_attributes,
+                cloudwatch_style_logs=self.cloudwatch_style_logs,
+                container_entrypoint_override=self.container_entrypoint_override,
+                runtime_image_override=self.runtime_image_override,
+                force_linux_paths=self.force_linux_paths,
+                no_build_artifacts=self.no_build_artifacts,
+                stdout_file=self.stdout_file,
+                stderr_file=self.stderr_file,
+                random_seed=self.random_seed,
+                random_seed_env_var=self.random_seed_env_var,
+                expect_log=self.expect_log,
+                x_ray_trace_id=self.x_ray_trace_id,
+                handled_error_exit_code=self.handled_error_exit_code,
+                unhandled_error_exit_code=self.unhandled_error_exit_code,
+                timeout_exit_code=self.timeout_exit_code,
             )
 
         msg = str(ex_ctx.exception)
@@ -417,6 +1248,41 @@ class TestCli(TestCase):
                 shutdown=self.shutdown,
                 container_host=self.container_host,
                 container_host_interface=self.container_host_interface,
+                show_env=self.show_env,
+                debug_port_host=self.debug_port_host,
+                runtime=self.runtime,
+                event_clipboard=self.event_clipboard,
+                after_invoke=self.after_invoke,
+                container_hostname=self.container_hostname,
+                shm_size=self.shm_size,
+                cpu_proportional=self.cpu_proportional,
+                tz=self.tz,
+                use_build_artifacts=self.use_build_artifacts,
+                buffered_output=self.buffered_output,
+                container_labels_from_file=self.container_labels_from_file,
+                env_file=self.env_file,
+                no_memory_limit=self.no_memory_limit,
+                aws_endpoint_url=self.aws_endpoint_url,
+                add_host=self.add_host,
+                import_values=self.import_values,
+                container_labels=self.container_labels,
+                shutdown_timeout=self.shutdown_timeout,
+                hook_name=self.hook_name,
+                resource_attributes=self.resource_attributes,
+                cloudwatch_style_logs=self.cloudwatch_style_logs,
+                container_entrypoint_override=self.container_entrypoint_override,
+                runtime_image_override=self.runtime_image_override,
+                force_linux_paths=self.force_linux_paths,
+                no_build_artifacts=self.no_build_artifacts,
+                stdout_file=self.stdout_file,
+                stderr_file=self.stderr_file,
+                random_seed=self.random_seed,
+                random_seed_env_var=self.random_seed_env_var,
+                expect_log=self.expect_log,
+                x_ray_trace_id=self.x_ray_trace_id,
+                handled_error_exit_code=self.handled_error_exit_code,
+                unhandled_error_exit_code=self.unhandled_error_exit_code,
+                timeout_exit_code=self.timeout_exit_code,
             )
 
         msg = str(ex_ctx.exception)
@@ -440,3 +1306,122 @@ class TestGetEvent(TestCase):
 
         self.assertEqual(result, event_data)
         fp_mock.read.assert_called_with()
+
+
+class TestGetEventFromClipboard(TestCase):
+    @parameterized.expand(
+        [
+            param("Darwin", ["pbpaste"]),
+            param("Windows", ["powershell", "-NoProfile", "-Command", "Get-Clipboard -Raw"]),
+            param("Linux", ["wl-paste", "--no-newline"]),
+        ]
+    )
+    @patch("samcli.commands.local.invoke.cli.subprocess.check_output")
+    @patch("samcli.commands.local.invoke.cli.platform.system")
+    def test_must_read_clipboard(self, system, command, system_mock, check_output_mock):
+        system_mock.return_value = system
+        check_output_mock.return_value = b'{"key": "value"}'
+
+        result = invoke_cli_get_event_from_clipboard()
+
+        self.assertEqual(result, '{"key": "value"}')
+        check_output_mock.assert_called_once_with(command, stderr=ANY)
+
+    @patch("samcli.commands.local.invoke.cli.subprocess.check_output")
+    @patch("samcli.commands.local.invoke.cli.platform.system")
+    def test_must_fall_back_to_next_clipboard_tool(self, system_mock, check_output_mock):
+        system_mock.return_value = "Linux"
+        check_output_mock.side_effect = [OSError("not found"), b"{}"]
+
+        result = invoke_cli_get_event_from_clipboard()
+
+        self.assertEqual(result, "{}")
+        check_output_mock.assert_called_with(["xclip", "-selection", "clipboard", "-out"], stderr=ANY)
+
+    @patch("samcli.commands.local.invoke.cli.subprocess.check_output")
+    @patch("samcli.commands.local.invoke.cli.platform.system")
+    def test_must_raise_if_clipboard_is_not_available(self, system_mock, check_output_mock):
+        system_mock.return_value = "Linux"
+        check_output_mock.side_effect = OSError("not found")
+
+        with self.assertRaises(UserException):
+            invoke_cli_get_event_from_clipboard()
+
+        self.assertEqual(check_output_mock.call_count, 3)
+
+    @patch("samcli.commands.local.invoke.cli.subprocess.check_output")
+    @patch("samcli.commands.local.invoke.cli.platform.system")
+    def test_must_raise_if_clipboard_is_empty(self, system_mock, check_output_mock):
+        system_mock.return_value = "Darwin"
+        check_output_mock.return_value = b"  \n"
+
+        with self.assertRaises(UserException):
+            invoke_cli_get_event_from_clipboard()
+
+
+class TestGetFunctionError(TestCase):
+    @parameterized.expand(
+        [
+            ('{"statusCode": 200}', None),
+            ("null", None),
+            ('{"errorMessage": "Bad order", "errorType": "ValueError", "stackTrace": []}', "Handled"),
+            ('{"errorMessage": "Bad order", "errorType": "errors.errorString"}', "Handled"),
+            (
+                '{"errorMessage": "division by zero", "errorType": "ZeroDivisionError", '
+                '"stackTrace": ["  File \\"/var/task/app.py\\", line 2, in handler\\n"]}',
+                "Unhandled",
+            ),
+            ('{"errorMessage": "Bad order", "errorType": "Error", "trace": ["Error: Bad order"]}', "Unhandled"),
+            (
+                '{"errorMessage": "Error: Runtime exited with error: exit status 1", "errorType": "Runtime.ExitError"}',
+                "Unhandled",
+            ),
+            ('{"errorMessage": "Unable to import module", "errorType": "Runtime.ImportModuleError"}', "Unhandled"),
+            ('{"errorMessage": "Task timed out after 3.00 seconds", "errorType": "Sandbox.Timedout"}', "Timeout"),
+            ("", None),
+        ]
+    )
+    def test_must_get_kind_of_function_error(self, lambda_response, expected):
+        self.assertEqual(invoke_cli_get_function_error(lambda_response), expected)
+
+    def test_must_get_timeout_when_function_timed_out(self):
+        self.assertEqual(invoke_cli_get_function_error("", timed_out=True), "Timeout")
+
+
+class TestFormatFunctionError(TestCase):
+    @parameterized.expand(
+        [
+            ('{"errorMessage": "Exited", "errorType": "Runtime.ExitError"}', "Runtime.ExitError: Exited\n"),
+            (
+                '{"errorMessage": "Bad order", "errorType": "Error", "stackTrace": ["Error: Bad order", '
+                '"    at handler (/var/task/app.js:3:9)"]}',
+                "Error: Bad order\n    Error: Bad order\n    at handler (/var/task/app.js:3:9)\n",
+            ),
+            (
+                '{"errorMessage": "Bad order", "errorType": "ValueError", '
+                '"stackTrace": [["/var/task/app.py", 3, "handler", "raise ValueError(\'Bad order\')"]]}',
+                "ValueError: Bad order\n    File \"/var/task/app.py\", line 3, in handler: "
+                "raise ValueError('Bad order')\n",
+            ),
+        ]
+    )
+    def test_must_format_error_with_stack_trace(self, lambda_response, expected):
+        self.assertEqual(invoke_cli_format_function_error(lambda_response), expected)
+
+
+class TestRunAfterInvoke(TestCase):
+    @patch("samcli.commands.local.invoke.cli.subprocess.run")
+    def test_must_pass_response_to_command(self, run_mock):
+        run_mock.return_value.returncode = 0
+
+        invoke_cli_run_after_invoke("./verify.sh", '{"statusCode": 200}')
+
+        run_mock.assert_called_once_with("./verify.sh", shell=True, input=b'{"statusCode": 200}', env=ANY, check=False)
+        self.assertEqual(run_mock.call_args[1]["env"]["SAM_INVOKE_RESPONSE"], '{"statusCode": 200}')
+
+    @patch("samcli.commands.local.invoke.cli.subprocess.run")
+    def test_must_raise_if_command_fails(self, run_mock):
+        run_mock.return_value.returncode = 1
+
+        with self.assertRaises(UserException):
+            invoke_cli_run_after_invoke("./verify.sh", "{}")
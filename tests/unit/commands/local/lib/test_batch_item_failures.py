@@ -0,0 +1,97 @@
+import json
+from unittest import TestCase
+from unittest.mock import patch, call
+
+from parameterized import parameterized
+
+from samcli.commands.local.lib.batch_item_failures import get_batch_records, report_batch_item_failures
+
+
+class TestGetBatchRecords(TestCase):
+    @parameterized.expand(
+        [
+            ({"Records": [{"eventSource": "aws:sqs", "messageId": "a"}]}, ("aws:sqs", ["a"])),
+            (
+                {"Records": [{"eventSource": "aws:kinesis", "kinesis": {"sequenceNumber": "4959033827149025"}}]},
+                ("aws:kinesis", ["4959033827149025"]),
+            ),
+            (
+                {"Records": [{"eventSource": "aws:dynamodb", "dynamodb": {"SequenceNumber": "111"}}]},
+                ("aws:dynamodb", ["111"]),
+            ),
+        ]
+    )
+    def test_must_return_record_ids_of_batch_events(self, event, expected):
+        self.assertEqual(get_batch_records(json.dumps(event)), expected)
+
+    @parameterized.expand(
+        [
+            ("not json",),
+            ("[]",),
+            ("{}",),
+            ('{"Records": []}',),
+            ('{"Records": [{"eventSource": "aws:s3"}]}',),
+            ('{"Records": [{"eventSource": "aws:sqs"}]}',),
+        ]
+    )
+    def test_must_return_none_for_other_events(self, event_data):
+        self.assertIsNone(get_batch_records(event_data))
+
+
+class TestReportBatchItemFailures(TestCase):
+    @patch("samcli.commands.local.lib.batch_item_failures.LOG")
+    def test_must_report_failed_sqs_messages(self, log_mock):
+        response = json.dumps({"batchItemFailures": [{"itemIdentifier": "c"}, {"itemIdentifier": "a"}]})
+
+        report_batch_item_failures("aws:sqs", ["a", "b", "c"], response)
+
+        log_mock.info.assert_has_calls(
+            [
+                call("%d of %d records reported as failed, the following records would be retried:", 2, 3),
+                call("  %s", "a"),
+                call("  %s", "c"),
+            ]
+        )
+
+    @patch("samcli.commands.local.lib.batch_item_failures.LOG")
+    def test_must_report_stream_records_from_first_failure(self, log_mock):
+        response = json.dumps({"batchItemFailures": [{"itemIdentifier": "2"}]})
+
+        report_batch_item_failures("aws:kinesis", ["1", "2", "3"], response)
+
+        log_mock.info.assert_has_calls(
+            [
+                call("%d of %d records reported as failed, the following records would be retried:", 1, 3),
+                call("  %s", "2"),
+                call("  %s", "3"),
+            ]
+        )
+
+    @patch("samcli.commands.local.lib.batch_item_failures.LOG")
+    def test_must_report_no_failures(self, log_mock):
+        report_batch_item_failures("aws:sqs", ["a"], '{"batchItemFailures": []}')
+
+        log_mock.info.assert_called_once_with("No batch item failures reported, no records would be retried")
+
+    @parameterized.expand(
+        [
+            ('{"batchItemFailures": [{"itemIdentifier": "unknown"}]}',),
+            ('{"batchItemFailures": [{"itemIdentifier": ""}]}',),
+            ('{"batchItemFailures": [{}]}',),
+            ('{"batchItemFailures": "a"}',),
+        ]
+    )
+    @patch("samcli.commands.local.lib.batch_item_failures.LOG")
+    def test_must_warn_when_whole_batch_would_be_retried(self, response, log_mock):
+        report_batch_item_failures("aws:sqs", ["a"], response)
+
+        log_mock.warning.assert_called_once()
+        log_mock.info.assert_not_called()
+
+    @parameterized.expand([("not json",), ('"a string"',), ('{"statusCode": 200}',)])
+    @patch("samcli.commands.local.lib.batch_item_failures.LOG")
+    def test_must_ignore_responses_without_batch_item_failures(self, response, log_mock):
+        report_batch_item_failures("aws:sqs", ["a"], response)
+
+        log_mock.info.assert_not_called()
+        log_mock.warning.assert_not_called()
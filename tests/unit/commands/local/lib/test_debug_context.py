@@ -74,3 +74,43 @@ class TestDebugContext(TestCase):
         debug_context = DebugContext(port, debug_path, debug_ars)
 
         self.assertFalse(debug_context.__nonzero__())
+
+    def test_for_function_without_function_debug_ports(self):
+        debug_context = DebugContext([1000], "debuggerpath", "debug_args")
+
+        self.assertIs(debug_context.for_function("FunctionA"), debug_context)
+
+    def test_for_function_with_function_debug_ports(self):
+        debug_context = DebugContext(
+            (range(5000, 5002),),
+            "debuggerpath",
+            "debug_args",
+            container_env_vars={"key": "value"},
+            function_debug_ports={"FunctionA": 5000, "FunctionB": 5001},
+        )
+
+        function_context = debug_context.for_function("FunctionB")
+
+        self.assertEqual(function_context.debug_ports, (5001,))
+        self.assertEqual(function_context.debugger_path, "debuggerpath")
+        self.assertEqual(function_context.debug_args, "debug_args")
+        self.assertEqual(function_context.debug_function, "FunctionB")
+        self.assertEqual(function_context.container_env_vars, {"key": "value"})
+        self.assertIsNone(function_context.function_debug_ports)
+
+    def test_for_function_keeps_debug_host_ports(self):
+        debug_context = DebugContext(
+            (range(5000, 5002),),
+            function_debug_ports={"FunctionA": 5000, "FunctionB": 5001},
+            debug_host_ports={5000: 6000, 5001: 6001},
+        )
+
+        function_context = debug_context.for_function("FunctionB")
+
+        self.assertEqual(function_context.debug_ports, (5001,))
+        self.assertEqual(function_context.debug_host_ports, {5000: 6000, 5001: 6001})
+
+    def test_for_function_not_assigned_a_port(self):
+        debug_context = DebugContext((range(5000, 5001),), function_debug_ports={"FunctionA": 5000})
+
+        self.assertIsNone(debug_context.for_function("FunctionB"))
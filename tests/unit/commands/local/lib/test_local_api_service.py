@@ -6,7 +6,7 @@ from unittest import TestCase
 
 from unittest.mock import Mock, patch
 
-from samcli.lib.providers.provider import Api
+from samcli.lib.providers.provider import Api, Cors
 from samcli.lib.providers.api_collector import ApiCollector
 from samcli.lib.providers.api_provider import ApiProvider
 from samcli.commands.local.lib.exceptions import NoApisDefined
@@ -67,9 +67,36 @@ class TestLocalApiService_start(TestCase):
             port=self.port,
             host=self.host,
             stderr=self.stderr_mock,
+            forwarded_host=None,
+            forwarded_proto=None,
+            throttle_rate=None,
+            max_concurrent_invocations=None,
+            invocation_queue_timeout=None,
         )
 
         self.apigw_service.create.assert_called_with()
+        self.apigw_service.bind.assert_not_called()
+        self.apigw_service.run.assert_called_with()
+
+    @patch("samcli.commands.local.lib.local_api_service.click")
+    @patch("samcli.commands.local.lib.local_api_service.LocalApigwService")
+    @patch("samcli.commands.local.lib.local_api_service.ApiProvider")
+    @patch.object(LocalApiService, "_make_static_dir_path")
+    @patch.object(LocalApiService, "_print_routes")
+    def test_must_print_port_chosen_by_os(
+        self, log_routes_mock, make_static_dir_mock, SamApiProviderMock, ApiGwServiceMock, click_mock
+    ):
+        SamApiProviderMock.return_value = self.api_provider_mock
+        ApiGwServiceMock.return_value = self.apigw_service
+        self.apigw_service.port = 54321
+
+        local_service = LocalApiService(self.lambda_invoke_context_mock, 0, self.host, self.static_dir)
+        local_service.start()
+
+        self.apigw_service.bind.assert_called_once_with()
+        self.assertEqual(local_service.port, 54321)
+        click_mock.echo.assert_called_once_with("Listening on http://abc:54321")
+        log_routes_mock.assert_called_with(self.api_provider_mock.api.routes, self.host, 54321)
         self.apigw_service.run.assert_called_with()
 
     @patch("samcli.commands.local.lib.local_api_service.LocalApigwService")
@@ -93,6 +120,68 @@ class TestLocalApiService_start(TestCase):
         with self.assertRaises(NoApisDefined):
             local_service.start()
 
+    @patch("samcli.commands.local.lib.local_api_service.ApiProvider")
+    def test_must_add_binary_media_types(self, SamApiProviderMock):
+        api = Api()
+        api.binary_media_types_set = {"image/gif"}
+        SamApiProviderMock.return_value.api = api
+
+        LocalApiService(
+            self.lambda_invoke_context_mock,
+            self.port,
+            self.host,
+            self.static_dir,
+            binary_media_types=("image/png", "application~1x-protobuf"),
+        )
+
+        self.assertEqual(api.binary_media_types_set, {"image/gif", "image/png", "application/x-protobuf"})
+
+    @patch("samcli.commands.local.lib.local_api_service.ApiProvider")
+    def test_must_override_cors_allow_origin(self, SamApiProviderMock):
+        api = Api(routes=[Route(methods=["GET", "OPTIONS"], function_name="func", path="/")])
+        api.cors = Cors(allow_origin="https://example.com", allow_methods="GET,OPTIONS", max_age=600)
+        SamApiProviderMock.return_value.api = api
+
+        LocalApiService(
+            self.lambda_invoke_context_mock,
+            self.port,
+            self.host,
+            self.static_dir,
+            cors_allow_origin="http://localhost:8080",
+        )
+
+        self.assertEqual(api.cors, Cors(allow_origin="http://localhost:8080", allow_methods="GET,OPTIONS", max_age=600))
+
+    @patch("samcli.commands.local.lib.local_api_service.ApiProvider")
+    def test_must_enable_cors_with_allow_origin_if_api_has_no_cors(self, SamApiProviderMock):
+        api = Api(routes=[Route(methods=["GET"], function_name="func", path="/")])
+        SamApiProviderMock.return_value.api = api
+
+        LocalApiService(
+            self.lambda_invoke_context_mock,
+            self.port,
+            self.host,
+            self.static_dir,
+            cors_allow_origin="http://localhost:8080",
+        )
+
+        self.assertEqual(
+            api.cors,
+            Cors(allow_origin="http://localhost:8080", allow_methods="DELETE,GET,HEAD,OPTIONS,PATCH,POST,PUT"),
+        )
+        # The routes must accept preflight requests
+        self.assertEqual(api.routes[0].methods, ["GET", "OPTIONS"])
+
+    @patch("samcli.commands.local.lib.local_api_service.ApiProvider")
+    def test_must_keep_cors_without_cors_allow_origin(self, SamApiProviderMock):
+        api = Api(routes=[Route(methods=["GET"], function_name="func", path="/")])
+        SamApiProviderMock.return_value.api = api
+
+        LocalApiService(self.lambda_invoke_context_mock, self.port, self.host, self.static_dir)
+
+        self.assertIsNone(api.cors)
+        self.assertEqual(api.routes[0].methods, ["GET"])
+
 
 class TestLocalApiService_print_routes(TestCase):
     def test_must_print_routes(self):
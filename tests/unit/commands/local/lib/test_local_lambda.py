@@ -2,16 +2,18 @@
 Testing local lambda runner
 """
 import os
+import tempfile
 from unittest import TestCase
-from unittest.mock import Mock, patch
+from unittest.mock import ANY, Mock, patch
 from parameterized import parameterized, param
 
-from samcli.commands.local.cli_common.user_exceptions import InvokeContextException
+from samcli.commands.local.cli_common.user_exceptions import InvokeContextException, InvalidSamTemplateException
 from samcli.commands.local.lib.local_lambda import LocalLambdaRunner
-from samcli.lib.providers.provider import Function
+from samcli.lib.providers.provider import Function, LayerVersion
 from samcli.lib.utils.packagetype import ZIP, IMAGE
+from samcli.lib.utils.stream_writer import LinePrefixingStreamWriter
 from samcli.local.docker.container import ContainerResponseException
-from samcli.local.lambdafn.exceptions import FunctionNotFound
+from samcli.local.lambdafn.exceptions import FunctionNotFound, UnsupportedCodeLocation
 from samcli.commands.local.lib.exceptions import (
     OverridesNotWellDefinedError,
     NoPrivilegeException,
@@ -69,6 +71,31 @@ class TestLocalLambda_get_aws_creds(TestCase):
         # assert no more calls to Session, and use the cached one
         self.assertEqual(boto3_mock.session.Session.call_count, 1)
 
+    @patch("samcli.commands.local.lib.local_lambda.RefreshableCredentials", new=Mock)
+    @patch("samcli.commands.local.lib.local_lambda.boto3")
+    def test_must_freeze_temporary_credentials_of_profile(self, boto3_mock):
+        # Credentials of SSO or assume role profiles are refreshable
+        creds = Mock()
+        creds.get_frozen_credentials.return_value = Mock(
+            access_key="ASIATEMPORARY", secret_key="temporary-secret", token="temporary-token"
+        )
+
+        mock_session = Mock()
+        mock_session.region_name = self.region
+        mock_session.get_credentials.return_value = creds
+        boto3_mock.session.Session.return_value = mock_session
+
+        expected = {
+            "region": self.region,
+            "key": "ASIATEMPORARY",
+            "secret": "temporary-secret",
+            "sessiontoken": "temporary-token",
+        }
+
+        actual = self.local_lambda.get_aws_creds()
+        self.assertEqual(expected, actual)
+        creds.get_frozen_credentials.assert_called_once_with()
+
     @patch("samcli.commands.local.lib.local_lambda.boto3")
     def test_must_work_with_no_region_name(self, boto3_mock):
         creds = Mock()
@@ -251,6 +278,62 @@ class TestLocalLambda_make_env_vars(TestCase):
             shell_env_values=os_environ,
             override_values=expected_override_value,
             aws_creds=self.aws_creds,
+            timezone=None,
+            runtime=function.runtime,
+            buffered_output=False,
+            random_seed=None,
+            random_seed_env_var=None,
+            aws_endpoint_url=None,
+        )
+
+    @parameterized.expand(
+        [
+            # Override for the function exists, and wins over the dotenv file
+            ({"function_name": {"a": "b"}}, {"a": "b", "e": "f"}),
+            # Override for the function does *not* exist
+            ({"otherfunction": {"c": "d"}}, {"a": "dotenv", "e": "f"}),
+            # Using a CloudFormation parameter file format
+            ({"Parameters": {"p1": "v1"}}, {"a": "dotenv", "e": "f", "p1": "v1"}),
+        ]
+    )
+    @patch("samcli.commands.local.lib.local_lambda.EnvironmentVariables")
+    @patch("samcli.commands.local.lib.local_lambda.os")
+    def test_must_compose_env_file_values_with_override_values(
+        self, env_vars_values, expected_override_value, os_mock, EnvironmentVariablesMock
+    ):
+        os_environ = {"some": "value"}
+        os_mock.environ = os_environ
+
+        function = Function(
+            stack_path="",
+            name="function_name",
+            functionname="function_name",
+            runtime="runtime",
+            memory=1234,
+            timeout=12,
+            handler="handler",
+            codeuri="codeuri",
+            environment=self.environ,
+            rolearn=None,
+            layers=[],
+            events=None,
+            metadata=None,
+            inlinecode=None,
+            imageuri=None,
+            imageconfig=None,
+            packagetype=ZIP,
+            codesign_config_arn=None,
+        )
+
+        self.local_lambda.env_vars_values = env_vars_values
+        self.local_lambda.env_file_values = {"a": "dotenv", "e": "f"}
+
+        self.local_lambda._make_env_vars(function)
+
+        self.assertEqual(EnvironmentVariablesMock.call_args[1]["override_values"], expected_override_value)
+        # Variables of the dotenv file that the template does not define are set too
+        self.assertEqual(
+            EnvironmentVariablesMock.call_args[1]["variables"], {"a": "dotenv", "e": "f", "var1": "value1"}
         )
 
     @parameterized.expand(
@@ -339,6 +422,12 @@ class TestLocalLambda_make_env_vars(TestCase):
             shell_env_values=os_environ,
             override_values=None,
             aws_creds=self.aws_creds,
+            timezone=None,
+            runtime=function.runtime,
+            buffered_output=False,
+            random_seed=None,
+            random_seed_env_var=None,
+            aws_endpoint_url=None,
         )
 
 
@@ -418,11 +507,107 @@ class TestLocalLambda_get_invoke_config(TestCase):
             memory=function.memory,
             timeout=function.timeout,
             env_vars=env_vars,
+            network_aliases=None,
+            entrypoint=None,
         )
 
         resolve_code_path_patch.assert_called_with(self.cwd, function.codeuri)
         self.local_lambda._make_env_vars.assert_called_with(function)
 
+    @parameterized.expand([("", "FunctionWithBuild"), ("ChildStackX", "FunctionWithBuild")])
+    @patch("samcli.commands.local.lib.local_lambda.resolve_code_path")
+    @patch("samcli.commands.local.lib.local_lambda.FunctionConfig")
+    def test_must_use_build_artifacts_if_they_exist(
+        self, stack_path, function_name, FunctionConfigMock, resolve_code_path_patch
+    ):
+        self.local_lambda._make_env_vars = Mock()
+        function = Mock(stack_path=stack_path, packagetype=ZIP, codeuri="codeuri", timeout=3, metadata=None, layers=[])
+        function.name = function_name
+        function.full_path = function_name
+        function.get_build_dir.side_effect = lambda build_root: os.path.join(build_root, stack_path, function_name)
+
+        with tempfile.TemporaryDirectory() as build_dir:
+            artifacts_dir = os.path.join(build_dir, stack_path, function_name)
+            os.makedirs(artifacts_dir)
+            self.local_lambda.build_dir = build_dir
+
+            self.local_lambda.get_invoke_config(function)
+
+        self.assertEqual(FunctionConfigMock.call_args[1]["code_abs_path"], os.path.abspath(artifacts_dir))
+        resolve_code_path_patch.assert_not_called()
+
+    @patch("samcli.commands.local.lib.local_lambda.resolve_code_path")
+    @patch("samcli.commands.local.lib.local_lambda.FunctionConfig")
+    def test_must_use_codeuri_if_function_was_not_built(self, FunctionConfigMock, resolve_code_path_patch):
+        self.local_lambda._make_env_vars = Mock()
+        resolve_code_path_patch.return_value = "codepath"
+        function = Mock(stack_path="", packagetype=ZIP, codeuri="codeuri", timeout=3, metadata=None, layers=[])
+        function.name = "FunctionWithoutBuild"
+        function.full_path = "FunctionWithoutBuild"
+        function.get_build_dir.side_effect = lambda build_root: os.path.join(build_root, "FunctionWithoutBuild")
+
+        with tempfile.TemporaryDirectory() as build_dir:
+            os.makedirs(os.path.join(build_dir, "OtherFunction"))
+            self.local_lambda.build_dir = build_dir
+
+            self.local_lambda.get_invoke_config(function)
+
+        self.assertEqual(FunctionConfigMock.call_args[1]["code_abs_path"], "codepath")
+        resolve_code_path_patch.assert_called_with(self.cwd, "codeuri")
+
+    @patch("samcli.commands.local.lib.local_lambda.resolve_code_path")
+    @patch("samcli.commands.local.lib.local_lambda.FunctionConfig")
+    def test_must_use_codeuri_if_build_artifacts_are_outdated(self, FunctionConfigMock, resolve_code_path_patch):
+        self.local_lambda._make_env_vars = Mock()
+        resolve_code_path_patch.return_value = "codepath"
+        function = Mock(stack_path="", packagetype=ZIP, codeuri="codeuri", timeout=3, metadata=None, layers=[])
+        function.name = "ChangedFunction"
+        function.full_path = "ChangedFunction"
+        function.get_build_dir.side_effect = lambda build_root: os.path.join(build_root, "ChangedFunction")
+
+        with tempfile.TemporaryDirectory() as build_dir:
+            os.makedirs(os.path.join(build_dir, "ChangedFunction"))
+            self.local_lambda.build_dir = build_dir
+            self.local_lambda.up_to_date_build_artifacts = {"OtherFunction"}
+
+            self.local_lambda.get_invoke_config(function)
+
+        self.assertEqual(FunctionConfigMock.call_args[1]["code_abs_path"], "codepath")
+        resolve_code_path_patch.assert_called_with(self.cwd, "codeuri")
+
+    @patch("samcli.commands.local.lib.local_lambda.resolve_code_path")
+    @patch("samcli.commands.local.lib.local_lambda.FunctionConfig")
+    def test_must_use_build_artifacts_of_layers_if_they_exist(self, FunctionConfigMock, resolve_code_path_patch):
+        self.local_lambda._make_env_vars = Mock()
+        built_layer = LayerVersion("BuiltLayer", "built_layer_codeuri")
+        unbuilt_layer = LayerVersion("UnbuiltLayer", "unbuilt_layer_codeuri")
+        arn_layer = LayerVersion("arn:aws:lambda:us-east-1:123456789012:layer:ArnLayer:1", None)
+        function = Mock(
+            stack_path="",
+            packagetype=ZIP,
+            codeuri="codeuri",
+            timeout=3,
+            metadata=None,
+            layers=[built_layer, unbuilt_layer, arn_layer],
+        )
+        function.name = "FunctionWithoutBuild"
+        function.full_path = "FunctionWithoutBuild"
+        function.get_build_dir.side_effect = lambda build_root: os.path.join(build_root, "FunctionWithoutBuild")
+
+        with tempfile.TemporaryDirectory() as build_dir:
+            os.makedirs(os.path.join(build_dir, "BuiltLayer"))
+            self.local_lambda.build_dir = build_dir
+
+            self.local_lambda.get_invoke_config(function)
+
+            layers = FunctionConfigMock.call_args[1]["layers"]
+            self.assertEqual(
+                [layer.codeuri for layer in layers],
+                [os.path.abspath(os.path.join(build_dir, "BuiltLayer")), "unbuilt_layer_codeuri", None],
+            )
+        # The layers of the function provider are left as they are
+        self.assertEqual(built_layer.codeuri, "built_layer_codeuri")
+
     @patch("samcli.commands.local.lib.local_lambda.resolve_code_path")
     @patch("samcli.commands.local.lib.local_lambda.LocalLambdaRunner.is_debugging")
     @patch("samcli.commands.local.lib.local_lambda.FunctionConfig")
@@ -479,12 +664,39 @@ class TestLocalLambda_get_invoke_config(TestCase):
             memory=function.memory,
             timeout=function.timeout,
             env_vars=env_vars,
+            network_aliases=None,
+            entrypoint=None,
         )
 
         resolve_code_path_patch.assert_called_with(self.cwd, "codeuri")
         self.local_lambda._make_env_vars.assert_called_with(function)
 
 
+class TestLocalLambda_get_network_aliases(TestCase):
+    @parameterized.expand(
+        [
+            (None, None),
+            ({}, None),
+            ({"BuildMethod": "makefile"}, None),
+            ({"NetworkAliases": "orders"}, ["orders"]),
+            ({"NetworkAliases": ["orders", "orders-api"]}, ["orders", "orders-api"]),
+        ]
+    )
+    def test_must_read_aliases_from_metadata(self, metadata, expected):
+        function = Mock()
+        function.metadata = metadata
+
+        self.assertEqual(LocalLambdaRunner._get_network_aliases(function), expected)
+
+    @parameterized.expand([({"NetworkAliases": {"Name": "orders"}},), ({"NetworkAliases": ["orders", 1]},)])
+    def test_must_fail_with_invalid_aliases(self, metadata):
+        function = Mock()
+        function.metadata = metadata
+
+        with self.assertRaises(InvalidSamTemplateException):
+            LocalLambdaRunner._get_network_aliases(function)
+
+
 class TestLocalLambda_invoke(TestCase):
     def setUp(self):
         self.runtime_mock = Mock()
@@ -525,6 +737,7 @@ class TestLocalLambda_invoke(TestCase):
             stderr=stderr,
             container_host=None,
             container_host_interface=None,
+            trace_id=None,
         )
 
     def test_must_work_packagetype_ZIP(self):
@@ -549,6 +762,36 @@ class TestLocalLambda_invoke(TestCase):
             stderr=stderr,
             container_host=None,
             container_host_interface=None,
+            trace_id=None,
+        )
+
+    def test_must_use_function_debug_context(self):
+        name = "name"
+        event = "event"
+        stdout = "stdout"
+        stderr = "stderr"
+        function = Mock(functionname="name", handler="app.handler", runtime="test", packagetype=ZIP)
+        function.name = "FunctionA"
+        invoke_config = "config"
+        debug_context = Mock()
+        self.local_lambda.debug_context = debug_context
+
+        self.function_provider_mock.get.return_value = function
+        self.local_lambda.get_invoke_config = Mock()
+        self.local_lambda.get_invoke_config.return_value = invoke_config
+
+        self.local_lambda.invoke(name, event, stdout, stderr)
+
+        debug_context.for_function.assert_called_once_with("FunctionA")
+        self.runtime_mock.invoke.assert_called_with(
+            invoke_config,
+            event,
+            debug_context=debug_context.for_function.return_value,
+            stdout=stdout,
+            stderr=stderr,
+            container_host=None,
+            container_host_interface=None,
+            trace_id=None,
         )
 
     def test_must_raise_if_no_privilege(self):
@@ -591,10 +834,24 @@ class TestLocalLambda_invoke(TestCase):
         function.functionname = "FunctionLogicalId"
 
         self.function_provider_mock.get.return_value = None  # function not found
+        self.function_provider_mock.has_s3_code_location.return_value = False
         self.function_provider_mock.get_all.return_value = [function]
-        with self.assertRaises(FunctionNotFound):
+        with self.assertRaises(FunctionNotFound) as ex_ctx:
             self.local_lambda.invoke("name", "event")
 
+        self.assertNotIsInstance(ex_ctx.exception, UnsupportedCodeLocation)
+
+    def test_must_raise_if_function_code_is_in_s3(self):
+        self.function_provider_mock.get.return_value = None  # function skipped because its code is in S3
+        self.function_provider_mock.has_s3_code_location.return_value = True
+
+        with self.assertRaises(UnsupportedCodeLocation) as ex_ctx:
+            self.local_lambda.invoke("name", "event")
+
+        self.assertIn("Set CodeUri to the local path", str(ex_ctx.exception))
+        self.function_provider_mock.has_s3_code_location.assert_called_with("name")
+        self.runtime_mock.invoke.assert_not_called()
+
     def test_must_not_raise_if_invoked_container_has_no_response(self):
         function = Mock()
         function.name = "name"
@@ -628,6 +885,7 @@ class TestLocalLambda_invoke(TestCase):
             stderr=stderr,
             container_host=None,
             container_host_interface=None,
+            trace_id=None,
         )
 
     def test_must_raise_if_imageuri_not_found(self):
@@ -687,7 +945,200 @@ class TestLocalLambda_invoke_with_container_host_option(TestCase):
             stderr=stderr,
             container_host="localhost",
             container_host_interface="127.0.0.1",
+            trace_id=None,
+        )
+
+
+class TestLocalLambda_invoke_with_show_env(TestCase):
+    def setUp(self):
+        self.runtime_mock = Mock()
+        self.function_provider_mock = Mock()
+        self.cwd = "/my/current/working/directory"
+
+        self.local_lambda = LocalLambdaRunner(
+            self.runtime_mock, self.function_provider_mock, self.cwd, env_vars_values={}, show_env=True
+        )
+
+    @patch("samcli.commands.local.lib.local_lambda.LOG")
+    def test_must_print_env_vars_with_masked_credentials(self, log_mock):
+        function = Mock(functionname="name", packagetype=ZIP)
+        function.name = "name"
+        invoke_config = Mock()
+        invoke_config.env_vars.resolve.return_value = {
+            "AWS_ACCESS_KEY_ID": "key",
+            "AWS_SECRET_ACCESS_KEY": "secret",
+            "AWS_SESSION_TOKEN": "token",
+            "AWS_REGION": "us-east-1",
+            "TABLE_NAME": "my-table",
+        }
+
+        self.function_provider_mock.get.return_value = function
+        self.local_lambda.get_invoke_config = Mock(return_value=invoke_config)
+
+        self.local_lambda.invoke("name", "event", "stdout", "stderr")
+
+        log_mock.info.assert_any_call("Environment variables for function '%s':", "name")
+        log_mock.info.assert_any_call("  %s=%s", "AWS_ACCESS_KEY_ID", "****")
+        log_mock.info.assert_any_call("  %s=%s", "AWS_SECRET_ACCESS_KEY", "****")
+        log_mock.info.assert_any_call("  %s=%s", "AWS_SESSION_TOKEN", "****")
+        log_mock.info.assert_any_call("  %s=%s", "AWS_REGION", "us-east-1")
+        log_mock.info.assert_any_call("  %s=%s", "TABLE_NAME", "my-table")
+        self.runtime_mock.invoke.assert_called_once()
+
+    @patch("samcli.commands.local.lib.local_lambda.LOG")
+    def test_must_not_print_env_vars_by_default(self, log_mock):
+        self.local_lambda.show_env = False
+        function = Mock(functionname="name", packagetype=ZIP)
+        invoke_config = Mock()
+
+        self.function_provider_mock.get.return_value = function
+        self.local_lambda.get_invoke_config = Mock(return_value=invoke_config)
+
+        self.local_lambda.invoke("name", "event", "stdout", "stderr")
+
+        invoke_config.env_vars.resolve.assert_not_called()
+
+
+class TestLocalLambda_invoke_with_cloudwatch_style_logs(TestCase):
+    def setUp(self):
+        self.runtime_mock = Mock()
+        self.function_provider_mock = Mock()
+        self.cwd = "/my/current/working/directory"
+
+        self.local_lambda = LocalLambdaRunner(
+            self.runtime_mock, self.function_provider_mock, self.cwd, env_vars_values={}, cloudwatch_style_logs=True
+        )
+
+    def test_must_prefix_logs_with_log_stream_name(self):
+        stderr = Mock()
+        function = Mock(functionname="name", packagetype=ZIP)
+
+        self.function_provider_mock.get.return_value = function
+        self.local_lambda.get_invoke_config = Mock()
+
+        self.local_lambda.invoke("name", "event", "stdout", stderr)
+
+        stderr_writer = self.runtime_mock.invoke.call_args[1]["stderr"]
+        self.assertIsInstance(stderr_writer, LinePrefixingStreamWriter)
+        stderr_writer.write(b"hello\n")
+        self.assertRegex(
+            stderr.write.call_args[0][0].decode("utf-8"),
+            r"^\d{4}/\d{2}/\d{2}/\[\$LATEST\][0-9a-f]{32} \d{4}-\d{2}-\d{2}T[0-9:.]+Z hello\n$",
+        )
+
+    def test_must_not_prefix_logs_by_default(self):
+        self.local_lambda.cloudwatch_style_logs = False
+        self.function_provider_mock.get.return_value = Mock(functionname="name", packagetype=ZIP)
+        self.local_lambda.get_invoke_config = Mock()
+
+        self.local_lambda.invoke("name", "event", "stdout", "stderr")
+
+        self.assertEqual(self.runtime_mock.invoke.call_args[1]["stderr"], "stderr")
+
+
+class TestLocalLambda_invoke_with_runtime_override(TestCase):
+    def setUp(self):
+        self.runtime_mock = Mock()
+        self.function_provider_mock = Mock()
+        self.cwd = "/my/current/working/directory"
+
+        self.local_lambda = LocalLambdaRunner(
+            self.runtime_mock, self.function_provider_mock, self.cwd, env_vars_values={}, runtime_override="python3.8"
         )
+        self.local_lambda.get_invoke_config = Mock()
+
+    def _make_function(self, packagetype):
+        return Function(
+            name="name",
+            functionname="name",
+            runtime="python3.6",
+            memory=None,
+            timeout=None,
+            handler="app.handler",
+            imageuri="image:tag" if packagetype == IMAGE else None,
+            packagetype=packagetype,
+            imageconfig=None,
+            codeuri="codeuri",
+            environment=None,
+            rolearn=None,
+            layers=[],
+            events=None,
+            metadata=None,
+            inlinecode=None,
+            codesign_config_arn=None,
+            stack_path="",
+        )
+
+    def test_must_invoke_zip_function_with_runtime_override(self):
+        self.function_provider_mock.get.return_value = self._make_function(ZIP)
+
+        self.local_lambda.invoke("name", "event", "stdout", "stderr")
+
+        function = self.local_lambda.get_invoke_config.call_args[0][0]
+        self.assertEqual(function.runtime, "python3.8")
+
+    def test_must_not_override_runtime_of_image_function(self):
+        self.function_provider_mock.get.return_value = self._make_function(IMAGE)
+
+        self.local_lambda.invoke("name", "event", "stdout", "stderr")
+
+        function = self.local_lambda.get_invoke_config.call_args[0][0]
+        self.assertEqual(function.runtime, "python3.6")
+
+
+class TestLocalLambda_invoke_with_runtime_management_config(TestCase):
+    def setUp(self):
+        self.runtime_mock = Mock()
+        self.function_provider_mock = Mock()
+        self.cwd = "/my/current/working/directory"
+
+        self.local_lambda = LocalLambdaRunner(
+            self.runtime_mock, self.function_provider_mock, self.cwd, env_vars_values={}
+        )
+        self.local_lambda.get_invoke_config = Mock()
+
+    def _make_function(self, runtime_management_config):
+        return Function(
+            name="name",
+            functionname="name",
+            runtime="python3.9",
+            memory=None,
+            timeout=None,
+            handler="app.handler",
+            imageuri=None,
+            packagetype=ZIP,
+            imageconfig=None,
+            codeuri="codeuri",
+            environment=None,
+            rolearn=None,
+            layers=[],
+            events=None,
+            metadata=None,
+            inlinecode=None,
+            codesign_config_arn=None,
+            stack_path="",
+            runtime_management_config=runtime_management_config,
+        )
+
+    @patch("samcli.commands.local.lib.local_lambda.LOG")
+    def test_must_warn_about_pinned_runtime_version(self, log_mock):
+        runtime_version_arn = "arn:aws:lambda:us-east-1::runtime:0123456789abcdef"
+        self.function_provider_mock.get.return_value = self._make_function(
+            {"UpdateRuntimeOn": "Manual", "RuntimeVersionArn": runtime_version_arn}
+        )
+
+        self.local_lambda.invoke("name", "event", "stdout", "stderr")
+
+        log_mock.warning.assert_called_once_with(ANY, "name", runtime_version_arn, "python3.9")
+
+    @parameterized.expand([(None,), ({"UpdateRuntimeOn": "Auto"},), ({"UpdateRuntimeOn": "FunctionUpdate"},)])
+    @patch("samcli.commands.local.lib.local_lambda.LOG")
+    def test_must_not_warn_without_pinned_runtime_version(self, runtime_management_config, log_mock):
+        self.function_provider_mock.get.return_value = self._make_function(runtime_management_config)
+
+        self.local_lambda.invoke("name", "event", "stdout", "stderr")
+
+        log_mock.warning.assert_not_called()
 
 
 class TestLocalLambda_is_debugging(TestCase):
@@ -1,7 +1,10 @@
+import re
+import socket
 from unittest import TestCase
 from unittest.mock import Mock, patch
 
 from samcli.commands.local.lib.local_lambda_service import LocalLambdaService
+from samcli.local.lambda_service.local_lambda_invoke_service import LocalLambdaInvokeService
 
 
 class TestLocalLambdaService(TestCase):
@@ -41,3 +44,24 @@ class TestLocalLambdaService(TestCase):
         )
         lambda_context_mock.create.assert_called_once()
         lambda_context_mock.run.assert_called_once()
+        lambda_context_mock.bind.assert_not_called()
+
+    @patch("samcli.commands.local.lib.local_lambda_service.click")
+    @patch.object(LocalLambdaInvokeService, "run", autospec=True)
+    def test_start_prints_port_chosen_by_os(self, run_mock, click_mock):
+        lambda_invoke_context_mock = Mock()
+
+        service = LocalLambdaService(lambda_invoke_context=lambda_invoke_context_mock, port=0, host="127.0.0.1")
+
+        service.start()
+
+        invoke_service = run_mock.call_args[0][0]
+        self.addCleanup(invoke_service._server.server_close)
+
+        match = re.fullmatch(r"Listening on http://127\.0\.0\.1:(\d+)", click_mock.echo.call_args[0][0])
+        port = int(match.group(1))
+        self.assertNotEqual(port, 0)
+        self.assertEqual(service.port, port)
+        # The server listens on the printed port before it starts serving
+        with socket.create_connection(("127.0.0.1", port), timeout=5):
+            pass
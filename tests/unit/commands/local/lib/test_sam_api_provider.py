@@ -9,7 +9,7 @@ from parameterized import parameterized
 from samcli.commands.validate.lib.exceptions import InvalidSamDocumentException
 from samcli.lib.providers.api_provider import ApiProvider
 from samcli.lib.providers.provider import Cors, Stack
-from samcli.local.apigw.local_apigw_service import Route
+from samcli.local.apigw.local_apigw_service import LocalApigwService, Route
 
 
 def make_mock_stacks_from_template(template):
@@ -46,6 +46,31 @@ class TestSamApiProviderWithImplicitApis(TestCase):
         self.assertEqual(len(provider.routes), 1)
         self.assertEqual(list(provider.routes)[0], Route(path="/path", methods=["GET"], function_name="SamFunc1"))
 
+    def test_provider_mounts_http_api_events(self):
+        template = {
+            "Resources": {
+                "SamFunc1": {
+                    "Type": "AWS::Serverless::Function",
+                    "Properties": {
+                        "CodeUri": "/usr/foo/bar",
+                        "Runtime": "nodejs4.3",
+                        "Handler": "index.handler",
+                        "Events": {
+                            "Event1": {"Type": "HttpApi", "Properties": {"Path": "/path", "Method": "GET"}},
+                            "Event2": {"Type": "Api", "Properties": {"Path": "/rest", "Method": "GET"}},
+                        },
+                    },
+                }
+            }
+        }
+
+        provider = ApiProvider(make_mock_stacks_from_template(template))
+
+        routes = {route.path: route for route in provider.routes}
+        self.assertEqual(routes["/path"], Route(path="/path", methods=["GET"], function_name="SamFunc1"))
+        self.assertEqual(routes["/path"].event_type, Route.HTTP)
+        self.assertEqual(routes["/rest"].event_type, Route.API)
+
     def test_provider_creates_api_for_all_events(self):
         template = {
             "Resources": {
@@ -280,6 +305,35 @@ class TestSamApiProviderWithImplicitApis(TestCase):
         self.assertCountEqual(provider.routes, expected_routes)
         self.assertCountEqual(provider.api.binary_media_types, binary)
 
+    def test_globals_api_apply_to_implicit_api(self):
+        template = {
+            "Globals": {
+                "Api": {
+                    "BinaryMediaTypes": ["image~1png"],
+                    "Cors": {"AllowMethods": "'GET'", "AllowOrigin": "'https://example.com'"},
+                }
+            },
+            "Resources": {
+                "SamFunc1": {
+                    "Type": "AWS::Serverless::Function",
+                    "Properties": {
+                        "CodeUri": "/usr/foo/bar",
+                        "Runtime": "python3.9",
+                        "Handler": "index.handler",
+                        "Events": {"Event1": {"Type": "Api", "Properties": {"Path": "/upload", "Method": "post"}}},
+                    },
+                }
+            },
+        }
+
+        provider = ApiProvider(make_mock_stacks_from_template(template))
+
+        # A request body of a binary media type of Globals.Api is passed to the function base64 encoded
+        self.assertTrue(LocalApigwService._should_base64_encode(provider.api.binary_media_types, "image/png"))
+        self.assertFalse(LocalApigwService._should_base64_encode(provider.api.binary_media_types, "application/json"))
+        self.assertEqual(provider.api.cors.allow_origin, "https://example.com")
+        self.assertIn("GET", provider.api.cors.allow_methods.split(","))
+
     @parameterized.expand([("GET", "/path", "overridden_by_top_level_stack"), ("get", "/path2", False)])
     def test_provider_with_multiple_stacks(self, method, func2_api_path, overridden_by_top_level_stack):
         """
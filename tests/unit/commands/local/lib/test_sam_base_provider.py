@@ -1,5 +1,6 @@
 from unittest import TestCase
 from unittest.mock import Mock, patch
+from parameterized import parameterized
 from samcli.lib.providers.sam_base_provider import SamBaseProvider
 from samcli.lib.intrinsic_resolver.intrinsic_property_resolver import IntrinsicResolver
 from samcli.lib.intrinsic_resolver.intrinsics_symbol_table import IntrinsicsSymbolTable
@@ -24,3 +25,150 @@ class TestSamBaseProvider_get_template(TestCase):
         called_parameter_values.update(overrides)
         SamTranslatorWrapperMock.assert_called_once_with(template, parameter_values=called_parameter_values)
         translator_instance.run_plugins.assert_called_once()
+
+    @parameterized.expand([(None, "dev"), ({"StageName": "prod"}, "prod")])
+    @patch("samcli.lib.providers.sam_base_provider.SamTranslatorWrapper")
+    def test_must_resolve_ref_to_parameter_with_override_or_default(
+        self, overrides, expected_stage, SamTranslatorWrapperMock
+    ):
+        template = {
+            "Parameters": {"StageName": {"Type": "String", "Default": "dev"}},
+            "Resources": {
+                "Function": {
+                    "Type": "AWS::Serverless::Function",
+                    "Properties": {"Environment": {"Variables": {"STAGE": {"Ref": "StageName"}}}},
+                }
+            },
+        }
+        SamTranslatorWrapperMock.return_value.run_plugins.return_value = template
+
+        result = SamBaseProvider.get_template(template, overrides)
+
+        self.assertEqual(
+            result["Resources"]["Function"]["Properties"]["Environment"]["Variables"]["STAGE"], expected_stage
+        )
+
+    @patch("samcli.lib.providers.sam_base_provider.SamTranslatorWrapper")
+    def test_must_resolve_ref_to_simple_table_name(self, SamTranslatorWrapperMock):
+        template = {
+            "Resources": {
+                "Function": {
+                    "Type": "AWS::Serverless::Function",
+                    "Properties": {"Environment": {"Variables": {"TABLE_NAME": {"Ref": "MyTable"}}}},
+                },
+                "MyTable": {"Type": "AWS::Serverless::SimpleTable", "Properties": {"TableName": "my-table"}},
+            },
+        }
+        SamTranslatorWrapperMock.return_value.run_plugins.return_value = template
+
+        result = SamBaseProvider.get_template(template)
+
+        self.assertEqual(
+            result["Resources"]["Function"]["Properties"]["Environment"]["Variables"]["TABLE_NAME"], "my-table"
+        )
+
+    @patch("samcli.lib.providers.sam_base_provider.SamTranslatorWrapper")
+    def test_must_resolve_resource_attributes_before_placeholders(self, SamTranslatorWrapperMock):
+        table_arn = "arn:aws:dynamodb:us-west-2:111122223333:table/orders"
+        template = {
+            "Resources": {
+                "Function": {
+                    "Type": "AWS::Serverless::Function",
+                    "Properties": {
+                        "Environment": {
+                            "Variables": {
+                                "TABLE_ARN": {"Fn::Sub": "${MyTable.Arn}"},
+                                "TABLE_STREAM": {"Fn::Sub": "${MyTable.Arn}/stream/*"},
+                                "TABLE_ARN_ATT": {"Fn::GetAtt": ["MyTable", "Arn"]},
+                                "TABLE_NAME": {"Ref": "MyTable"},
+                                "QUEUE_ARN": {"Fn::GetAtt": ["MyQueue", "Arn"]},
+                            }
+                        }
+                    },
+                },
+                "MyTable": {"Type": "AWS::DynamoDB::Table"},
+                "MyQueue": {"Type": "AWS::SQS::Queue"},
+            },
+        }
+        SamTranslatorWrapperMock.return_value.run_plugins.return_value = template
+        placeholder_queue_arn = SamBaseProvider.get_template(template)["Resources"]["Function"]["Properties"][
+            "Environment"
+        ]["Variables"]["QUEUE_ARN"]
+
+        result = SamBaseProvider.get_template(
+            template, resource_attributes={"MyTable": {"Arn": table_arn, "Ref": "orders"}}
+        )
+
+        variables = result["Resources"]["Function"]["Properties"]["Environment"]["Variables"]
+        self.assertEqual(variables["TABLE_ARN"], table_arn)
+        self.assertEqual(variables["TABLE_STREAM"], table_arn + "/stream/*")
+        self.assertEqual(variables["TABLE_ARN_ATT"], table_arn)
+        self.assertEqual(variables["TABLE_NAME"], "orders")
+        # Attributes missing from the resource attributes still resolve to the placeholders
+        self.assertEqual(variables["QUEUE_ARN"], placeholder_queue_arn)
+
+    @patch("samcli.lib.providers.sam_base_provider.SamTranslatorWrapper")
+    def test_must_resolve_import_value_from_import_values(self, SamTranslatorWrapperMock):
+        template = {
+            "Parameters": {"NetworkStack": {"Type": "String", "Default": "network"}},
+            "Resources": {
+                "Function": {
+                    "Type": "AWS::Serverless::Function",
+                    "Properties": {
+                        "Environment": {
+                            "Variables": {
+                                "VPC_ID": {"Fn::ImportValue": {"Fn::Sub": "${NetworkStack}-VpcId"}},
+                                "SUBNET_ID": {"Fn::ImportValue": "MissingSubnetId"},
+                            }
+                        }
+                    },
+                }
+            },
+        }
+        SamTranslatorWrapperMock.return_value.run_plugins.return_value = template
+
+        result = SamBaseProvider.get_template(template, import_values={"network-VpcId": "vpc-0123"})
+
+        variables = result["Resources"]["Function"]["Properties"]["Environment"]["Variables"]
+        self.assertEqual(variables["VPC_ID"], "vpc-0123")
+        # An export missing from the import values is left unresolved
+        self.assertEqual(variables["SUBNET_ID"], {"Fn::ImportValue": "MissingSubnetId"})
+
+    @parameterized.expand(
+        [
+            (None, "arn:aws:s3:::bucket", "s3.amazonaws.com"),
+            ({"AWS::Region": "cn-north-1"}, "arn:aws-cn:s3:::bucket", "s3.amazonaws.com.cn"),
+            ({"AWS::Region": "us-gov-west-1"}, "arn:aws-us-gov:s3:::bucket", "s3.amazonaws.com"),
+            (
+                {"AWS::Region": "cn-north-1", "AWS::Partition": "custom", "AWS::URLSuffix": "example.com"},
+                "arn:custom:s3:::bucket",
+                "s3.example.com",
+            ),
+        ]
+    )
+    @patch("samcli.lib.providers.sam_base_provider.SamTranslatorWrapper")
+    def test_must_resolve_partition_and_url_suffix(
+        self, overrides, expected_arn, expected_endpoint, SamTranslatorWrapperMock
+    ):
+        template = {
+            "Resources": {
+                "Function": {
+                    "Type": "AWS::Serverless::Function",
+                    "Properties": {
+                        "Environment": {
+                            "Variables": {
+                                "BUCKET_ARN": {"Fn::Sub": "arn:${AWS::Partition}:s3:::bucket"},
+                                "S3_ENDPOINT": {"Fn::Sub": "s3.${AWS::URLSuffix}"},
+                            }
+                        }
+                    },
+                }
+            },
+        }
+        SamTranslatorWrapperMock.return_value.run_plugins.return_value = template
+
+        result = SamBaseProvider.get_template(template, overrides)
+
+        variables = result["Resources"]["Function"]["Properties"]["Environment"]["Variables"]
+        self.assertEqual(variables["BUCKET_ARN"], expected_arn)
+        self.assertEqual(variables["S3_ENDPOINT"], expected_endpoint)
@@ -526,7 +526,7 @@ class TestSamFunctionProvider_init(TestCase):
         provider = SamFunctionProvider([stack])
 
         extract_mock.assert_called_with([stack], False, False)
-        get_template_mock.assert_called_with(template, self.parameter_overrides)
+        get_template_mock.assert_called_with(template, self.parameter_overrides, None, None)
         self.assertEqual(provider.functions, extract_result)
 
     @patch.object(SamFunctionProvider, "_extract_functions")
@@ -893,6 +893,23 @@ class TestSamFunctionProvider_convert_lambda_function_resource(TestCase):
 
         self.assertEqual(expected, result)
 
+    def test_must_convert_runtime_management_config(self):
+
+        name = "myname"
+        runtime_management_config = {
+            "UpdateRuntimeOn": "Manual",
+            "RuntimeVersionArn": "arn:aws:lambda:us-east-1::runtime:0123456789abcdef",
+        }
+        properties = {
+            "Code": {"Bucket": "bucket"},
+            "Runtime": "myruntime",
+            "RuntimeManagementConfig": runtime_management_config,
+        }
+
+        result = SamFunctionProvider._convert_lambda_function_resource(STACK, name, properties, [])
+
+        self.assertEqual(result.runtime_management_config, runtime_management_config)
+
     def test_must_use_inlinecode(self):
 
         name = "myname"
@@ -1076,6 +1093,56 @@ class TestSamFunctionProvider_get(TestCase):
         self.assertIsNone(provider.get("somefunc"), "Must return None when Function is not found")
 
 
+class TestSamFunctionProvider_has_s3_code_location(TestCase):
+    def setUp(self):
+        self.template = {
+            "Resources": {
+                "SamFuncWithS3Object": {
+                    "Type": "AWS::Serverless::Function",
+                    "Properties": {
+                        "FunctionName": "S3ObjectFunctionName",
+                        "CodeUri": {"Bucket": "bucket", "Key": "key", "Version": "1"},
+                        "Runtime": "python3.8",
+                        "Handler": "index.handler",
+                    },
+                },
+                "SamFuncWithS3Uri": {
+                    "Type": "AWS::Serverless::Function",
+                    "Properties": {"CodeUri": "s3://bucket/key", "Runtime": "python3.8", "Handler": "index.handler"},
+                },
+                "LambdaFuncWithS3Code": {
+                    "Type": "AWS::Lambda::Function",
+                    "Properties": {
+                        "Code": {"S3Bucket": "bucket", "S3Key": "key"},
+                        "Runtime": "python3.8",
+                        "Handler": "index.handler",
+                    },
+                },
+                "SamFuncWithLocalCode": {
+                    "Type": "AWS::Serverless::Function",
+                    "Properties": {"CodeUri": "./code", "Runtime": "python3.8", "Handler": "index.handler"},
+                },
+            }
+        }
+        self.provider = SamFunctionProvider([make_root_stack(self.template)], ignore_code_extraction_warnings=True)
+
+    @parameterized.expand(
+        [
+            ("SamFuncWithS3Object", True),
+            ("S3ObjectFunctionName", True),
+            ("SamFuncWithS3Uri", True),
+            ("LambdaFuncWithS3Code", True),
+            ("SamFuncWithLocalCode", False),
+            ("UnknownFunction", False),
+        ]
+    )
+    def test_must_detect_functions_with_s3_code(self, name, expected):
+        self.assertEqual(self.provider.has_s3_code_location(name), expected)
+
+    def test_s3_code_functions_are_not_extracted(self):
+        self.assertIsNone(self.provider.get("SamFuncWithS3Object"))
+
+
 class TestSamFunctionProvider_get_all(TestCase):
     def test_must_work_with_no_functions(self):
         provider = SamFunctionProvider([])
@@ -49,6 +49,35 @@ class TestCli(TestCase):
 
         self.container_host = "localhost"
         self.container_host_interface = "127.0.0.1"
+        self.debug_port_host = (6000,)
+        self.container_hostname = "sam-local"
+        self.shm_size = "1g"
+        self.cpu_proportional = False
+        self.use_build_artifacts = False
+        self.buffered_output = False
+        self.container_labels_from_file = "labels.yaml"
+        self.env_file = ".env"
+        self.no_memory_limit = False
+        self.aws_endpoint_url = "http://localstack:4566"
+        self.add_host = (("db.local", "192.168.1.10"),)
+        self.import_values = "exports.json"
+        self.container_labels = (("team", "serverless"),)
+        self.shutdown_timeout = 5
+        self.hook_name = "terraform"
+        self.resource_attributes = "attributes.json"
+        self.cloudwatch_style_logs = True
+        self.container_entrypoint_override = ["/opt/wrapper", "/var/rapid/aws-lambda-rie"]
+        self.runtime_image_override = (("python3.8", "registry.example.com/lambda-python3.8:1.0"),)
+        self.force_linux_paths = True
+        self.no_build_artifacts = True
+        self.binary_media_types = ("image/png",)
+        self.forwarded_host = "api.example.com"
+        self.forwarded_proto = "https"
+        self.watch = False
+        self.cors_allow_origin = "http://localhost:8080"
+        self.throttle_rate = 10.0
+        self.max_concurrent_invocations = 4
+        self.invocation_queue_timeout = 30.0
 
     @patch("samcli.commands.local.cli_common.invoke_context.InvokeContext")
     @patch("samcli.commands.local.lib.local_api_service.LocalApiService")
@@ -87,14 +116,58 @@ class TestCli(TestCase):
             shutdown=self.shutdown,
             container_host=self.container_host,
             container_host_interface=self.container_host_interface,
+            debug_host_ports=self.debug_port_host,
+            container_hostname=self.container_hostname,
+            shm_size=self.shm_size,
+            cpu_proportional=self.cpu_proportional,
+            use_build_artifacts=self.use_build_artifacts,
+            buffered_output=self.buffered_output,
+            container_labels_file=self.container_labels_from_file,
+            env_file=self.env_file,
+            no_memory_limit=self.no_memory_limit,
+            aws_endpoint_url=self.aws_endpoint_url,
+            add_host=self.add_host,
+            import_values_file=self.import_values,
+            container_labels=self.container_labels,
+            shutdown_timeout=self.shutdown_timeout,
+            hook_name=self.hook_name,
+            resource_attributes_file=self.resource_attributes,
+            cloudwatch_style_logs=self.cloudwatch_style_logs,
+            container_entrypoint=self.container_entrypoint_override,
+            runtime_image_override=self.runtime_image_override,
+            force_linux_paths=self.force_linux_paths,
+            no_build_artifacts=self.no_build_artifacts,
         )
 
         local_api_service_mock.assert_called_with(
-            lambda_invoke_context=context_mock, port=self.port, host=self.host, static_dir=self.static_dir
+            lambda_invoke_context=context_mock,
+            port=self.port,
+            host=self.host,
+            static_dir=self.static_dir,
+            binary_media_types=self.binary_media_types,
+            forwarded_host=self.forwarded_host,
+            forwarded_proto=self.forwarded_proto,
+            cors_allow_origin=self.cors_allow_origin,
+            throttle_rate=self.throttle_rate,
+            max_concurrent_invocations=self.max_concurrent_invocations,
+            invocation_queue_timeout=self.invocation_queue_timeout,
         )
 
         service_mock.start.assert_called_with()
 
+    @parameterized.expand([(None, "LAZY"), ("EAGER", "EAGER")])
+    @patch("samcli.commands.local.cli_common.invoke_context.InvokeContext")
+    @patch("samcli.commands.local.lib.local_api_service.LocalApiService")
+    def test_watch_must_use_warm_containers(
+        self, warm_containers, expected_mode, local_api_service_mock, invoke_context_mock
+    ):
+        self.warm_containers = warm_containers
+        self.watch = True
+
+        self.call_cli()
+
+        self.assertEqual(invoke_context_mock.call_args[1]["warm_container_initialization_mode"], expected_mode)
+
     @patch("samcli.commands.local.cli_common.invoke_context.InvokeContext")
     @patch("samcli.commands.local.lib.local_api_service.LocalApiService")
     def test_must_raise_if_no_api_defined(self, local_api_service_mock, invoke_context_mock):
@@ -195,4 +268,33 @@ class TestCli(TestCase):
             shutdown=self.shutdown,
             container_host=self.container_host,
             container_host_interface=self.container_host_interface,
+            debug_port_host=self.debug_port_host,
+            container_hostname=self.container_hostname,
+            shm_size=self.shm_size,
+            cpu_proportional=self.cpu_proportional,
+            use_build_artifacts=self.use_build_artifacts,
+            buffered_output=self.buffered_output,
+            container_labels_from_file=self.container_labels_from_file,
+            env_file=self.env_file,
+            no_memory_limit=self.no_memory_limit,
+            aws_endpoint_url=self.aws_endpoint_url,
+            add_host=self.add_host,
+            import_values=self.import_values,
+            container_labels=self.container_labels,
+            shutdown_timeout=self.shutdown_timeout,
+            hook_name=self.hook_name,
+            resource_attributes=self.resource_attributes,
+            cloudwatch_style_logs=self.cloudwatch_style_logs,
+            container_entrypoint_override=self.container_entrypoint_override,
+            runtime_image_override=self.runtime_image_override,
+            force_linux_paths=self.force_linux_paths,
+            no_build_artifacts=self.no_build_artifacts,
+            binary_media_types=self.binary_media_types,
+            forwarded_host=self.forwarded_host,
+            forwarded_proto=self.forwarded_proto,
+            watch=self.watch,
+            cors_allow_origin=self.cors_allow_origin,
+            throttle_rate=self.throttle_rate,
+            max_concurrent_invocations=self.max_concurrent_invocations,
+            invocation_queue_timeout=self.invocation_queue_timeout,
         )
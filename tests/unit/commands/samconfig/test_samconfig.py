@@ -29,6 +29,8 @@ class TestSamConfigForAllCommands(TestCase):
         self.scratch_dir = tempfile.mkdtemp()
         Path(self.scratch_dir, "envvar.json").write_text("{}")
         Path(self.scratch_dir, "container-envvar.json").write_text("{}")
+        Path(self.scratch_dir, "container-labels.yaml").write_text("team: serverless")
+        Path(self.scratch_dir, ".env").write_text("STAGE=local")
 
         os.chdir(self.scratch_dir)
 
@@ -98,7 +100,7 @@ class TestSamConfigForAllCommands(TestCase):
                 LOG.exception("Command failed", exc_info=result.exc_info)
             self.assertIsNone(result.exception)
 
-            do_cli_mock.assert_called_with(ANY, str(Path(os.getcwd(), "mytemplate.yaml")))
+            do_cli_mock.assert_called_with(ANY, str(Path(os.getcwd(), "mytemplate.yaml")), False, False)
 
     @patch("samcli.commands.build.command.do_cli")
     def test_build(self, do_cli_mock):
@@ -276,6 +278,38 @@ class TestSamConfigForAllCommands(TestCase):
             "force_image_build": True,
             "shutdown": True,
             "parameter_overrides": "ParameterKey=Key,ParameterValue=Value ParameterKey=Key2,ParameterValue=Value2",
+            "runtime": "python3.8",
+            "after_invoke": "./verify.sh",
+            "container_hostname": "sam-local",
+            "shm_size": "1g",
+            "expect_log": ["processed order"],
+            "x_ray_trace_id": "1-5759e988-bd862e3fe1be46a994272793",
+            "handled_error_exit_code": 2,
+            "unhandled_error_exit_code": 3,
+            "timeout_exit_code": 4,
+            "cpu_proportional": True,
+            "tz": "Europe/Paris",
+            "use_build_artifacts": True,
+            "buffered_output": True,
+            "container_labels_from_file": "container-labels.yaml",
+            "env_file": ".env",
+            "no_memory_limit": True,
+            "stdout_file": "response.json",
+            "stderr_file": "logs.txt",
+            "random_seed": "42",
+            "random_seed_env_var": "TEST_SEED",
+            "aws_endpoint_url": "http://localstack:4566",
+            "add_host": ["db.local:192.168.1.10"],
+            "import_values": "exports.json",
+            "container_labels": ["team=serverless"],
+            "shutdown_timeout": 5,
+            "hook_name": "terraform",
+            "resource_attributes": "attributes.json",
+            "cloudwatch_style_logs": True,
+            "container_entrypoint_override": "/opt/wrapper /var/rapid/aws-lambda-rie",
+            "runtime_image_override": ["python3.8=registry.example.com/lambda-python3.8:1.0"],
+            "force_linux_paths": True,
+            "no_build_artifacts": True,
         }
 
         # NOTE: Because we don't load the full Click BaseCommand here, this is mounted as top-level command
@@ -314,6 +348,41 @@ class TestSamConfigForAllCommands(TestCase):
                 {"Key": "Value", "Key2": "Value2"},
                 "localhost",
                 "127.0.0.1",
+                False,
+                (),
+                "python3.8",
+                False,
+                "./verify.sh",
+                "sam-local",
+                "1g",
+                ("processed order",),
+                "1-5759e988-bd862e3fe1be46a994272793",
+                2,
+                3,
+                4,
+                True,
+                "Europe/Paris",
+                True,
+                True,
+                "container-labels.yaml",
+                ".env",
+                True,
+                "response.json",
+                "logs.txt",
+                "42",
+                "TEST_SEED",
+                "http://localstack:4566",
+                (("db.local", "192.168.1.10"),),
+                "exports.json",
+                (("team", "serverless"),),
+                5,
+                "terraform",
+                "attributes.json",
+                True,
+                ["/opt/wrapper", "/var/rapid/aws-lambda-rie"],
+                (("python3.8", "registry.example.com/lambda-python3.8:1.0"),),
+                True,
+                True,
             )
 
     @patch("samcli.commands.local.start_api.cli.do_cli")
@@ -337,6 +406,34 @@ class TestSamConfigForAllCommands(TestCase):
             "force_image_build": True,
             "shutdown": False,
             "parameter_overrides": "ParameterKey=Key,ParameterValue=Value ParameterKey=Key2,ParameterValue=Value2",
+            "binary_media_types": ["image/png"],
+            "container_hostname": "sam-local",
+            "forwarded_host": "api.example.com",
+            "forwarded_proto": "https",
+            "shm_size": "1g",
+            "watch": True,
+            "cpu_proportional": True,
+            "use_build_artifacts": True,
+            "buffered_output": True,
+            "cors_allow_origin": "http://localhost:8080",
+            "container_labels_from_file": "container-labels.yaml",
+            "env_file": ".env",
+            "no_memory_limit": True,
+            "throttle_rate": 10.0,
+            "aws_endpoint_url": "http://localstack:4566",
+            "max_concurrent_invocations": 4,
+            "invocation_queue_timeout": 30.0,
+            "add_host": ["db.local:192.168.1.10"],
+            "import_values": "exports.json",
+            "container_labels": ["team=serverless"],
+            "shutdown_timeout": 5,
+            "hook_name": "terraform",
+            "resource_attributes": "attributes.json",
+            "cloudwatch_style_logs": True,
+            "container_entrypoint_override": "/opt/wrapper /var/rapid/aws-lambda-rie",
+            "runtime_image_override": ["python3.8=registry.example.com/lambda-python3.8:1.0"],
+            "force_linux_paths": True,
+            "no_build_artifacts": True,
         }
 
         # NOTE: Because we don't load the full Click BaseCommand here, this is mounted as top-level command
@@ -377,6 +474,35 @@ class TestSamConfigForAllCommands(TestCase):
                 None,
                 "localhost",
                 "127.0.0.1",
+                ("image/png",),
+                (),
+                "sam-local",
+                "api.example.com",
+                "https",
+                "1g",
+                True,
+                True,
+                True,
+                True,
+                "http://localhost:8080",
+                "container-labels.yaml",
+                ".env",
+                True,
+                10.0,
+                "http://localstack:4566",
+                4,
+                30.0,
+                (("db.local", "192.168.1.10"),),
+                "exports.json",
+                (("team", "serverless"),),
+                5,
+                "terraform",
+                "attributes.json",
+                True,
+                ["/opt/wrapper", "/var/rapid/aws-lambda-rie"],
+                (("python3.8", "registry.example.com/lambda-python3.8:1.0"),),
+                True,
+                True,
             )
 
     @patch("samcli.commands.local.start_lambda.cli.do_cli")
@@ -399,6 +525,27 @@ class TestSamConfigForAllCommands(TestCase):
             "force_image_build": True,
             "shutdown": False,
             "parameter_overrides": "ParameterKey=Key,ParameterValue=Value",
+            "debug_port_host": [4, 5, 6],
+            "container_hostname": "sam-local",
+            "shm_size": "1g",
+            "cpu_proportional": True,
+            "use_build_artifacts": True,
+            "buffered_output": True,
+            "container_labels_from_file": "container-labels.yaml",
+            "env_file": ".env",
+            "no_memory_limit": True,
+            "aws_endpoint_url": "http://localstack:4566",
+            "add_host": ["db.local:192.168.1.10"],
+            "import_values": "exports.json",
+            "container_labels": ["team=serverless"],
+            "shutdown_timeout": 5,
+            "hook_name": "terraform",
+            "resource_attributes": "attributes.json",
+            "cloudwatch_style_logs": True,
+            "container_entrypoint_override": "/opt/wrapper /var/rapid/aws-lambda-rie",
+            "runtime_image_override": ["python3.8=registry.example.com/lambda-python3.8:1.0"],
+            "force_linux_paths": True,
+            "no_build_artifacts": True,
         }
 
         # NOTE: Because we don't load the full Click BaseCommand here, this is mounted as top-level command
@@ -438,6 +585,27 @@ class TestSamConfigForAllCommands(TestCase):
                 None,
                 "localhost",
                 "127.0.0.1",
+                (4, 5, 6),
+                "sam-local",
+                "1g",
+                True,
+                True,
+                True,
+                "container-labels.yaml",
+                ".env",
+                True,
+                "http://localstack:4566",
+                (("db.local", "192.168.1.10"),),
+                "exports.json",
+                (("team", "serverless"),),
+                5,
+                "terraform",
+                "attributes.json",
+                True,
+                ["/opt/wrapper", "/var/rapid/aws-lambda-rie"],
+                (("python3.8", "registry.example.com/lambda-python3.8:1.0"),),
+                True,
+                True,
             )
 
     @patch("samcli.lib.cli_validation.image_repository_validation.get_template_function_resource_ids")
@@ -890,6 +1058,10 @@ class TestSamConfigWithOverrides(TestCase):
                 None,
                 "localhost",
                 "127.0.0.1",
+                (),
+                None,
+                None,
+                False,
             )
 
     @patch("samcli.commands.local.start_lambda.cli.do_cli")
@@ -983,6 +1155,10 @@ class TestSamConfigWithOverrides(TestCase):
                 None,
                 "localhost",
                 "127.0.0.1",
+                (),
+                None,
+                None,
+                False,
             )
 
     @patch("samcli.commands.validate.validate.do_cli")
@@ -1004,7 +1180,7 @@ class TestSamConfigWithOverrides(TestCase):
                 LOG.exception("Command failed", exc_info=result.exc_info)
             self.assertIsNone(result.exception)
 
-            do_cli_mock.assert_called_with(ANY, str(Path(os.getcwd(), "mytemplate.yaml")))
+            do_cli_mock.assert_called_with(ANY, str(Path(os.getcwd(), "mytemplate.yaml")), False, False)
 
 
 @contextmanager
@@ -0,0 +1,202 @@
+from unittest import TestCase
+
+from samcli.commands.validate.lib.sam_template_linter import LintFinding, get_lint_findings
+
+TEMPLATE = """AWSTemplateFormatVersion: '2010-09-09'
+Transform: AWS::Serverless-2016-10-31
+Parameters:
+  Stage:
+    Type: String
+Resources:
+  OrdersApi:
+    Type: AWS::Serverless::Api
+    Properties:
+      StageName: !Ref Stage
+  OrdersFunction:
+    Type: AWS::Serverless::Function
+    Properties:
+      Handler: app.handler
+      Runtime: python3.9
+      Environment:
+        Variables:
+          TABLE: !Ref OrdersTable
+          ROLE_ARN: !GetAtt OrdersFunctionRole.Arn
+          API_URL: !Sub "https://${OrdersApi}.execute-api.${AWS::Region}.amazonaws.com/${Stage}"
+      Events:
+        Create:
+          Type: Api
+          Properties:
+            RestApiId: !Ref OrdersApi
+            Path: /orders
+            Method: post
+        Implicit:
+          Type: HttpApi
+          Properties:
+            Path: /orders
+            Method: get
+  OrdersTable:
+    Type: AWS::Serverless::SimpleTable
+Outputs:
+  ApiUrl:
+    Value: !Ref OrdersApi.Stage
+"""
+
+
+class TestGetLintFindings(TestCase):
+    def test_must_return_no_findings_for_valid_template(self):
+        self.assertEqual(get_lint_findings(TEMPLATE), [])
+
+    def test_must_report_orphaned_references(self):
+        template = """Parameters:
+  BucketName:
+    Type: String
+Resources:
+  ReportFunction:
+    Type: AWS::Serverless::Function
+    Properties:
+      Handler: app.handler
+      Role: !GetAtt ReportRole.Arn
+      Policies:
+        - S3ReadPolicy:
+            BucketName: !Ref ReportBucketName
+        - Statement:
+            - Effect: Allow
+              Action: s3:PutObject
+              Resource: !Sub "arn:${AWS::Partition}:s3:::${Bucket}/${!Literal}"
+        - SQSPollerPolicy:
+            QueueName: !Sub
+              - "${Name}-${Suffix}"
+              - Name: !Ref BucketName
+      Events:
+        Schedule:
+          Type: Schedule
+          Properties:
+            Schedule: rate(1 hour)
+"""
+
+        self.assertEqual(
+            get_lint_findings(template),
+            [
+                LintFinding(
+                    "ERROR",
+                    "Resources.ReportFunction.Properties.Role",
+                    (9, 13),
+                    "Fn::GetAtt references missing resource ReportRole",
+                ),
+                LintFinding(
+                    "ERROR",
+                    "Resources.ReportFunction.Properties.Policies.0.S3ReadPolicy.BucketName",
+                    (12, 25),
+                    "Ref references undefined parameter or resource ReportBucketName",
+                ),
+                LintFinding(
+                    "ERROR",
+                    "Resources.ReportFunction.Properties.Policies.1.Statement.0.Resource",
+                    (16, 25),
+                    "Fn::Sub references undefined parameter or resource Bucket",
+                ),
+                LintFinding(
+                    "ERROR",
+                    "Resources.ReportFunction.Properties.Policies.2.SQSPollerPolicy.QueueName",
+                    (18, 24),
+                    "Fn::Sub references undefined parameter or resource Suffix",
+                ),
+            ],
+        )
+
+    def test_must_report_dangling_events_and_functions_without_events(self):
+        template = """Resources:
+  OrdersFunction:
+    Type: AWS::Serverless::Function
+    Properties:
+      Handler: app.handler
+      Events:
+        Create:
+          Type: Api
+          Properties:
+            RestApiId: !Ref OrdersApi
+            Path: /orders
+            Method: post
+        List:
+          Type: HttpApi
+          Properties:
+            ApiId: !Ref OrdersTable
+  OrdersTable:
+    Type: AWS::Serverless::SimpleTable
+  CleanupFunction:
+    Type: AWS::Serverless::Function
+    Properties:
+      Handler: app.handler
+"""
+
+        findings = get_lint_findings(template)
+
+        self.assertEqual(
+            findings,
+            [
+                LintFinding(
+                    "ERROR",
+                    "Resources.OrdersFunction.Properties.Events.Create.Properties.RestApiId",
+                    (10, 24),
+                    "Event Create references API OrdersApi, which does not exist",
+                ),
+                LintFinding(
+                    "ERROR",
+                    "Resources.OrdersFunction.Properties.Events.List.Properties.ApiId",
+                    (16, 20),
+                    "Event List references OrdersTable of type AWS::Serverless::SimpleTable, which is not an API",
+                ),
+                LintFinding(
+                    "WARNING",
+                    "Resources.CleanupFunction",
+                    (20, 5),
+                    "Function CleanupFunction has no event sources, it can only be invoked directly",
+                ),
+            ],
+        )
+        self.assertEqual(
+            str(findings[2]),
+            "[WARNING] Resources.CleanupFunction (line 20, column 5): "
+            "Function CleanupFunction has no event sources, it can only be invoked directly",
+        )
+
+    def test_must_report_findings_of_json_template(self):
+        template = '{"Resources": {"Queue": {"Type": "AWS::SQS::Queue", "Properties": {"Tags": [{"Ref": "Missing"}]}}}}'
+
+        self.assertEqual(
+            get_lint_findings(template),
+            [
+                LintFinding(
+                    "ERROR",
+                    "Resources.Queue.Properties.Tags.0",
+                    (1, 77),
+                    "Ref references undefined parameter or resource Missing",
+                )
+            ],
+        )
+
+    def test_must_report_every_missing_reference_of_a_path_once(self):
+        template = """Resources:
+  Queue:
+    Type: AWS::SQS::Queue
+    Properties:
+      QueueName: !Sub "${Prefix}-${Name}-${Prefix}"
+"""
+
+        self.assertEqual(
+            get_lint_findings(template),
+            [
+                LintFinding(
+                    "ERROR",
+                    "Resources.Queue.Properties.QueueName",
+                    (5, 18),
+                    "Fn::Sub references undefined parameter or resource Prefix",
+                ),
+                LintFinding(
+                    "ERROR",
+                    "Resources.Queue.Properties.QueueName",
+                    (5, 18),
+                    "Fn::Sub references undefined parameter or resource Name",
+                ),
+            ],
+        )
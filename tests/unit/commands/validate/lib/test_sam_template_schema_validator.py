@@ -0,0 +1,84 @@
+from collections import deque
+from unittest import TestCase
+from unittest.mock import Mock, patch
+
+from samcli.commands.validate.lib.sam_template_schema_validator import get_schema_violations, _read_schema
+
+TEMPLATE = """AWSTemplateFormatVersion: '2010-09-09'
+Transform: AWS::Serverless-2016-10-31
+Resources:
+  MyFunction:
+    Type: AWS::Serverless::Function
+    Properties:
+      Handler: app.handler
+      Runtime: !Ref Runtime
+      Events:
+        - Api
+"""
+
+
+class TestGetSchemaViolations(TestCase):
+    def setUp(self):
+        self.validator_for_patch = patch("samcli.commands.validate.lib.sam_template_schema_validator.validator_for")
+        self.read_schema_patch = patch("samcli.commands.validate.lib.sam_template_schema_validator._read_schema")
+        self.validator_for_mock = self.validator_for_patch.start()
+        self.read_schema_mock = self.read_schema_patch.start()
+        self.read_schema_mock.return_value = {"schema": "value"}
+        self.validator_mock = Mock()
+        self.validator_for_mock.return_value.return_value = self.validator_mock
+
+    def tearDown(self):
+        self.validator_for_patch.stop()
+        self.read_schema_patch.stop()
+
+    def test_must_return_no_violations_for_valid_template(self):
+        self.validator_mock.iter_errors.return_value = []
+
+        self.assertEqual(get_schema_violations(TEMPLATE), [])
+
+        self.validator_for_mock.assert_called_once_with({"schema": "value"})
+        self.validator_for_mock.return_value.assert_called_once_with({"schema": "value"})
+
+    def test_must_report_line_and_column_of_each_violation(self):
+        self.validator_mock.iter_errors.return_value = [
+            Mock(absolute_path=deque(["Resources", "MyFunction", "Properties", "Events"]), message="not an object"),
+            Mock(absolute_path=deque(["Resources", "MyFunction", "Properties", "Handler"]), message="bad handler"),
+            Mock(absolute_path=deque(["Resources", "MyFunction", "Properties", "Events", 0]), message="bad event"),
+        ]
+
+        self.assertEqual(
+            get_schema_violations(TEMPLATE),
+            [
+                "Resources.MyFunction.Properties.Handler (line 7, column 16): bad handler",
+                "Resources.MyFunction.Properties.Events (line 10, column 9): not an object",
+                "Resources.MyFunction.Properties.Events.0 (line 10, column 11): bad event",
+            ],
+        )
+
+    def test_must_report_violations_without_position(self):
+        self.validator_mock.iter_errors.return_value = [
+            Mock(absolute_path=deque([]), message="Resources is required"),
+            Mock(absolute_path=deque(["Resources", "Unknown"]), message="unknown resource"),
+        ]
+
+        self.assertEqual(
+            get_schema_violations(TEMPLATE),
+            ["Template (line 1, column 1): Resources is required", "Resources.Unknown: unknown resource"],
+        )
+
+
+class TestReadSchema(TestCase):
+    @patch("samcli.commands.validate.lib.sam_template_schema_validator.pkgutil")
+    def test_must_read_the_schema_packaged_with_the_translator(self, pkgutil_mock):
+        pkgutil_mock.get_data.return_value = b'{"schema": "value"}'
+
+        self.assertEqual(_read_schema(), {"schema": "value"})
+
+        pkgutil_mock.get_data.assert_called_once_with("samtranslator.validator", "sam_schema/schema.json")
+
+    @patch("samcli.commands.validate.lib.sam_template_schema_validator.pkgutil")
+    def test_must_raise_if_the_schema_is_not_packaged(self, pkgutil_mock):
+        pkgutil_mock.get_data.return_value = None
+
+        with self.assertRaises(FileNotFoundError):
+            _read_schema()
@@ -70,6 +70,76 @@ class TestSamTemplateValidator(TestCase):
         translate_mock.translate.assert_called_once_with(sam_template=template, parameter_values={})
         sam_parser.Parser.assert_called_once()
 
+    @patch("samcli.commands.validate.lib.sam_template_validator.Session")
+    @patch("samcli.commands.validate.lib.sam_template_validator.Translator")
+    @patch("samcli.commands.validate.lib.sam_template_validator.parser")
+    def test_is_valid_skips_connectors(self, sam_parser, sam_translator, boto_session_patch):
+        managed_policy_mock = Mock()
+        managed_policy_mock.load.return_value = {"policy": "SomePolicy"}
+        template = {
+            "Resources": {
+                "MyTable": {"Type": "AWS::Serverless::SimpleTable"},
+                "MyConnector": {"Type": "AWS::Serverless::Connector", "Properties": {}},
+            }
+        }
+
+        translate_mock = Mock()
+        translate_mock.translate.return_value = {"c": "d"}
+        sam_translator.return_value = translate_mock
+
+        validator = SamTemplateValidator(template, managed_policy_mock)
+        validator.is_valid()
+
+        translate_mock.translate.assert_called_once_with(
+            sam_template={"Resources": {"MyTable": {"Type": "AWS::Serverless::SimpleTable"}}}, parameter_values={}
+        )
+        self.assertEqual(validator.connectors, ["MyConnector"])
+
+    @patch("samcli.commands.validate.lib.sam_template_validator.Session")
+    @patch("samcli.commands.validate.lib.sam_template_validator.Translator")
+    @patch("samcli.commands.validate.lib.sam_template_validator.parser")
+    def test_is_valid_collects_vpc_configs(self, sam_parser, sam_translator, boto_session_patch):
+        managed_policy_mock = Mock()
+        managed_policy_mock.load.return_value = {"policy": "SomePolicy"}
+        template = {
+            "Parameters": {"SubnetId": {"Type": "String", "Default": "subnet-1"}},
+            "Globals": {"Function": {"VpcConfig": {"SecurityGroupIds": ["sg-global"]}}},
+            "Resources": {
+                "MyFunction": {
+                    "Type": "AWS::Serverless::Function",
+                    "Properties": {
+                        "CodeUri": ".",
+                        "VpcConfig": {"SubnetIds": [{"Ref": "SubnetId"}, {"Fn::ImportValue": "OtherSubnet"}]},
+                    },
+                },
+                "MyLambdaFunction": {
+                    "Type": "AWS::Lambda::Function",
+                    "Properties": {
+                        "VpcConfig": {"SubnetIds": ["subnet-2"], "SecurityGroupIds": [{"Ref": "MySecurityGroup"}]}
+                    },
+                },
+                "MySecurityGroup": {"Type": "AWS::EC2::SecurityGroup"},
+            },
+        }
+
+        translate_mock = Mock()
+        translate_mock.translate.return_value = {"c": "d"}
+        sam_translator.return_value = translate_mock
+
+        validator = SamTemplateValidator(template, managed_policy_mock)
+        validator.is_valid()
+
+        self.assertEqual(
+            validator.vpc_configs,
+            {
+                "MyFunction": {
+                    "SecurityGroupIds": ["sg-global"],
+                    "SubnetIds": ["subnet-1", {"Fn::ImportValue": "OtherSubnet"}],
+                },
+                "MyLambdaFunction": {"SubnetIds": ["subnet-2"], "SecurityGroupIds": ["MySecurityGroup"]},
+            },
+        )
+
     def test_init(self):
         managed_policy_mock = Mock()
         template = {"a": "b"}
@@ -81,6 +151,8 @@ class TestSamTemplateValidator(TestCase):
 
         # check to see if SamParser was created
         self.assertIsNotNone(validator.sam_parser)
+        self.assertEqual(validator.connectors, [])
+        self.assertEqual(validator.vpc_configs, {})
 
     def test_uri_is_s3_uri(self):
         self.assertTrue(SamTemplateValidator.is_s3_uri("s3://bucket/key"))
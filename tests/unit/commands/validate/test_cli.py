@@ -1,13 +1,18 @@
+import os
+import tempfile
 from unittest import TestCase
-from unittest.mock import Mock, patch
+from unittest.mock import ANY, Mock, patch
 from collections import namedtuple
 
+from click.testing import CliRunner
+
 from botocore.exceptions import NoCredentialsError
 
 from samcli.commands.exceptions import UserException
 from samcli.commands.local.cli_common.user_exceptions import SamTemplateNotFoundException, InvalidSamTemplateException
 from samcli.commands.validate.lib.exceptions import InvalidSamDocumentException
-from samcli.commands.validate.validate import do_cli, _read_sam_file
+from samcli.commands.validate.lib.sam_template_linter import LintFinding
+from samcli.commands.validate.validate import cli, do_cli, _read_sam_file
 
 ctx_mock = namedtuple("ctx", ["profile", "region"])
 
@@ -49,7 +54,7 @@ class TestValidateCli(TestCase):
         template_valiadator.return_value = is_valid_mock
 
         with self.assertRaises(InvalidSamTemplateException):
-            do_cli(ctx=ctx_mock(profile="profile", region="region"), template=template_path)
+            do_cli(ctx=ctx_mock(profile="profile", region="region"), template=template_path, schema=False, lint=False)
 
     @patch("samcli.commands.validate.lib.sam_template_validator.SamTemplateValidator")
     @patch("samcli.commands.validate.validate.click")
@@ -63,7 +68,7 @@ class TestValidateCli(TestCase):
         template_valiadator.return_value = is_valid_mock
 
         with self.assertRaises(UserException):
-            do_cli(ctx=ctx_mock(profile="profile", region="region"), template=template_path)
+            do_cli(ctx=ctx_mock(profile="profile", region="region"), template=template_path, schema=False, lint=False)
 
     @patch("samcli.commands.validate.lib.sam_template_validator.SamTemplateValidator")
     @patch("samcli.commands.validate.validate.click")
@@ -74,6 +79,163 @@ class TestValidateCli(TestCase):
 
         is_valid_mock = Mock()
         is_valid_mock.is_valid.return_value = True
+        is_valid_mock.connectors = []
+        is_valid_mock.vpc_configs = {}
+        template_valiadator.return_value = is_valid_mock
+
+        do_cli(ctx=ctx_mock(profile="profile", region="region"), template=template_path, schema=False, lint=False)
+
+    @patch("samcli.commands.validate.lib.sam_template_validator.SamTemplateValidator")
+    @patch("samcli.commands.validate.validate.click")
+    @patch("samcli.commands.validate.validate._read_sam_file")
+    def test_template_passes_validation_with_connectors(self, read_sam_file_patch, click_patch, template_valiadator):
+        template_path = "path_to_template"
+        read_sam_file_patch.return_value = {"a": "b"}
+
+        is_valid_mock = Mock()
+        is_valid_mock.is_valid.return_value = True
+        is_valid_mock.connectors = ["MyConnector"]
+        is_valid_mock.vpc_configs = {}
+        template_valiadator.return_value = is_valid_mock
+
+        do_cli(ctx=ctx_mock(profile="profile", region="region"), template=template_path, schema=False, lint=False)
+
+        click_patch.secho.assert_called_with(
+            "Resource MyConnector of type AWS::Serverless::Connector is recognized but not simulated locally",
+            fg="yellow",
+        )
+
+    @patch("samcli.commands.validate.lib.sam_template_validator.SamTemplateValidator")
+    @patch("samcli.commands.validate.validate.click")
+    @patch("samcli.commands.validate.validate._read_sam_file")
+    def test_template_passes_validation_with_vpc_config(self, read_sam_file_patch, click_patch, template_valiadator):
+        template_path = "path_to_template"
+        read_sam_file_patch.return_value = {"a": "b"}
+
+        is_valid_mock = Mock()
+        is_valid_mock.is_valid.return_value = True
+        is_valid_mock.connectors = []
+        is_valid_mock.vpc_configs = {
+            "MyFunction": {"SubnetIds": ["subnet-1", {"Fn::ImportValue": "Subnet"}], "SecurityGroupIds": ["sg-1"]}
+        }
+        template_valiadator.return_value = is_valid_mock
+
+        do_cli(ctx=ctx_mock(profile="profile", region="region"), template=template_path, schema=False, lint=False)
+
+        click_patch.secho.assert_called_with(
+            'Function MyFunction is configured to run in a VPC (SubnetIds: subnet-1, {"Fn::ImportValue": "Subnet"}, '
+            "SecurityGroupIds: sg-1), but VPC networking is not simulated locally",
+            fg="yellow",
+        )
+
+    @patch("samcli.commands.validate.lib.sam_template_schema_validator.get_schema_violations")
+    @patch("samcli.commands.validate.lib.sam_template_validator.SamTemplateValidator")
+    @patch("samcli.commands.validate.validate.click")
+    @patch("samcli.commands.validate.validate._read_sam_file")
+    def test_template_fails_schema_validation(
+        self, read_sam_file_patch, click_patch, template_valiadator, get_schema_violations_patch
+    ):
+        template_path = "path_to_template"
+        read_sam_file_patch.return_value = {"a": "b"}
+        get_schema_violations_patch.return_value = ["Resources.MyFunction (line 3, column 5): bad property"]
+
+        with self.assertRaises(InvalidSamTemplateException):
+            do_cli(ctx=ctx_mock(profile="profile", region="region"), template=template_path, schema=True, lint=False)
+
+        click_patch.secho.assert_called_with("Resources.MyFunction (line 3, column 5): bad property", fg="red")
+        template_valiadator.assert_not_called()
+
+    @patch("samcli.commands.validate.lib.sam_template_schema_validator.get_schema_violations")
+    @patch("samcli.commands.validate.lib.sam_template_validator.SamTemplateValidator")
+    @patch("samcli.commands.validate.validate.click")
+    @patch("samcli.commands.validate.validate._read_sam_file")
+    def test_template_passes_schema_validation(
+        self, read_sam_file_patch, click_patch, template_valiadator, get_schema_violations_patch
+    ):
+        template_path = "path_to_template"
+        read_sam_file_patch.return_value = {"a": "b"}
+        get_schema_violations_patch.return_value = []
+
+        is_valid_mock = Mock()
+        is_valid_mock.is_valid.return_value = True
+        is_valid_mock.connectors = []
+        is_valid_mock.vpc_configs = {}
+        template_valiadator.return_value = is_valid_mock
+
+        do_cli(ctx=ctx_mock(profile="profile", region="region"), template=template_path, schema=True, lint=False)
+
+        click_patch.open_file.assert_called_with(template_path, "r", encoding="utf-8")
+        is_valid_mock.is_valid.assert_called_once_with()
+
+    @patch("samcli.commands.validate.lib.sam_template_linter.get_lint_findings")
+    @patch("samcli.commands.validate.lib.sam_template_validator.SamTemplateValidator")
+    @patch("samcli.commands.validate.validate.click")
+    @patch("samcli.commands.validate.validate._read_sam_file")
+    def test_template_fails_lint_with_errors(
+        self, read_sam_file_patch, click_patch, template_valiadator, get_lint_findings_patch
+    ):
+        template_path = "path_to_template"
+        read_sam_file_patch.return_value = {"a": "b"}
+        get_lint_findings_patch.return_value = [
+            LintFinding("WARNING", "Resources.MyFunction", (3, 3), "no event sources"),
+            LintFinding("ERROR", "Resources.MyFunction.Properties.Role", (6, 13), "missing resource"),
+        ]
+
+        with self.assertRaises(InvalidSamTemplateException):
+            do_cli(ctx=ctx_mock(profile="profile", region="region"), template=template_path, schema=False, lint=True)
+
+        click_patch.secho.assert_any_call(
+            "[WARNING] Resources.MyFunction (line 3, column 3): no event sources", fg="yellow"
+        )
+        click_patch.secho.assert_any_call(
+            "[ERROR] Resources.MyFunction.Properties.Role (line 6, column 13): missing resource", fg="red"
+        )
+        template_valiadator.assert_not_called()
+
+    @patch("samcli.commands.validate.lib.sam_template_linter.get_lint_findings")
+    @patch("samcli.commands.validate.lib.sam_template_validator.SamTemplateValidator")
+    @patch("samcli.commands.validate.validate.click")
+    @patch("samcli.commands.validate.validate._read_sam_file")
+    def test_template_passes_lint_with_warnings(
+        self, read_sam_file_patch, click_patch, template_valiadator, get_lint_findings_patch
+    ):
+        template_path = "path_to_template"
+        read_sam_file_patch.return_value = {"a": "b"}
+        get_lint_findings_patch.return_value = [
+            LintFinding("WARNING", "Resources.MyFunction", None, "no event sources"),
+        ]
+
+        is_valid_mock = Mock()
+        is_valid_mock.is_valid.return_value = True
+        is_valid_mock.connectors = []
+        is_valid_mock.vpc_configs = {}
         template_valiadator.return_value = is_valid_mock
 
-        do_cli(ctx=ctx_mock(profile="profile", region="region"), template=template_path)
+        do_cli(ctx=ctx_mock(profile="profile", region="region"), template=template_path, schema=False, lint=True)
+
+        click_patch.secho.assert_any_call("[WARNING] Resources.MyFunction: no event sources", fg="yellow")
+        is_valid_mock.is_valid.assert_called_once_with()
+
+    @patch("samcli.commands._utils.options.atexit")
+    @patch("samcli.commands.validate.validate.do_cli")
+    def test_template_piped_to_stdin(self, do_cli_patch, atexit_patch):
+        template_body = "Resources:\n  MyFunction:\n    Type: AWS::Serverless::Function\n"
+        piped_templates = []
+        do_cli_patch.side_effect = lambda ctx, template, schema, lint: piped_templates.append(
+            (template, _read_sam_file(template))
+        )
+
+        cwd = os.getcwd()
+        with tempfile.TemporaryDirectory() as working_dir:
+            os.chdir(working_dir)
+            try:
+                result = CliRunner().invoke(cli, ["--template", "-"], input=template_body)
+            finally:
+                os.chdir(cwd)
+
+        self.assertIsNone(result.exception)
+        ((template, template_dict),) = piped_templates
+        # Local paths of the template are relative to the current directory
+        self.assertEqual(os.path.dirname(template), os.path.realpath(working_dir))
+        self.assertEqual(template_dict, {"Resources": {"MyFunction": {"Type": "AWS::Serverless::Function"}}})
+        atexit_patch.register.assert_called_once_with(ANY, template)
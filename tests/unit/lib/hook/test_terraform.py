@@ -0,0 +1,220 @@
+import json
+import os
+import shutil
+import tempfile
+from unittest import TestCase
+
+from samcli.lib.hook import prepare_hook_template
+from samcli.lib.hook.terraform import InvalidTerraformPlanException, prepare_template, translate_plan
+from samcli.lib.providers.sam_function_provider import SamFunctionProvider
+from samcli.lib.providers.sam_stack_provider import SamLocalStackProvider
+
+
+def lambda_function(address, **values):
+    return {"address": address, "mode": "managed", "type": "aws_lambda_function", "name": "fn", "values": values}
+
+
+class TestTranslatePlan(TestCase):
+    def test_must_translate_zip_function(self):
+        plan = {
+            "planned_values": {
+                "root_module": {
+                    "resources": [
+                        lambda_function(
+                            "aws_lambda_function.hello",
+                            function_name="hello-world",
+                            handler="app.handler",
+                            runtime="python3.8",
+                            filename="build/hello.zip",
+                            timeout=10,
+                            memory_size=256,
+                            layers=["arn:aws:lambda:us-east-1:123456789012:layer:deps:1"],
+                            environment=[{"variables": {"TABLE": "orders"}}],
+                        ),
+                        {"address": "aws_s3_bucket.b", "mode": "managed", "type": "aws_s3_bucket", "values": {}},
+                    ]
+                }
+            }
+        }
+
+        template = translate_plan(plan, "/project")
+
+        self.assertEqual(
+            template["Resources"],
+            {
+                "AwsLambdaFunctionHello": {
+                    "Type": "AWS::Serverless::Function",
+                    "Properties": {
+                        "FunctionName": "hello-world",
+                        "Handler": "app.handler",
+                        "Runtime": "python3.8",
+                        "Timeout": 10,
+                        "MemorySize": 256,
+                        "Layers": ["arn:aws:lambda:us-east-1:123456789012:layer:deps:1"],
+                        "CodeUri": os.path.normpath("/project/build/hello.zip"),
+                        "Environment": {"Variables": {"TABLE": "orders"}},
+                    },
+                }
+            },
+        )
+
+    def test_must_mount_source_dir_of_archive_and_read_child_modules(self):
+        plan = {
+            "planned_values": {
+                "root_module": {
+                    "child_modules": [
+                        {
+                            "resources": [
+                                lambda_function(
+                                    'module.api.aws_lambda_function.handler["orders"]',
+                                    handler="index.handler",
+                                    runtime="nodejs14.x",
+                                    filename="./dist/orders.zip",
+                                )
+                            ]
+                        }
+                    ]
+                }
+            },
+            "prior_state": {
+                "values": {
+                    "root_module": {
+                        "resources": [
+                            {
+                                "address": "data.archive_file.orders",
+                                "mode": "data",
+                                "type": "archive_file",
+                                "values": {"output_path": "dist/orders.zip", "source_dir": "src/orders"},
+                            }
+                        ]
+                    }
+                }
+            },
+        }
+
+        template = translate_plan(plan, "/project")
+
+        function = template["Resources"]["ModuleApiAwsLambdaFunctionHandlerOrders"]
+        self.assertEqual(function["Properties"]["CodeUri"], os.path.normpath("/project/src/orders"))
+
+    def test_must_translate_image_function(self):
+        plan = {
+            "planned_values": {
+                "root_module": {
+                    "resources": [
+                        lambda_function(
+                            "aws_lambda_function.image",
+                            package_type="Image",
+                            image_uri="123456789012.dkr.ecr.us-east-1.amazonaws.com/app:latest",
+                        )
+                    ]
+                }
+            }
+        }
+
+        properties = translate_plan(plan, "/project")["Resources"]["AwsLambdaFunctionImage"]["Properties"]
+
+        self.assertEqual(properties["PackageType"], "Image")
+        self.assertEqual(properties["ImageUri"], "123456789012.dkr.ecr.us-east-1.amazonaws.com/app:latest")
+
+    def test_must_skip_function_without_known_code(self):
+        plan = {"planned_values": {"root_module": {"resources": [lambda_function("aws_lambda_function.x")]}}}
+
+        self.assertEqual(translate_plan(plan, "/project")["Resources"], {})
+
+    def test_must_skip_function_with_code_in_s3(self):
+        function = lambda_function("aws_lambda_function.s3", s3_bucket="bucket", s3_key="app.zip")
+        plan = {"planned_values": {"root_module": {"resources": [function]}}}
+
+        self.assertEqual(translate_plan(plan, "/project")["Resources"], {})
+
+    def test_must_fail_when_logical_ids_collide(self):
+        plan = {
+            "planned_values": {
+                "root_module": {
+                    "resources": [
+                        lambda_function("aws_lambda_function.my_fn", filename="a.zip"),
+                        lambda_function("aws_lambda_function.my-fn", filename="b.zip"),
+                    ]
+                }
+            }
+        }
+
+        with self.assertRaisesRegex(InvalidTerraformPlanException, "AwsLambdaFunctionMyFn"):
+            translate_plan(plan, "/project")
+
+    def test_must_fail_without_planned_values(self):
+        with self.assertRaises(InvalidTerraformPlanException):
+            translate_plan({"format_version": "1.0"}, "/project")
+
+
+class TestPrepareTemplate(TestCase):
+    def setUp(self):
+        self.project_dir = tempfile.mkdtemp()
+        self.addCleanup(shutil.rmtree, self.project_dir)
+
+    def test_plan_produces_invocable_function(self):
+        os.mkdir(os.path.join(self.project_dir, "src"))
+        plan = {
+            "format_version": "1.0",
+            "planned_values": {
+                "root_module": {
+                    "resources": [
+                        lambda_function(
+                            "aws_lambda_function.hello",
+                            function_name="hello-world",
+                            handler="main",
+                            runtime="go1.x",
+                            filename="hello.zip",
+                            timeout=5,
+                            memory_size=512,
+                            environment=[{"variables": {"STAGE": "dev"}}],
+                        )
+                    ]
+                }
+            },
+            "prior_state": {
+                "values": {
+                    "root_module": {
+                        "resources": [
+                            {
+                                "address": "data.archive_file.hello",
+                                "mode": "data",
+                                "type": "archive_file",
+                                "values": {"output_path": "hello.zip", "source_dir": "src"},
+                            }
+                        ]
+                    }
+                }
+            },
+        }
+        plan_file = os.path.join(self.project_dir, "plan.json")
+        with open(plan_file, "w") as fp:
+            json.dump(plan, fp)
+
+        template_file = prepare_hook_template("terraform", plan_file)
+        stacks, _ = SamLocalStackProvider.get_stacks(template_file)
+        function = SamFunctionProvider(stacks).get("hello-world")
+
+        self.assertIsNotNone(function)
+        self.assertEqual(function.name, "AwsLambdaFunctionHello")
+        self.assertEqual(function.handler, "main")
+        self.assertEqual(function.runtime, "go1.x")
+        self.assertEqual(function.timeout, 5)
+        self.assertEqual(function.memory, 512)
+        self.assertEqual(function.environment, {"Variables": {"STAGE": "dev"}})
+        self.assertEqual(function.codeuri, os.path.join(self.project_dir, "src"))
+
+    def test_must_fail_if_plan_is_not_json(self):
+        plan_file = os.path.join(self.project_dir, "plan.out")
+        with open(plan_file, "w") as fp:
+            fp.write("binary plan")
+
+        with self.assertRaises(InvalidTerraformPlanException):
+            prepare_template(plan_file)
+
+    def test_must_fail_if_plan_does_not_exist(self):
+        with self.assertRaises(InvalidTerraformPlanException) as ex_ctx:
+            prepare_template(os.path.join(self.project_dir, "template.yml"))
+
+        self.assertIn("terraform show -json", str(ex_ctx.exception))
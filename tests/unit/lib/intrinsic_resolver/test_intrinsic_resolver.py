@@ -164,6 +164,11 @@ class TestIntrinsicFnSelectResolver(TestCase):
         result = self.resolver.intrinsic_property_resolver(intrinsic, True)
         self.assertEqual(result, "g;h;i;a")
 
+    def test_fn_select_of_fn_split(self):
+        intrinsic = {"Fn::Select": [1, {"Fn::Split": [",", "a,b,c"]}]}
+        result = self.resolver.intrinsic_property_resolver(intrinsic, True)
+        self.assertEqual(result, "b")
+
     @parameterized.expand(
         [
             ("Fn::Select should fail for values that are not lists: {}".format(item), item)
@@ -225,6 +230,15 @@ class TestIntrinsicFnFindInMapResolver(TestCase):
         result = self.resolver.intrinsic_property_resolver(intrinsic, True)
         self.assertEqual(result, "final")
 
+    def test_find_in_map_with_ref_in_keys(self):
+        template = {"Mappings": {"RegionMap": {"us-east-1": {"AMI": "ami-0ff8a91507f77f867"}}}}
+        symbol_resolver = IntrinsicsSymbolTable(template=template, logical_id_translator={"AWS::Region": "us-east-1"})
+        resolver = IntrinsicResolver(template=template, symbol_resolver=symbol_resolver)
+
+        intrinsic = {"Fn::FindInMap": ["RegionMap", {"Ref": "AWS::Region"}, "AMI"]}
+        result = resolver.intrinsic_property_resolver(intrinsic, True)
+        self.assertEqual(result, "ami-0ff8a91507f77f867")
+
     @parameterized.expand(
         [
             ("Fn::FindInMap should fail if the list does not resolve to a string: {}".format(item), item)
@@ -289,6 +303,15 @@ class TestIntrinsicFnAzsResolver(TestCase):
         with self.assertRaises(InvalidIntrinsicException, msg=name):
             self.resolver.intrinsic_property_resolver({"Fn::GetAZs": intrinsic}, True)
 
+    def test_select_first_of_default_get_azs(self):
+        result = self.resolver.intrinsic_property_resolver({"Fn::Select": [0, {"Fn::GetAZs": ""}]}, True)
+        self.assertEqual(result, "us-east-1a")
+
+    @parameterized.expand([("ap-east-1",), ("cn-north-1",), ("us-gov-west-1",)])
+    def test_get_azs_of_region_without_known_azs(self, region):
+        result = self.resolver.intrinsic_property_resolver({"Fn::GetAZs": region}, True)
+        self.assertEqual(result, [region + "a", region + "b", region + "c"])
+
     def test_fn_azs_invalid_region(self):
         intrinsic = "UNKOWN REGION"
         with self.assertRaises(InvalidIntrinsicException, msg="FN::GetAzs should fail for unknown region"):
@@ -508,6 +531,16 @@ class TestIntrinsicFnSubResolver(TestCase):
         with self.assertRaises(InvalidIntrinsicException, msg=name):
             self.resolver.intrinsic_property_resolver({"Fn::Sub": intrinsic}, True)
 
+    def test_fn_sub_escaped_literal(self):
+        intrinsic = {
+            "Fn::Sub": [
+                "${!Literal} in ${AWS::Region}, ${!MyItem} is ${MyItem}",
+                {"MyItem": {"Ref": "AWS::AccountId"}},
+            ]
+        }
+        result = self.resolver.intrinsic_property_resolver(intrinsic, True)
+        self.assertEqual(result, "${Literal} in us-east-1, ${MyItem} is 123456789012")
+
     @parameterized.expand(
         [
             ("If Fn::Sub is a list, first argument must resolve to a string: {}".format(item), item)
@@ -547,6 +580,25 @@ class TestIntrinsicFnImportValueResolver(TestCase):
         with self.assertRaises(InvalidIntrinsicException, msg="Fn::ImportValue should be unsupported"):
             self.resolver.intrinsic_property_resolver({"Fn::ImportValue": ""}, True)
 
+    def test_fn_import_value_from_import_values(self):
+        resolver = IntrinsicResolver(
+            template={},
+            symbol_resolver=IntrinsicsSymbolTable(logical_id_translator={"Env": "prod"}),
+            import_values={"prod-TableName": "orders"},
+        )
+
+        result = resolver.intrinsic_property_resolver({"Fn::ImportValue": {"Fn::Sub": "${Env}-TableName"}}, True)
+
+        self.assertEqual(result, "orders")
+
+    def test_fn_import_value_missing_export(self):
+        resolver = IntrinsicResolver(
+            template={}, symbol_resolver=IntrinsicsSymbolTable(), import_values={"TableName": "orders"}
+        )
+
+        with self.assertRaises(InvalidIntrinsicException, msg="The export QueueUrl is not in the import values"):
+            resolver.intrinsic_property_resolver({"Fn::ImportValue": "QueueUrl"}, True)
+
 
 class TestIntrinsicFnEqualsResolver(TestCase):
     def setUp(self):
@@ -862,6 +914,20 @@ class TestIntrinsicFnIfResolver(TestCase):
         result = self.resolver.intrinsic_property_resolver(intrinsic, True)
         self.assertFalse(result)
 
+    def test_fn_if_nested_in_fn_join(self):
+        intrinsic = {
+            "Fn::Join": [
+                "-",
+                [
+                    {"Fn::If": ["TestCondition", "prod", "dev"]},
+                    {"Fn::If": ["NotTestCondition", "blue", {"Fn::If": ["TestCondition", "green", "red"]}]},
+                ],
+            ]
+        }
+
+        result = self.resolver.intrinsic_property_resolver(intrinsic, True)
+        self.assertEqual(result, "prod-green")
+
     @parameterized.expand(
         [
             ("Fn::If must an argument that resolves to a list: {}".format(item), item)
@@ -1071,6 +1137,55 @@ class TestResolveTemplate(TestCase):
         resolver = IntrinsicResolver(template=template, symbol_resolver=symbol_resolver)
         self.assertEqual(resolver.resolve_template(), expected_template)
 
+    def test_nested_intrinsics_in_policy_statements_resolved(self):
+        template = {
+            "Parameters": {"TableName": {"Default": "orders"}, "Stage": {"Default": "dev"}},
+            "Resources": {
+                "Test": {
+                    "Type": "AWS::IAM::Policy",
+                    "Properties": {
+                        "PolicyDocument": {
+                            "Statement": [
+                                {
+                                    "Effect": "Allow",
+                                    "Action": ["dynamodb:GetItem"],
+                                    "Resource": [
+                                        {"Fn::Sub": "arn:aws:dynamodb:us-east-1:123456789012:table/${TableName}"},
+                                        {"Fn::Join": ["/", [{"Fn::Sub": "${TableName}-${Stage}"}, "index", "*"]]},
+                                    ],
+                                    "Condition": {"StringEquals": {"aws:RequestTag/stage": [{"Ref": "Stage"}]}},
+                                },
+                                {
+                                    "Effect": "Allow",
+                                    "Action": ["sqs:SendMessage"],
+                                    "Resource": [{"Fn::Select": [3, ["queue-a", "queue-b"]]}, {"Ref": "Stage"}],
+                                },
+                            ]
+                        }
+                    },
+                }
+            },
+        }
+
+        expected_statements = [
+            {
+                "Effect": "Allow",
+                "Action": ["dynamodb:GetItem"],
+                "Resource": ["arn:aws:dynamodb:us-east-1:123456789012:table/orders", "orders-dev/index/*"],
+                "Condition": {"StringEquals": {"aws:RequestTag/stage": ["dev"]}},
+            },
+            {
+                "Effect": "Allow",
+                "Action": ["sqs:SendMessage"],
+                "Resource": [{"Fn::Select": [3, ["queue-a", "queue-b"]]}, "dev"],
+            },
+        ]
+
+        symbol_resolver = IntrinsicsSymbolTable(template=template, logical_id_translator={})
+        resolver = IntrinsicResolver(template=template, symbol_resolver=symbol_resolver)
+        resources = resolver.resolve_template(ignore_errors=True)["Resources"]
+        self.assertEqual(resources["Test"]["Properties"]["PolicyDocument"]["Statement"], expected_statements)
+
     def load_test_data(self, template_path):
         integration_path = str(Path(__file__).resolve().parents[0].joinpath("test_data", template_path))
         with open(integration_path) as f:
@@ -2,6 +2,8 @@ from unittest import TestCase
 
 from unittest.mock import patch
 
+from parameterized import parameterized
+
 from samcli.lib.intrinsic_resolver.invalid_intrinsic_exception import InvalidSymbolException
 from samcli.lib.intrinsic_resolver.intrinsic_property_resolver import IntrinsicResolver
 from samcli.lib.intrinsic_resolver.intrinsics_symbol_table import IntrinsicsSymbolTable
@@ -48,6 +50,17 @@ class TestIntrinsicsSymbolTablePseudoProperties(TestCase):
         mock_os.getenv.return_value = "cn-west-1"
         self.assertEqual(self.symbol_table.handle_pseudo_url_prefix(), "amazonaws.com.cn")
 
+    @parameterized.expand(
+        [
+            ("us-east-1", "aws", "amazonaws.com"),
+            ("us-gov-west-1", "aws-us-gov", "amazonaws.com"),
+            ("cn-north-1", "aws-cn", "amazonaws.com.cn"),
+        ]
+    )
+    def test_get_partition_and_url_suffix_of_region(self, region, expected_partition, expected_url_suffix):
+        self.assertEqual(IntrinsicsSymbolTable.get_partition(region), expected_partition)
+        self.assertEqual(IntrinsicsSymbolTable.get_url_suffix(region), expected_url_suffix)
+
     def test_get_availability_zone(self):
         res = IntrinsicsSymbolTable.get_availability_zone("us-east-1")
         self.assertIn("us-east-1a", res)
@@ -130,6 +143,27 @@ class TestSymbolResolution(TestCase):
         res = IntrinsicsSymbolTable(template=template).arn_resolver("LambdaFunction", service_name="lambda")
         self.assertEqual(res, "arn:aws:lambda:us-east-1:123456789012:function:function-name-override")
 
+    def test_simple_table_ref_with_table_name(self):
+        template = {
+            "Resources": {"MyTable": {"Type": "AWS::Serverless::SimpleTable", "Properties": {"TableName": "my-table"}}}
+        }
+        res = IntrinsicsSymbolTable(template=template).resolve_symbols("MyTable", IntrinsicResolver.REF)
+        self.assertEqual(res, "my-table")
+
+    def test_simple_table_ref_defaults_to_logical_id(self):
+        template = {
+            "Resources": {
+                "MyTable": {"Type": "AWS::Serverless::SimpleTable"},
+                "OtherTable": {
+                    "Type": "AWS::Serverless::SimpleTable",
+                    "Properties": {"TableName": {"Fn::Sub": "${AWS::StackName}-table"}},
+                },
+            }
+        }
+        symbol_resolver = IntrinsicsSymbolTable(template=template)
+        self.assertEqual(symbol_resolver.resolve_symbols("MyTable", IntrinsicResolver.REF), "MyTable")
+        self.assertEqual(symbol_resolver.resolve_symbols("OtherTable", IntrinsicResolver.REF), "OtherTable")
+
     def test_resolver_ignore_errors(self):
         resolver = IntrinsicsSymbolTable()
         res = resolver.resolve_symbols("UNKNOWN", "SOME UNKNOWN RESOURCE PROPERTY", ignore_errors=True)
@@ -0,0 +1,39 @@
+from unittest import TestCase
+from unittest.mock import patch
+
+from samcli.lib.samlib.wrapper import SamTranslatorWrapper, pop_connectors
+
+
+class TestPopConnectors(TestCase):
+    def test_must_remove_connectors(self):
+        function = {"Type": "AWS::Serverless::Function", "Properties": {}}
+        connector = {
+            "Type": "AWS::Serverless::Connector",
+            "Properties": {"Source": {"Id": "Function"}, "Destination": {"Id": "Table"}, "Permissions": ["Read"]},
+        }
+        template = {"Resources": {"Function": function, "Connector": connector}}
+
+        result = pop_connectors(template)
+
+        self.assertEqual(result, {"Connector": connector})
+        self.assertEqual(template, {"Resources": {"Function": function}})
+
+    def test_must_ignore_templates_without_resources(self):
+        template = {"Resources": "invalid"}
+
+        result = pop_connectors(template)
+
+        self.assertEqual(result, {})
+        self.assertEqual(template, {"Resources": "invalid"})
+
+
+class TestSamTranslatorWrapper_run_plugins(TestCase):
+    @patch("samcli.lib.samlib.wrapper.prepare_plugins")
+    @patch("samcli.lib.samlib.wrapper._SamParserReimplemented")
+    def test_must_put_connectors_back_when_the_parser_drops_the_resources(self, parser_mock, prepare_plugins_mock):
+        connector = {"Type": "AWS::Serverless::Connector", "Properties": {}}
+        parser_mock.return_value.parse.side_effect = lambda template, plugins: template.pop("Resources")
+
+        result = SamTranslatorWrapper({"Resources": {"Connector": connector}}).run_plugins()
+
+        self.assertEqual(result, {"Resources": {"Connector": connector}})
@@ -1,4 +1,5 @@
 from unittest import TestCase
+from unittest.mock import Mock, patch
 from parameterized import parameterized, param
 
 from samcli.lib.utils.colors import Colored
@@ -21,8 +22,61 @@ class TestColored(TestCase):
     def test_various_decorations(self, decoration_name, ansi_prefix):
         expected = ansi_prefix + self.msg + "\x1b[0m"
 
-        with_color = Colored()
+        with_color = Colored(colorize=True)
         without_color = Colored(colorize=False)
 
         self.assertEqual(expected, getattr(with_color, decoration_name)(self.msg))
         self.assertEqual(self.msg, getattr(without_color, decoration_name)(self.msg))
+
+
+class TestColored_colors_enabled(TestCase):
+    def setUp(self):
+        self.msg = "message"
+        self.stdout = Mock()
+        self.stdout.isatty.return_value = True
+        self.stderr = Mock()
+        self.stderr.isatty.return_value = True
+
+        for patcher in (
+            patch("samcli.lib.utils.colors.sys.stdout", self.stdout),
+            patch("samcli.lib.utils.colors.sys.stderr", self.stderr),
+            patch.dict("samcli.lib.utils.colors.os.environ", {}, clear=True),
+            patch.object(Colored, "_colors_disabled", False),
+        ):
+            patcher.start()
+            self.addCleanup(patcher.stop)
+
+    def assert_no_escape_codes(self, colored):
+        for decoration_name in ("red", "green", "cyan", "white", "yellow", "underline"):
+            self.assertEqual(getattr(colored, decoration_name)(self.msg), self.msg)
+
+    def test_must_color_on_terminals_by_default(self):
+        self.assertTrue(Colored().colorize)
+        self.assertEqual(Colored().red(self.msg), "\x1b[31mmessage\x1b[0m")
+
+    @parameterized.expand([param("stdout"), param("stderr")])
+    def test_must_not_color_when_output_is_not_a_terminal(self, stream_name):
+        getattr(self, stream_name).isatty.return_value = False
+
+        self.assert_no_escape_codes(Colored())
+
+    def test_must_not_color_when_output_is_closed(self):
+        self.stdout.isatty.side_effect = ValueError("I/O operation on closed file")
+
+        self.assert_no_escape_codes(Colored())
+
+    def test_must_not_color_when_colors_are_disabled(self):
+        Colored.disable_colors()
+
+        self.assert_no_escape_codes(Colored())
+        self.assert_no_escape_codes(Colored(colorize=True))
+
+    def test_must_not_color_with_no_color_environment_variable(self):
+        with patch.dict("samcli.lib.utils.colors.os.environ", {"NO_COLOR": "1"}):
+            self.assert_no_escape_codes(Colored())
+            self.assert_no_escape_codes(Colored(colorize=True))
+
+    def test_must_color_when_asked_to_even_if_output_is_not_a_terminal(self):
+        self.stdout.isatty.return_value = False
+
+        self.assertTrue(Colored(colorize=True).colorize)
@@ -0,0 +1,53 @@
+from unittest import TestCase
+
+from parameterized import parameterized
+
+from samcli.lib.utils.dotenv import parse_dotenv, DotenvParseError
+
+
+class TestParseDotenv(TestCase):
+    def test_must_parse_definitions(self):
+        content = "A=1\nB = two \nexport C=three\n"
+
+        self.assertEqual(parse_dotenv(content), {"A": "1", "B": "two", "C": "three"})
+
+    def test_must_skip_comments_and_blank_lines(self):
+        content = "# a comment\n\n   \nA=1\n  # an indented comment\nB=2 # a trailing comment\n"
+
+        self.assertEqual(parse_dotenv(content), {"A": "1", "B": "2"})
+
+    @parameterized.expand(
+        [
+            ('A="x=1&y=2"', "x=1&y=2"),
+            ("A='x=1&y=2'", "x=1&y=2"),
+            ("A=x=1", "x=1"),
+            ('A="a # not a comment"', "a # not a comment"),
+            ('A="with spaces" # comment', "with spaces"),
+            ('A="line\\nbreak \\"quoted\\""', 'line\nbreak "quoted"'),
+            ("A='line\\nliteral'", "line\\nliteral"),
+            ("A=", ""),
+            ('A=""', ""),
+            ("A=value#no-space", "value#no-space"),
+        ]
+    )
+    def test_must_parse_values(self, content, expected_value):
+        self.assertEqual(parse_dotenv(content), {"A": expected_value})
+
+    def test_later_definition_wins(self):
+        self.assertEqual(parse_dotenv("A=1\nA=2"), {"A": "2"})
+
+    @parameterized.expand(
+        [
+            ("A",),
+            ("=value",),
+            ("1A=value",),
+            ("A B=value",),
+            ('A="unterminated',),
+            ('A="quoted" trailing',),
+        ]
+    )
+    def test_must_raise_for_invalid_lines(self, content):
+        with self.assertRaises(DotenvParseError) as ex_ctx:
+            parse_dotenv("# comment\n" + content)
+
+        self.assertIn("Line 2", str(ex_ctx.exception))
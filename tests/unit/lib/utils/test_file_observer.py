@@ -276,6 +276,14 @@ class FileObserver_on_change(TestCase):
             "parent_path2": self._parent2_watcher_mock,
         }
 
+        # Run the debounced check right away
+        self.timer_patch = patch("samcli.lib.utils.file_observer.Timer")
+        self.timer_mock = self.timer_patch.start()
+        self.timer_mock.side_effect = lambda interval, function: Mock(start=function)
+
+    def tearDown(self):
+        self.timer_patch.stop()
+
     @patch("samcli.lib.utils.file_observer.Path")
     @patch("samcli.lib.utils.file_observer.calculate_checksum")
     def test_modification_event_got_fired_for_sub_path_and_check_sum_changed(self, calculate_checksum_mock, PathMock):
@@ -352,6 +360,40 @@ class FileObserver_on_change(TestCase):
         )
         self.on_change.assert_called_once_with(["parent_path1/path1"])
 
+    @patch("samcli.lib.utils.file_observer.Path")
+    @patch("samcli.lib.utils.file_observer.calculate_checksum")
+    def test_events_within_debounce_interval_are_reported_once(self, calculate_checksum_mock, PathMock):
+        timers = []
+        self.timer_mock.side_effect = lambda interval, function: timers.append(Mock(function=function)) or timers[-1]
+        PathMock.return_value.exists.return_value = True
+        calculate_checksum_mock.side_effect = ["1111", "2222"]
+
+        self.observer.on_change(Mock(src_path="parent_path1/path1/sub_path1"))
+        self.observer.on_change(Mock(src_path="parent_path1/path1/sub_path2"))
+        self.observer.on_change(Mock(src_path="parent_path2/path3/sub_path"))
+
+        self.timer_mock.assert_called_with(FileObserver.DEBOUNCE_INTERVAL, self.observer._on_debounced_change)
+        self.assertEqual(len(timers), 3)
+        timers[0].cancel.assert_called_once_with()
+        timers[1].cancel.assert_called_once_with()
+        timers[2].cancel.assert_not_called()
+        self.on_change.assert_not_called()
+
+        timers[2].function()
+
+        self.on_change.assert_called_once_with(["parent_path1/path1", "parent_path2/path3"])
+
+    @patch("samcli.lib.utils.file_observer.calculate_checksum")
+    def test_paths_unwatched_before_the_events_settle_down_are_not_reported(self, calculate_checksum_mock):
+        self.timer_mock.side_effect = None
+
+        self.observer.on_change(Mock(src_path="parent_path1/path1/sub_path"))
+        self.observer._observed_paths.pop("parent_path1/path1")
+        self.observer._on_debounced_change()
+
+        calculate_checksum_mock.assert_not_called()
+        self.on_change.assert_not_called()
+
 
 class FileObserver_start(TestCase):
     @patch("samcli.lib.utils.file_observer.Observer")
@@ -2,9 +2,16 @@
 Tests for StreamWriter
 """
 
+import io
+import re
 from unittest import TestCase
 
-from samcli.lib.utils.stream_writer import StreamWriter
+from samcli.lib.utils.stream_writer import (
+    StreamWriter,
+    CapturingStreamWriter,
+    LinePrefixingStreamWriter,
+    cloudwatch_log_prefix,
+)
 
 from unittest.mock import Mock
 
@@ -49,3 +56,52 @@ class TestStreamWriter(TestCase):
             writer.write(line)
             flush_mock.assert_called_once_with()
             flush_mock.reset_mock()
+
+
+class TestCapturingStreamWriter(TestCase):
+    def test_must_write_to_stream_and_capture(self):
+        stream_mock = Mock()
+
+        writer = CapturingStreamWriter(stream_mock)
+        writer.write(b"first ")
+        writer.write(b"second")
+
+        stream_mock.write.assert_called_with(b"second")
+        self.assertEqual(writer.getvalue(), b"first second")
+
+
+class TestLinePrefixingStreamWriter(TestCase):
+    def test_must_prefix_each_line_of_chunked_writes(self):
+        stream = io.BytesIO()
+        prefixes = iter(["1 ", "2 ", "3 "])
+
+        writer = LinePrefixingStreamWriter(StreamWriter(stream), lambda: next(prefixes))
+        writer.write(b"START RequestId: abc\nhello ")
+        writer.write(b"world\n")
+        writer.write(b"END")
+
+        self.assertEqual(stream.getvalue(), b"1 START RequestId: abc\n2 hello world\n3 END")
+
+    def test_must_prefix_str_lines(self):
+        stream = io.StringIO()
+
+        writer = LinePrefixingStreamWriter(StreamWriter(stream), lambda: "> ")
+        writer.write("first\nsecond\n")
+
+        self.assertEqual(stream.getvalue(), "> first\n> second\n")
+
+
+class TestCloudwatchLogPrefix(TestCase):
+    def test_must_prefix_log_line_with_log_stream_name_and_rfc3339_timestamp(self):
+        stream = io.BytesIO()
+        log_stream_name = "2021/09/01/[$LATEST]0123456789abcdef0123456789abcdef"
+
+        writer = LinePrefixingStreamWriter(StreamWriter(stream), cloudwatch_log_prefix(log_stream_name))
+        writer.write(b"INFO processing order\n")
+
+        self.assertRegex(
+            stream.getvalue().decode("utf-8"),
+            r"^{} \d{{4}}-\d{{2}}-\d{{2}}T\d{{2}}:\d{{2}}:\d{{2}}\.\d{{3}}Z INFO processing order\n$".format(
+                re.escape(log_stream_name)
+            ),
+        )
@@ -0,0 +1,33 @@
+import re
+from unittest import TestCase
+from unittest.mock import patch
+
+from samcli.lib.utils.xray import generate_trace_header, get_trace_header
+
+
+class TestGenerateTraceHeader(TestCase):
+    @patch("samcli.lib.utils.xray.time")
+    def test_must_start_trace_at_current_time(self, time_mock):
+        time_mock.time.return_value = 1465510280.5
+
+        header = generate_trace_header()
+
+        self.assertRegex(header, r"^Root=1-5759e988-[0-9a-f]{24};Sampled=1$")
+
+    def test_must_generate_a_new_trace_every_time(self):
+        root_ids = {re.match(r"Root=([^;]+)", generate_trace_header()).group(1) for _ in range(10)}
+
+        self.assertEqual(len(root_ids), 10)
+
+
+class TestGetTraceHeader(TestCase):
+    def test_must_wrap_root_trace_id(self):
+        self.assertEqual(
+            get_trace_header("1-5759e988-bd862e3fe1be46a994272793"),
+            "Root=1-5759e988-bd862e3fe1be46a994272793;Sampled=1",
+        )
+
+    def test_must_keep_tracing_header(self):
+        header = "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=0"
+
+        self.assertEqual(get_trace_header(header), header)
@@ -0,0 +1,35 @@
+from unittest import TestCase
+from unittest.mock import patch
+
+from samcli.local.apigw.invocation_limiter import InvocationLimiter, default_max_concurrent_invocations
+
+
+class TestInvocationLimiter(TestCase):
+    def test_must_allow_invocations_up_to_the_limit(self):
+        limiter = InvocationLimiter(2, timeout=0)
+
+        self.assertTrue(limiter.acquire())
+        self.assertTrue(limiter.acquire())
+        self.assertFalse(limiter.acquire())
+
+    def test_must_allow_invocation_once_another_finishes(self):
+        limiter = InvocationLimiter(1, timeout=0)
+
+        self.assertTrue(limiter.acquire())
+        limiter.release()
+
+        self.assertTrue(limiter.acquire())
+
+    def test_must_not_release_more_than_acquired(self):
+        limiter = InvocationLimiter(1)
+
+        with self.assertRaises(ValueError):
+            limiter.release()
+
+    @patch("samcli.local.apigw.invocation_limiter.os.cpu_count")
+    def test_default_must_depend_on_cpus(self, cpu_count_mock):
+        cpu_count_mock.return_value = 4
+        self.assertEqual(default_max_concurrent_invocations(), 8)
+
+        cpu_count_mock.return_value = None
+        self.assertEqual(default_max_concurrent_invocations(), 2)
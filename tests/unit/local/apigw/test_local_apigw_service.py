@@ -1,17 +1,21 @@
 import base64
 import copy
+import gzip
 import json
+import threading
+import time
+import zlib
 from datetime import datetime
 from unittest import TestCase
 
 from unittest.mock import Mock, patch, ANY, MagicMock
 from parameterized import parameterized, param
-from werkzeug.datastructures import Headers
+from werkzeug.datastructures import Headers, MIMEAccept
 
 from samcli.lib.providers.provider import Api
 from samcli.lib.providers.provider import Cors
 from samcli.local.apigw.local_apigw_service import LocalApigwService, Route, LambdaResponseParseException
-from samcli.local.lambdafn.exceptions import FunctionNotFound
+from samcli.local.lambdafn.exceptions import FunctionNotFound, FunctionTimeoutException
 
 
 class TestApiGatewayService(TestCase):
@@ -56,6 +60,11 @@ class TestApiGatewayService(TestCase):
         self.stderr = Mock()
         self.api = Api(routes=self.api_list_of_routes)
         self.http = Api(routes=self.http_list_of_routes)
+
+        request_patch = patch("samcli.local.apigw.local_apigw_service.request")
+        self.request_mock = request_patch.start()
+        self.request_mock.headers = {}
+        self.addCleanup(request_patch.stop)
         self.api_service = LocalApigwService(
             self.api, self.lambda_runner, port=3000, host="127.0.0.1", stderr=self.stderr
         )
@@ -85,8 +94,10 @@ class TestApiGatewayService(TestCase):
         result = self.api_service._request_handler()
 
         self.assertEqual(result, make_response_mock)
-        self.lambda_runner.invoke.assert_called_with(ANY, ANY, stdout=ANY, stderr=self.stderr)
-        self.api_service._construct_v_1_0_event.assert_called_with(ANY, ANY, ANY, ANY, ANY)
+        self.lambda_runner.invoke.assert_called_with(ANY, ANY, stdout=ANY, stderr=self.stderr, trace_id=ANY)
+        self.api_service._construct_v_1_0_event.assert_called_with(
+            ANY, ANY, ANY, ANY, ANY, forwarded_host=None, forwarded_proto=None
+        )
 
     @patch.object(LocalApigwService, "get_request_methods_endpoints")
     def test_http_request_must_invoke_lambda(self, request_mock):
@@ -113,8 +124,10 @@ class TestApiGatewayService(TestCase):
         result = self.http_service._request_handler()
 
         self.assertEqual(result, make_response_mock)
-        self.lambda_runner.invoke.assert_called_with(ANY, ANY, stdout=ANY, stderr=self.stderr)
-        self.http_service._construct_v_2_0_event_http.assert_called_with(ANY, ANY, ANY, ANY, ANY, ANY)
+        self.lambda_runner.invoke.assert_called_with(ANY, ANY, stdout=ANY, stderr=self.stderr, trace_id=ANY)
+        self.http_service._construct_v_2_0_event_http.assert_called_with(
+            ANY, ANY, ANY, ANY, ANY, ANY, forwarded_host=None, forwarded_proto=None
+        )
 
     @patch.object(LocalApigwService, "get_request_methods_endpoints")
     def test_http_v1_payload_request_must_invoke_lambda(self, request_mock):
@@ -141,8 +154,10 @@ class TestApiGatewayService(TestCase):
         result = self.http_service._request_handler()
 
         self.assertEqual(result, make_response_mock)
-        self.lambda_runner.invoke.assert_called_with(ANY, ANY, stdout=ANY, stderr=self.stderr)
-        self.http_service._construct_v_1_0_event.assert_called_with(ANY, ANY, ANY, ANY, ANY)
+        self.lambda_runner.invoke.assert_called_with(ANY, ANY, stdout=ANY, stderr=self.stderr, trace_id=ANY)
+        self.http_service._construct_v_1_0_event.assert_called_with(
+            ANY, ANY, ANY, ANY, ANY, forwarded_host=None, forwarded_proto=None
+        )
 
     @patch.object(LocalApigwService, "get_request_methods_endpoints")
     def test_http_v2_payload_request_must_invoke_lambda(self, request_mock):
@@ -169,8 +184,10 @@ class TestApiGatewayService(TestCase):
         result = self.http_service._request_handler()
 
         self.assertEqual(result, make_response_mock)
-        self.lambda_runner.invoke.assert_called_with(ANY, ANY, stdout=ANY, stderr=self.stderr)
-        self.http_service._construct_v_2_0_event_http.assert_called_with(ANY, ANY, ANY, ANY, ANY, ANY)
+        self.lambda_runner.invoke.assert_called_with(ANY, ANY, stdout=ANY, stderr=self.stderr, trace_id=ANY)
+        self.http_service._construct_v_2_0_event_http.assert_called_with(
+            ANY, ANY, ANY, ANY, ANY, ANY, forwarded_host=None, forwarded_proto=None
+        )
 
     @patch.object(LocalApigwService, "get_request_methods_endpoints")
     def test_api_options_request_must_invoke_lambda(self, request_mock):
@@ -194,7 +211,7 @@ class TestApiGatewayService(TestCase):
         result = self.api_service._request_handler()
 
         self.assertEqual(result, make_response_mock)
-        self.lambda_runner.invoke.assert_called_with(ANY, ANY, stdout=ANY, stderr=self.stderr)
+        self.lambda_runner.invoke.assert_called_with(ANY, ANY, stdout=ANY, stderr=self.stderr, trace_id=ANY)
 
     @patch.object(LocalApigwService, "get_request_methods_endpoints")
     def test_http_options_request_must_invoke_lambda(self, request_mock):
@@ -218,7 +235,7 @@ class TestApiGatewayService(TestCase):
         result = self.http_service._request_handler()
 
         self.assertEqual(result, make_response_mock)
-        self.lambda_runner.invoke.assert_called_with(ANY, ANY, stdout=ANY, stderr=self.stderr)
+        self.lambda_runner.invoke.assert_called_with(ANY, ANY, stdout=ANY, stderr=self.stderr, trace_id=ANY)
 
     @patch.object(LocalApigwService, "get_request_methods_endpoints")
     @patch("samcli.local.apigw.local_apigw_service.LambdaOutputParser")
@@ -275,6 +292,190 @@ class TestApiGatewayService(TestCase):
 
         self.assertEqual(result, make_response_mock)
 
+    @patch.object(LocalApigwService, "get_request_methods_endpoints")
+    def test_request_handler_answers_cors_preflight_request(self, request_mock):
+        self.api.cors = Cors(allow_origin="http://localhost:8080", allow_methods="GET,OPTIONS", allow_headers="X-Key")
+        self.api_service._get_current_route = MagicMock()
+        service_response_mock = Mock()
+        self.api_service.service_response = service_response_mock
+
+        request_mock.return_value = ("OPTIONS", "test")
+        result = self.api_service._request_handler()
+
+        self.assertEqual(result, service_response_mock.return_value)
+        service_response_mock.assert_called_once_with(
+            "",
+            Headers(
+                {
+                    "Access-Control-Allow-Origin": "http://localhost:8080",
+                    "Access-Control-Allow-Methods": "GET,OPTIONS",
+                    "Access-Control-Allow-Headers": "X-Key",
+                }
+            ),
+            200,
+        )
+        self.lambda_runner.invoke.assert_not_called()
+
+    @patch.object(LocalApigwService, "get_request_methods_endpoints")
+    def test_request_handler_adds_cors_headers_to_response(self, request_mock):
+        self.api.cors = Cors(allow_origin="http://localhost:8080", allow_methods="GET,OPTIONS")
+        self.api_service._get_current_route = MagicMock()
+        self.api_service._construct_v_1_0_event = Mock()
+        self.api_service._parse_v1_payload_format_lambda_output = Mock(
+            return_value=(200, Headers({"Access-Control-Allow-Methods": "GET"}), "body")
+        )
+        service_response_mock = Mock()
+        self.api_service.service_response = service_response_mock
+
+        request_mock.return_value = ("GET", "test")
+        self.api_service._request_handler()
+
+        (_, headers, _) = service_response_mock.call_args[0]
+        self.assertEqual(headers["Access-Control-Allow-Origin"], "http://localhost:8080")
+        # Headers set by the function are kept
+        self.assertEqual(headers["Access-Control-Allow-Methods"], "GET")
+
+    @patch.object(LocalApigwService, "get_request_methods_endpoints")
+    @patch("samcli.local.apigw.local_apigw_service.ServiceErrorResponses")
+    def test_request_handler_throttles_requests_above_throttle_rate(self, service_error_responses_patch, request_mock):
+        api_service = LocalApigwService(
+            self.api, self.lambda_runner, port=3000, host="127.0.0.1", stderr=self.stderr, throttle_rate=1
+        )
+        api_service._get_current_route = Mock(return_value=self.api_gateway_route)
+        api_service._construct_v_1_0_event = Mock()
+        api_service._parse_v1_payload_format_lambda_output = Mock(return_value=(200, Headers({}), "body"))
+        api_service.service_response = Mock()
+        too_many_requests_response_mock = Mock()
+        service_error_responses_patch.too_many_requests_response.return_value = too_many_requests_response_mock
+
+        request_mock.return_value = ("GET", "/")
+        api_service._request_handler()
+        response = api_service._request_handler()
+
+        self.assertEqual(response, too_many_requests_response_mock)
+        self.lambda_runner.invoke.assert_called_once()
+
+    @patch.object(LocalApigwService, "get_request_methods_endpoints")
+    def test_request_handler_logs_route_and_function_output_in_debug(self, request_mock):
+        self.api_service._get_current_route = Mock(return_value=self.api_gateway_route)
+        self.api_service._construct_v_1_0_event = Mock()
+        self.api_service._parse_v1_payload_format_lambda_output = Mock(return_value=(502, Headers({}), "body"))
+        self.api_service.service_response = Mock()
+        self.lambda_runner.invoke.side_effect = lambda *args, **kwargs: kwargs["stdout"].write(b"not a response")
+        request_mock.return_value = ("GET", "/")
+
+        with self.assertLogs("samcli.local.apigw.local_apigw_service", level="DEBUG") as logs:
+            self.api_service._request_handler()
+
+        self.assertIn(
+            "Request GET / matched the route / of function {}".format(self.function_name), "\n".join(logs.output)
+        )
+        self.assertIn(
+            "Function {} wrote to stdout: b'not a response'".format(self.function_name), "\n".join(logs.output)
+        )
+
+    def test_log_response_logs_status_in_debug(self):
+        self.request_mock.method = "GET"
+        self.request_mock.path = "/missing"
+        response = Mock(status_code=403)
+
+        with self.assertLogs("samcli.local.apigw.local_apigw_service", level="DEBUG") as logs:
+            self.assertEqual(LocalApigwService._log_response(response), response)
+
+        self.assertEqual(
+            logs.output, ["DEBUG:samcli.local.apigw.local_apigw_service:Responded to GET /missing with status 403"]
+        )
+
+    @patch.object(LocalApigwService, "get_request_methods_endpoints")
+    def test_request_handler_caps_concurrent_invocations(self, request_mock):
+        api_service = LocalApigwService(
+            self.api, self.lambda_runner, port=3000, host="127.0.0.1", stderr=self.stderr, max_concurrent_invocations=2
+        )
+        api_service._get_current_route = Mock(return_value=self.api_gateway_route)
+        api_service._construct_v_1_0_event = Mock()
+        api_service._parse_v1_payload_format_lambda_output = Mock(return_value=(200, Headers({}), "body"))
+        api_service.service_response = Mock()
+        request_mock.return_value = ("GET", "/")
+
+        lock = threading.Lock()
+        running = []
+        max_running = []
+
+        def invoke(*args, **kwargs):
+            with lock:
+                running.append(1)
+                max_running.append(len(running))
+            time.sleep(0.05)
+            with lock:
+                running.pop()
+
+        self.lambda_runner.invoke.side_effect = invoke
+
+        threads = [threading.Thread(target=api_service._request_handler) for _ in range(6)]
+        for thread in threads:
+            thread.start()
+        for thread in threads:
+            thread.join()
+
+        self.assertEqual(self.lambda_runner.invoke.call_count, 6)
+        self.assertEqual(max(max_running), 2)
+
+    @patch.object(LocalApigwService, "get_request_methods_endpoints")
+    @patch("samcli.local.apigw.local_apigw_service.ServiceErrorResponses")
+    def test_request_handler_rejects_requests_waiting_too_long_for_an_invocation(
+        self, service_error_responses_patch, request_mock
+    ):
+        api_service = LocalApigwService(
+            self.api,
+            self.lambda_runner,
+            port=3000,
+            host="127.0.0.1",
+            stderr=self.stderr,
+            max_concurrent_invocations=1,
+            invocation_queue_timeout=0,
+        )
+        api_service._get_current_route = Mock(return_value=self.api_gateway_route)
+        api_service._construct_v_1_0_event = Mock()
+        service_unavailable_response_mock = Mock()
+        service_error_responses_patch.service_unavailable_response.return_value = service_unavailable_response_mock
+        request_mock.return_value = ("GET", "/")
+
+        # Another request is running a function
+        api_service._invocation_limiter.acquire()
+        response = api_service._request_handler()
+
+        self.assertEqual(response, service_unavailable_response_mock)
+        self.lambda_runner.invoke.assert_not_called()
+
+    @patch.object(LocalApigwService, "get_request_methods_endpoints")
+    def test_request_handler_does_not_add_cors_headers_without_cors(self, request_mock):
+        self.api_service._get_current_route = MagicMock()
+        self.api_service._construct_v_1_0_event = Mock()
+        self.api_service._parse_v1_payload_format_lambda_output = Mock(return_value=(200, Headers({}), "body"))
+        service_response_mock = Mock()
+        self.api_service.service_response = service_response_mock
+
+        request_mock.return_value = ("GET", "test")
+        self.api_service._request_handler()
+
+        (_, headers, _) = service_response_mock.call_args[0]
+        self.assertNotIn("Access-Control-Allow-Origin", headers)
+
+    @patch("samcli.local.apigw.local_apigw_service.LOG")
+    @patch("samcli.local.apigw.local_apigw_service.Flask")
+    def test_create_warns_about_route_shadowed_by_health_check(self, flask, log_mock):
+        app_mock = MagicMock()
+        app_mock.config = {}
+        flask.return_value = app_mock
+        api = Api(routes=[Route(methods=["GET", "POST"], function_name="HealthFunction", path="/_sam/health")])
+        service = LocalApigwService(api, self.lambda_runner)
+
+        service.create()
+
+        log_mock.warning.assert_called_once_with(ANY, "/_sam/health", "HealthFunction")
+        # The health check is registered first, so that it takes precedence over the routes of the API
+        self.assertEqual(app_mock.add_url_rule.call_args_list[0][1]["endpoint"], "_sam_health")
+
     def test_create_creates_dict_of_routes(self):
         function_name_1 = Mock()
         function_name_2 = Mock()
@@ -318,13 +519,21 @@ class TestApiGatewayService(TestCase):
 
         self.api_service.create()
 
-        app_mock.add_url_rule.assert_called_once_with(
+        app_mock.add_url_rule.assert_any_call(
             "/",
             endpoint="/",
             view_func=self.api_service._request_handler,
             methods=["GET"],
             provide_automatic_options=False,
         )
+        app_mock.add_url_rule.assert_any_call(
+            "/_sam/health",
+            endpoint="_sam_health",
+            view_func=self.api_service._health_check_handler,
+            methods=["GET"],
+            provide_automatic_options=False,
+        )
+        self.assertEqual(app_mock.add_url_rule.call_count, 2)
 
     def test_api_initalize_creates_default_values(self):
         self.assertEqual(self.api_service.port, 3000)
@@ -365,6 +574,25 @@ class TestApiGatewayService(TestCase):
 
         self.assertEqual(response, not_found_response_mock)
 
+    @patch.object(LocalApigwService, "get_request_methods_endpoints")
+    @patch("samcli.local.apigw.local_apigw_service.ServiceErrorResponses")
+    def test_request_handles_function_timeout(self, service_error_responses_patch, request_mock):
+        timeout_response_mock = Mock()
+        self.api_service._construct_v_1_0_event = Mock()
+        self.api_service._get_current_route = MagicMock()
+        self.api_service._get_current_route.methods = []
+
+        service_error_responses_patch.gateway_timeout_response.return_value = timeout_response_mock
+
+        self.lambda_runner.invoke.side_effect = FunctionTimeoutException("HelloWorld", 3)
+        request_mock.return_value = ("test", "test")
+        response = self.api_service._request_handler()
+
+        self.assertEqual(response, timeout_response_mock)
+        service_error_responses_patch.gateway_timeout_response.assert_called_once_with(
+            "Task timed out after 3.00 seconds"
+        )
+
     @patch.object(LocalApigwService, "get_request_methods_endpoints")
     def test_request_throws_when_invoke_fails(self, request_mock):
         self.lambda_runner.invoke.side_effect = Exception()
@@ -424,6 +652,40 @@ class TestApiGatewayService(TestCase):
         result = self.api_service._request_handler()
         self.assertEqual(result, failure_mock)
 
+    @parameterized.expand(
+        [
+            ({}, None),
+            (
+                {"X-Amzn-Trace-Id": "Root=1-5759e988-bd862e3fe1be46a994272793"},
+                "Root=1-5759e988-bd862e3fe1be46a994272793",
+            ),
+        ]
+    )
+    @patch.object(LocalApigwService, "get_request_methods_endpoints")
+    @patch("samcli.local.apigw.local_apigw_service.generate_trace_header")
+    def test_request_handler_invokes_lambda_with_trace_id(
+        self, request_headers, expected_trace_id, generate_trace_header_mock, request_mock
+    ):
+        generate_trace_header_mock.return_value = "Root=1-00000001-000000000000000000000001;Sampled=1"
+        expected_trace_id = expected_trace_id or generate_trace_header_mock.return_value
+        self.request_mock.headers = request_headers
+
+        self.api_service._get_current_route = MagicMock()
+        self.api_service._construct_v_1_0_event = Mock()
+        self.api_service._parse_v1_payload_format_lambda_output = Mock(
+            return_value=("status_code", Headers({"headers": "headers"}), "body")
+        )
+        self.api_service.service_response = Mock()
+        request_mock.return_value = ("test", "test")
+
+        self.api_service._request_handler()
+
+        self.lambda_runner.invoke.assert_called_with(
+            ANY, ANY, stdout=ANY, stderr=self.stderr, trace_id=expected_trace_id
+        )
+        headers = self.api_service.service_response.call_args[0][1]
+        self.assertEqual(headers["X-Amzn-Trace-Id"], expected_trace_id)
+
     def test_get_current_route(self):
         request_mock = Mock()
         request_mock.return_value.endpoint = "path"
@@ -641,6 +903,64 @@ class TestServiceParsingV1PayloadFormatLambdaOutput(TestCase):
         self.assertEqual(headers, Headers({"Content-Type": "application/octet-stream"}))
         self.assertEqual(body, binary_body)
 
+    @parameterized.expand(
+        [
+            param("", True),
+            param("br", True),
+            param("gzip;q=0, deflate", True),
+            param("gzip, deflate, br", False),
+            param("*", False),
+        ]
+    )
+    @patch("samcli.local.apigw.local_apigw_service.LocalApigwService._should_base64_decode_body")
+    def test_parse_decodes_gzip_body_if_client_does_not_accept_gzip(
+        self, accept_encoding, expected_decoded, should_decode_body_patch
+    ):
+        should_decode_body_patch.return_value = True
+
+        gzipped_body = gzip.compress(b'{"message": "Hello from Lambda"}')
+        lambda_output = {
+            "statusCode": 200,
+            "headers": {"Content-Type": "application/json", "Content-Encoding": "gzip"},
+            "body": base64.b64encode(gzipped_body).decode("utf-8"),
+            "isBase64Encoded": True,
+        }
+        flask_request = Mock()
+        flask_request.headers = {"Accept-Encoding": accept_encoding}
+
+        (status_code, headers, body) = LocalApigwService._parse_v1_payload_format_lambda_output(
+            json.dumps(lambda_output), binary_types=["*/*"], flask_request=flask_request
+        )
+
+        self.assertEqual(status_code, 200)
+        if expected_decoded:
+            self.assertEqual(body, b'{"message": "Hello from Lambda"}')
+            self.assertNotIn("Content-Encoding", headers)
+        else:
+            self.assertEqual(body, gzipped_body)
+            self.assertEqual(headers["Content-Encoding"], "gzip")
+
+    @patch("samcli.local.apigw.local_apigw_service.LocalApigwService._should_base64_decode_body")
+    def test_parse_decodes_deflate_body_if_client_does_not_accept_deflate(self, should_decode_body_patch):
+        should_decode_body_patch.return_value = True
+
+        deflated_body = zlib.compress(b"Hello from Lambda")
+        lambda_output = {
+            "statusCode": 200,
+            "headers": {"Content-Type": "text/plain", "Content-Encoding": "deflate"},
+            "body": base64.b64encode(deflated_body).decode("utf-8"),
+            "isBase64Encoded": True,
+        }
+        flask_request = Mock()
+        flask_request.headers = {"Accept-Encoding": "gzip"}
+
+        (_, headers, body) = LocalApigwService._parse_v1_payload_format_lambda_output(
+            json.dumps(lambda_output), binary_types=["*/*"], flask_request=flask_request
+        )
+
+        self.assertEqual(body, b"Hello from Lambda")
+        self.assertNotIn("Content-Encoding", headers)
+
     def test_status_code_not_int(self):
         lambda_output = (
             '{"statusCode": "str", "headers": {}, "body": "{\\"message\\":\\"Hello from Lambda\\"}", '
@@ -818,6 +1138,27 @@ class TestServiceParsingV2PayloadFormatLambdaOutput(TestCase):
         self.assertEqual(headers, Headers({"Content-Type": "application/octet-stream"}))
         self.assertEqual(body, binary_body)
 
+    @patch("samcli.local.apigw.local_apigw_service.LocalApigwService._should_base64_decode_body")
+    def test_parse_decodes_gzip_body_if_client_does_not_accept_gzip(self, should_decode_body_patch):
+        should_decode_body_patch.return_value = True
+
+        gzipped_body = gzip.compress(b'{"message": "Hello from Lambda"}')
+        lambda_output = {
+            "statusCode": 200,
+            "headers": {"Content-Type": "application/json", "Content-Encoding": "gzip"},
+            "body": base64.b64encode(gzipped_body).decode("utf-8"),
+            "isBase64Encoded": True,
+        }
+        flask_request = Mock()
+        flask_request.headers = {}
+
+        (_, headers, body) = LocalApigwService._parse_v2_payload_format_lambda_output(
+            json.dumps(lambda_output), binary_types=["*/*"], flask_request=flask_request
+        )
+
+        self.assertEqual(body, b'{"message": "Hello from Lambda"}')
+        self.assertNotIn("Content-Encoding", headers)
+
     def test_status_code_int_str(self):
         lambda_output = (
             '{"statusCode": "200", "headers": {}, "body": "{\\"message\\":\\"Hello from Lambda\\"}", '
@@ -961,6 +1302,19 @@ class TestService_construct_event(TestCase):
 
         self.assertEqual(actual_event_json["body"], self.expected_dict["body"])
 
+    def test_construct_event_with_repeated_query_string_params_and_headers(self):
+        self.request_mock.args.lists.return_value = {"x": ["1", "2"]}.items()
+        self.request_mock.headers.keys.return_value = ["X-Test"]
+        self.request_mock.headers.getlist.side_effect = [["first", "second"]]
+
+        actual_event_str = LocalApigwService._construct_v_1_0_event(self.request_mock, 3000, binary_types=[])
+        actual_event_json = json.loads(actual_event_str)
+
+        self.assertEqual(actual_event_json["queryStringParameters"], {"x": "2"})
+        self.assertEqual(actual_event_json["multiValueQueryStringParameters"], {"x": ["1", "2"]})
+        self.assertEqual(actual_event_json["headers"]["X-Test"], "second")
+        self.assertEqual(actual_event_json["multiValueHeaders"]["X-Test"], ["first", "second"])
+
     def test_construct_event_no_data(self):
         self.request_mock.get_data.return_value = None
 
@@ -986,6 +1340,32 @@ class TestService_construct_event(TestCase):
         self.assertEqual(actual_event_json["body"], base64_body)
         self.assertEqual(actual_event_json["isBase64Encoded"], True)
 
+    def test_construct_event_with_png_data(self):
+        # PNG signature followed by the start of the IHDR chunk, which is not valid UTF-8
+        png_body = b"\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01\x00\x00\x00\x01\x08\x06"
+        self.request_mock.mimetype = "image/png"
+        self.request_mock.get_data.return_value = png_body
+
+        actual_event_str = LocalApigwService._construct_v_1_0_event(self.request_mock, 3000, binary_types=["image/png"])
+        actual_event_json = json.loads(actual_event_str)
+        self.validate_request_context_and_remove_request_time_data(actual_event_json)
+
+        self.assertEqual(base64.b64decode(actual_event_json["body"]), png_body)
+        self.assertEqual(actual_event_json["isBase64Encoded"], True)
+
+    def test_construct_event_with_forwarded_host_and_proto(self):
+        actual_event_str = LocalApigwService._construct_v_1_0_event(
+            self.request_mock, 3000, binary_types=[], forwarded_host="api.example.com", forwarded_proto="https"
+        )
+        actual_event_json = json.loads(actual_event_str)
+
+        self.assertEqual(actual_event_json["headers"]["Host"], "api.example.com")
+        self.assertEqual(actual_event_json["headers"]["X-Forwarded-Host"], "api.example.com")
+        self.assertEqual(actual_event_json["headers"]["X-Forwarded-Proto"], "https")
+        self.assertEqual(actual_event_json["multiValueHeaders"]["Host"], ["api.example.com"])
+        self.assertEqual(actual_event_json["multiValueHeaders"]["X-Forwarded-Proto"], ["https"])
+        self.assertEqual(actual_event_json["requestContext"]["domainName"], "api.example.com")
+
     def test_event_headers_with_empty_list(self):
         request_mock = Mock()
         headers_mock = Mock()
@@ -1030,6 +1410,21 @@ class TestService_construct_event(TestCase):
             ),
         )
 
+    def test_event_headers_with_repeated_headers(self):
+        request_mock = Mock()
+        headers_mock = Mock()
+        headers_mock.keys.return_value = ["Accept", "X-Test"]
+        headers_mock.getlist.side_effect = [["application/json"], ["first", "second"]]
+        request_mock.headers = headers_mock
+        request_mock.scheme = "http"
+
+        headers, multi_value_headers = LocalApigwService._event_headers(request_mock, "3000")
+
+        self.assertEqual(headers["X-Test"], "second")
+        self.assertEqual(multi_value_headers["X-Test"], ["first", "second"])
+        self.assertEqual(headers["Accept"], "application/json")
+        self.assertEqual(multi_value_headers["Accept"], ["application/json"])
+
     def test_query_string_params_with_empty_params(self):
         request_mock = Mock()
         query_param_args_mock = Mock()
@@ -1143,6 +1538,22 @@ class TestService_construct_event_http(TestCase):
         actual_event_dict["requestContext"]["requestId"] = ""
         self.assertEqual(actual_event_dict, self.expected_dict)
 
+    def test_construct_event_with_forwarded_host_and_proto(self):
+        actual_event_str = LocalApigwService._construct_v_2_0_event_http(
+            self.request_mock,
+            3000,
+            binary_types=[],
+            route_key="GET /endpoint",
+            forwarded_host="api.example.com",
+            forwarded_proto="https",
+        )
+        actual_event_dict = json.loads(actual_event_str)
+
+        self.assertEqual(actual_event_dict["headers"]["Host"], "api.example.com")
+        self.assertEqual(actual_event_dict["headers"]["X-Forwarded-Host"], "api.example.com")
+        self.assertEqual(actual_event_dict["headers"]["X-Forwarded-Proto"], "https")
+        self.assertEqual(actual_event_dict["headers"]["X-Forwarded-Port"], "3000")
+
     def test_v2_route_key(self):
         route_key = LocalApigwService._v2_route_key("GET", "/path", False)
         self.assertEqual(route_key, "GET /path")
@@ -1208,16 +1619,134 @@ class TestService_should_base64_encode(TestCase):
             param("Mimeyype is in binary types", ["image/gif"], "image/gif"),
             param("Mimetype defined and binary types has */*", ["*/*"], "image/gif"),
             param("*/* is in binary types with no mimetype defined", ["*/*"], None),
+            param("Mimetype matches a wildcard of binary types", ["multipart/*"], "multipart/form-data"),
+            param(
+                "Form mimetype is in binary types",
+                ["application/x-www-form-urlencoded"],
+                "application/x-www-form-urlencoded",
+            ),
         ]
     )
     def test_should_base64_encode_returns_true(self, test_case_name, binary_types, mimetype):
         self.assertTrue(LocalApigwService._should_base64_encode(binary_types, mimetype))
 
-    @parameterized.expand([param("Mimetype is not in binary types", ["image/gif"], "application/octet-stream")])
+    @parameterized.expand(
+        [
+            param("Mimetype is not in binary types", ["image/gif"], "application/octet-stream"),
+            param("Mimetype does not match a wildcard of binary types", ["image/*"], "multipart/form-data"),
+            param("No mimetype defined", ["image/*"], None),
+        ]
+    )
     def test_should_base64_encode_returns_false(self, test_case_name, binary_types, mimetype):
         self.assertFalse(LocalApigwService._should_base64_encode(binary_types, mimetype))
 
 
+class TestService_should_base64_decode_body(TestCase):
+    @parameterized.expand(
+        [
+            param("Accepted content type is in binary types", ["image/png"], "image/png", "image/png"),
+            param("Content type has parameters", ["multipart/form-data"], "multipart/*", "multipart/form-data; b=1"),
+            param("Accepted content type matches a wildcard", ["image/*"], "*/*", "image/png"),
+        ]
+    )
+    def test_should_base64_decode_body_returns_true(self, test_case_name, binary_types, accept, content_type):
+        flask_request = Mock(accept_mimetypes=MIMEAccept([(accept, 1)]))
+        headers = Headers({"Content-Type": content_type})
+
+        self.assertTrue(LocalApigwService._should_base64_decode_body(binary_types, flask_request, headers, True))
+
+    @parameterized.expand(
+        [
+            param("Content type is not accepted", ["image/png"], "application/json", "image/png", True),
+            param("Content type is not in binary types", ["image/png"], "*/*", "text/html; charset=utf-8", True),
+            param("Body is not base64 encoded", ["image/png"], "image/png", "image/png", False),
+        ]
+    )
+    def test_should_base64_decode_body_returns_false(
+        self, test_case_name, binary_types, accept, content_type, is_base_64_encoded
+    ):
+        flask_request = Mock(accept_mimetypes=MIMEAccept([(accept, 1)]))
+        headers = Headers({"Content-Type": content_type})
+
+        self.assertFalse(
+            LocalApigwService._should_base64_decode_body(binary_types, flask_request, headers, is_base_64_encoded)
+        )
+
+
+class TestServiceFormBodies(TestCase):
+    def setUp(self):
+        self.api = Api(routes=[Route(methods=["POST"], function_name="UploadFunction", path="/upload")])
+        self.lambda_runner = Mock()
+        self.lambda_runner.is_debugging.return_value = False
+        self.events = []
+
+        def invoke(function_name, event, stdout, stderr, trace_id):
+            # Echoes the body of the request, with the content type of the request
+            event = json.loads(event)
+            self.events.append(event)
+            response = {
+                "statusCode": 200,
+                "headers": {"Content-Type": event["headers"]["Content-Type"]},
+                "body": event["body"],
+                "isBase64Encoded": event["isBase64Encoded"],
+            }
+            stdout.write(json.dumps(response).encode("utf-8"))
+
+        self.lambda_runner.invoke.side_effect = invoke
+
+    def post(self, binary_types, data, content_type, accept):
+        self.api.binary_media_types_set = set(binary_types)
+        service = LocalApigwService(self.api, self.lambda_runner, port=3000, host="127.0.0.1", stderr=Mock())
+        service.create()
+
+        client = service._app.test_client()
+        return client.post("/upload", data=data, content_type=content_type, headers={"Accept": accept})
+
+    @parameterized.expand([(["multipart/form-data"],), (["multipart/*"],), (["*/*"],)])
+    def test_posting_multipart_form(self, binary_types):
+        content_type = "multipart/form-data; boundary=sam-boundary"
+        body = (
+            b"--sam-boundary\r\n"
+            b'Content-Disposition: form-data; name="description"\r\n\r\n'
+            b"logo\r\n"
+            b"--sam-boundary\r\n"
+            b'Content-Disposition: form-data; name="file"; filename="logo.png"\r\n'
+            b"Content-Type: image/png\r\n\r\n"
+            b"\x89PNG\r\n\x1a\n\x00\xff\xfe\r\n"
+            b"--sam-boundary--\r\n"
+        )
+
+        response = self.post(binary_types, body, content_type, "multipart/form-data")
+
+        self.assertTrue(self.events[0]["isBase64Encoded"])
+        self.assertEqual(base64.b64decode(self.events[0]["body"]), body)
+        self.assertEqual(response.status_code, 200)
+        self.assertEqual(response.headers["Content-Type"], content_type)
+        self.assertEqual(response.get_data(), body)
+
+    def test_posting_urlencoded_form(self):
+        content_type = "application/x-www-form-urlencoded"
+        body = b"name=caf%C3%A9&size=2"
+
+        response = self.post([content_type], body, content_type, content_type)
+
+        self.assertTrue(self.events[0]["isBase64Encoded"])
+        self.assertEqual(base64.b64decode(self.events[0]["body"]), body)
+        self.assertEqual(response.headers["Content-Type"], content_type)
+        self.assertEqual(response.get_data(), body)
+
+    def test_posting_form_that_is_not_binary(self):
+        content_type = "application/x-www-form-urlencoded"
+        body = b"name=cafe&size=2"
+
+        response = self.post(["image/png"], body, content_type, "*/*")
+
+        self.assertFalse(self.events[0]["isBase64Encoded"])
+        self.assertEqual(self.events[0]["body"], "name=cafe&size=2")
+        self.assertEqual(response.headers["Content-Type"], content_type)
+        self.assertEqual(response.get_data(), body)
+
+
 class TestServiceCorsToHeaders(TestCase):
     def test_basic_conversion(self):
         cors = Cors(
@@ -32,6 +32,35 @@ class TestServiceErrorResponses(TestCase):
         jsonify_patch.assert_called_with({"message": "No function defined for resource method"})
         make_response_patch.assert_called_with({"json": "Response"}, 502)
 
+    @patch("samcli.local.apigw.service_error_responses.make_response")
+    @patch("samcli.local.apigw.service_error_responses.jsonify")
+    def test_too_many_requests_response(self, jsonify_patch, make_response_patch):
+        jsonify_patch.return_value = {"json": "Response"}
+        response_mock = Mock()
+        response_mock.headers = {}
+        make_response_patch.return_value = response_mock
+
+        response = ServiceErrorResponses.too_many_requests_response(0.2)
+
+        self.assertEqual(response, response_mock)
+        self.assertEqual(response.headers, {"Retry-After": "1"})
+
+        jsonify_patch.assert_called_with({"message": "Too Many Requests"})
+        make_response_patch.assert_called_with({"json": "Response"}, 429)
+
+    @patch("samcli.local.apigw.service_error_responses.make_response")
+    @patch("samcli.local.apigw.service_error_responses.jsonify")
+    def test_service_unavailable_response(self, jsonify_patch, make_response_patch):
+        jsonify_patch.return_value = {"json": "Response"}
+        make_response_patch.return_value = {"Some Response"}
+
+        response = ServiceErrorResponses.service_unavailable_response()
+
+        self.assertEqual(response, {"Some Response"})
+
+        jsonify_patch.assert_called_with({"message": "Service Unavailable"})
+        make_response_patch.assert_called_with({"json": "Response"}, 503)
+
     @patch("samcli.local.apigw.service_error_responses.make_response")
     @patch("samcli.local.apigw.service_error_responses.jsonify")
     def test_route_not_found(self, jsonify_patch, make_response_patch):
@@ -0,0 +1,47 @@
+from unittest import TestCase
+from unittest.mock import Mock
+
+from samcli.local.apigw.throttler import RouteThrottler
+
+
+class TestRouteThrottler(TestCase):
+    def setUp(self):
+        self.clock = Mock(return_value=100.0)
+
+    def test_must_allow_requests_up_to_the_rate(self):
+        throttler = RouteThrottler(2, clock=self.clock)
+
+        self.assertEqual(throttler.acquire("/:GET"), 0)
+        self.assertEqual(throttler.acquire("/:GET"), 0)
+        self.assertEqual(throttler.acquire("/:GET"), 0.5)
+
+    def test_must_refill_over_time(self):
+        throttler = RouteThrottler(1, clock=self.clock)
+
+        self.assertEqual(throttler.acquire("/:GET"), 0)
+        self.assertEqual(throttler.acquire("/:GET"), 1)
+
+        self.clock.return_value = 100.5
+        self.assertEqual(throttler.acquire("/:GET"), 0.5)
+
+        self.clock.return_value = 101.0
+        self.assertEqual(throttler.acquire("/:GET"), 0)
+
+    def test_must_throttle_every_route_separately(self):
+        throttler = RouteThrottler(1, clock=self.clock)
+
+        self.assertEqual(throttler.acquire("/:GET"), 0)
+        self.assertEqual(throttler.acquire("/:POST"), 0)
+        self.assertEqual(throttler.acquire("/other:GET"), 0)
+        self.assertGreater(throttler.acquire("/:GET"), 0)
+
+    def test_must_allow_one_request_with_rate_below_one(self):
+        throttler = RouteThrottler(0.5, clock=self.clock)
+
+        self.assertEqual(throttler.acquire("/:GET"), 0)
+        self.assertEqual(throttler.acquire("/:GET"), 2)
+
+    def test_must_throttle_every_request_with_zero_rate(self):
+        throttler = RouteThrottler(0, clock=self.clock)
+
+        self.assertEqual(throttler.acquire("/:GET"), 1)
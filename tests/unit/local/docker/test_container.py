@@ -5,11 +5,27 @@ import docker
 from docker.errors import NotFound, APIError
 from unittest import TestCase
 from unittest.mock import Mock, call, patch, ANY
+from parameterized import parameterized
 
+import requests
 from requests import RequestException
 
 from samcli.lib.utils.packagetype import IMAGE
-from samcli.local.docker.container import Container, ContainerResponseException
+from samcli.local.docker.container import (
+    Container,
+    ContainerResponseException,
+    SAM_LOCAL_FUNCTION_LABEL,
+    SAM_LOCAL_LABEL,
+)
+
+
+class TestContainer_get_proportional_nano_cpus(TestCase):
+    @parameterized.expand([(128, 72357263), (1769, 1000000000), (3008, 1700395703), (10240, 4000000000)])
+    @patch("samcli.local.docker.container.os.cpu_count")
+    def test_must_scale_cpus_with_memory(self, memory_mb, expected_nano_cpus, cpu_count_mock):
+        cpu_count_mock.return_value = 4
+
+        self.assertEqual(Container.get_proportional_nano_cpus(memory_mb), expected_nano_cpus)
 
 
 class TestContainer_init(TestCase):
@@ -109,6 +125,7 @@ class TestContainer_create(TestCase):
                 for container_port, host_port in {**self.exposed_ports, **self.always_exposed_ports}.items()
             },
             use_config_proxy=True,
+            labels={SAM_LOCAL_LABEL: "true"},
         )
         self.mock_docker_client.networks.get.assert_not_called()
 
@@ -155,6 +172,7 @@ class TestContainer_create(TestCase):
             volumes=expected_volumes,
             tty=False,
             use_config_proxy=True,
+            labels={SAM_LOCAL_LABEL: "true"},
             environment=self.env_vars,
             ports={
                 container_port: (self.container_host_interface, host_port)
@@ -215,6 +233,7 @@ class TestContainer_create(TestCase):
             volumes=translated_volumes,
             tty=False,
             use_config_proxy=True,
+            labels={SAM_LOCAL_LABEL: "true"},
             environment=self.env_vars,
             ports={
                 container_port: ("127.0.0.1", host_port)
@@ -257,6 +276,7 @@ class TestContainer_create(TestCase):
             working_dir=self.working_dir,
             tty=False,
             use_config_proxy=True,
+            labels={SAM_LOCAL_LABEL: "true"},
             volumes=expected_volumes,
             ports=self.always_exposed_ports,
         )
@@ -264,6 +284,29 @@ class TestContainer_create(TestCase):
         self.mock_docker_client.networks.get.assert_called_with(network_id)
         network_mock.connect.assert_called_with(container_id)
 
+    def test_must_connect_to_network_with_aliases_on_create(self):
+        generated_id = "fooobar"
+        self.mock_docker_client.containers.create.return_value = Mock()
+        self.mock_docker_client.containers.create.return_value.id = generated_id
+
+        network_mock = Mock()
+        self.mock_docker_client.networks.get.return_value = network_mock
+
+        container = Container(
+            self.image,
+            self.cmd,
+            self.working_dir,
+            self.host_dir,
+            docker_client=self.mock_docker_client,
+            network_aliases=["orders"],
+        )
+        container.network_id = "some id"
+
+        container.create()
+
+        self.mock_docker_client.networks.get.assert_called_with("some id")
+        network_mock.connect.assert_called_with(generated_id, aliases=["orders"])
+
     def test_must_connect_to_host_network_on_create(self):
         """
         Create a container with only required values. Optional values are not provided
@@ -296,12 +339,194 @@ class TestContainer_create(TestCase):
             ports=self.always_exposed_ports,
             tty=False,
             use_config_proxy=True,
+            labels={SAM_LOCAL_LABEL: "true"},
             volumes=expected_volumes,
             network_mode="host",
         )
 
         self.mock_docker_client.networks.get.assert_not_called()
 
+    def test_must_set_hostname_on_create(self):
+        expected_volumes = {self.host_dir: {"bind": self.working_dir, "mode": "ro,delegated"}}
+        self.mock_docker_client.containers.create.return_value = Mock()
+
+        container = Container(
+            self.image, self.cmd, self.working_dir, self.host_dir, docker_client=self.mock_docker_client
+        )
+        container.hostname = "sam-local"
+
+        container.create()
+
+        self.mock_docker_client.containers.create.assert_called_with(
+            self.image,
+            command=self.cmd,
+            working_dir=self.working_dir,
+            ports=self.always_exposed_ports,
+            tty=False,
+            use_config_proxy=True,
+            labels={SAM_LOCAL_LABEL: "true"},
+            volumes=expected_volumes,
+            hostname="sam-local",
+        )
+
+    def test_must_not_set_hostname_on_host_network(self):
+        self.mock_docker_client.containers.create.return_value = Mock()
+
+        container = Container(
+            self.image, self.cmd, self.working_dir, self.host_dir, docker_client=self.mock_docker_client
+        )
+        container.network_id = "host"
+        container.hostname = "sam-local"
+
+        container.create()
+
+        self.assertNotIn("hostname", self.mock_docker_client.containers.create.call_args[1])
+
+    def test_must_set_shm_size_on_create(self):
+        self.mock_docker_client.containers.create.return_value = Mock()
+
+        container = Container(
+            self.image, self.cmd, self.working_dir, self.host_dir, docker_client=self.mock_docker_client
+        )
+        container.shm_size = "1g"
+
+        container.create()
+
+        self.assertEqual(self.mock_docker_client.containers.create.call_args[1]["shm_size"], "1g")
+
+    def test_must_not_limit_memory_on_create_with_no_memory_limit(self):
+        self.mock_docker_client.containers.create.return_value = Mock()
+
+        container = Container(
+            self.image,
+            self.cmd,
+            self.working_dir,
+            self.host_dir,
+            memory_limit_mb=128,
+            docker_client=self.mock_docker_client,
+        )
+        container.no_memory_limit = True
+
+        container.create()
+
+        self.assertNotIn("mem_limit", self.mock_docker_client.containers.create.call_args[1])
+
+    def test_must_set_labels_on_create(self):
+        self.mock_docker_client.containers.create.return_value = Mock()
+
+        container = Container(
+            self.image, self.cmd, self.working_dir, self.host_dir, docker_client=self.mock_docker_client
+        )
+        container.labels = {"team": "serverless"}
+
+        container.create()
+
+        self.assertEqual(
+            self.mock_docker_client.containers.create.call_args[1]["labels"],
+            {"team": "serverless", SAM_LOCAL_LABEL: "true"},
+        )
+
+    def test_must_label_container_with_its_own_labels(self):
+        self.mock_docker_client.containers.create.return_value = Mock()
+
+        container = Container(
+            self.image,
+            self.cmd,
+            self.working_dir,
+            self.host_dir,
+            docker_client=self.mock_docker_client,
+            labels={SAM_LOCAL_FUNCTION_LABEL: "HelloWorldFunction"},
+        )
+
+        container.create()
+
+        self.assertEqual(
+            self.mock_docker_client.containers.create.call_args[1]["labels"],
+            {"com.aws.sam.local": "true", "com.aws.sam.local.function": "HelloWorldFunction"},
+        )
+
+    def test_must_set_extra_hosts_on_create(self):
+        self.mock_docker_client.containers.create.return_value = Mock()
+
+        container = Container(
+            self.image, self.cmd, self.working_dir, self.host_dir, docker_client=self.mock_docker_client
+        )
+        container.extra_hosts = {"db.local": "192.168.1.10", "cache.local": "host-gateway"}
+
+        container.create()
+
+        self.assertEqual(
+            self.mock_docker_client.containers.create.call_args[1]["extra_hosts"],
+            {"db.local": "192.168.1.10", "cache.local": "host-gateway"},
+        )
+
+    def test_must_not_set_extra_hosts_on_create_by_default(self):
+        self.mock_docker_client.containers.create.return_value = Mock()
+
+        container = Container(
+            self.image, self.cmd, self.working_dir, self.host_dir, docker_client=self.mock_docker_client
+        )
+
+        container.create()
+
+        self.assertNotIn("extra_hosts", self.mock_docker_client.containers.create.call_args[1])
+
+    @patch("samcli.local.docker.utils.os")
+    def test_must_translate_volume_path_if_forced_to_linux_paths(self, os_mock):
+        os_mock.name = "posix"
+        self.mock_docker_client.containers.create.return_value = Mock()
+
+        container = Container(
+            self.image,
+            self.cmd,
+            self.working_dir,
+            "\\\\fileserver\\share\\sam-app",
+            docker_client=self.mock_docker_client,
+        )
+        container.force_linux_paths = True
+
+        container.create()
+
+        self.assertEqual(
+            self.mock_docker_client.containers.create.call_args[1]["volumes"],
+            {"//fileserver/share/sam-app": {"bind": self.working_dir, "mode": "ro,delegated"}},
+        )
+
+    @patch("samcli.local.docker.container.os.cpu_count")
+    def test_must_set_proportional_cpus_on_create(self, cpu_count_mock):
+        cpu_count_mock.return_value = 8
+        self.mock_docker_client.containers.create.return_value = Mock()
+
+        container = Container(
+            self.image,
+            self.cmd,
+            self.working_dir,
+            self.host_dir,
+            memory_limit_mb=1769,
+            docker_client=self.mock_docker_client,
+        )
+        container.cpu_proportional = True
+
+        container.create()
+
+        self.assertEqual(self.mock_docker_client.containers.create.call_args[1]["nano_cpus"], 1000000000)
+
+    def test_must_not_limit_cpus_by_default_on_create(self):
+        self.mock_docker_client.containers.create.return_value = Mock()
+
+        container = Container(
+            self.image,
+            self.cmd,
+            self.working_dir,
+            self.host_dir,
+            memory_limit_mb=1769,
+            docker_client=self.mock_docker_client,
+        )
+
+        container.create()
+
+        self.assertNotIn("nano_cpus", self.mock_docker_client.containers.create.call_args[1])
+
     def test_must_fail_if_already_created(self):
 
         container = Container(
@@ -342,11 +567,31 @@ class TestContainer_stop(TestCase):
         self.container.stop(time=3)
 
         self.mock_docker_client.containers.get.assert_called_with("someid")
-        real_container_mock.stop.assert_called_with(timeout=3)
+        real_container_mock.stop.assert_called_once_with(timeout=3)
 
         # Ensure ID remains set
         self.assertIsNotNone(self.container.id)
 
+    def test_must_kill_container_right_away_without_timeout(self):
+        self.container.is_created.return_value = True
+        real_container_mock = Mock()
+        self.mock_docker_client.containers.get.return_value = real_container_mock
+
+        self.container.stop(time=0)
+
+        # Docker sends SIGTERM and, once the timeout expires, SIGKILL
+        real_container_mock.stop.assert_called_once_with(timeout=0)
+
+    def test_must_work_when_container_is_not_running(self):
+        self.container.is_created.return_value = True
+        real_container_mock = Mock()
+        self.mock_docker_client.containers.get.return_value = real_container_mock
+        real_container_mock.stop.side_effect = APIError("Container someid is not running")
+
+        self.container.stop()
+
+        real_container_mock.stop.assert_called_once_with(timeout=3)
+
     def test_must_work_when_container_is_not_found(self):
         self.container.is_created.return_value = True
         real_container_mock = Mock()
@@ -414,6 +659,17 @@ class TestContainer_delete(TestCase):
         # Must reset ID to None because container is now gone
         self.assertIsNone(self.container.id)
 
+    def test_must_wait_for_logs_thread_after_delete(self):
+        self.container.is_created.return_value = True
+        logs_thread_mock = Mock()
+        logs_thread_mock.is_alive.return_value = True
+        self.container._logs_thread = logs_thread_mock
+
+        self.container.delete()
+
+        logs_thread_mock.join.assert_called_with(timeout=Container.LOGS_THREAD_JOIN_TIMEOUT)
+        self.assertIsNone(self.container.id)
+
     def test_must_work_when_container_is_not_found(self):
         self.container.is_created.return_value = True
         real_container_mock = Mock()
@@ -509,6 +765,48 @@ class TestContainer_start(TestCase):
             self.container.start(input_data="some input data")
 
 
+class TestContainer_wait_until_ready(TestCase):
+    def setUp(self):
+        self.mock_docker_client = Mock()
+        self.container = Container(
+            IMAGE, "cmd", "working_dir", "host_dir", docker_client=self.mock_docker_client, container_host="localhost"
+        )
+        self.container.rapid_port_host = "7077"
+
+    @patch("samcli.local.docker.container.time")
+    @patch("samcli.local.docker.container.requests.get")
+    def test_must_poll_until_rapid_responds(self, get_mock, time_mock):
+        time_mock.monotonic.return_value = 0
+        get_mock.side_effect = [RequestException(), Mock()]
+
+        self.container.wait_until_ready()
+
+        get_mock.assert_called_with("http://localhost:7077/", timeout=1)
+        self.assertEqual(get_mock.call_count, 2)
+        time_mock.sleep.assert_called_once_with(Container.RAPID_READY_POLL_INTERVAL)
+
+    @patch("samcli.local.docker.container.time")
+    @patch("samcli.local.docker.container.requests.get")
+    def test_must_not_poll_once_ready(self, get_mock, time_mock):
+        time_mock.monotonic.return_value = 0
+
+        self.container.wait_until_ready()
+        self.container.wait_until_ready()
+
+        get_mock.assert_called_once()
+
+    @patch("samcli.local.docker.container.time")
+    @patch("samcli.local.docker.container.requests.get")
+    def test_must_raise_if_not_ready_before_timeout(self, get_mock, time_mock):
+        time_mock.monotonic.side_effect = [0, 5, Container.RAPID_READY_TIMEOUT]
+        get_mock.side_effect = RequestException()
+
+        with self.assertRaises(ContainerResponseException):
+            self.container.wait_until_ready()
+
+        self.assertEqual(get_mock.call_count, 2)
+
+
 class TestContainer_wait_for_result(TestCase):
     def setUp(self):
         self.image = IMAGE
@@ -579,21 +877,43 @@ class TestContainer_wait_for_result(TestCase):
                 call(
                     "http://localhost:7077/2015-03-31/functions/function/invocations",
                     data=b"{}",
+                    headers=None,
                     timeout=(self.timeout, None),
                 ),
                 call(
                     "http://localhost:7077/2015-03-31/functions/function/invocations",
                     data=b"{}",
+                    headers=None,
                     timeout=(self.timeout, None),
                 ),
                 call(
                     "http://localhost:7077/2015-03-31/functions/function/invocations",
                     data=b"{}",
+                    headers=None,
                     timeout=(self.timeout, None),
                 ),
             ],
         )
 
+    @patch("samcli.local.docker.container.requests")
+    def test_wait_for_result_with_trace_id(self, mock_requests):
+        self.container.is_created.return_value = True
+        self.container._write_container_output = Mock()
+        self.container.rapid_port_host = "7077"
+        mock_requests.post.return_value.content = b'{"hello":"world"}'
+
+        trace_id = "Root=1-5759e988-bd862e3fe1be46a994272793;Sampled=1"
+        self.container.wait_for_result(
+            event=self.event, name=self.name, stdout=Mock(), stderr=Mock(), trace_id=trace_id
+        )
+
+        mock_requests.post.assert_called_with(
+            "http://localhost:7077/2015-03-31/functions/function/invocations",
+            data=b"{}",
+            headers={"X-Amzn-Trace-Id": trace_id},
+            timeout=(self.timeout, None),
+        )
+
     @patch("samcli.local.docker.container.requests")
     def test_wait_for_result_error(self, mock_requests):
         self.container.is_created.return_value = True
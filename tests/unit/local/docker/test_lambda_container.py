@@ -106,7 +106,7 @@ class TestLambdaContainer_init(TestCase):
 
         get_image_mock.assert_called_with(image_builder_mock, self.runtime, self.packagetype, self.imageuri, [])
         get_exposed_ports_mock.assert_called_with(self.debug_options)
-        get_debug_settings_mock.assert_called_with(self.runtime, self.debug_options)
+        get_debug_settings_mock.assert_called_with(self.runtime, self.debug_options, self.memory_mb)
         get_additional_options_mock.assert_called_with(self.runtime, self.debug_options)
         get_additional_volumes_mock.assert_called_with(self.runtime, self.debug_options)
 
@@ -395,6 +395,82 @@ class TestLambdaContainer_init(TestCase):
         get_additional_options_mock.assert_called_with(self.runtime, self.debug_options)
         get_additional_volumes_mock.assert_called_with(self.runtime, self.debug_options)
 
+    @patch("samcli.local.docker.lambda_container.LOG")
+    @patch.object(LambdaContainer, "_get_image")
+    def test_must_use_entrypoint_override_verbatim_zip(self, get_image_mock, log_mock):
+        get_image_mock.return_value = "image"
+        entrypoint = ["/opt/wrapper", "--verbose", "/var/rapid/aws-lambda-rie"]
+
+        container = LambdaContainer(
+            image_config=self.image_config,
+            imageuri=self.imageuri,
+            packagetype=self.packagetype,
+            runtime=self.runtime,
+            handler=self.handler,
+            code_dir=self.code_dir,
+            layers=[],
+            lambda_image=Mock(),
+            env_vars=self.env_var,
+            debug_options=self.debug_options,
+            entrypoint=entrypoint,
+        )
+
+        self.assertEqual(entrypoint, container._entrypoint)
+        self.assertEqual([self.handler], container._cmd)
+        # The debugger is not started by the overriding entrypoint
+        log_mock.warning.assert_called_once()
+
+    @patch("samcli.local.docker.lambda_container.LOG")
+    @patch.object(LambdaContainer, "_get_config")
+    @patch.object(LambdaContainer, "_get_image")
+    def test_must_use_entrypoint_override_verbatim_image(self, get_image_mock, get_config_mock, log_mock):
+        get_image_mock.return_value = "image"
+        get_config_mock.return_value = {"Cmd": ["app.handler"], "Entrypoint": ["/lambda-entrypoint.sh"]}
+        entrypoint = ["/opt/wrapper"]
+
+        container = LambdaContainer(
+            image_config=None,
+            imageuri="my-image:latest",
+            packagetype=IMAGE,
+            runtime=None,
+            handler=None,
+            code_dir=self.code_dir,
+            layers=[],
+            lambda_image=Mock(),
+            env_vars={},
+            entrypoint=entrypoint,
+        )
+
+        self.assertEqual(entrypoint, container._entrypoint)
+        self.assertEqual(["app.handler"], container._cmd)
+        log_mock.warning.assert_not_called()
+
+    @parameterized.expand(
+        [
+            ({"var": "value"}, {"var": "value", "NODE_OPTIONS": "--max-old-space-size=870"}),
+            # The function's own NODE_OPTIONS take precedence
+            ({"NODE_OPTIONS": "--enable-source-maps"}, {"NODE_OPTIONS": "--enable-source-maps"}),
+        ]
+    )
+    @patch.object(LambdaContainer, "_get_image")
+    def test_must_size_heap_without_debug(self, env_vars, expected_env_vars, get_image_mock):
+        get_image_mock.return_value = "image"
+
+        container = LambdaContainer(
+            image_config=self.image_config,
+            imageuri=self.imageuri,
+            packagetype=self.packagetype,
+            runtime=self.runtime,
+            handler=self.handler,
+            code_dir=self.code_dir,
+            layers=[],
+            lambda_image=Mock(),
+            env_vars=env_vars,
+            memory_mb=self.memory_mb,
+        )
+
+        self.assertEqual(expected_env_vars, container._env_vars)
+
     def test_must_fail_for_unsupported_runtime(self):
 
         runtime = "foo"
@@ -433,6 +509,13 @@ class TestLambdaContainer_get_exposed_ports(TestCase):
 
         self.assertEqual(expected, result)
 
+    def test_must_map_debug_port_to_given_host_port(self):
+
+        debug_options = DebugContext(debug_ports=[5858, 9229], debug_host_ports={5858: 6858})
+        result = LambdaContainer._get_exposed_ports(debug_options)
+
+        self.assertEqual({5858: 6858, 9229: 9229}, result)
+
     def test_empty_ports_list(self):
 
         debug_options = DebugContext(debug_ports=[])
@@ -73,3 +73,61 @@ class TestLambdaDebugSettings(TestCase):
     def test_parse_go_delve_api_version_not_called_for_other_runtimes(self, runtime, parse_go_delve_api_version_mock):
         LambdaDebugSettings.get_debug_settings(1234, [], {}, runtime.value, {})
         parse_go_delve_api_version_mock.assert_not_called()
+
+    @parameterized.expand([(Runtime.java8,), (Runtime.java8al2,), (Runtime.java11,)])
+    def test_java_heap_is_sized_from_memory(self, runtime):
+        _, env_vars = LambdaDebugSettings.get_debug_settings(1234, [], {}, runtime.value, {}, memory_mb=1024)
+
+        self.assertIn("-XX:MaxHeapSize=891289k", env_vars["_JAVA_OPTIONS"])
+
+    @parameterized.expand([(Runtime.java8,), (Runtime.java8al2,), (Runtime.java11,)])
+    def test_java_heap_defaults_without_memory(self, runtime):
+        _, env_vars = LambdaDebugSettings.get_debug_settings(1234, [], {}, runtime.value, {})
+
+        self.assertIn("-XX:MaxHeapSize=2618163k", env_vars["_JAVA_OPTIONS"])
+
+    @parameterized.expand([(Runtime.nodejs10x,), (Runtime.nodejs12x,), (Runtime.nodejs14x,)])
+    def test_node_old_space_is_sized_from_memory(self, runtime):
+        entrypoint, _ = LambdaDebugSettings.get_debug_settings(1234, [], {}, runtime.value, {}, memory_mb=1024)
+
+        self.assertIn("--max-old-space-size=870", entrypoint)
+        self.assertEqual(entrypoint[-1], "/var/runtime/index.js")
+
+    @parameterized.expand([(Runtime.nodejs10x,), (Runtime.nodejs12x,), (Runtime.nodejs14x,)])
+    def test_node_old_space_not_set_without_memory(self, runtime):
+        entrypoint, _ = LambdaDebugSettings.get_debug_settings(1234, [], {}, runtime.value, {})
+
+        self.assertFalse(any(arg.startswith("--max-old-space-size") for arg in entrypoint))
+
+    @parameterized.expand([(Runtime.dotnetcore21,), (Runtime.dotnetcore31,)])
+    def test_dotnet_waits_for_debugger(self, runtime):
+        entrypoint, env_vars = LambdaDebugSettings.get_debug_settings(1234, ["--debug-arg"], {}, runtime.value, {})
+
+        self.assertEqual(entrypoint[-2:], ["/var/runtime/bootstrap", "--debug-arg"])
+        self.assertEqual(env_vars["_AWS_LAMBDA_DOTNET_DEBUGGING"], "1")
+
+    def test_go_runs_delve_on_debug_port(self):
+        entrypoint, env_vars = LambdaDebugSettings.get_debug_settings(
+            1234, ["-delveAPI=2"], {}, Runtime.go1x.value, {"delvePath": "/tmp/lambci_debug_files/dlv"}
+        )
+
+        self.assertEqual(entrypoint, ["/var/rapid/aws-lambda-rie", "--log-level", "error"])
+        self.assertEqual(env_vars["_AWS_LAMBDA_GO_DEBUGGING"], "1")
+        self.assertEqual(env_vars["_AWS_LAMBDA_GO_DELVE_LISTEN_PORT"], 1234)
+        self.assertEqual(env_vars["_AWS_LAMBDA_GO_DELVE_API_VERSION"], 2)
+        self.assertEqual(env_vars["_AWS_LAMBDA_GO_DELVE_PATH"], "/tmp/lambci_debug_files/dlv")
+
+    @parameterized.expand(
+        [
+            (Runtime.java8, 1024, {"_JAVA_OPTIONS": "-XX:MaxHeapSize=891289k"}),
+            (Runtime.java11, None, {"_JAVA_OPTIONS": "-XX:MaxHeapSize=2618163k"}),
+            (Runtime.nodejs14x, 1024, {"NODE_OPTIONS": "--max-old-space-size=870"}),
+            (Runtime.nodejs14x, None, {}),
+            (Runtime.python38, 1024, {}),
+        ]
+    )
+    def test_heap_env_vars(self, runtime, memory_mb, expected):
+        self.assertEqual(LambdaDebugSettings.get_heap_env_vars(runtime.value, memory_mb), expected)
+
+    def test_no_heap_env_vars_without_runtime(self):
+        self.assertEqual(LambdaDebugSettings.get_heap_env_vars(None, 1024), {})
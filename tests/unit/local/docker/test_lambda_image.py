@@ -5,6 +5,7 @@ from unittest import TestCase
 from unittest.mock import patch, Mock, mock_open, ANY
 
 from docker.errors import ImageNotFound, BuildError, APIError
+from parameterized import parameterized
 
 from samcli.commands.local.lib.exceptions import InvalidIntermediateImageError
 from samcli.lib.utils.packagetype import ZIP, IMAGE
@@ -76,6 +77,26 @@ class TestLambdaImage(TestCase):
         # No Layers are added.
         layer_downloader_mock.assert_not_called()
 
+    @parameterized.expand([("provided",), ("provided.al2",)])
+    @patch("samcli.local.docker.lambda_image.LambdaImage._build_image")
+    def test_building_image_of_custom_runtime(self, runtime, build_image_patch):
+        docker_client_mock = Mock()
+        docker_client_mock.images.get.side_effect = ImageNotFound("image not found")
+        layer_downloader_mock = Mock()
+
+        lambda_image = LambdaImage(layer_downloader_mock, False, False, docker_client=docker_client_mock)
+
+        self.assertEqual(
+            lambda_image.build(runtime, ZIP, None, []),
+            f"amazon/aws-sam-cli-emulation-image-{runtime}:rapid-{version}",
+        )
+        build_image_patch.assert_called_once_with(
+            f"amazon/aws-sam-cli-emulation-image-{runtime}:latest",
+            f"amazon/aws-sam-cli-emulation-image-{runtime}:rapid-{version}",
+            [],
+            stream=ANY,
+        )
+
     def test_building_image_with_non_accpeted_package_type(self):
         docker_client_mock = Mock()
         layer_downloader_mock = Mock()
@@ -185,6 +206,82 @@ class TestLambdaImage(TestCase):
             stream=stream,
         )
 
+    @patch("samcli.local.docker.lambda_image.LambdaImage._build_image")
+    def test_building_image_of_overridden_runtime_image(self, build_image_patch):
+        docker_client_mock = Mock()
+        docker_client_mock.images.get.side_effect = ImageNotFound("image not found")
+
+        stream = io.StringIO()
+
+        lambda_image = LambdaImage(
+            "layer_downloader",
+            False,
+            False,
+            docker_client=docker_client_mock,
+            runtime_images={"python3.8": "registry.example.com/lambda-python3.8:1.0"},
+        )
+        actual_image_id = lambda_image.build("python3.8", ZIP, None, [], stream=stream)
+
+        self.assertEqual(actual_image_id, f"registry.example.com/lambda-python3.8:rapid-{version}")
+        build_image_patch.assert_called_once_with(
+            "registry.example.com/lambda-python3.8:1.0",
+            f"registry.example.com/lambda-python3.8:rapid-{version}",
+            [],
+            stream=stream,
+        )
+
+    @patch("samcli.local.docker.lambda_image.LambdaImage._build_image")
+    def test_building_image_of_runtime_that_is_not_overridden(self, build_image_patch):
+        docker_client_mock = Mock()
+        docker_client_mock.images.get.side_effect = ImageNotFound("image not found")
+
+        stream = io.StringIO()
+
+        lambda_image = LambdaImage(
+            "layer_downloader",
+            False,
+            False,
+            docker_client=docker_client_mock,
+            runtime_images={"python3.8": "registry.example.com/lambda-python3.8:1.0"},
+        )
+        lambda_image.build("python3.7", ZIP, None, [], stream=stream)
+
+        build_image_patch.assert_called_once_with(
+            "amazon/aws-sam-cli-emulation-image-python3.7:latest",
+            f"amazon/aws-sam-cli-emulation-image-python3.7:rapid-{version}",
+            [],
+            stream=stream,
+        )
+
+    @patch("samcli.local.docker.lambda_image.LambdaImage._build_image")
+    @patch("samcli.local.docker.lambda_image.LambdaImage._generate_docker_image_version")
+    def test_building_image_with_layers_of_overridden_runtime_image(
+        self, generate_docker_image_version_patch, build_image_patch
+    ):
+        layer_downloader_mock = Mock()
+        layer_downloader_mock.download_all.return_value = ["layers1"]
+
+        generate_docker_image_version_patch.return_value = "image-version"
+
+        docker_client_mock = Mock()
+        docker_client_mock.images.get.side_effect = ImageNotFound("image not found")
+
+        stream = io.StringIO()
+
+        lambda_image = LambdaImage(
+            layer_downloader_mock,
+            False,
+            False,
+            docker_client=docker_client_mock,
+            runtime_images={"python3.8": "registry.example.com/lambda-python3.8:1.0"},
+        )
+        actual_image_id = lambda_image.build("python3.8", ZIP, None, ["layers1"], stream=stream)
+
+        self.assertRegex(actual_image_id, r"^samcli/lambda:image-version-[0-9a-f]{12}$")
+        build_image_patch.assert_called_once_with(
+            "registry.example.com/lambda-python3.8:1.0", actual_image_id, ["layers1"], stream=stream
+        )
+
     @patch("samcli.local.docker.lambda_image.hashlib")
     def test_generate_docker_image_version(self, hashlib_patch):
         haslib_sha256_mock = Mock()
@@ -8,8 +8,10 @@ from unittest import TestCase
 from unittest.mock import Mock, patch, MagicMock, ANY, call
 
 import requests
-from docker.errors import APIError, ImageNotFound
+from parameterized import parameterized
+from docker.errors import APIError, ImageNotFound, NotFound
 from samcli.local.docker.manager import ContainerManager, DockerImagePullFailedException
+from samcli.local.docker.exceptions import DockerAPIVersionNotSupportedException
 
 
 # pywintypes is not available non-Windows OS,
@@ -48,6 +50,7 @@ class TestContainerManager_run(TestCase):
         self.container_mock.start = Mock()
         self.container_mock.create = Mock()
         self.container_mock.is_created = Mock()
+        self.container_mock.labels = None
 
     def test_must_pull_image_and_run_container(self):
         input_data = "input data"
@@ -65,6 +68,100 @@ class TestContainerManager_run(TestCase):
         self.manager.pull_image.assert_called_with(self.image_name)
         self.container_mock.start.assert_called_with(input_data=input_data)
 
+    def test_must_set_container_hostname_before_create(self):
+        self.manager = ContainerManager(docker_client=self.mock_docker_client, container_hostname="sam-local")
+        self.manager.has_image = Mock(return_value=True)
+        self.manager.skip_pull_image = True
+        self.container_mock.is_created.return_value = False
+
+        self.manager.run(self.container_mock)
+
+        self.assertEqual(self.container_mock.hostname, "sam-local")
+        self.container_mock.create.assert_called_with()
+
+    def test_must_set_shm_size_before_create(self):
+        self.manager = ContainerManager(docker_client=self.mock_docker_client, shm_size="1g")
+        self.manager.has_image = Mock(return_value=True)
+        self.manager.skip_pull_image = True
+        self.container_mock.is_created.return_value = False
+
+        self.manager.run(self.container_mock)
+
+        self.assertEqual(self.container_mock.shm_size, "1g")
+        self.container_mock.create.assert_called_with()
+
+    def test_must_set_labels_before_create(self):
+        self.manager = ContainerManager(docker_client=self.mock_docker_client, labels={"team": "serverless"})
+        self.manager.has_image = Mock(return_value=True)
+        self.manager.skip_pull_image = True
+        self.container_mock.is_created.return_value = False
+
+        self.manager.run(self.container_mock)
+
+        self.assertEqual(self.container_mock.labels, {"team": "serverless"})
+        self.container_mock.create.assert_called_with()
+
+    def test_must_merge_labels_of_container_over_common_labels(self):
+        self.manager = ContainerManager(
+            docker_client=self.mock_docker_client,
+            labels={"team": "serverless", "com.aws.sam.local.function": "Overridden"},
+        )
+        self.manager.has_image = Mock(return_value=True)
+        self.manager.skip_pull_image = True
+        self.container_mock.is_created.return_value = False
+        self.container_mock.labels = {"com.aws.sam.local.function": "HelloWorldFunction"}
+
+        self.manager.run(self.container_mock)
+
+        self.assertEqual(
+            self.container_mock.labels,
+            {"team": "serverless", "com.aws.sam.local.function": "HelloWorldFunction"},
+        )
+
+    def test_must_set_extra_hosts_before_create(self):
+        self.manager = ContainerManager(docker_client=self.mock_docker_client, extra_hosts={"db.local": "10.0.0.2"})
+        self.manager.has_image = Mock(return_value=True)
+        self.manager.skip_pull_image = True
+        self.container_mock.is_created.return_value = False
+
+        self.manager.run(self.container_mock)
+
+        self.assertEqual(self.container_mock.extra_hosts, {"db.local": "10.0.0.2"})
+        self.container_mock.create.assert_called_with()
+
+    def test_must_set_force_linux_paths_before_create(self):
+        self.manager = ContainerManager(docker_client=self.mock_docker_client, force_linux_paths=True)
+        self.manager.has_image = Mock(return_value=True)
+        self.manager.skip_pull_image = True
+        self.container_mock.is_created.return_value = False
+
+        self.manager.run(self.container_mock)
+
+        self.assertTrue(self.container_mock.force_linux_paths)
+        self.container_mock.create.assert_called_with()
+
+    def test_must_set_no_memory_limit_before_create(self):
+        self.manager = ContainerManager(docker_client=self.mock_docker_client, no_memory_limit=True)
+        self.manager.has_image = Mock(return_value=True)
+        self.manager.skip_pull_image = True
+        self.container_mock.is_created.return_value = False
+
+        self.manager.run(self.container_mock)
+
+        self.assertTrue(self.container_mock.no_memory_limit)
+        self.container_mock.create.assert_called_with()
+
+    def test_must_set_cpu_proportional_before_create(self):
+        self.manager = ContainerManager(docker_client=self.mock_docker_client, cpu_proportional=True)
+        self.manager.has_image = Mock(return_value=True)
+        self.manager.skip_pull_image = True
+        self.container_mock.is_created.return_value = False
+
+        self.manager.run(self.container_mock)
+
+        self.assertTrue(self.container_mock.cpu_proportional)
+        self.container_mock.create.assert_called_with()
+
     def test_must_pull_image_if_image_exist_and_no_skip(self):
         input_data = "input data"
 
@@ -143,6 +240,44 @@ class TestContainerManager_run(TestCase):
         self.manager.pull_image.assert_not_called()
         self.container_mock.start.assert_called_with(input_data=input_data)
 
+    def test_must_fail_if_asked_to_skip_pulling_and_image_does_not_exist(self):
+        input_data = "input data"
+
+        self.manager.has_image = Mock()
+        self.manager.pull_image = Mock()
+
+        # Assume the image doesn't exist.
+        self.manager.has_image.return_value = False
+        # And, skip pulling
+        self.manager.skip_pull_image = True
+        self.container_mock.is_created.return_value = False
+
+        with self.assertRaises(DockerImagePullFailedException) as ex_ctx:
+            self.manager.run(self.container_mock, input_data)
+
+        self.assertIn("docker pull {}".format(self.image_name), str(ex_ctx.exception))
+        self.assertIn("--skip-pull-image", str(ex_ctx.exception))
+        self.manager.pull_image.assert_not_called()
+        self.container_mock.create.assert_not_called()
+        self.container_mock.start.assert_not_called()
+
+    def test_must_not_pull_samcli_image_if_asked_to_skip(self):
+        input_data = "input data"
+        self.container_mock.image = "samcli/lambda-python:3.9-x86_64-b22538ac72603f4028dfb8ff5"
+
+        self.manager.has_image = Mock()
+        self.manager.pull_image = Mock()
+
+        self.manager.has_image.return_value = False
+        self.manager.skip_pull_image = True
+        self.container_mock.is_created.return_value = False
+
+        self.manager.run(self.container_mock, input_data)
+
+        self.manager.pull_image.assert_not_called()
+        self.container_mock.create.assert_called_once_with()
+        self.container_mock.start.assert_called_with(input_data=input_data)
+
     def test_must_fail_if_image_pull_failed_and_image_does_not_exist(self):
         input_data = "input data"
 
@@ -220,7 +355,7 @@ class TestContainerManager_pull_image(TestCase):
     def test_must_pull_and_print_progress_dots(self):
 
         stream = io.StringIO()
-        pull_result = [1, 2, 3, 4, 5, 6, 7, 8, 9, 0]
+        pull_result = [{"status": "Downloading"} for _ in range(10)]
         self.mock_docker_client.api.pull.return_value = pull_result
         expected_stream_output = "\nFetching {} Docker container image...{}\n".format(
             self.image_name, "." * len(pull_result)  # Progress bar will print one dot per response from pull API
@@ -231,7 +366,8 @@ class TestContainerManager_pull_image(TestCase):
         self.mock_docker_client.api.pull.assert_called_with(self.image_name, stream=True, decode=True, tag="latest")
         self.assertEqual(stream.getvalue(), expected_stream_output)
 
-    def test_must_raise_if_image_not_found(self):
+    @patch("samcli.local.docker.manager.time")
+    def test_must_raise_if_image_not_found(self, time_mock):
         msg = "some error"
         self.mock_docker_client.api.pull.side_effect = APIError(msg)
 
@@ -241,9 +377,65 @@ class TestContainerManager_pull_image(TestCase):
         ex = context.exception
         self.assertEqual(str(ex), msg)
 
+    @patch("samcli.local.docker.manager.time")
+    def test_must_raise_if_pull_reports_an_error(self, time_mock):
+        self.mock_docker_client.api.pull.return_value = [{"status": "Pulling fs layer"}, {"error": "no space left"}]
+
+        with self.assertRaises(DockerImagePullFailedException) as context:
+            self.manager.pull_image(self.image_name, stream=io.StringIO())
+
+        self.assertEqual(str(context.exception), "no space left")
+        self.mock_docker_client.api.pull.assert_called_once()
+
+    @patch("samcli.local.docker.manager.time")
+    def test_must_retry_pull_with_backoff(self, time_mock):
+        stream = io.StringIO()
+        self.mock_docker_client.api.pull.side_effect = [
+            APIError("rate limited", response=Mock(status_code=429)),
+            requests.exceptions.ConnectionError("connection reset"),
+            [{"status": "Downloading"}, {"status": "Downloaded"}],
+        ]
+
+        self.manager.pull_image(self.image_name, stream=stream)
+
+        self.assertEqual(self.mock_docker_client.api.pull.call_count, 3)
+        time_mock.sleep.assert_has_calls([call(1), call(2)])
+        self.assertEqual(stream.getvalue(), "\nFetching {} Docker container image.....\n".format(self.image_name))
+
+    @patch("samcli.local.docker.manager.time")
+    def test_must_give_up_after_last_attempt(self, time_mock):
+        self.mock_docker_client.api.pull.side_effect = APIError("unavailable", response=Mock(status_code=503))
+
+        with self.assertRaises(DockerImagePullFailedException):
+            self.manager.pull_image(self.image_name)
+
+        self.assertEqual(self.mock_docker_client.api.pull.call_count, 3)
+        time_mock.sleep.assert_has_calls([call(1), call(2)])
+        self.assertEqual(time_mock.sleep.call_count, 2)
+
+    @patch("samcli.local.docker.manager.time")
+    def test_must_not_retry_if_image_does_not_exist(self, time_mock):
+        self.mock_docker_client.api.pull.side_effect = NotFound("not found")
+
+        with self.assertRaises(DockerImagePullFailedException):
+            self.manager.pull_image(self.image_name)
+
+        self.mock_docker_client.api.pull.assert_called_once()
+        time_mock.sleep.assert_not_called()
+
+    @patch("samcli.local.docker.manager.time")
+    def test_must_not_retry_if_access_is_denied(self, time_mock):
+        self.mock_docker_client.api.pull.side_effect = APIError("denied", response=Mock(status_code=401))
+
+        with self.assertRaises(DockerImagePullFailedException):
+            self.manager.pull_image(self.image_name)
+
+        self.mock_docker_client.api.pull.assert_called_once()
+        time_mock.sleep.assert_not_called()
+
     @patch("samcli.local.docker.manager.threading")
     def test_multiple_image_pulls_must_use_locks(self, mock_threading):
-        self.mock_docker_client.api.pull.return_value = [1, 2, 3]
+        self.mock_docker_client.api.pull.return_value = [{"status": "Downloading"}]
 
         # mock general lock
         mock_lock = MagicMock()
@@ -267,6 +459,35 @@ class TestContainerManager_pull_image(TestCase):
         mock_lock.assert_has_calls(3 * [call.__enter__(), call.__exit__(ANY, ANY, ANY)], any_order=True)
 
 
+class TestContainerManager_check_docker_api_version(TestCase):
+    def setUp(self):
+        self.docker_client_mock = Mock()
+        self.manager = ContainerManager(docker_client=self.docker_client_mock)
+
+    def test_must_pass_if_api_version_is_supported(self):
+        self.docker_client_mock.api.version.return_value = {"ApiVersion": "1.41"}
+
+        self.manager.check_docker_api_version()
+
+    def test_must_raise_actionable_error_if_api_version_is_too_old(self):
+        self.docker_client_mock.api.version.return_value = {"ApiVersion": "1.30"}
+
+        with self.assertRaises(DockerAPIVersionNotSupportedException) as ex_ctx:
+            self.manager.check_docker_api_version()
+
+        self.assertEqual(
+            str(ex_ctx.exception),
+            "Docker API version 1.30 is older than 1.35, the minimum that AWS SAM CLI supports. "
+            "Please update Docker, or if you use a remote Docker daemon, check that DOCKER_HOST "
+            "points to an up to date one.",
+        )
+
+    def test_must_not_raise_if_docker_is_not_reachable(self):
+        self.docker_client_mock.api.version.side_effect = requests.exceptions.ConnectionError("error")
+
+        self.manager.check_docker_api_version()
+
+
 class TestContainerManager_is_docker_reachable(TestCase):
     def setUp(self):
         self.ping_mock = Mock()
@@ -379,3 +600,22 @@ class TestContainerManager_stop(TestCase):
 
         manager.stop(container)
         container.delete.assert_called_with()
+
+    @parameterized.expand([(3,), (5,)])
+    def test_must_stop_container_gracefully_before_delete(self, shutdown_timeout):
+        # The container gets SIGTERM, and SIGKILL only if it is still running after the shutdown timeout
+        manager = ContainerManager(docker_client=Mock(), shutdown_timeout=shutdown_timeout)
+        container = Mock()
+
+        manager.stop(container)
+
+        self.assertEqual(container.mock_calls, [call.stop(time=shutdown_timeout), call.delete()])
+
+    def test_must_not_stop_container_gracefully_without_shutdown_timeout(self):
+        # Deleting forcibly removes the container, which kills it right away
+        manager = ContainerManager(docker_client=Mock(), shutdown_timeout=0)
+        container = Mock()
+
+        manager.stop(container)
+
+        self.assertEqual(container.mock_calls, [call.delete()])
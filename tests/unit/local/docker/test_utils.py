@@ -6,7 +6,16 @@ import os
 from unittest import TestCase
 from unittest.mock import patch, Mock
 
-from samcli.local.docker.utils import to_posix_path, find_free_port
+import requests
+from docker.errors import APIError
+from parameterized import parameterized
+
+from samcli.local.docker.utils import (
+    to_posix_path,
+    find_free_port,
+    get_docker_api_version,
+    is_docker_api_version_supported,
+)
 from samcli.local.docker.exceptions import NoFreePortsError
 
 
@@ -26,6 +35,40 @@ class TestPosixPath(TestCase):
         mock_os.name = "posix"
         self.assertEqual(self.current_working_dir, to_posix_path(self.current_working_dir))
 
+    @parameterized.expand(
+        [
+            # Drive letters
+            ("C:\\Users\\UserName\\sam-app", "/c/Users/UserName/sam-app"),
+            ("d:\\projects\\sam-app", "/d/projects/sam-app"),
+            ("C:\\", "/c/"),
+            ("\\\\?\\C:\\Users\\UserName\\sam-app", "/c/Users/UserName/sam-app"),
+            # UNC paths of network shares
+            ("\\\\fileserver\\share\\sam-app", "//fileserver/share/sam-app"),
+            ("\\\\?\\UNC\\fileserver\\share\\sam-app", "//fileserver/share/sam-app"),
+            # Paths of WSL distros
+            ("\\\\wsl$\\Ubuntu\\home\\UserName\\sam-app", "/home/UserName/sam-app"),
+            ("\\\\wsl.localhost\\Ubuntu-20.04\\home\\UserName\\sam-app", "/home/UserName/sam-app"),
+            ("\\\\WSL$\\Ubuntu", "/"),
+            # Already slashed
+            ("C:/Users/UserName/sam-app", "/c/Users/UserName/sam-app"),
+            ("//fileserver/share/sam-app", "//fileserver/share/sam-app"),
+            ("//wsl$/Ubuntu/home/UserName/sam-app", "/home/UserName/sam-app"),
+            ("/c/Users/UserName/sam-app", "/c/Users/UserName/sam-app"),
+            ("/mnt/c/Users/UserName/sam-app", "/mnt/c/Users/UserName/sam-app"),
+            ("/home/UserName/sam-app", "/home/UserName/sam-app"),
+        ]
+    )
+    @patch("samcli.local.docker.utils.os")
+    def test_convert_windows_paths(self, path, expected, mock_os):
+        mock_os.name = "nt"
+        self.assertEqual(expected, to_posix_path(path))
+
+    @patch("samcli.local.docker.utils.os")
+    def test_convert_path_if_forced_to_linux_paths(self, mock_os):
+        mock_os.name = "posix"
+        self.assertEqual(self.posixpath, to_posix_path(self.ntpath, force_linux_paths=True))
+        self.assertEqual("//fileserver/share/sam-app", to_posix_path("\\\\fileserver\\share\\sam-app", True))
+
 
 class TestFreePorts(TestCase):
     @patch("samcli.local.docker.utils.socket")
@@ -54,3 +97,31 @@ class TestFreePorts(TestCase):
         mock_random.randrange = Mock(side_effect=[1, 2, 3] * 3)
         with self.assertRaises(NoFreePortsError):
             find_free_port(start=1, end=4)
+
+
+class TestDockerApiVersion(TestCase):
+    def test_must_read_api_version_from_unversioned_endpoint(self):
+        docker_client_mock = Mock()
+        docker_client_mock.api.version.return_value = {"ApiVersion": "1.41", "Version": "20.10.7"}
+
+        self.assertEqual(get_docker_api_version(docker_client_mock), "1.41")
+        docker_client_mock.api.version.assert_called_once_with(api_version=False)
+
+    def test_must_return_none_if_docker_is_not_reachable(self):
+        docker_client_mock = Mock()
+        docker_client_mock.api.version.side_effect = requests.exceptions.ConnectionError("error")
+
+        self.assertIsNone(get_docker_api_version(docker_client_mock))
+
+    def test_must_return_none_if_docker_raises_api_error(self):
+        docker_client_mock = Mock()
+        docker_client_mock.api.version.side_effect = APIError("error")
+
+        self.assertIsNone(get_docker_api_version(docker_client_mock))
+
+    def test_must_compare_versions_numerically(self):
+        self.assertTrue(is_docker_api_version_supported("1.35"))
+        self.assertTrue(is_docker_api_version_supported("1.41"))
+        self.assertTrue(is_docker_api_version_supported("1.100"))
+        self.assertFalse(is_docker_api_version_supported("1.30"))
+        self.assertFalse(is_docker_api_version_supported("1.4"))
@@ -3,7 +3,7 @@ from unittest.mock import Mock, patch, ANY, call
 
 from samcli.local.lambda_service import local_lambda_invoke_service
 from samcli.local.lambda_service.local_lambda_invoke_service import LocalLambdaInvokeService
-from samcli.local.lambdafn.exceptions import FunctionNotFound
+from samcli.local.lambdafn.exceptions import FunctionNotFound, FunctionTimeoutException
 
 
 class TestLocalLambdaService(TestCase):
@@ -37,13 +37,21 @@ class TestLocalLambdaService(TestCase):
 
         service.create()
 
-        app_mock.add_url_rule.assert_called_once_with(
+        app_mock.add_url_rule.assert_any_call(
             "/2015-03-31/functions/<function_name>/invocations",
             endpoint="/2015-03-31/functions/<function_name>/invocations",
             view_func=service._invoke_request_handler,
             methods=["POST"],
             provide_automatic_options=False,
         )
+        app_mock.add_url_rule.assert_any_call(
+            "/_sam/health",
+            endpoint="_sam_health",
+            view_func=service._health_check_handler,
+            methods=["GET"],
+            provide_automatic_options=False,
+        )
+        self.assertEqual(app_mock.add_url_rule.call_count, 2)
 
     @patch("samcli.local.lambda_service.local_lambda_invoke_service.LocalLambdaInvokeService.service_response")
     @patch("samcli.local.lambda_service.local_lambda_invoke_service.LambdaOutputParser")
@@ -87,6 +95,28 @@ class TestLocalLambdaService(TestCase):
 
         lambda_error_responses_mock.resource_not_found.assert_called_once_with("NotFound")
 
+    @patch("samcli.local.lambda_service.local_lambda_invoke_service.LocalLambdaInvokeService.service_response")
+    def test_invoke_request_handler_on_function_timeout(self, service_response_mock):
+        request_mock = Mock()
+        request_mock.get_data.return_value = b"{}"
+        local_lambda_invoke_service.request = request_mock
+
+        lambda_runner_mock = Mock()
+        lambda_runner_mock.invoke.side_effect = FunctionTimeoutException("HelloWorld", 3)
+
+        service_response_mock.return_value = "request response"
+
+        service = LocalLambdaInvokeService(lambda_runner=lambda_runner_mock, port=3000, host="localhost")
+
+        response = service._invoke_request_handler(function_name="HelloWorld")
+
+        self.assertEqual(response, "request response")
+        service_response_mock.assert_called_once_with(
+            '{"errorMessage": "Task timed out after 3.00 seconds"}',
+            {"Content-Type": "application/json", "x-amz-function-error": "Unhandled"},
+            200,
+        )
+
     @patch("samcli.local.lambda_service.local_lambda_invoke_service.LocalLambdaInvokeService.service_response")
     @patch("samcli.local.lambda_service.local_lambda_invoke_service.LambdaOutputParser")
     def test_request_handler_returns_process_stdout_when_making_response(
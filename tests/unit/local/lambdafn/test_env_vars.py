@@ -250,6 +250,98 @@ class TestEnvironmentVariables_resolve(TestCase):
 
         self.assertEqual(environ.resolve(), expected)
 
+    def test_timezone_takes_precedence(self):
+        """
+        Given a timezone, and a value for TZ from the template, the shell and the overrides
+        """
+
+        environ = EnvironmentVariables(
+            self.name,
+            self.memory,
+            self.timeout,
+            self.handler,
+            variables={"TZ": "America/New_York"},
+            shell_env_values={"TZ": "Asia/Tokyo"},
+            override_values={"TZ": "Australia/Sydney"},
+            timezone="Europe/Paris",
+        )
+
+        self.assertEqual(environ.resolve()["TZ"], "Europe/Paris")
+
+    def test_without_timezone(self):
+        environ = EnvironmentVariables(self.name, self.memory, self.timeout, self.handler)
+
+        self.assertNotIn("TZ", environ.resolve())
+
+    @parameterized.expand([(None, "RANDOM_SEED"), ("TEST_SEED", "TEST_SEED")])
+    def test_random_seed_takes_precedence(self, random_seed_env_var, expected_name):
+        environ = EnvironmentVariables(
+            self.name,
+            self.memory,
+            self.timeout,
+            self.handler,
+            variables={expected_name: "1"},
+            override_values={expected_name: "2"},
+            random_seed=42,
+            random_seed_env_var=random_seed_env_var,
+        )
+
+        self.assertEqual(environ.resolve()[expected_name], "42")
+
+    def test_without_random_seed(self):
+        environ = EnvironmentVariables(self.name, self.memory, self.timeout, self.handler)
+
+        self.assertNotIn("RANDOM_SEED", environ.resolve())
+
+    def test_aws_endpoint_url(self):
+        environ = EnvironmentVariables(
+            self.name,
+            self.memory,
+            self.timeout,
+            self.handler,
+            variables={"AWS_ENDPOINT_URL_S3": "http://minio:9000"},
+            aws_endpoint_url="http://localstack:4566",
+        )
+
+        result = environ.resolve()
+
+        self.assertEqual(result["AWS_ENDPOINT_URL"], "http://localstack:4566")
+        self.assertEqual(result["AWS_ENDPOINT_URL_DYNAMODB"], "http://localstack:4566")
+        self.assertEqual(result["AWS_ENDPOINT_URL_S3"], "http://minio:9000")
+
+    def test_without_aws_endpoint_url(self):
+        environ = EnvironmentVariables(self.name, self.memory, self.timeout, self.handler)
+
+        self.assertFalse([name for name in environ.resolve() if name.startswith("AWS_ENDPOINT_URL")])
+
+    @parameterized.expand(
+        [
+            ("python3.9", False, "1"),
+            ("python2.7", False, "1"),
+            ("python3.9", True, None),
+            ("nodejs14.x", False, None),
+            (None, False, None),
+        ]
+    )
+    def test_must_disable_output_buffering_of_runtime(self, runtime, buffered_output, expected):
+        environ = EnvironmentVariables(
+            self.name, self.memory, self.timeout, self.handler, runtime=runtime, buffered_output=buffered_output
+        )
+
+        self.assertEqual(environ.resolve().get("PYTHONUNBUFFERED"), expected)
+
+    def test_function_variables_take_precedence_over_unbuffered_output(self):
+        environ = EnvironmentVariables(
+            self.name,
+            self.memory,
+            self.timeout,
+            self.handler,
+            variables={"PYTHONUNBUFFERED": ""},
+            runtime="python3.9",
+        )
+
+        self.assertEqual(environ.resolve()["PYTHONUNBUFFERED"], "")
+
 
 class TestEnvironmentVariables_get_aws_variables(TestCase):
     def setUp(self):
@@ -2,6 +2,7 @@
 Unit tests for Lambda runtime
 """
 
+import threading
 from unittest import TestCase
 from unittest.mock import Mock, patch, MagicMock, ANY, call
 from parameterized import parameterized
@@ -9,7 +10,9 @@ from parameterized import parameterized
 from samcli.lib.utils.packagetype import ZIP, IMAGE
 from samcli.lib.providers.provider import LayerVersion
 from samcli.local.lambdafn.runtime import LambdaRuntime, _unzip_file, WarmLambdaRuntime
+from samcli.local.docker.container import SAM_LOCAL_FUNCTION_LABEL
 from samcli.local.lambdafn.config import FunctionConfig
+from samcli.local.lambdafn.exceptions import FunctionTimeoutException
 
 
 class LambdaRuntime_create(TestCase):
@@ -83,6 +86,9 @@ class LambdaRuntime_create(TestCase):
             memory_mb=self.DEFAULT_MEMORY,
             container_host=None,
             container_host_interface=None,
+            network_aliases=None,
+            labels={SAM_LOCAL_FUNCTION_LABEL: self.name},
+            entrypoint=None,
         )
         # Run the container and get results
         self.manager_mock.create.assert_called_with(container)
@@ -241,7 +247,7 @@ class LambdaRuntime_invoke(TestCase):
         self.runtime._get_code_dir = MagicMock()
         self.runtime._get_code_dir.return_value = code_dir
 
-        self.runtime._clean_decompressed_paths = MagicMock()
+        self.runtime.clean_decompressed_paths = MagicMock()
 
         # Configure interrupt handler
         self.runtime._configure_interrupt = Mock()
@@ -273,17 +279,23 @@ class LambdaRuntime_invoke(TestCase):
             memory_mb=self.DEFAULT_MEMORY,
             container_host=None,
             container_host_interface=None,
+            network_aliases=None,
+            labels={SAM_LOCAL_FUNCTION_LABEL: self.name},
+            entrypoint=None,
         )
 
         # Run the container and get results
         self.manager_mock.run.assert_called_with(container)
         self.runtime._configure_interrupt.assert_called_with(self.name, self.DEFAULT_TIMEOUT, container, True)
-        container.wait_for_result.assert_called_with(event=event, name=self.name, stdout=stdout, stderr=stderr)
+        container.wait_for_result.assert_called_with(
+            event=event, name=self.name, stdout=stdout, stderr=stderr, trace_id=None
+        )
 
         # Finally block
         timer.cancel.assert_called_with()
         self.manager_mock.stop.assert_called_with(container)
-        self.runtime._clean_decompressed_paths.assert_called_with()
+        # The decompressed code is reused by the next invocations
+        self.runtime.clean_decompressed_paths.assert_not_called()
 
     @patch("samcli.local.lambdafn.runtime.LambdaContainer")
     def test_exception_from_run_must_trigger_cleanup(self, LambdaContainerMock):
@@ -360,6 +372,36 @@ class LambdaRuntime_invoke(TestCase):
         # In any case, stop the container
         self.manager_mock.stop.assert_called_with(container)
 
+    @patch("samcli.local.lambdafn.runtime.LambdaContainer")
+    def test_function_running_past_timeout_must_raise_and_report(self, LambdaContainerMock):
+        container = Mock()
+        container_stopped = threading.Event()
+        stderr = Mock()
+
+        def wait_for_result(**kwargs):
+            # Like a function that sleeps, it only stops responding when the container is stopped
+            self.assertTrue(container_stopped.wait(5))
+            raise ConnectionError("container was stopped")
+
+        self.func_config.timeout = 0.1
+        self.runtime = LambdaRuntime(self.manager_mock, Mock())
+        self.runtime._get_code_dir = MagicMock()
+
+        LambdaContainerMock.return_value = container
+        container.wait_for_result.side_effect = wait_for_result
+        self.manager_mock.stop.side_effect = lambda _: container_stopped.set()
+
+        with self.assertRaises(FunctionTimeoutException) as ex_ctx:
+            self.runtime.invoke(self.func_config, "event", stdout=Mock(), stderr=stderr)
+
+        self.assertEqual(str(ex_ctx.exception), "Task timed out after 0.10 seconds")
+        self.assertEqual(ex_ctx.exception.function_name, self.name)
+        stderr.write.assert_called_once_with(
+            b"Task timed out after 0.10 seconds\n"
+            b"REPORT Function: name\tDuration: 100.00 ms\tMemory Size: 128 MB\tStatus: timeout\n"
+        )
+        self.assertEqual(self.runtime._timed_out_containers, set())
+
     @patch("samcli.local.lambdafn.runtime.LambdaContainer")
     def test_keyboard_interrupt_must_not_raise(self, LambdaContainerMock):
         event = "event"
@@ -467,6 +509,7 @@ class TestLambdaRuntime_configure_interrupt(TestCase):
 
         # This method should be called from within the Timer Handler
         self.manager_mock.stop.assert_called_with(self.container)
+        self.assertTrue(self.runtime._is_timed_out(self.container))
 
 
 class TestLambdaRuntime_get_code_dir(TestCase):
@@ -513,6 +556,39 @@ class TestLambdaRuntime_get_code_dir(TestCase):
         # Because we never unzipped anything, we should never delete
         shutil_mock.rmtree.assert_not_called()
 
+    @patch("samcli.local.lambdafn.runtime.os")
+    @patch("samcli.local.lambdafn.runtime._unzip_file")
+    def test_must_reuse_decompressed_dir_until_archive_changes(self, unzip_file_mock, os_mock):
+        code_path = "foo.jar"
+        unzip_file_mock.side_effect = ["decompressed-dir-1", "decompressed-dir-2"]
+        os_mock.path.isfile.return_value = True
+        os_mock.path.getmtime.return_value = 100.0
+
+        self.assertEqual(self.runtime._get_code_dir(code_path), "decompressed-dir-1")
+        self.assertEqual(self.runtime._get_code_dir(code_path), "decompressed-dir-1")
+        unzip_file_mock.assert_called_once_with(code_path)
+
+        os_mock.path.getmtime.return_value = 200.0
+        self.assertEqual(self.runtime._get_code_dir(code_path), "decompressed-dir-2")
+        self.assertEqual(
+            self.runtime._temp_uncompressed_paths_to_be_cleaned, ["decompressed-dir-1", "decompressed-dir-2"]
+        )
+
+    @patch("samcli.local.lambdafn.runtime.shutil")
+    @patch("samcli.local.lambdafn.runtime.os")
+    @patch("samcli.local.lambdafn.runtime._unzip_file")
+    def test_must_decompress_again_once_cleaned(self, unzip_file_mock, os_mock, shutil_mock):
+        code_path = "foo.zip"
+        unzip_file_mock.side_effect = ["decompressed-dir-1", "decompressed-dir-2"]
+        os_mock.path.isfile.return_value = True
+        os_mock.path.getmtime.return_value = 100.0
+
+        self.runtime._get_code_dir(code_path)
+        self.runtime.clean_decompressed_paths()
+
+        shutil_mock.rmtree.assert_called_once_with("decompressed-dir-1")
+        self.assertEqual(self.runtime._get_code_dir(code_path), "decompressed-dir-2")
+
 
 class TestWarmLambdaRuntime_invoke(TestCase):
 
@@ -601,12 +677,18 @@ class TestWarmLambdaRuntime_invoke(TestCase):
             memory_mb=self.DEFAULT_MEMORY,
             container_host=None,
             container_host_interface=None,
+            network_aliases=None,
+            labels={SAM_LOCAL_FUNCTION_LABEL: self.name},
+            entrypoint=None,
         )
 
         # Run the container and get results
         self.manager_mock.run.assert_called_with(container)
+        container.wait_until_ready.assert_called_with()
         self.runtime._configure_interrupt.assert_called_with(self.name, self.DEFAULT_TIMEOUT, container, True)
-        container.wait_for_result.assert_called_with(event=event, name=self.name, stdout=stdout, stderr=stderr)
+        container.wait_for_result.assert_called_with(
+            event=event, name=self.name, stdout=stdout, stderr=stderr, trace_id=None
+        )
 
         # Finally block
         timer.cancel.assert_called_with()
@@ -680,6 +762,9 @@ class TestWarmLambdaRuntime_create(TestCase):
             memory_mb=self.DEFAULT_MEMORY,
             container_host=None,
             container_host_interface=None,
+            network_aliases=None,
+            labels={SAM_LOCAL_FUNCTION_LABEL: self.name},
+            entrypoint=None,
         )
 
         self.manager_mock.create.assert_called_with(container)
@@ -747,6 +832,9 @@ class TestWarmLambdaRuntime_create(TestCase):
             memory_mb=self.DEFAULT_MEMORY,
             container_host=None,
             container_host_interface=None,
+            network_aliases=None,
+            labels={SAM_LOCAL_FUNCTION_LABEL: self.name},
+            entrypoint=None,
         )
         self.manager_mock.create.assert_called_with(container)
         # validate that the created container got cached
@@ -908,6 +996,31 @@ class TestWarmLambdaRuntime_on_code_change(TestCase):
         )
 
 
+class TestWarmLambdaRuntime_configure_interrupt(TestCase):
+    def setUp(self):
+        self.name = "name"
+        self.timeout = 123
+        self.container = Mock()
+
+        self.manager_mock = Mock()
+        self.runtime = WarmLambdaRuntime(self.manager_mock, Mock())
+        self.runtime._containers[self.name] = self.container
+
+    @patch("samcli.local.lambdafn.runtime.threading")
+    def test_timer_handler_must_stop_and_forget_timed_out_container(self, ThreadingMock):
+        def fake_timer(timeout, handler, args):
+            handler()
+            return Mock()
+
+        ThreadingMock.Timer = fake_timer
+
+        self.runtime._configure_interrupt(self.name, self.timeout, self.container, False)
+
+        self.manager_mock.stop.assert_called_with(self.container)
+        self.assertTrue(self.runtime._is_timed_out(self.container))
+        self.assertEqual(self.runtime._containers, {})
+
+
 class TestUnzipFile(TestCase):
     @patch("samcli.local.lambdafn.runtime.tempfile")
     @patch("samcli.local.lambdafn.runtime.unzip")
@@ -1,4 +1,10 @@
+import io
 import os
+import shutil
+import tempfile
+import threading
+import zipfile
+from http.server import BaseHTTPRequestHandler, HTTPServer
 from unittest import TestCase
 from unittest.mock import Mock, call, patch
 
@@ -7,6 +13,7 @@ from pathlib import Path
 
 from parameterized import parameterized
 
+from samcli.lib.providers.provider import LayerVersion
 from samcli.local.layers.layer_downloader import LayerDownloader
 from samcli.commands.local.cli_common.user_exceptions import CredentialsRequired, ResourceNotFound
 
@@ -216,3 +223,60 @@ class TestLayerDownloader_fetch_layer_uri(TestCase):
 
         with self.assertRaises(ClientError):
             download_layers._fetch_layer_uri(layer=layer)
+
+
+def _layer_zip():
+    zip_buffer = io.BytesIO()
+    with zipfile.ZipFile(zip_buffer, "w") as layer_zip:
+        layer_zip.writestr("python/shared.py", "VALUE = 42\n")
+    return zip_buffer.getvalue()
+
+
+class LayerContentHandler(BaseHTTPRequestHandler):
+    content = _layer_zip()
+
+    def do_GET(self):
+        self.send_response(200)
+        self.send_header("Content-Length", str(len(self.content)))
+        self.end_headers()
+        self.wfile.write(self.content)
+
+    def log_message(self, *args):
+        pass
+
+
+class TestLayerDownloader_download_from_server(TestCase):
+    def setUp(self):
+        self.server = HTTPServer(("127.0.0.1", 0), LayerContentHandler)
+        threading.Thread(target=self.server.serve_forever, daemon=True).start()
+        self.layer_cache = tempfile.mkdtemp()
+
+        self.lambda_client_mock = Mock()
+        self.lambda_client_mock.get_layer_version.return_value = {
+            "Content": {"Location": "http://127.0.0.1:{}/layer.zip".format(self.server.server_port)}
+        }
+
+    def tearDown(self):
+        self.server.shutdown()
+        self.server.server_close()
+        shutil.rmtree(self.layer_cache)
+
+    def test_must_download_extract_and_reuse_the_layer(self):
+        arn = "arn:aws:lambda:us-east-1:123456789012:layer:shared:1"
+        download_layers = LayerDownloader(self.layer_cache, ".", [], self.lambda_client_mock)
+
+        layer = download_layers.download(LayerVersion(arn, None))
+
+        self.lambda_client_mock.get_layer_version.assert_called_once_with(
+            LayerName="arn:aws:lambda:us-east-1:123456789012:layer:shared", VersionNumber=1
+        )
+        self.assertEqual(layer.codeuri, str(Path(self.layer_cache, layer.name).resolve()))
+        with open(os.path.join(layer.codeuri, "python", "shared.py"), "r") as shared_file:
+            self.assertEqual(shared_file.read(), "VALUE = 42\n")
+        self.assertFalse(os.path.exists(layer.codeuri + ".zip"))
+
+        # The cached version is used by the next invokes
+        cached_layer = download_layers.download(LayerVersion(arn, None))
+
+        self.assertEqual(cached_layer.codeuri, layer.codeuri)
+        self.lambda_client_mock.get_layer_version.assert_called_once()
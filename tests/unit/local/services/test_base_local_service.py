@@ -1,6 +1,11 @@
+import json
+import socket
+import threading
+import urllib.request
 from unittest import TestCase
 from unittest.mock import Mock, patch
 
+from flask import Flask
 from parameterized import parameterized, param
 
 from samcli.local.services.base_local_service import BaseLocalService, LambdaOutputParser
@@ -38,6 +43,69 @@ class TestLocalHostRunner(TestCase):
 
         app_run_mock.assert_called_once_with(threaded=False, host="127.0.0.1", port=3000)
 
+    def test_run_serves_bound_server(self):
+        service = BaseLocalService(is_debugging=False, port=0, host="127.0.0.1")
+        service._app = Mock()
+        service._server = Mock()
+
+        service.run()
+
+        service._server.serve_forever.assert_called_once_with()
+        service._app.run.assert_not_called()
+
+    def test_runtime_error_raised_when_binding_app_not_created(self):
+        service = BaseLocalService(is_debugging=False, port=0, host="127.0.0.1")
+
+        with self.assertRaises(RuntimeError):
+            service.bind()
+
+    @parameterized.expand([(False, True), (True, False)])
+    @patch("samcli.local.services.base_local_service.make_server")
+    def test_bind_uses_port_of_server(self, is_debugging, expected_threaded, make_server_mock):
+        make_server_mock.return_value.server_port = 54321
+        service = BaseLocalService(is_debugging=is_debugging, port=0, host="127.0.0.1")
+        service._app = Mock()
+
+        service.bind()
+
+        make_server_mock.assert_called_once_with("127.0.0.1", 0, service._app, threaded=expected_threaded)
+        self.assertEqual(service.port, 54321)
+
+    def test_bind_listens_on_port_chosen_by_os(self):
+        service = BaseLocalService(is_debugging=False, port=0, host="127.0.0.1")
+        service._app = Flask(__name__)
+
+        service.bind()
+        self.addCleanup(service._server.server_close)
+
+        self.assertNotEqual(service.port, 0)
+        with socket.create_connection(("127.0.0.1", service.port), timeout=5):
+            pass
+
+    @patch.object(BaseLocalService, "service_response")
+    def test_health_check_reports_ready(self, service_response_mock):
+        service = BaseLocalService(is_debugging=False, port=3000, host="127.0.0.1")
+
+        service._health_check_handler()
+
+        service_response_mock.assert_called_once_with('{"status": "ok"}', {"Content-Type": "application/json"}, 200)
+
+    def test_health_check_reports_ready_once_serving(self):
+        service = BaseLocalService(is_debugging=False, port=0, host="127.0.0.1")
+        service._app = Flask(__name__)
+        service._add_health_check_route()
+        service.bind()
+        self.addCleanup(service._server.server_close)
+
+        threading.Thread(target=service.run, daemon=True).start()
+        self.addCleanup(service._server.shutdown)
+
+        # The server listens once it is bound, the request waits until it serves
+        url = "http://127.0.0.1:{}/_sam/health".format(service.port)
+        with urllib.request.urlopen(url, timeout=10) as response:
+            self.assertEqual(response.status, 200)
+            self.assertEqual(json.loads(response.read()), {"status": "ok"})
+
     @patch("samcli.local.services.base_local_service.Response")
     def test_service_response(self, flask_response_patch):
         flask_response_mock = Mock()
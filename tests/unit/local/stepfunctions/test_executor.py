@@ -0,0 +1,320 @@
+import json
+from unittest import TestCase
+from unittest.mock import Mock, patch
+
+from parameterized import parameterized
+
+from samcli.local.lambdafn.exceptions import FunctionTimeoutException
+from samcli.local.stepfunctions.exceptions import StateMachineDefinitionError, StateMachineExecutionFailed
+from samcli.local.stepfunctions.executor import LocalStateMachineExecutor
+from samcli.local.stepfunctions.state_machine import StateMachine
+
+
+class TestLocalStateMachineExecutor(TestCase):
+    def setUp(self):
+        self.functions = {}
+        self.invoked_events = []
+        self.local_lambda_runner = Mock()
+        self.local_lambda_runner.invoke.side_effect = self._invoke
+        self.executor = LocalStateMachineExecutor(self.local_lambda_runner)
+
+    def _invoke(self, function_name, event, stdout, stderr):
+        self.invoked_events.append((function_name, json.loads(event)))
+        response = self.functions[function_name](json.loads(event))
+        stdout.write(response.encode("utf-8") if isinstance(response, str) else json.dumps(response).encode("utf-8"))
+
+    def _execute(self, states, execution_input, start_at=None):
+        definition = {"StartAt": start_at or list(states)[0], "States": states}
+        return self.executor.execute(StateMachine(name="OrderStateMachine", definition=definition), execution_input)
+
+    def test_must_pass_output_of_every_task_to_the_next(self):
+        self.functions["Validate"] = lambda event: {**event, "valid": True}
+        self.functions["my-ship-function"] = lambda event: {"shipped": event["valid"]}
+
+        output = self._execute(
+            {
+                "Validate": {
+                    "Type": "Task",
+                    "Resource": "arn:aws:lambda:us-east-1:123456789012:function:Validate",
+                    "Next": "Ship",
+                },
+                "Ship": {
+                    "Type": "Task",
+                    "Resource": "arn:aws:lambda:us-east-1:123456789012:function:my-ship-function:live",
+                    "End": True,
+                },
+            },
+            {"orderId": 42},
+        )
+
+        self.assertEqual(output, {"shipped": True})
+        self.assertEqual(
+            self.invoked_events,
+            [("Validate", {"orderId": 42}), ("my-ship-function", {"orderId": 42, "valid": True})],
+        )
+
+    def test_must_invoke_function_through_lambda_invoke_integration(self):
+        self.functions["Price"] = lambda event: {"price": event["quantity"] * 2}
+
+        output = self._execute(
+            {
+                "Price": {
+                    "Type": "Task",
+                    "Resource": "arn:aws:states:::lambda:invoke",
+                    "Parameters": {"FunctionName": "Price", "Payload": {"quantity.$": "$.order.quantity"}},
+                    "ResultSelector": {"price.$": "$.Payload.price"},
+                    "ResultPath": "$.pricing",
+                    "OutputPath": "$.pricing",
+                    "End": True,
+                }
+            },
+            {"order": {"quantity": 3}},
+        )
+
+        self.assertEqual(output, {"price": 6})
+        self.assertEqual(self.invoked_events, [("Price", {"quantity": 3})])
+
+    def test_must_send_effective_input_through_lambda_invoke_integration_without_payload(self):
+        self.functions["Price"] = lambda event: {"price": event["quantity"] * 2}
+
+        output = self._execute(
+            {
+                "Price": {
+                    "Type": "Task",
+                    "Resource": "arn:aws:states:::lambda:invoke",
+                    "InputPath": "$.order",
+                    "Parameters": {"FunctionName": "Price"},
+                    "OutputPath": "$.Payload",
+                    "End": True,
+                }
+            },
+            {"order": {"quantity": 3}},
+        )
+
+        self.assertEqual(output, {"price": 6})
+        self.assertEqual(self.invoked_events, [("Price", {"quantity": 3})])
+
+    @parameterized.expand(
+        [
+            ({"type": "book", "price": 12}, "Books"),
+            ({"type": "toy", "price": 5}, "CheapToys"),
+            ({"type": "toy", "price": 50}, "Other"),
+            ({"type": "food"}, "Other"),
+        ]
+    )
+    def test_must_follow_matching_choice(self, execution_input, expected_state):
+        output = self._execute(
+            {
+                "Route": {
+                    "Type": "Choice",
+                    "Choices": [
+                        {"Variable": "$.type", "StringEquals": "book", "Next": "Books"},
+                        {
+                            "And": [
+                                {"Variable": "$.price", "IsPresent": True},
+                                {"Variable": "$.price", "NumericLessThan": 10},
+                                {"Not": {"Variable": "$.type", "StringMatches": "b*"}},
+                            ],
+                            "Next": "CheapToys",
+                        },
+                    ],
+                    "Default": "Other",
+                },
+                "Books": {"Type": "Pass", "Result": "Books", "End": True},
+                "CheapToys": {"Type": "Pass", "Result": "CheapToys", "End": True},
+                "Other": {"Type": "Pass", "Result": "Other", "End": True},
+            },
+            execution_input,
+        )
+
+        self.assertEqual(output, expected_state)
+
+    @parameterized.expand(
+        [
+            ({"Variable": "$.a", "NumericEqualsPath": "$.b"}, {"a": 1, "b": 1}, True),
+            ({"Variable": "$.a", "NumericGreaterThanEquals": 2}, {"a": 1}, False),
+            ({"Variable": "$.a", "NumericEquals": 1}, {"a": "1"}, False),
+            ({"Variable": "$.a", "BooleanEquals": True}, {"a": True}, True),
+            ({"Variable": "$.a", "IsNull": True}, {"a": None}, True),
+            ({"Variable": "$.a[1]", "StringLessThan": "b"}, {"a": ["z", "a"]}, True),
+            ({"Variable": "$.a", "StringMatches": "log-\\*-*.txt"}, {"a": "log-*-1.txt"}, True),
+            ({"Variable": "$.a", "StringMatches": "log-\\*-*.txt"}, {"a": "log-1-1.txt"}, False),
+            (
+                {"Variable": "$.a", "TimestampLessThan": "2020-01-01T00:00:00Z"},
+                {"a": "2019-12-31T23:00:00-02:00"},
+                False,
+            ),
+            ({"Variable": "$.a", "IsTimestamp": True}, {"a": "2019-12-31T23:00:00.123Z"}, True),
+            ({"Or": [{"Variable": "$.a", "IsString": True}, {"Variable": "$.a", "IsBoolean": True}]}, {"a": 1}, False),
+        ]
+    )
+    def test_must_evaluate_choice_rules(self, rule, data, expected):
+        self.assertEqual(self.executor._evaluate_rule(rule, data), expected)
+
+    def test_must_fail_when_no_choice_matches(self):
+        with self.assertRaises(StateMachineExecutionFailed) as ctx:
+            self._execute({"Route": {"Type": "Choice", "Choices": []}}, {})
+
+        self.assertEqual(ctx.exception.error, "States.NoChoiceMatched")
+
+    def test_must_apply_parameters_of_pass_state_with_context_object(self):
+        output = self._execute(
+            {
+                "Wait": {"Type": "Wait", "Seconds": 3600, "Next": "Prepare"},
+                "Prepare": {
+                    "Type": "Pass",
+                    "InputPath": "$.order",
+                    "Parameters": {
+                        "id.$": "$.id",
+                        "items.$": "$.items[0]",
+                        "state.$": "$$.State.Name",
+                        "source": {"input.$": "$$.Execution.Input"},
+                    },
+                    "ResultPath": "$.prepared",
+                    "Next": "Done",
+                },
+                "Done": {"Type": "Succeed", "OutputPath": "$.prepared"},
+            },
+            {"order": {"id": 42, "items": ["book"]}},
+        )
+
+        self.assertEqual(
+            output,
+            {
+                "id": 42,
+                "items": "book",
+                "state": "Prepare",
+                "source": {"input": {"order": {"id": 42, "items": ["book"]}}},
+            },
+        )
+
+    def test_must_catch_error_of_function(self):
+        self.functions["Charge"] = lambda event: {"errorType": "PaymentDeclined", "errorMessage": "Card declined"}
+
+        output = self._execute(
+            {
+                "Charge": {
+                    "Type": "Task",
+                    "Resource": "arn:aws:lambda:us-east-1:123456789012:function:Charge",
+                    "Catch": [
+                        {"ErrorEquals": ["States.Timeout"], "Next": "Failed"},
+                        {"ErrorEquals": ["PaymentDeclined"], "ResultPath": "$.error", "Next": "Declined"},
+                    ],
+                    "End": True,
+                },
+                "Declined": {"Type": "Pass", "End": True},
+                "Failed": {"Type": "Fail"},
+            },
+            {"orderId": 42},
+        )
+
+        self.assertEqual(output["orderId"], 42)
+        self.assertEqual(output["error"]["Error"], "PaymentDeclined")
+        self.assertEqual(json.loads(output["error"]["Cause"])["errorMessage"], "Card declined")
+
+    def test_must_catch_timeout_of_function(self):
+        self.local_lambda_runner.invoke.side_effect = FunctionTimeoutException("Charge", 3)
+
+        output = self._execute(
+            {
+                "Charge": {
+                    "Type": "Task",
+                    "Resource": "arn:aws:lambda:us-east-1:123456789012:function:Charge",
+                    "Catch": [{"ErrorEquals": ["States.Timeout"], "ResultPath": "$.error", "Next": "TimedOut"}],
+                    "End": True,
+                },
+                "TimedOut": {"Type": "Pass", "End": True},
+            },
+            {"orderId": 42},
+        )
+
+        self.assertEqual(output["error"], {"Error": "States.Timeout", "Cause": "Function Charge timed out"})
+
+    def test_must_retry_function_up_to_max_attempts(self):
+        responses = iter(["", "", {"done": True}])
+        self.functions["Flaky"] = lambda event: next(responses)
+
+        output = self._execute(
+            {
+                "Flaky": {
+                    "Type": "Task",
+                    "Resource": "arn:aws:lambda:us-east-1:123456789012:function:Flaky",
+                    "Retry": [{"ErrorEquals": ["States.ALL"], "MaxAttempts": 2, "IntervalSeconds": 60}],
+                    "End": True,
+                }
+            },
+            {},
+        )
+
+        self.assertEqual(output, {"done": True})
+        self.assertEqual(self.local_lambda_runner.invoke.call_count, 3)
+
+    def test_must_fail_after_max_attempts(self):
+        self.functions["Broken"] = lambda event: {"errorType": "Runtime.ExitError", "errorMessage": "exit status 1"}
+
+        with self.assertRaises(StateMachineExecutionFailed) as ctx:
+            self._execute(
+                {
+                    "Broken": {
+                        "Type": "Task",
+                        "Resource": "arn:aws:lambda:us-east-1:123456789012:function:Broken",
+                        "Retry": [{"ErrorEquals": ["States.TaskFailed"], "MaxAttempts": 1}],
+                        "End": True,
+                    }
+                },
+                {},
+            )
+
+        self.assertEqual(ctx.exception.error, "Runtime.ExitError")
+        self.assertEqual(self.local_lambda_runner.invoke.call_count, 2)
+
+    def test_must_fail_with_error_of_fail_state(self):
+        with self.assertRaises(StateMachineExecutionFailed) as ctx:
+            self._execute({"Reject": {"Type": "Fail", "Error": "OrderRejected", "Cause": "Out of stock"}}, {})
+
+        self.assertEqual(str(ctx.exception), "Execution failed with error OrderRejected: Out of stock")
+
+    @patch("samcli.local.stepfunctions.executor.MAX_STATE_TRANSITIONS", 10)
+    def test_must_fail_when_execution_loops_forever(self):
+        self.functions["CheckStatus"] = lambda event: {"status": "PENDING"}
+
+        with self.assertRaises(StateMachineExecutionFailed) as ctx:
+            self._execute(
+                {
+                    "CheckStatus": {
+                        "Type": "Task",
+                        "Resource": "arn:aws:lambda:us-east-1:123456789012:function:CheckStatus",
+                        "Next": "IsDone",
+                    },
+                    "IsDone": {
+                        "Type": "Choice",
+                        "Choices": [{"Variable": "$.status", "StringEquals": "DONE", "Next": "Done"}],
+                        "Default": "WaitForStatus",
+                    },
+                    "WaitForStatus": {"Type": "Wait", "Seconds": 30, "Next": "CheckStatus"},
+                    "Done": {"Type": "Succeed"},
+                },
+                {},
+            )
+
+        self.assertEqual(ctx.exception.error, "States.Runtime")
+        self.assertIn("maximum of 10 state transitions", str(ctx.exception))
+        self.assertEqual(self.local_lambda_runner.invoke.call_count, 4)
+
+    def test_must_fail_when_path_does_not_match(self):
+        with self.assertRaises(StateMachineExecutionFailed) as ctx:
+            self._execute({"Prepare": {"Type": "Pass", "InputPath": "$.order", "End": True}}, {"id": 42})
+
+        self.assertEqual(ctx.exception.error, "States.Runtime")
+
+    @parameterized.expand(
+        [
+            ({"Type": "Map", "End": True},),
+            ({"Type": "Task", "Resource": "arn:aws:states:::sqs:sendMessage", "End": True},),
+            ({"Type": "Pass", "Parameters": {"message.$": "States.Format('Hello {}', $.name)"}, "End": True},),
+            ({"Type": "Pass", "Next": "Missing"},),
+        ]
+    )
+    def test_must_raise_on_unsupported_definition(self, state):
+        with self.assertRaises(StateMachineDefinitionError):
+            self._execute({"State": state}, {"name": "world"})
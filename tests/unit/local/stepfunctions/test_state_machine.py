@@ -0,0 +1,112 @@
+import json
+import os
+import tempfile
+from unittest import TestCase
+from unittest.mock import Mock
+
+from samcli.local.stepfunctions.exceptions import StateMachineDefinitionError
+from samcli.local.stepfunctions.state_machine import get_state_machine
+
+
+class TestGetStateMachine(TestCase):
+    def setUp(self):
+        self.definition = {
+            "StartAt": "Validate",
+            "States": {"Validate": {"Type": "Task", "Resource": "${ValidateFunctionArn}", "End": True}},
+        }
+
+    def _make_stack(self, resources, stack_path="", location="template.yaml"):
+        stack = Mock()
+        stack.resources = resources
+        stack.stack_path = stack_path
+        stack.location = location
+        return stack
+
+    def test_must_substitute_definition_substitutions(self):
+        stack = self._make_stack(
+            {
+                "OrderStateMachine": {
+                    "Type": "AWS::Serverless::StateMachine",
+                    "Properties": {
+                        "Definition": self.definition,
+                        "DefinitionSubstitutions": {
+                            "ValidateFunctionArn": "arn:aws:lambda:us-east-1:123456789012:function:Validate"
+                        },
+                    },
+                }
+            }
+        )
+
+        state_machine = get_state_machine([stack], "OrderStateMachine")
+
+        self.assertEqual(state_machine.name, "OrderStateMachine")
+        self.assertEqual(
+            state_machine.definition["States"]["Validate"]["Resource"],
+            "arn:aws:lambda:us-east-1:123456789012:function:Validate",
+        )
+
+    def test_must_read_definition_string_of_nested_stack(self):
+        root_stack = self._make_stack({"ChildStack": {"Type": "AWS::Serverless::Application"}})
+        child_stack = self._make_stack(
+            {
+                "OrderStateMachine": {
+                    "Type": "AWS::StepFunctions::StateMachine",
+                    "Properties": {"DefinitionString": json.dumps(self.definition)},
+                }
+            },
+            stack_path="ChildStack",
+        )
+
+        state_machine = get_state_machine([root_stack, child_stack], "ChildStack/OrderStateMachine")
+
+        self.assertEqual(state_machine.definition, self.definition)
+
+    def test_must_read_local_definition_uri_relative_to_template(self):
+        with tempfile.TemporaryDirectory() as template_dir:
+            with open(os.path.join(template_dir, "order.asl.json"), "w") as definition_file:
+                json.dump(self.definition, definition_file)
+            stack = self._make_stack(
+                {
+                    "OrderStateMachine": {
+                        "Type": "AWS::Serverless::StateMachine",
+                        "Properties": {"DefinitionUri": "order.asl.json"},
+                    }
+                },
+                location=os.path.join(template_dir, "template.yaml"),
+            )
+
+            state_machine = get_state_machine([stack], "OrderStateMachine")
+
+        self.assertEqual(state_machine.definition, self.definition)
+
+    def test_must_return_none_for_other_resources(self):
+        stack = self._make_stack({"ValidateFunction": {"Type": "AWS::Serverless::Function"}})
+
+        self.assertIsNone(get_state_machine([stack], "ValidateFunction"))
+        self.assertIsNone(get_state_machine([stack], "OrderStateMachine"))
+
+    def test_must_raise_when_definition_is_in_s3(self):
+        stack = self._make_stack(
+            {
+                "OrderStateMachine": {
+                    "Type": "AWS::Serverless::StateMachine",
+                    "Properties": {"DefinitionUri": "s3://bucket/order.asl.json"},
+                }
+            }
+        )
+
+        with self.assertRaises(StateMachineDefinitionError):
+            get_state_machine([stack], "OrderStateMachine")
+
+    def test_must_raise_when_definition_string_is_not_json(self):
+        stack = self._make_stack(
+            {
+                "OrderStateMachine": {
+                    "Type": "AWS::StepFunctions::StateMachine",
+                    "Properties": {"DefinitionString": "{not json"},
+                }
+            }
+        )
+
+        with self.assertRaisesRegex(StateMachineDefinitionError, "OrderStateMachine"):
+            get_state_machine([stack], "OrderStateMachine")
@@ -13,7 +13,9 @@
 from botocore.compat import OrderedDict
 
 from unittest import TestCase
-from samcli.yamlhelper import yaml_parse, yaml_dump
+import yaml
+
+from samcli.yamlhelper import get_node_position, yaml_parse, yaml_dump
 
 
 class TestYaml(TestCase):
@@ -144,6 +146,43 @@ class TestYaml(TestCase):
         self.assertTrue(isinstance(output, OrderedDict))
         self.assertEqual(output.get("test").get("property"), "value")
 
+    def test_yaml_anchors_with_intrinsic_tags(self):
+        test_yaml = """
+        Resources:
+            Function1:
+                Type: AWS::Serverless::Function
+                Properties:
+                    Environment: &environment
+                        Variables: &variables
+                            TABLE: !Ref Table
+                            TABLE_ARN: !GetAtt Table.Arn
+            Function2:
+                Type: AWS::Serverless::Function
+                Properties:
+                    Role: &role !GetAtt Role.Arn
+                    Environment: *environment
+            Function3:
+                Type: AWS::Serverless::Function
+                Properties:
+                    Role: *role
+                    Environment:
+                        Variables:
+                            <<: *variables
+                            STAGE: !Sub "${AWS::Region}-dev"
+        """
+        environment = {"Variables": {"TABLE": {"Ref": "Table"}, "TABLE_ARN": {"Fn::GetAtt": ["Table", "Arn"]}}}
+
+        output = yaml_parse(test_yaml)
+        resources = output.get("Resources")
+        self.assertEqual(resources["Function1"]["Properties"]["Environment"], environment)
+        self.assertEqual(resources["Function2"]["Properties"]["Environment"], environment)
+        self.assertEqual(resources["Function2"]["Properties"]["Role"], {"Fn::GetAtt": ["Role", "Arn"]})
+        self.assertEqual(resources["Function3"]["Properties"]["Role"], {"Fn::GetAtt": ["Role", "Arn"]})
+        self.assertEqual(
+            resources["Function3"]["Properties"]["Environment"]["Variables"],
+            dict(environment["Variables"], STAGE={"Fn::Sub": "${AWS::Region}-dev"}),
+        )
+
     def test_unroll_yaml_anchors(self):
         properties = {"Foo": "bar", "Spam": "eggs"}
         template = {"Resources": {"Resource1": {"Properties": properties}, "Resource2": {"Properties": properties}}}
@@ -161,3 +200,12 @@ class TestYaml(TestCase):
         )
         actual = yaml_dump(template)
         self.assertEqual(actual, expected)
+
+    def test_get_node_position(self):
+        root_node = yaml.compose("Resources:\n  Queue:\n    Tags:\n      - Key: Name\n", Loader=yaml.SafeLoader)
+
+        self.assertEqual(get_node_position(root_node, ["Resources", "Queue", "Tags", 0, "Key"]), (4, 14))
+        self.assertEqual(get_node_position(root_node, []), (1, 1))
+        self.assertIsNone(get_node_position(root_node, ["Resources", "Missing"]))
+        self.assertIsNone(get_node_position(root_node, ["Resources", "Queue", "Tags", 1]))
+        self.assertIsNone(get_node_position(None, ["Resources"]))